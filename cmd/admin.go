@@ -0,0 +1,31 @@
+package main
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// adminMiddleware protege las rutas de administración con el token definido en ADMIN_TOKEN,
+// que debe enviarse como "Authorization: Bearer <token>". Si ADMIN_TOKEN no está definido
+// las rutas de administración quedan deshabilitadas.
+func adminMiddleware() gin.HandlerFunc {
+	token := os.Getenv("ADMIN_TOKEN")
+	return func(c *gin.Context) {
+		if token == "" {
+			abortWithError(c, http.StatusForbidden, "ADMIN_DISABLED")
+			return
+		}
+		provided, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(provided), []byte(token)) != 1 {
+			abortWithError(c, http.StatusUnauthorized, "ADMIN_UNAUTHORIZED")
+			return
+		}
+		// Las escrituras de administración se atribuyen al administrador en la auditoría
+		c.Set(actorContextKey, "admin")
+		c.Next()
+	}
+}
@@ -0,0 +1,185 @@
+package main
+
+import (
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+	"lab6/internal"
+)
+
+// Límites de entradas por página en /admin/audit.
+const (
+	defaultAuditLimit = 100
+	maxAuditLimit     = 500
+)
+
+// maxActorLength es la longitud máxima del actor informado en X-Actor.
+const maxActorLength = 100
+
+// Claves bajo las que se guardan el ID de solicitud y el actor en el contexto.
+const (
+	requestIDContextKey = "requestID"
+	actorContextKey     = "actor"
+)
+
+// validRequestID limita los ID de solicitud recibidos a valores seguros para registrar.
+var validRequestID = regexp.MustCompile(`^[A-Za-z0-9._-]{1,64}$`)
+
+// requestIDMiddleware asigna a cada solicitud un ID que se retorna en X-Request-ID y se
+// guarda en la auditoría. Si el cliente envía un X-Request-ID válido se reutiliza.
+func requestIDMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := requestID(c.GetHeader("X-Request-ID"))
+		c.Set(requestIDContextKey, id)
+		c.Header("X-Request-ID", id)
+		c.Next()
+	}
+}
+
+// requestID retorna el ID recibido del cliente si es válido o, si no, uno nuevo al azar.
+func requestID(received string) string {
+	if validRequestID.MatchString(received) {
+		return received
+	}
+	buf := make([]byte, 16)
+	rand.Read(buf)
+	return hex.EncodeToString(buf)
+}
+
+// truncateActor recorta el actor a maxActorLength caracteres.
+func truncateActor(actor string) string {
+	for utf8.RuneCountInString(actor) > maxActorLength {
+		_, size := utf8.DecodeLastRuneInString(actor)
+		actor = actor[:len(actor)-size]
+	}
+	return actor
+}
+
+// auditInfo identifica al actor y la solicitud de una escritura. Las rutas de administración
+// se atribuyen a "admin"; el resto usa el encabezado X-Actor o, si falta, la IP del cliente.
+func auditInfo(c *gin.Context) internal.AuditInfo {
+	actor := c.GetString(actorContextKey)
+	if actor == "" {
+		actor = strings.TrimSpace(c.GetHeader("X-Actor"))
+	}
+	if actor == "" {
+		actor = c.ClientIP()
+	}
+	info := internal.AuditInfo{Actor: truncateActor(actor), RequestID: c.GetString(requestIDContextKey)}
+	if key, ok := c.Get(idempotencyKeyContextKey); ok {
+		info.Idempotency = key.(*internal.IdempotencyKey)
+	}
+	return info
+}
+
+// getMatchHistory godoc
+// @Summary Obtiene el historial de un partido
+// @Description Retorna los cambios registrados del partido, del más antiguo al más reciente, con el actor,
+// @Description la operación, el ID de solicitud y el diff de cada cambio. Incluye partidos en la papelera o purgados.
+// @Tags Audit
+// @Produce json,text/csv,xml,application/x-yaml,application/x-ndjson
+// @Param id path int true "ID del partido"
+// @Param format query string false "Formato de la respuesta (alternativa a Accept)" Enums(json, csv, xml, yaml, ndjson)
+// @Success 200 {array} internal.AuditEntry
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /matches/{id}/history [get]
+func getMatchHistory(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		respondError(c, http.StatusBadRequest, "INVALID_ID")
+		return
+	}
+
+	entries, err := internal.GetMatchHistory(id)
+	if errors.Is(err, sql.ErrNoRows) {
+		respondError(c, http.StatusNotFound, "MATCH_NOT_FOUND")
+		return
+	}
+	if err != nil {
+		respondInternalError(c, err)
+		return
+	}
+	render(c, http.StatusOK, entries)
+}
+
+// getAuditLog godoc
+// @Summary Consulta la auditoría
+// @Description Retorna las entradas de auditoría que cumplen los filtros, de la más reciente a la más antigua.
+// @Tags Admin
+// @Produce json,text/csv,xml,application/x-yaml,application/x-ndjson
+// @Security AdminToken
+// @Param matchId query int false "ID del partido"
+// @Param actor query string false "Actor que realizó el cambio"
+// @Param operation query string false "Operación" Enums(create, update, delete, restore, increment, merge, purge, clock)
+// @Param requestId query string false "ID de la solicitud (X-Request-ID)"
+// @Param from query string false "Desde (RFC3339, inclusive)"
+// @Param to query string false "Hasta (RFC3339, exclusivo)"
+// @Param limit query int false "Máximo de entradas (1-500)"
+// @Param offset query int false "Entradas a omitir"
+// @Param format query string false "Formato de la respuesta (alternativa a Accept)" Enums(json, csv, xml, yaml, ndjson)
+// @Success 200 {array} internal.AuditEntry
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /admin/audit [get]
+func getAuditLog(c *gin.Context) {
+	filter := internal.AuditFilter{
+		Actor:     c.Query("actor"),
+		Operation: c.Query("operation"),
+		RequestID: c.Query("requestId"),
+		Limit:     defaultAuditLimit,
+	}
+
+	if value := c.Query("matchId"); value != "" {
+		id, err := strconv.Atoi(value)
+		if err != nil || id <= 0 {
+			respondError(c, http.StatusBadRequest, "INVALID_ID")
+			return
+		}
+		filter.MatchID = id
+	}
+	for param, target := range map[string]*time.Time{"from": &filter.From, "to": &filter.To} {
+		if value := c.Query(param); value != "" {
+			parsed, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				respondError(c, http.StatusBadRequest, "INVALID_TIMESTAMP", param)
+				return
+			}
+			*target = parsed
+		}
+	}
+	if value := c.Query("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit < 1 || limit > maxAuditLimit {
+			respondError(c, http.StatusBadRequest, "INVALID_LIMIT", maxAuditLimit)
+			return
+		}
+		filter.Limit = limit
+	}
+	if value := c.Query("offset"); value != "" {
+		offset, err := strconv.Atoi(value)
+		if err != nil || offset < 0 {
+			respondError(c, http.StatusBadRequest, "INVALID_OFFSET")
+			return
+		}
+		filter.Offset = offset
+	}
+
+	entries, err := internal.GetAuditLog(filter)
+	if err != nil {
+		respondInternalError(c, err)
+		return
+	}
+	render(c, http.StatusOK, entries)
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+)
+
+func TestGetAuditLogFilters(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	auditRows := func() *sqlmock.Rows {
+		return sqlmock.NewRows([]string{"id", "match_id", "actor", "request_id", "operation", "before", "after", "diff", "created_at"}).
+			AddRow(3, 7, "admin", "req-1", "delete", []byte(`{"deleted_at": null}`), []byte(`{"deleted_at": "2025-04-02T09:00:00Z"}`),
+				[]byte(`{"deleted_at":{"before":null,"after":"2025-04-02T09:00:00Z"}}`), time.Date(2025, 4, 2, 9, 0, 0, 0, time.UTC))
+	}
+
+	tests := []struct {
+		name       string
+		query      string
+		setup      func(mock sqlmock.Sqlmock)
+		wantStatus int
+		wantCode   string
+	}{
+		{
+			name:  "actor y operación",
+			query: "?actor=admin&operation=delete",
+			setup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`WHERE actor = \$1 AND operation = \$2 ORDER BY created_at DESC, id DESC LIMIT \$3 OFFSET \$4`).
+					WithArgs("admin", "delete", defaultAuditLimit, 0).WillReturnRows(auditRows())
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:  "intervalo de fechas con zona horaria",
+			query: "?from=2025-04-01T00:00:00%2B02:00&to=2025-05-01T00:00:00Z&limit=10&offset=20",
+			setup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`WHERE created_at >= \$1 AND created_at < \$2 ORDER BY created_at DESC, id DESC LIMIT \$3 OFFSET \$4`).
+					WithArgs(sqlmock.AnyArg(), time.Date(2025, 5, 1, 0, 0, 0, 0, time.UTC), 10, 20).WillReturnRows(auditRows())
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:  "partido y solicitud",
+			query: "?matchId=7&requestId=req-1",
+			setup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`WHERE match_id = \$1 AND request_id = \$2 ORDER`).
+					WithArgs(7, "req-1", defaultAuditLimit, 0).WillReturnRows(auditRows())
+			},
+			wantStatus: http.StatusOK,
+		},
+		{name: "fecha sin zona horaria", query: "?from=2025-04-01", wantStatus: http.StatusBadRequest, wantCode: "INVALID_TIMESTAMP"},
+		{name: "hasta inválido", query: "?to=mañana", wantStatus: http.StatusBadRequest, wantCode: "INVALID_TIMESTAMP"},
+		{name: "partido inválido", query: "?matchId=0", wantStatus: http.StatusBadRequest, wantCode: "INVALID_ID"},
+		{name: "límite fuera de rango", query: "?limit=501", wantStatus: http.StatusBadRequest, wantCode: "INVALID_LIMIT"},
+		{name: "desplazamiento negativo", query: "?offset=-1", wantStatus: http.StatusBadRequest, wantCode: "INVALID_OFFSET"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := mockDB(t)
+			if tt.setup != nil {
+				tt.setup(mock)
+			}
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodGet, "/api/admin/audit"+tt.query, nil)
+
+			getAuditLog(c)
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, se esperaba %d: %s", w.Code, tt.wantStatus, w.Body.String())
+			}
+			if tt.wantCode != "" {
+				var body map[string]any
+				if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil || body["code"] != tt.wantCode {
+					t.Errorf("cuerpo = %s, se esperaba el código %s", w.Body.String(), tt.wantCode)
+				}
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
@@ -0,0 +1,196 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"lab6/internal"
+)
+
+// maxBatchOperations limita la cantidad de operaciones por lote.
+const maxBatchOperations = 100
+
+// Modos de ejecución de un lote.
+const (
+	batchModeAtomic     = "atomic"
+	batchModeBestEffort = "bestEffort"
+)
+
+// batchOperationRequest es una operación tal como se recibe en el body de /batch.
+type batchOperationRequest struct {
+	Op        string `json:"op" example:"create"`
+	ID        int    `json:"id,omitempty"`
+	Version   int    `json:"version,omitempty"`
+	Stat      string `json:"stat,omitempty" example:"goals"`
+	HomeTeam  string `json:"homeTeam,omitempty" example:"Barcelona"`
+	AwayTeam  string `json:"awayTeam,omitempty" example:"Real Madrid"`
+	MatchDate string `json:"matchDate,omitempty" example:"2025-04-01"`
+}
+
+// batchRequest es el body aceptado por /batch.
+type batchRequest struct {
+	Mode       string                  `json:"mode" example:"atomic"`
+	Operations []batchOperationRequest `json:"operations"`
+}
+
+// batchOperationResult es el resultado de una operación del lote.
+type batchOperationResult struct {
+	Index   int    `json:"index"`
+	Op      string `json:"op"`
+	Status  int    `json:"status"`
+	ID      int    `json:"id,omitempty"`
+	Version int    `json:"version,omitempty"`
+	Code    string `json:"code,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+// batchResponse es la respuesta de /batch.
+type batchResponse struct {
+	Mode      string                 `json:"mode"`
+	Committed bool                   `json:"committed"`
+	Results   []batchOperationResult `json:"results"`
+}
+
+// toBatchOperation valida una operación recibida y la convierte al modelo interno.
+func toBatchOperation(req batchOperationRequest) (internal.BatchOperation, error) {
+	op := internal.BatchOperation{Op: req.Op, Stat: req.Stat, ID: req.ID, Version: req.Version}
+
+	switch req.Op {
+	case internal.BatchCreate, internal.BatchUpdate:
+		parsedDate, err := time.Parse("2006-01-02", req.MatchDate)
+		if err != nil {
+			return op, newAPIError("INVALID_DATE")
+		}
+		op.Match = internal.Match{HomeTeam: req.HomeTeam, AwayTeam: req.AwayTeam, MatchDate: parsedDate, DateOnly: true}
+	case internal.BatchDelete:
+	case internal.BatchIncrement:
+		switch req.Stat {
+		case internal.StatGoals, internal.StatYellowCards, internal.StatRedCards, internal.StatExtraTime:
+		default:
+			return op, newAPIError("INVALID_STAT", req.Stat)
+		}
+	default:
+		return op, newAPIError("INVALID_OPERATION", req.Op)
+	}
+
+	// Las operaciones sobre partidos existentes requieren ID y versión, igual que If-Match
+	if req.Op != internal.BatchCreate {
+		if req.ID <= 0 {
+			return op, newAPIError("INVALID_ID")
+		}
+		if req.Version <= 0 {
+			return op, newAPIError("VERSION_REQUIRED")
+		}
+	}
+	return op, nil
+}
+
+// batchResultStatus retorna el código HTTP que describe el resultado de una operación.
+func batchResultStatus(op string, err error) int {
+	switch {
+	case err == nil && op == internal.BatchCreate:
+		return http.StatusCreated
+	case err == nil:
+		return http.StatusOK
+	case errors.Is(err, internal.ErrBatchSkipped):
+		return http.StatusFailedDependency
+	default:
+		return writeErrorStatus(err)
+	}
+}
+
+// runBatch godoc
+// @Summary Ejecuta un lote de operaciones
+// @Description Ejecuta en orden y dentro de una única transacción una lista de operaciones create, update, delete e increment.
+// @Description En modo "atomic" (por defecto) cualquier falla revierte todo el lote; en modo "bestEffort" solo se revierte la operación fallida.
+// @Description Las operaciones distintas de create requieren "id" y "version" del partido.
+// @Tags Batch
+// @Accept json
+// @Produce json
+// @Param Idempotency-Key header string false "Clave para reintentar la solicitud sin repetir la escritura"
+// @Param batch body batchRequest true "Operaciones del lote"
+// @Success 200 {object} batchResponse "Lote confirmado"
+// @Failure 400 {object} map[string]interface{}
+// @Failure 422 {object} batchResponse "Lote revertido"
+// @Failure 500 {object} map[string]string
+// @Router /batch [post]
+func runBatch(c *gin.Context) {
+	var requestBody batchRequest
+	if err := c.ShouldBindJSON(&requestBody); err != nil {
+		respondError(c, http.StatusBadRequest, "INVALID_BODY")
+		return
+	}
+
+	if requestBody.Mode == "" {
+		requestBody.Mode = batchModeAtomic
+	}
+	if requestBody.Mode != batchModeAtomic && requestBody.Mode != batchModeBestEffort {
+		respondError(c, http.StatusBadRequest, "BATCH_INVALID_MODE")
+		return
+	}
+	if len(requestBody.Operations) == 0 || len(requestBody.Operations) > maxBatchOperations {
+		respondError(c, http.StatusBadRequest, "BATCH_INVALID_SIZE", maxBatchOperations)
+		return
+	}
+
+	// Se validan todas las operaciones antes de abrir la transacción
+	ops := make([]internal.BatchOperation, len(requestBody.Operations))
+	var invalid []batchOperationResult
+	for i, req := range requestBody.Operations {
+		op, err := toBatchOperation(req)
+		if err != nil {
+			code, args := errorCode(err)
+			invalid = append(invalid, batchOperationResult{Index: i, Op: req.Op, Status: http.StatusBadRequest, ID: req.ID, Code: code, Error: translate(c, code, args...)})
+			continue
+		}
+		ops[i] = op
+	}
+	if len(invalid) > 0 {
+		body := errorBody(c, "BATCH_INVALID_OPERATIONS")
+		body["results"] = invalid
+		c.JSON(http.StatusBadRequest, body)
+		return
+	}
+
+	results, committed, err := internal.RunBatch(ops, requestBody.Mode == batchModeAtomic, auditInfo(c))
+	if err != nil {
+		respondInternalError(c, err)
+		return
+	}
+
+	response := batchResponse{Mode: requestBody.Mode, Committed: committed, Results: make([]batchOperationResult, len(results))}
+	for i, result := range results {
+		item := batchOperationResult{
+			Index:   i,
+			Op:      ops[i].Op,
+			Status:  batchResultStatus(ops[i].Op, result.Err),
+			ID:      result.ID,
+			Version: result.Version,
+		}
+		switch {
+		case result.Err != nil:
+			code, args := errorCode(result.Err)
+			item.Code = code
+			item.Error = translate(c, code, args...)
+			item.Version = 0
+		case !committed:
+			// La operación se aplicó pero el lote se revirtió, por lo que su efecto se descarta
+			item.Status = http.StatusFailedDependency
+			item.Code = "BATCH_ROLLED_BACK"
+			item.Error = translate(c, item.Code)
+			item.Version = 0
+			if ops[i].Op == internal.BatchCreate {
+				item.ID = 0
+			}
+		}
+		response.Results[i] = item
+	}
+
+	if !committed {
+		c.JSON(http.StatusUnprocessableEntity, response)
+		return
+	}
+	c.JSON(http.StatusOK, response)
+}
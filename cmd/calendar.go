@@ -0,0 +1,146 @@
+package main
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	// La imagen final no incluye la base de zonas horarias, por eso se embebe en el binario
+	_ "time/tzdata"
+
+	"github.com/gin-gonic/gin"
+	"lab6/internal"
+)
+
+// calendarDay agrupa los partidos que comienzan en un mismo día local.
+type calendarDay struct {
+	Date    string    `json:"date" example:"2025-04-01"`
+	Count   int       `json:"count"`
+	Matches []matchV2 `json:"matches"`
+}
+
+// calendarResponse es la respuesta de /calendar.
+type calendarResponse struct {
+	TimeZone string        `json:"timeZone" example:"Europe/Madrid"`
+	From     string        `json:"from" example:"2025-04-01"`
+	To       string        `json:"to" example:"2025-04-30"`
+	Total    int           `json:"total"`
+	Days     []calendarDay `json:"days"`
+}
+
+// parseISOWeek retorna el lunes (a medianoche en loc) de una semana ISO 8601 con formato "2025-W14".
+func parseISOWeek(value string, loc *time.Location) (time.Time, bool) {
+	yearText, weekText, ok := strings.Cut(strings.ToUpper(value), "-W")
+	if !ok {
+		return time.Time{}, false
+	}
+	year, err := strconv.Atoi(yearText)
+	if err != nil || len(yearText) != 4 {
+		return time.Time{}, false
+	}
+	week, err := strconv.Atoi(weekText)
+	if err != nil || len(weekText) != 2 || week < 1 || week > 53 {
+		return time.Time{}, false
+	}
+
+	// El 4 de enero siempre pertenece a la semana 1
+	jan4 := time.Date(year, time.January, 4, 0, 0, 0, 0, loc)
+	monday := jan4.AddDate(0, 0, -((int(jan4.Weekday())+6)%7)+(week-1)*7)
+	if y, w := monday.ISOWeek(); y != year || w != week {
+		return time.Time{}, false
+	}
+	return monday, true
+}
+
+// getCalendar godoc
+// @Summary Calendario de partidos por mes o semana
+// @Description Retorna los partidos del mes (?month=2025-04) o de la semana ISO (?week=2025-W14) agrupados por día,
+// @Description con la cantidad de partidos de cada día. Los días se calculan en la zona horaria indicada en ?tz=
+// @Description (por defecto UTC), de modo que un partido nocturno aparece en la fecha local correcta.
+// @Description Los partidos registrados sin hora (como los de v1) conservan su fecha en cualquier zona horaria; un partido
+// @Description de v2 a medianoche UTC tiene hora y se muestra en la fecha local.
+// @Tags Calendar
+// @Produce json,text/csv,xml,application/x-yaml,application/x-ndjson
+// @Param month query string false "Mes con formato YYYY-MM"
+// @Param week query string false "Semana ISO con formato YYYY-Www"
+// @Param tz query string false "Zona horaria IANA, por ejemplo Europe/Madrid"
+// @Param format query string false "Formato de la respuesta (alternativa a Accept)" Enums(json, csv, xml, yaml, ndjson)
+// @Success 200 {object} calendarResponse
+// @Failure 400 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /calendar [get]
+func getCalendar(c *gin.Context) {
+	loc := time.UTC
+	if tz := c.Query("tz"); tz != "" {
+		var err error
+		if loc, err = time.LoadLocation(tz); err != nil {
+			respondError(c, http.StatusBadRequest, "INVALID_TIME_ZONE", tz)
+			return
+		}
+	}
+
+	month, week := c.Query("month"), c.Query("week")
+	var from, to time.Time
+	switch {
+	case month != "" && week != "", month == "" && week == "":
+		respondError(c, http.StatusBadRequest, "CALENDAR_RANGE_REQUIRED")
+		return
+	case month != "":
+		start, err := time.ParseInLocation("2006-01", month, loc)
+		if err != nil {
+			respondError(c, http.StatusBadRequest, "INVALID_MONTH")
+			return
+		}
+		from, to = start, start.AddDate(0, 1, 0)
+	default:
+		start, ok := parseISOWeek(week, loc)
+		if !ok {
+			respondError(c, http.StatusBadRequest, "INVALID_WEEK")
+			return
+		}
+		from, to = start, start.AddDate(0, 0, 7)
+	}
+
+	// Los partidos sin hora no dependen de la zona horaria, así que se consulta un día más a cada
+	// lado y newCalendarResponse descarta los que quedan fuera del rango
+	matches, err := internal.GetMatchesBetween(from.AddDate(0, 0, -1), to.AddDate(0, 0, 1))
+	if err != nil {
+		respondInternalError(c, err)
+		return
+	}
+	render(c, http.StatusOK, newCalendarResponse(from, to, loc, matches))
+}
+
+// newCalendarResponse agrupa por día local los partidos del rango [from, to), generando también
+// los días sin partidos.
+func newCalendarResponse(from, to time.Time, loc *time.Location, matches []internal.Match) calendarResponse {
+	response := calendarResponse{
+		TimeZone: loc.String(),
+		From:     from.Format(time.DateOnly),
+		To:       to.AddDate(0, 0, -1).Format(time.DateOnly),
+		Days:     []calendarDay{},
+	}
+	index := map[string]int{}
+	for day := from; day.Before(to); day = day.AddDate(0, 0, 1) {
+		date := day.Format(time.DateOnly)
+		index[date] = len(response.Days)
+		response.Days = append(response.Days, calendarDay{Date: date, Matches: []matchV2{}})
+	}
+	for _, m := range matches {
+		// Los partidos sin hora se guardan a medianoche UTC: su fecha es la misma en cualquier zona horaria
+		kickoff := m.MatchDate.In(loc)
+		if m.DateOnly {
+			kickoff = m.MatchDate.UTC()
+		}
+		i, ok := index[kickoff.Format(time.DateOnly)]
+		if !ok {
+			continue
+		}
+		match := toMatchV2(m)
+		match.Kickoff = kickoff.Format(time.RFC3339)
+		response.Days[i].Matches = append(response.Days[i].Matches, match)
+		response.Days[i].Count++
+		response.Total++
+	}
+	return response
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"lab6/internal"
+)
+
+func TestNewCalendarResponse(t *testing.T) {
+	madrid, _ := time.LoadLocation("Europe/Madrid")
+	mexico, _ := time.LoadLocation("America/Mexico_City")
+
+	tests := []struct {
+		name        string
+		loc         *time.Location
+		matchDate   time.Time
+		dateOnly    bool
+		wantDate    string
+		wantKickoff string
+	}{
+		{
+			name:        "partido nocturno en UTC pasa al día siguiente en Madrid",
+			loc:         madrid,
+			matchDate:   time.Date(2025, 4, 2, 22, 30, 0, 0, time.UTC),
+			wantDate:    "2025-04-03",
+			wantKickoff: "2025-04-03T00:30:00+02:00",
+		},
+		{
+			name:        "partido con hora en una zona al oeste de UTC",
+			loc:         mexico,
+			matchDate:   time.Date(2025, 4, 3, 2, 0, 0, 0, time.UTC),
+			wantDate:    "2025-04-02",
+			wantKickoff: "2025-04-02T20:00:00-06:00",
+		},
+		{
+			name:        "partido sin hora conserva su fecha al oeste de UTC",
+			loc:         mexico,
+			matchDate:   time.Date(2025, 4, 3, 0, 0, 0, 0, time.UTC),
+			dateOnly:    true,
+			wantDate:    "2025-04-03",
+			wantKickoff: "2025-04-03T00:00:00Z",
+		},
+		{
+			name:        "partido sin hora el primer día del rango al oeste de UTC",
+			loc:         mexico,
+			matchDate:   time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC),
+			dateOnly:    true,
+			wantDate:    "2025-04-01",
+			wantKickoff: "2025-04-01T00:00:00Z",
+		},
+		{
+			name:      "partido sin hora fuera del rango",
+			loc:       mexico,
+			matchDate: time.Date(2025, 5, 1, 0, 0, 0, 0, time.UTC),
+			dateOnly:  true,
+		},
+		{
+			name:        "partido de v2 a medianoche UTC tiene hora y sigue la zona horaria",
+			loc:         mexico,
+			matchDate:   time.Date(2025, 4, 3, 0, 0, 0, 0, time.UTC),
+			wantDate:    "2025-04-02",
+			wantKickoff: "2025-04-02T18:00:00-06:00",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			from := time.Date(2025, 4, 1, 0, 0, 0, 0, tt.loc)
+			to := from.AddDate(0, 1, 0)
+			match := internal.Match{ID: 1, HomeTeam: "Sevilla", AwayTeam: "Betis", MatchDate: tt.matchDate, DateOnly: tt.dateOnly}
+
+			response := newCalendarResponse(from, to, tt.loc, []internal.Match{match})
+
+			if len(response.Days) != 30 {
+				t.Fatalf("días = %d, se esperaban 30", len(response.Days))
+			}
+			var found []string
+			for _, day := range response.Days {
+				for _, m := range day.Matches {
+					found = append(found, day.Date)
+					if m.Kickoff != tt.wantKickoff {
+						t.Errorf("kickoff = %s, se esperaba %s", m.Kickoff, tt.wantKickoff)
+					}
+				}
+				if day.Count != len(day.Matches) {
+					t.Errorf("count del día %s = %d, con %d partidos", day.Date, day.Count, len(day.Matches))
+				}
+			}
+			switch {
+			case tt.wantDate == "" && len(found) != 0:
+				t.Errorf("el partido quedó en %v, se esperaba fuera del calendario", found)
+			case tt.wantDate != "" && (len(found) != 1 || found[0] != tt.wantDate):
+				t.Errorf("el partido quedó en %v, se esperaba %s", found, tt.wantDate)
+			}
+			if response.Total != len(found) {
+				t.Errorf("total = %d, se esperaba %d", response.Total, len(found))
+			}
+		})
+	}
+}
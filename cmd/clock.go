@@ -0,0 +1,132 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"lab6/internal"
+)
+
+// Acciones aceptadas por POST /matches/{id}/clock.
+const (
+	clockActionStart    = "start"
+	clockActionEnd      = "end"
+	clockActionStoppage = "stoppage"
+)
+
+// clockInput es el body de una acción sobre el reloj del partido.
+type clockInput struct {
+	// Action es start (inicia Period), end (termina el periodo en curso) o stoppage (anuncia Minutes de descuento).
+	Action  string `json:"action" binding:"required" enums:"start,end,stoppage" example:"start"`
+	Period  string `json:"period,omitempty" enums:"first_half,second_half,extra_time_first,extra_time_second" example:"first_half"`
+	Minutes int    `json:"minutes,omitempty" example:"3"`
+}
+
+// getMatchClock godoc
+// @Summary Obtiene el reloj de un partido
+// @Description Retorna el periodo en curso y el minuto actual calculado por el servidor (por ejemplo "45+2'"),
+// @Description junto con el descuento anunciado, el inicio del periodo y la hora del servidor para que el cliente
+// @Description pueda avanzar el reloj localmente. Fuera de juego display es "HT" en el descanso y "FT" al finalizar.
+// @Tags Clock
+// @Produce json,text/csv,xml,application/x-yaml,application/x-ndjson
+// @Param id path int true "ID del partido"
+// @Param format query string false "Formato de la respuesta (alternativa a Accept)" Enums(json, csv, xml, yaml, ndjson)
+// @Success 200 {object} internal.MatchClock
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /matches/{id}/clock [get]
+func getMatchClock(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		respondError(c, http.StatusBadRequest, "INVALID_ID")
+		return
+	}
+
+	clock, err := internal.GetMatchClock(id)
+	if errors.Is(err, sql.ErrNoRows) {
+		respondError(c, http.StatusNotFound, "MATCH_NOT_FOUND")
+		return
+	}
+	if err != nil {
+		respondInternalError(c, err)
+		return
+	}
+	render(c, http.StatusOK, clock)
+}
+
+// updateMatchClock godoc
+// @Summary Controla el reloj de un partido
+// @Description Registra un evento del reloj: "start" inicia el periodo indicado, que debe ser el siguiente
+// @Description (first_half es el saque inicial; extra_time_first marca el tiempo extra), "end" termina el periodo en curso
+// @Description y "stoppage" anuncia los minutos de descuento (1-30). El pitazo final se registra con POST /v2/matches/{id}/finish.
+// @Description Los goles y tarjetas registrados con el reloj en marcha quedan con su minuto en el log de eventos.
+// @Tags Clock
+// @Accept json
+// @Produce json
+// @Param id path int true "ID del partido"
+// @Param If-Match header string true "ETag obtenido al consultar el partido"
+// @Param Idempotency-Key header string false "Clave para reintentar la solicitud sin repetir la escritura"
+// @Param clock body clockInput true "Acción sobre el reloj"
+// @Success 200 {object} internal.MatchClock
+// @Header 200 {string} ETag "Nueva versión del partido"
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string "El partido finalizó, el reloj no está en el estado requerido o el periodo no es el siguiente"
+// @Failure 412 {object} internal.Match "Versión desactualizada, se retorna la actual"
+// @Failure 428 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /matches/{id}/clock [post]
+func updateMatchClock(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		respondError(c, http.StatusBadRequest, "INVALID_ID")
+		return
+	}
+	version, ok := requireIfMatch(c)
+	if !ok {
+		return
+	}
+	var input clockInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		respondError(c, http.StatusBadRequest, "INVALID_BODY")
+		return
+	}
+
+	info := auditInfo(c)
+	switch input.Action {
+	case clockActionStart:
+		err = internal.StartPeriod(id, version, input.Period, info)
+	case clockActionEnd:
+		err = internal.EndPeriod(id, version, info)
+	case clockActionStoppage:
+		if input.Minutes < 1 || input.Minutes > internal.MaxStoppageMinutes {
+			respondError(c, http.StatusBadRequest, "INVALID_STOPPAGE", internal.MaxStoppageMinutes)
+			return
+		}
+		err = internal.AnnounceStoppage(id, version, input.Minutes, info)
+	default:
+		respondError(c, http.StatusBadRequest, "INVALID_CLOCK_ACTION", input.Action)
+		return
+	}
+	if err != nil {
+		respondWriteError(c, id, err)
+		return
+	}
+
+	match, err := internal.GetMatchByID(id)
+	if err != nil {
+		respondInternalError(c, err)
+		return
+	}
+	clock, err := internal.GetMatchClock(id)
+	if err != nil {
+		respondInternalError(c, err)
+		return
+	}
+	c.Header("ETag", matchETag(match.Version))
+	c.JSON(http.StatusOK, clock)
+}
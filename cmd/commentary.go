@@ -0,0 +1,277 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+	"lab6/internal"
+)
+
+const (
+	// maxCommentaryLength limita la cantidad de caracteres de un comentario.
+	maxCommentaryLength = 2000
+
+	// maxCommentaryMinute es el último minuto reglamentario de un partido con tiempo extra.
+	maxCommentaryMinute = 120
+
+	// defaultCommentaryLimit y maxCommentaryLimit acotan el listado de comentarios.
+	defaultCommentaryLimit = 20
+	maxCommentaryLimit     = 200
+)
+
+// commentaryInput es el body aceptado para publicar un comentario.
+type commentaryInput struct {
+	Text string `json:"text" example:"Disparo al palo desde fuera del área"`
+	// Minute es opcional: si se omite se usa el minuto del evento enlazado o el del reloj del partido.
+	Minute   *int   `json:"minute,omitempty" example:"45"`
+	Stoppage int    `json:"stoppage,omitempty" example:"2"`
+	EventID  *int64 `json:"eventId,omitempty"`
+	Pinned   bool   `json:"pinned,omitempty"`
+}
+
+// commentaryPatchInput es el body aceptado para editar un comentario; los campos omitidos no cambian.
+type commentaryPatchInput struct {
+	Text     *string `json:"text,omitempty"`
+	Minute   *int    `json:"minute,omitempty"`
+	Stoppage *int    `json:"stoppage,omitempty"`
+	EventID  *int64  `json:"eventId,omitempty"`
+	Pinned   *bool   `json:"pinned,omitempty"`
+}
+
+// validCommentaryText normaliza el texto de un comentario y verifica su longitud.
+func validCommentaryText(text string) (string, bool) {
+	text = strings.TrimSpace(text)
+	return text, text != "" && utf8.RuneCountInString(text) <= maxCommentaryLength
+}
+
+// validCommentaryMinute verifica el minuto y el descuento de un comentario.
+func validCommentaryMinute(minute *int, stoppage int) bool {
+	if minute == nil {
+		return stoppage == 0
+	}
+	return *minute >= 0 && *minute <= maxCommentaryMinute && stoppage >= 0 && stoppage <= internal.MaxStoppageMinutes
+}
+
+// respondCommentaryError traduce el error de una escritura de comentarios a la respuesta HTTP.
+func respondCommentaryError(c *gin.Context, err error, notFound string) {
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		respondError(c, http.StatusNotFound, notFound)
+	case errors.Is(err, internal.ErrCommentaryEvent):
+		respondError(c, http.StatusBadRequest, "COMMENTARY_INVALID_EVENT")
+	default:
+		respondInternalError(c, err)
+	}
+}
+
+// getCommentary godoc
+// @Summary Obtiene los comentarios de un partido
+// @Description Retorna los comentarios minuto a minuto del partido, del más reciente al más antiguo.
+// @Description El total de comentarios que cumplen el filtro se informa en X-Total-Count.
+// @Tags Commentary
+// @Produce json,text/csv,xml,application/x-yaml,application/x-ndjson
+// @Param id path int true "ID del partido"
+// @Param pinned query bool false "Solo los comentarios destacados"
+// @Param limit query int false "Máximo de comentarios (1-200, por defecto 20)"
+// @Param offset query int false "Comentarios a omitir"
+// @Param format query string false "Formato de la respuesta (alternativa a Accept)" Enums(json, csv, xml, yaml, ndjson)
+// @Success 200 {array} internal.CommentaryEntry
+// @Header 200 {integer} X-Total-Count "Cantidad total de comentarios"
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /matches/{id}/commentary [get]
+func getCommentary(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		respondError(c, http.StatusBadRequest, "INVALID_ID")
+		return
+	}
+
+	filter := internal.CommentaryFilter{Limit: defaultCommentaryLimit}
+	if value := c.Query("pinned"); value != "" {
+		if filter.Pinned, err = strconv.ParseBool(value); err != nil {
+			respondError(c, http.StatusBadRequest, "INVALID_BOOLEAN", "pinned")
+			return
+		}
+	}
+	if value := c.Query("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit < 1 || limit > maxCommentaryLimit {
+			respondError(c, http.StatusBadRequest, "INVALID_LIMIT", maxCommentaryLimit)
+			return
+		}
+		filter.Limit = limit
+	}
+	if value := c.Query("offset"); value != "" {
+		offset, err := strconv.Atoi(value)
+		if err != nil || offset < 0 {
+			respondError(c, http.StatusBadRequest, "INVALID_OFFSET")
+			return
+		}
+		filter.Offset = offset
+	}
+
+	entries, total, err := internal.GetCommentary(id, filter)
+	if errors.Is(err, sql.ErrNoRows) {
+		respondError(c, http.StatusNotFound, "MATCH_NOT_FOUND")
+		return
+	}
+	if err != nil {
+		respondInternalError(c, err)
+		return
+	}
+	c.Header("X-Total-Count", strconv.Itoa(total))
+	render(c, http.StatusOK, entries)
+}
+
+// createCommentary godoc
+// @Summary Publica un comentario
+// @Description Agrega un comentario al partido. Si no se indica minute se usa el minuto del evento enlazado
+// @Description en eventId o, si no hay evento, el minuto actual del reloj del partido. Los suscriptores de
+// @Description /ws reciben el comentario en un mensaje {"type": "commentary"}.
+// @Tags Commentary
+// @Accept json
+// @Produce json
+// @Param id path int true "ID del partido"
+// @Param X-Actor header string false "Autor del comentario (por defecto la IP del cliente)"
+// @Param Idempotency-Key header string false "Clave para reintentar la solicitud sin repetir la escritura"
+// @Param commentary body commentaryInput true "Comentario"
+// @Success 201 {object} internal.CommentaryEntry
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /matches/{id}/commentary [post]
+func createCommentary(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		respondError(c, http.StatusBadRequest, "INVALID_ID")
+		return
+	}
+	var input commentaryInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		respondError(c, http.StatusBadRequest, "INVALID_BODY")
+		return
+	}
+	text, ok := validCommentaryText(input.Text)
+	if !ok {
+		respondError(c, http.StatusBadRequest, "COMMENTARY_INVALID_TEXT", maxCommentaryLength)
+		return
+	}
+	if !validCommentaryMinute(input.Minute, input.Stoppage) {
+		respondError(c, http.StatusBadRequest, "COMMENTARY_INVALID_MINUTE", maxCommentaryMinute, internal.MaxStoppageMinutes)
+		return
+	}
+
+	info := auditInfo(c)
+	entry, err := internal.CreateCommentary(internal.CommentaryEntry{
+		MatchID:  id,
+		Minute:   input.Minute,
+		Stoppage: input.Stoppage,
+		Text:     text,
+		EventID:  input.EventID,
+		Pinned:   input.Pinned,
+		Author:   info.Actor,
+	}, info)
+	if err != nil {
+		respondCommentaryError(c, err, "MATCH_NOT_FOUND")
+		return
+	}
+	c.JSON(http.StatusCreated, entry)
+}
+
+// updateCommentary godoc
+// @Summary Edita un comentario
+// @Description Modifica el texto, el minuto, el evento enlazado o el destacado de un comentario. Los campos
+// @Description omitidos no cambian. Los suscriptores de /ws reciben el comentario editado con su nueva revisión.
+// @Tags Commentary
+// @Accept json
+// @Produce json
+// @Param id path int true "ID del partido"
+// @Param entryId path int true "ID del comentario"
+// @Param Idempotency-Key header string false "Clave para reintentar la solicitud sin repetir la escritura"
+// @Param commentary body commentaryPatchInput true "Cambios del comentario"
+// @Success 200 {object} internal.CommentaryEntry
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /matches/{id}/commentary/{entryId} [patch]
+func updateCommentary(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		respondError(c, http.StatusBadRequest, "INVALID_ID")
+		return
+	}
+	entryID, err := strconv.Atoi(c.Param("entryId"))
+	if err != nil {
+		respondError(c, http.StatusBadRequest, "INVALID_ID")
+		return
+	}
+	var input commentaryPatchInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		respondError(c, http.StatusBadRequest, "INVALID_BODY")
+		return
+	}
+	if input.Text != nil {
+		text, ok := validCommentaryText(*input.Text)
+		if !ok {
+			respondError(c, http.StatusBadRequest, "COMMENTARY_INVALID_TEXT", maxCommentaryLength)
+			return
+		}
+		input.Text = &text
+	}
+	if (input.Minute != nil && !validCommentaryMinute(input.Minute, 0)) ||
+		(input.Stoppage != nil && (*input.Stoppage < 0 || *input.Stoppage > internal.MaxStoppageMinutes)) {
+		respondError(c, http.StatusBadRequest, "COMMENTARY_INVALID_MINUTE", maxCommentaryMinute, internal.MaxStoppageMinutes)
+		return
+	}
+
+	entry, err := internal.UpdateCommentary(id, entryID, internal.CommentaryPatch{
+		Text:     input.Text,
+		Minute:   input.Minute,
+		Stoppage: input.Stoppage,
+		EventID:  input.EventID,
+		Pinned:   input.Pinned,
+	}, auditInfo(c))
+	if err != nil {
+		respondCommentaryError(c, err, "COMMENTARY_NOT_FOUND")
+		return
+	}
+	c.JSON(http.StatusOK, entry)
+}
+
+// deleteCommentary godoc
+// @Summary Elimina un comentario
+// @Description Quita el comentario del listado del partido. Los suscriptores de /ws reciben el comentario con deletedAt.
+// @Tags Commentary
+// @Param id path int true "ID del partido"
+// @Param entryId path int true "ID del comentario"
+// @Param Idempotency-Key header string false "Clave para reintentar la solicitud sin repetir la escritura"
+// @Success 204
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /matches/{id}/commentary/{entryId} [delete]
+func deleteCommentary(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		respondError(c, http.StatusBadRequest, "INVALID_ID")
+		return
+	}
+	entryID, err := strconv.Atoi(c.Param("entryId"))
+	if err != nil {
+		respondError(c, http.StatusBadRequest, "INVALID_ID")
+		return
+	}
+
+	if err := internal.DeleteCommentary(id, entryID, auditInfo(c)); err != nil {
+		respondCommentaryError(c, err, "COMMENTARY_NOT_FOUND")
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"lab6/internal"
+)
+
+// getCompetitions godoc
+// @Summary Obtiene todas las competiciones
+// @Description Retorna las competiciones registradas. Los partidos pertenecen a La Liga (ID 1) salvo que se indique otra.
+// @Tags Competitions
+// @Produce json,text/csv,xml,application/x-yaml,application/x-ndjson
+// @Param format query string false "Formato de la respuesta (alternativa a Accept)" Enums(json, csv, xml, yaml, ndjson)
+// @Success 200 {array} internal.Competition
+// @Failure 500 {object} map[string]string
+// @Router /competitions [get]
+func getCompetitions(c *gin.Context) {
+	competitions, err := internal.GetCompetitions()
+	if err != nil {
+		respondInternalError(c, err)
+		return
+	}
+	render(c, http.StatusOK, competitions)
+}
+
+// getCompetition godoc
+// @Summary Obtiene una competición por ID
+// @Description Retorna el nombre y el país de la competición.
+// @Tags Competitions
+// @Produce json,text/csv,xml,application/x-yaml,application/x-ndjson
+// @Param id path int true "ID de la competición"
+// @Param format query string false "Formato de la respuesta (alternativa a Accept)" Enums(json, csv, xml, yaml, ndjson)
+// @Success 200 {object} internal.Competition
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /competitions/{id} [get]
+func getCompetition(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		respondError(c, http.StatusBadRequest, "INVALID_ID")
+		return
+	}
+
+	competition, err := internal.GetCompetitionByID(id)
+	if errors.Is(err, sql.ErrNoRows) {
+		respondError(c, http.StatusNotFound, "COMPETITION_NOT_FOUND")
+		return
+	}
+	if err != nil {
+		respondInternalError(c, err)
+		return
+	}
+	render(c, http.StatusOK, competition)
+}
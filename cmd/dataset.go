@@ -0,0 +1,309 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"log"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"lab6/internal"
+)
+
+const (
+	// datasetFormat identifica los archivos generados por /admin/export.
+	datasetFormat = "laligatracker-dataset"
+
+	// datasetVersion es la versión del formato del respaldo. Debe incrementarse cuando
+	// cambien las tablas o columnas exportadas de forma incompatible.
+	datasetVersion = 1
+
+	// datasetManifestFile es el nombre del manifiesto dentro del archivo.
+	datasetManifestFile = "manifest.json"
+
+	// maxDatasetSize limita el tamaño del respaldo recibido por /admin/import.
+	maxDatasetSize = 100 << 20
+
+	// maxDatasetUncompressedSize limita el tamaño descomprimido de todos los archivos del respaldo,
+	// para que un ZIP pequeño no pueda agotar la memoria al expandirse.
+	maxDatasetUncompressedSize = 1 << 30
+)
+
+// datasetManifestTable describe un archivo de tabla del respaldo.
+type datasetManifestTable struct {
+	Name   string `json:"name" example:"matches"`
+	File   string `json:"file" example:"matches.json"`
+	Rows   int    `json:"rows"`
+	SHA256 string `json:"sha256"`
+}
+
+// datasetManifest es el contenido de manifest.json.
+type datasetManifest struct {
+	Format    string                 `json:"format" example:"laligatracker-dataset"`
+	Version   int                    `json:"version" example:"1"`
+	CreatedAt time.Time              `json:"createdAt"`
+	Tables    []datasetManifestTable `json:"tables"`
+}
+
+// datasetImportResponse es la respuesta de /admin/import.
+type datasetImportResponse struct {
+	Version  int                           `json:"version" example:"1"`
+	Conflict string                        `json:"conflict" example:"fail"`
+	Tables   []internal.DatasetTableResult `json:"tables"`
+}
+
+// writeDatasetTable escribe los registros de una tabla como un arreglo JSON con un registro por línea
+// y retorna su descripción para el manifiesto.
+func writeDatasetTable(archive *zip.Writer, table string, rows iter.Seq2[json.RawMessage, error]) (datasetManifestTable, error) {
+	entry := datasetManifestTable{Name: table, File: table + ".json"}
+	file, err := archive.Create(entry.File)
+	if err != nil {
+		return entry, err
+	}
+	hash := sha256.New()
+	w := io.MultiWriter(file, hash)
+
+	io.WriteString(w, "[")
+	for row, err := range rows {
+		if err != nil {
+			return entry, err
+		}
+		if entry.Rows > 0 {
+			io.WriteString(w, ",")
+		}
+		io.WriteString(w, "\n")
+		if _, err := w.Write(row); err != nil {
+			return entry, err
+		}
+		entry.Rows++
+	}
+	if _, err := io.WriteString(w, "\n]\n"); err != nil {
+		return entry, err
+	}
+	entry.SHA256 = hex.EncodeToString(hash.Sum(nil))
+	return entry, nil
+}
+
+// exportDataset godoc
+// @Summary Exporta todos los datos
+// @Description Genera un archivo ZIP con un JSON por tabla (equipos, alias, partidos, eventos y auditoría) y un
+// @Description manifest.json con la versión del formato, la cantidad de registros y el SHA-256 de cada tabla.
+// @Description Todas las tablas se leen en el mismo instante y el archivo se transmite a medida que se genera.
+// @Tags Admin
+// @Produce application/zip
+// @Security AdminToken
+// @Success 200 {file} file "Respaldo en formato ZIP"
+// @Failure 401 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /admin/export [get]
+func exportDataset(c *gin.Context) {
+	manifest := datasetManifest{Format: datasetFormat, Version: datasetVersion, CreatedAt: time.Now().UTC()}
+	archive := zip.NewWriter(c.Writer)
+	filename := fmt.Sprintf("laligatracker-%s.zip", manifest.CreatedAt.Format("20060102T150405Z"))
+
+	err := internal.ExportDataset(func(table string, rows iter.Seq2[json.RawMessage, error]) error {
+		c.Header("Content-Type", "application/zip")
+		c.Header("Content-Disposition", `attachment; filename="`+filename+`"`)
+		entry, err := writeDatasetTable(archive, table, rows)
+		if err != nil {
+			return err
+		}
+		manifest.Tables = append(manifest.Tables, entry)
+		return nil
+	})
+	if err == nil {
+		var file io.Writer
+		if file, err = archive.Create(datasetManifestFile); err == nil {
+			encoder := json.NewEncoder(file)
+			encoder.SetIndent("", "  ")
+			if err = encoder.Encode(manifest); err == nil {
+				err = archive.Close()
+			}
+		}
+	}
+	if err != nil {
+		// Si el archivo ya empezó a transmitirse no se puede cambiar el estado; se deja sin
+		// el directorio central del ZIP para que el cliente lo detecte como incompleto
+		if c.Writer.Written() {
+			log.Printf("error al exportar los datos: %v", err)
+			return
+		}
+		c.Writer.Header().Del("Content-Type")
+		c.Writer.Header().Del("Content-Disposition")
+		respondInternalError(c, err)
+	}
+}
+
+// readDatasetArchive valida el manifiesto y las tablas del respaldo y retorna sus registros por tabla.
+func readDatasetArchive(data []byte) (datasetManifest, map[string][]json.RawMessage, error) {
+	var manifest datasetManifest
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return manifest, nil, newAPIError("DATASET_INVALID_ARCHIVE")
+	}
+	files := map[string]*zip.File{}
+	for _, file := range archive.File {
+		files[file.Name] = file
+	}
+
+	// El tamaño descomprimido del encabezado puede ser falso, por lo que además de rechazarlo se
+	// limita la lectura a lo que queda del total permitido
+	remaining := int64(maxDatasetUncompressedSize)
+	readFile := func(name string) ([]byte, error) {
+		file, ok := files[name]
+		if !ok {
+			return nil, newAPIError("DATASET_MISSING_FILE", name)
+		}
+		if file.UncompressedSize64 > uint64(remaining) {
+			return nil, newAPIError("DATASET_TOO_LARGE", maxDatasetUncompressedSize>>20)
+		}
+		r, err := file.Open()
+		if err != nil {
+			return nil, newAPIError("DATASET_INVALID_ARCHIVE")
+		}
+		defer r.Close()
+		content, err := io.ReadAll(io.LimitReader(r, remaining+1))
+		if err != nil {
+			return nil, newAPIError("DATASET_INVALID_ARCHIVE")
+		}
+		if int64(len(content)) > remaining {
+			return nil, newAPIError("DATASET_TOO_LARGE", maxDatasetUncompressedSize>>20)
+		}
+		remaining -= int64(len(content))
+		return content, nil
+	}
+
+	content, err := readFile(datasetManifestFile)
+	if err != nil {
+		return manifest, nil, err
+	}
+	if err := json.Unmarshal(content, &manifest); err != nil || manifest.Format != datasetFormat {
+		return manifest, nil, newAPIError("DATASET_INVALID_ARCHIVE")
+	}
+	if manifest.Version != datasetVersion {
+		return manifest, nil, newAPIError("DATASET_UNSUPPORTED_VERSION", manifest.Version, datasetVersion)
+	}
+
+	tables := map[string][]json.RawMessage{}
+	known := internal.DatasetTables()
+	for _, entry := range manifest.Tables {
+		if !slices.Contains(known, entry.Name) {
+			return manifest, nil, newAPIError("DATASET_UNKNOWN_TABLE", entry.Name)
+		}
+		if _, ok := tables[entry.Name]; ok {
+			return manifest, nil, newAPIError("DATASET_INVALID_ARCHIVE")
+		}
+		content, err := readFile(entry.File)
+		if err != nil {
+			return manifest, nil, err
+		}
+		sum := sha256.Sum256(content)
+		if hex.EncodeToString(sum[:]) != entry.SHA256 {
+			return manifest, nil, newAPIError("DATASET_CHECKSUM_MISMATCH", entry.File)
+		}
+		var rows []json.RawMessage
+		if err := json.Unmarshal(content, &rows); err != nil || len(rows) != entry.Rows {
+			return manifest, nil, newAPIError("DATASET_INVALID_TABLE", entry.File)
+		}
+		tables[entry.Name] = rows
+	}
+	return manifest, tables, nil
+}
+
+// importDataset godoc
+// @Summary Restaura un respaldo
+// @Description Importa en una única transacción un archivo generado por /admin/export. El parámetro conflict indica
+// @Description qué hacer con los registros que ya existen: "fail" (por defecto) revierte la importación con 409,
+// @Description "skip" conserva los existentes, "overwrite" los reemplaza y "replace" vacía las tablas antes de importar.
+// @Description Con skip y overwrite, una llave existente que corresponde a otro registro (por ejemplo otro partido con
+// @Description el mismo ID o un evento distinto) responde 409 sin importar nada. La proyección de los partidos
+// @Description importados se reconstruye a partir de su log de eventos en la misma transacción.
+// @Description Los eventos importados no generan entregas de webhooks; los eventos anteriores pendientes sí las generan.
+// @Description Responde 413 si el archivo supera 100 MB o si su contenido descomprimido supera 1 GB.
+// @Tags Admin
+// @Accept multipart/form-data
+// @Produce json
+// @Security AdminToken
+// @Param file formData file true "Respaldo en formato ZIP"
+// @Param conflict query string false "Manejo de registros existentes" Enums(fail, skip, overwrite, replace) default(fail)
+// @Success 200 {object} datasetImportResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 413 {object} map[string]string
+// @Failure 422 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /admin/import [post]
+func importDataset(c *gin.Context) {
+	conflict := c.DefaultQuery("conflict", internal.DatasetConflictFail)
+	switch conflict {
+	case internal.DatasetConflictFail, internal.DatasetConflictSkip, internal.DatasetConflictOverwrite, internal.DatasetConflictReplace:
+	default:
+		respondError(c, http.StatusBadRequest, "DATASET_INVALID_CONFLICT_MODE", conflict)
+		return
+	}
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxDatasetSize)
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			respondError(c, http.StatusRequestEntityTooLarge, "IMPORT_FILE_TOO_LARGE", maxDatasetSize>>20)
+			return
+		}
+		respondError(c, http.StatusBadRequest, "IMPORT_FILE_REQUIRED")
+		return
+	}
+	file, err := fileHeader.Open()
+	if err != nil {
+		respondInternalError(c, err)
+		return
+	}
+	defer file.Close()
+	data, err := io.ReadAll(file)
+	if err != nil {
+		respondInternalError(c, err)
+		return
+	}
+
+	manifest, tables, err := readDatasetArchive(data)
+	if err != nil {
+		code, args := errorCode(err)
+		status := http.StatusBadRequest
+		if code == "DATASET_TOO_LARGE" {
+			status = http.StatusRequestEntityTooLarge
+		}
+		respondError(c, status, code, args...)
+		return
+	}
+
+	results, err := internal.ImportDataset(tables, conflict, auditInfo(c))
+	var conflictErr *internal.DatasetConflictError
+	var mismatchErr *internal.DatasetMismatchError
+	switch {
+	case errors.As(err, &conflictErr):
+		respondError(c, http.StatusConflict, "DATASET_CONFLICT", conflictErr.Table)
+		return
+	case errors.As(err, &mismatchErr):
+		respondError(c, http.StatusConflict, "DATASET_KEY_MISMATCH", mismatchErr.Rows, mismatchErr.Table)
+		return
+	case errors.Is(err, internal.ErrInvalidDataset):
+		body := errorBody(c, "DATASET_INVALID")
+		body["detail"] = err.Error()
+		c.JSON(http.StatusUnprocessableEntity, body)
+		return
+	case err != nil:
+		respondInternalError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, datasetImportResponse{Version: manifest.Version, Conflict: conflict, Tables: results})
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"lab6/internal"
+)
+
+// matchETag construye el valor del encabezado ETag a partir de la versión del partido.
+func matchETag(version int) string {
+	return `"` + strconv.Itoa(version) + `"`
+}
+
+// requireIfMatch lee la versión esperada desde el encabezado If-Match.
+// Si el encabezado falta o no es válido responde al cliente y retorna false.
+func requireIfMatch(c *gin.Context) (int, bool) {
+	header := strings.TrimSpace(c.GetHeader("If-Match"))
+	if header == "" {
+		respondError(c, http.StatusPreconditionRequired, "IF_MATCH_REQUIRED")
+		return 0, false
+	}
+
+	// Se admite el formato débil (W/"n") aunque la comparación siempre es exacta
+	tag := strings.TrimPrefix(header, "W/")
+	version, err := strconv.Atoi(strings.Trim(tag, `"`))
+	if err != nil {
+		respondError(c, http.StatusBadRequest, "IF_MATCH_INVALID")
+		return 0, false
+	}
+	return version, true
+}
+
+// writeErrorStatus retorna el código HTTP correspondiente al error de una escritura condicionada.
+func writeErrorStatus(err error) int {
+	var unknownTeam *internal.UnknownTeamError
+	switch {
+	case errors.As(err, &unknownTeam):
+		return http.StatusUnprocessableEntity
+	case errors.Is(err, internal.ErrVersionMismatch):
+		return http.StatusPreconditionFailed
+	case errors.Is(err, internal.ErrMatchFinished), errors.Is(err, internal.ErrMatchNotDeleted),
+		errors.Is(err, internal.ErrClockRunning), errors.Is(err, internal.ErrClockStopped),
+		errors.Is(err, internal.ErrInvalidPeriod):
+		return http.StatusConflict
+	case errors.Is(err, sql.ErrNoRows):
+		return http.StatusNotFound
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// respondWriteError traduce el error de una escritura condicionada a la respuesta HTTP.
+// Ante una versión desactualizada retorna 412 junto con la representación actual del partido.
+func respondWriteError(c *gin.Context, id int, err error) {
+	respondWriteErrorAs(c, id, err, func(m internal.Match) any { return m })
+}
+
+// respondWriteErrorAs es como respondWriteError pero usa present para construir
+// la representación del partido, de modo que cada versión de la API retorne la suya.
+func respondWriteErrorAs(c *gin.Context, id int, err error, present func(internal.Match) any) {
+	switch status := writeErrorStatus(err); status {
+	case http.StatusPreconditionFailed:
+		match, getErr := internal.GetMatchByID(id)
+		if getErr != nil {
+			respondError(c, status, "VERSION_MISMATCH")
+			return
+		}
+		c.Header("ETag", matchETag(match.Version))
+		c.JSON(status, present(match))
+	case http.StatusNotFound:
+		respondError(c, status, "MATCH_NOT_FOUND")
+	case http.StatusConflict:
+		code, args := errorCode(err)
+		respondError(c, status, code, args...)
+	case http.StatusUnprocessableEntity:
+		var unknownTeam *internal.UnknownTeamError
+		errors.As(err, &unknownTeam)
+		body := errorBody(c, "UNKNOWN_TEAM", unknownTeam.Name)
+		body["team"] = unknownTeam.Name
+		body["suggestions"] = unknownTeam.Suggestions
+		c.JSON(status, body)
+	default:
+		respondInternalError(c, err)
+	}
+}
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	"lab6/internal"
+)
+
+func TestRequireIfMatch(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name        string
+		header      string
+		wantVersion int
+		wantStatus  int
+		wantCode    string
+	}{
+		{name: "versión fuerte", header: `"3"`, wantVersion: 3, wantStatus: http.StatusOK},
+		{name: "versión débil", header: `W/"3"`, wantVersion: 3, wantStatus: http.StatusOK},
+		{name: "con espacios", header: ` "12" `, wantVersion: 12, wantStatus: http.StatusOK},
+		{name: "sin If-Match", wantStatus: http.StatusPreconditionRequired, wantCode: "IF_MATCH_REQUIRED"},
+		{name: "If-Match que no es una versión", header: `"abc"`, wantStatus: http.StatusBadRequest, wantCode: "IF_MATCH_INVALID"},
+		{name: "comodín", header: `*`, wantStatus: http.StatusBadRequest, wantCode: "IF_MATCH_INVALID"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var version int
+			router := gin.New()
+			router.Use(localeMiddleware())
+			router.PUT("/api/matches/:id", func(c *gin.Context) {
+				var ok bool
+				if version, ok = requireIfMatch(c); ok {
+					c.Status(http.StatusOK)
+				}
+			})
+
+			req := httptest.NewRequest(http.MethodPut, "/api/matches/1", nil)
+			if tt.header != "" {
+				req.Header.Set("If-Match", tt.header)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, se esperaba %d", w.Code, tt.wantStatus)
+			}
+			if version != tt.wantVersion {
+				t.Errorf("versión = %d, se esperaba %d", version, tt.wantVersion)
+			}
+			if tt.wantCode != "" {
+				var body map[string]any
+				if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil || body["code"] != tt.wantCode {
+					t.Errorf("code = %v, se esperaba %s", body["code"], tt.wantCode)
+				}
+			}
+		})
+	}
+}
+
+func TestWriteErrorStatus(t *testing.T) {
+	tests := []struct {
+		err  error
+		want int
+	}{
+		{err: fmt.Errorf("al actualizar: %w", internal.ErrVersionMismatch), want: http.StatusPreconditionFailed},
+		{err: &internal.UnknownTeamError{Name: "Betiz"}, want: http.StatusUnprocessableEntity},
+		{err: internal.ErrMatchFinished, want: http.StatusConflict},
+		{err: internal.ErrClockRunning, want: http.StatusConflict},
+		{err: sql.ErrNoRows, want: http.StatusNotFound},
+		{err: errors.New("conexión perdida"), want: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		if got := writeErrorStatus(tt.err); got != tt.want {
+			t.Errorf("writeErrorStatus(%v) = %d, se esperaba %d", tt.err, got, tt.want)
+		}
+	}
+}
+
+// TestRespondWriteErrorAsVersionMismatch verifica que un 412 incluya la representación y el ETag
+// actuales del partido, para que el cliente pueda reintentar sobre la versión vigente.
+func TestRespondWriteErrorAsVersionMismatch(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	kickoff := time.Date(2025, 4, 5, 19, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name        string
+		current     *sqlmock.Rows
+		wantETag    string
+		wantVersion float64
+		wantCode    string
+	}{
+		{
+			name: "con el partido actual",
+			current: sqlmock.NewRows([]string{"id", "home_team", "away_team", "match_date", "version",
+				"goals_match", "yellow_cards_match", "red_cards_match", "extra_time", "finished", "date_only"}).
+				AddRow(4, "Sevilla", "Betis", kickoff, 5, 2, 1, 0, false, false, false),
+			wantETag:    `"5"`,
+			wantVersion: 5,
+		},
+		{
+			name:     "si el partido ya no existe",
+			current:  sqlmock.NewRows([]string{"id"}),
+			wantCode: "VERSION_MISMATCH",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := mockDB(t)
+			mock.ExpectQuery(`FROM matches WHERE id = \$1 AND deleted_at IS NULL`).WithArgs(4).WillReturnRows(tt.current)
+
+			router := gin.New()
+			router.Use(localeMiddleware())
+			router.PUT("/api/v2/matches/:id", func(c *gin.Context) {
+				respondWriteErrorAs(c, 4, internal.ErrVersionMismatch, presentMatchV2)
+			})
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodPut, "/api/v2/matches/4", nil))
+
+			if w.Code != http.StatusPreconditionFailed {
+				t.Errorf("status = %d, se esperaba 412", w.Code)
+			}
+			if etag := w.Header().Get("ETag"); etag != tt.wantETag {
+				t.Errorf("ETag = %q, se esperaba %q", etag, tt.wantETag)
+			}
+			var body map[string]any
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+				t.Fatal(err)
+			}
+			if tt.wantCode != "" && body["code"] != tt.wantCode {
+				t.Errorf("code = %v, se esperaba %s", body["code"], tt.wantCode)
+			}
+			if tt.wantVersion != 0 {
+				if body["version"] != tt.wantVersion || body["kickoff"] != "2025-04-05T19:00:00Z" {
+					t.Errorf("body = %v, se esperaba la representación v2 de la versión %v", body, tt.wantVersion)
+				}
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"lab6/internal"
+)
+
+// projectionRebuildResponse es la respuesta de la reconstrucción de proyecciones.
+type projectionRebuildResponse struct {
+	Matches int `json:"matches"`
+}
+
+// lookupMatch obtiene el partido solicitado. Si se envía ?asOf= lo reconstruye a partir de los
+// eventos ocurridos hasta ese instante. El booleano historical indica si se usó asOf; ok es
+// false si ya se respondió con un error.
+func lookupMatch(c *gin.Context, id int) (match internal.Match, historical, ok bool) {
+	var err error
+	if value := c.Query("asOf"); value != "" {
+		asOf, parseErr := time.Parse(time.RFC3339, value)
+		if parseErr != nil {
+			respondError(c, http.StatusBadRequest, "INVALID_TIMESTAMP", "asOf")
+			return match, true, false
+		}
+		historical = true
+		match, err = internal.GetMatchAsOf(id, asOf)
+	} else {
+		match, err = internal.GetMatchByID(id)
+	}
+
+	if errors.Is(err, sql.ErrNoRows) {
+		respondError(c, http.StatusNotFound, "MATCH_NOT_FOUND")
+		return match, historical, false
+	}
+	if err != nil {
+		respondInternalError(c, err)
+		return match, historical, false
+	}
+	return match, historical, true
+}
+
+// getMatchEvents godoc
+// @Summary Obtiene el log de eventos de un partido
+// @Description Retorna en orden los eventos del partido (MatchScheduled, GoalScored, CardShown, ExtraTimeStarted,
+// @Description MatchFinished, MatchDeleted, MatchRestored, TeamsRenamed, PeriodStarted, PeriodEnded, StoppageTimeAnnounced).
+// @Description Aplicarlos en orden reproduce su estado actual. Los eventos ocurridos con el reloj en marcha incluyen su minuto en clock.
+// @Tags Events
+// @Produce json,text/csv,xml,application/x-yaml,application/x-ndjson
+// @Param id path int true "ID del partido"
+// @Param format query string false "Formato de la respuesta (alternativa a Accept)" Enums(json, csv, xml, yaml, ndjson)
+// @Success 200 {array} internal.MatchEvent
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /matches/{id}/events [get]
+func getMatchEvents(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		respondError(c, http.StatusBadRequest, "INVALID_ID")
+		return
+	}
+
+	events, err := internal.GetMatchEvents(id)
+	if errors.Is(err, sql.ErrNoRows) {
+		respondError(c, http.StatusNotFound, "MATCH_NOT_FOUND")
+		return
+	}
+	if err != nil {
+		respondInternalError(c, err)
+		return
+	}
+	render(c, http.StatusOK, events)
+}
+
+// finishMatchV2 godoc
+// @Summary Finaliza un partido (v2)
+// @Description Registra el evento MatchFinished si la versión coincide con If-Match. Un partido finalizado
+// @Description no admite más goles, tarjetas ni tiempo extra.
+// @Tags Matches v2
+// @Produce json
+// @Param id path int true "ID del partido"
+// @Param If-Match header string true "ETag obtenido al consultar el partido"
+// @Param Idempotency-Key header string false "Clave para reintentar la solicitud sin repetir la escritura"
+// @Success 200 {object} matchV2
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string "El partido ya finalizó"
+// @Failure 412 {object} matchV2 "Versión desactualizada, se retorna la actual"
+// @Failure 428 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /v2/matches/{id}/finish [post]
+func finishMatchV2(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		respondError(c, http.StatusBadRequest, "INVALID_ID")
+		return
+	}
+
+	version, ok := requireIfMatch(c)
+	if !ok {
+		return
+	}
+
+	if err := internal.FinishMatch(id, version, auditInfo(c)); err != nil {
+		respondWriteErrorAs(c, id, err, presentMatchV2)
+		return
+	}
+	respondMatchV2(c, http.StatusOK, id)
+}
+
+// rebuildProjections godoc
+// @Summary Reconstruye las proyecciones
+// @Description Repite el log completo de eventos y reescribe la tabla de partidos a partir de él.
+// @Tags Admin
+// @Produce json
+// @Security AdminToken
+// @Success 200 {object} projectionRebuildResponse
+// @Failure 401 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /admin/projections/rebuild [post]
+func rebuildProjections(c *gin.Context) {
+	rebuilt, err := internal.RebuildProjections(auditInfo(c))
+	if err != nil {
+		respondInternalError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, projectionRebuildResponse{Matches: rebuilt})
+}
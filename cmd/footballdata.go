@@ -0,0 +1,248 @@
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"lab6/internal"
+)
+
+const (
+	// footballDataDivision es el código de La Liga en football-data.co.uk.
+	footballDataDivision = "SP1"
+
+	// footballDataMaxStat limita los goles y tarjetas por equipo de una fila.
+	footballDataMaxStat = 99
+)
+
+// footballDataLocation es la zona horaria de la columna Time de football-data.co.uk (hora del Reino Unido).
+var footballDataLocation = mustLoadLocation("Europe/London")
+
+// footballDataDateLayouts son los formatos de la columna Date: las temporadas antiguas usan año de dos dígitos.
+var footballDataDateLayouts = []string{"02/01/2006", "02/01/06"}
+
+// footballDataHeader son las columnas que escribe la exportación, en el orden de football-data.co.uk.
+var footballDataHeader = []string{"Div", "Date", "Time", "HomeTeam", "AwayTeam", "FTHG", "FTAG", "FTR", "HY", "AY", "HR", "AR"}
+
+// footballDataColumns son los nombres aceptados para cada columna leída. Algunos archivos antiguos usan
+// HT/AT para los equipos y HG/AG para los goles.
+var footballDataColumns = map[string][]string{
+	"Date":     {"date"},
+	"Time":     {"time"},
+	"HomeTeam": {"hometeam", "ht"},
+	"AwayTeam": {"awayteam", "at"},
+	"FTHG":     {"fthg", "hg"},
+	"FTAG":     {"ftag", "ag"},
+	"HY":       {"hy"},
+	"AY":       {"ay"},
+	"HR":       {"hr"},
+	"AR":       {"ar"},
+}
+
+// mustLoadLocation carga una zona horaria de la base embebida con time/tzdata.
+func mustLoadLocation(name string) *time.Location {
+	location, err := time.LoadLocation(name)
+	if err != nil {
+		panic(err)
+	}
+	return location
+}
+
+// readFootballDataRecords lee un CSV de football-data.co.uk y retorna sus filas de datos junto con
+// la función que convierte cada una en partido. Las columnas que no corresponden al modelo (cuotas,
+// tiros, córners, resultado al descanso) se ignoran.
+func readFootballDataRecords(data []byte) ([]importRecord, func(importRecord) (internal.ImportRow, error), error) {
+	records, err := readCSVRecords(data, ",")
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(records) == 0 {
+		return nil, nil, newAPIError("IMPORT_NO_ROWS")
+	}
+
+	header := importHeader(records[0])
+	positions := map[string]int{}
+	for column, names := range footballDataColumns {
+		for _, name := range names {
+			if position, ok := header[name]; ok {
+				positions[column] = position
+				break
+			}
+		}
+	}
+	for _, column := range []string{"Date", "HomeTeam", "AwayTeam"} {
+		if _, ok := positions[column]; !ok {
+			return nil, nil, newAPIError("IMPORT_MISSING_COLUMN", column)
+		}
+	}
+
+	dataRecords, err := importDataRecords(records)
+	if err != nil {
+		return nil, nil, err
+	}
+	return dataRecords, func(record importRecord) (internal.ImportRow, error) {
+		return toFootballDataRow(record, positions)
+	}, nil
+}
+
+// toFootballDataRow convierte una fila de football-data.co.uk en partido. Las filas sin FTHG ni FTAG
+// (como las del archivo de próximos partidos) se importan como partidos programados.
+func toFootballDataRow(record importRecord, positions map[string]int) (internal.ImportRow, error) {
+	field := func(column string) string {
+		if position, ok := positions[column]; ok && position < len(record.Values) {
+			return strings.TrimSpace(record.Values[position])
+		}
+		return ""
+	}
+
+	row := internal.ImportRow{Line: record.Line}
+	row.Match.HomeTeam, row.Match.AwayTeam = field("HomeTeam"), field("AwayTeam")
+	for _, column := range []string{"HomeTeam", "AwayTeam", "Date"} {
+		if field(column) == "" {
+			return row, newAPIError("IMPORT_MISSING_VALUE", column)
+		}
+	}
+
+	matchDate, err := parseFootballDataDate(field("Date"), field("Time"))
+	if err != nil {
+		return row, err
+	}
+	row.Match.MatchDate, row.Match.DateOnly = matchDate, field("Time") == ""
+
+	if field("FTHG") == "" && field("FTAG") == "" {
+		return row, nil
+	}
+	var result internal.MatchResult
+	for _, stat := range []struct {
+		column   string
+		target   *int
+		required bool
+	}{
+		{"FTHG", &result.HomeGoals, true}, {"FTAG", &result.AwayGoals, true},
+		{"HY", &result.HomeYellowCards, false}, {"AY", &result.AwayYellowCards, false},
+		{"HR", &result.HomeRedCards, false}, {"AR", &result.AwayRedCards, false},
+	} {
+		value := field(stat.column)
+		if value == "" && !stat.required {
+			continue
+		}
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 || n > footballDataMaxStat {
+			return row, newAPIError("IMPORT_INVALID_NUMBER", stat.column, value)
+		}
+		*stat.target = n
+	}
+	row.Result = &result
+	return row, nil
+}
+
+// parseFootballDataDate interpreta Date (DD/MM/YYYY o DD/MM/YY) y, si la hay, Time (HH:MM, hora del
+// Reino Unido). Sin hora el partido queda a medianoche UTC, igual que en la importación genérica.
+func parseFootballDataDate(date, clock string) (time.Time, error) {
+	for _, layout := range footballDataDateLayouts {
+		day, err := time.Parse(layout, date)
+		if err != nil {
+			continue
+		}
+		if clock == "" {
+			return day, nil
+		}
+		kickoff, err := time.ParseInLocation(layout+" 15:04", date+" "+clock, footballDataLocation)
+		if err != nil {
+			return time.Time{}, newAPIError("IMPORT_INVALID_DATE", date+" "+clock, "DD/MM/YYYY HH:mm")
+		}
+		return kickoff.UTC(), nil
+	}
+	return time.Time{}, newAPIError("IMPORT_INVALID_DATE", date, "DD/MM/YYYY")
+}
+
+// footballDataStat formatea una estadística por equipo, o deja las columnas vacías si algún evento
+// no indica a qué equipo corresponde.
+func footballDataStat(count internal.SideCount) (string, string) {
+	if count.Unattributed > 0 {
+		return "", ""
+	}
+	return strconv.Itoa(count.Home), strconv.Itoa(count.Away)
+}
+
+// exportFootballData godoc
+// @Summary Exporta partidos en el formato de football-data.co.uk
+// @Description Genera un CSV con las columnas Div, Date, Time, HomeTeam, AwayTeam, FTHG, FTAG, FTR, HY, AY, HR y AR.
+// @Description Los partidos sin finalizar se exportan sin resultado. Los goles y tarjetas por equipo se obtienen del log
+// @Description de eventos: si alguno se registró sin indicar el equipo (por ejemplo con PATCH /matches/{id}/goals),
+// @Description las columnas de esa estadística quedan vacías. Date y Time están en hora del Reino Unido.
+// @Tags Import
+// @Produce text/csv
+// @Param season query int false "Temporada, por su año de inicio (2024 es la 2024-25, de julio a junio)"
+// @Param division query string false "Código de la columna Div" default(SP1)
+// @Success 200 {string} string "CSV de football-data.co.uk"
+// @Failure 400 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /export/football-data [get]
+func exportFootballData(c *gin.Context) {
+	division := c.DefaultQuery("division", footballDataDivision)
+	from := time.Date(1, time.January, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(9999, time.January, 1, 0, 0, 0, 0, time.UTC)
+	filename := division + ".csv"
+	if value := c.Query("season"); value != "" {
+		season, err := strconv.Atoi(value)
+		if err != nil || season < 1900 || season > 2100 {
+			respondError(c, http.StatusBadRequest, "INVALID_SEASON")
+			return
+		}
+		from, to = internal.SeasonBounds(season)
+		filename = fmt.Sprintf("%s-%d-%02d.csv", division, season, (season+1)%100)
+	}
+
+	matches, err := internal.GetMatchesBetween(from, to)
+	if err != nil {
+		respondInternalError(c, err)
+		return
+	}
+	ids := make([]int, len(matches))
+	for i, m := range matches {
+		ids[i] = m.ID
+	}
+	sides, err := internal.GetMatchSides(ids)
+	if err != nil {
+		respondInternalError(c, err)
+		return
+	}
+
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Header("Content-Disposition", `attachment; filename="`+filename+`"`)
+	c.Status(http.StatusOK)
+	w := csv.NewWriter(c.Writer)
+	w.Write(footballDataHeader)
+	for _, m := range matches {
+		date, clock := m.MatchDate.UTC().Format("02/01/2006"), ""
+		if !m.DateOnly {
+			local := m.MatchDate.In(footballDataLocation)
+			date, clock = local.Format("02/01/2006"), local.Format("15:04")
+		}
+		record := []string{division, date, clock, m.HomeTeam, m.AwayTeam, "", "", "", "", "", "", ""}
+		if m.Finished {
+			s := sides[m.ID]
+			record[5], record[6] = footballDataStat(s.Goals)
+			if s.Goals.Unattributed == 0 {
+				switch {
+				case s.Goals.Home > s.Goals.Away:
+					record[7] = "H"
+				case s.Goals.Home < s.Goals.Away:
+					record[7] = "A"
+				default:
+					record[7] = "D"
+				}
+			}
+			record[8], record[9] = footballDataStat(s.YellowCards)
+			record[10], record[11] = footballDataStat(s.RedCards)
+		}
+		w.Write(record)
+	}
+	w.Flush()
+}
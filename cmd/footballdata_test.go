@@ -0,0 +1,244 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	"lab6/internal"
+)
+
+func TestParseFootballDataDate(t *testing.T) {
+	tests := []struct {
+		name     string
+		date     string
+		clock    string
+		want     time.Time
+		wantCode string
+	}{
+		{name: "año de cuatro dígitos sin hora", date: "05/04/2025", want: time.Date(2025, 4, 5, 0, 0, 0, 0, time.UTC)},
+		{name: "año de dos dígitos", date: "27/08/00", want: time.Date(2000, 8, 27, 0, 0, 0, 0, time.UTC)},
+		{name: "año de dos dígitos con hora", date: "05/04/25", clock: "20:00", want: time.Date(2025, 4, 5, 19, 0, 0, 0, time.UTC)},
+		{name: "horario de invierno del Reino Unido", date: "15/03/2025", clock: "15:00", want: time.Date(2025, 3, 15, 15, 0, 0, 0, time.UTC)},
+		{name: "día del cambio al horario de verano", date: "30/03/2025", clock: "15:00", want: time.Date(2025, 3, 30, 14, 0, 0, 0, time.UTC)},
+		{name: "horario de verano del Reino Unido", date: "05/04/2025", clock: "15:00", want: time.Date(2025, 4, 5, 14, 0, 0, 0, time.UTC)},
+		{name: "día del cambio al horario de invierno", date: "26/10/2025", clock: "15:00", want: time.Date(2025, 10, 26, 15, 0, 0, 0, time.UTC)},
+		{name: "fecha inválida", date: "2025-04-05", wantCode: "IMPORT_INVALID_DATE"},
+		{name: "hora inválida", date: "05/04/2025", clock: "8pm", wantCode: "IMPORT_INVALID_DATE"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseFootballDataDate(tt.date, tt.clock)
+			if tt.wantCode != "" {
+				if code, _ := errorCode(err); code != tt.wantCode {
+					t.Errorf("código = %q (%v), se esperaba %s", code, err, tt.wantCode)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !got.Equal(tt.want) || got.Location() != time.UTC {
+				t.Errorf("parseFootballDataDate(%q, %q) = %v, se esperaba %v", tt.date, tt.clock, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFootballDataRows(t *testing.T) {
+	const header = "Div,Date,Time,HomeTeam,AwayTeam,FTHG,FTAG,FTR,HTHG,HTAG,HY,AY,HR,AR,B365H\n"
+
+	tests := []struct {
+		name         string
+		data         string
+		wantCode     string
+		wantDate     time.Time
+		wantDateOnly bool
+		wantResult   *internal.MatchResult
+	}{
+		{
+			name:       "resultado con tarjetas, ignorando las columnas que no son del modelo",
+			data:       header + "SP1,05/04/2025,20:00,Betis,Sevilla,2,1,H,1,0,3,4,0,1,1.9\n",
+			wantDate:   time.Date(2025, 4, 5, 19, 0, 0, 0, time.UTC),
+			wantResult: &internal.MatchResult{HomeGoals: 2, AwayGoals: 1, HomeYellowCards: 3, AwayYellowCards: 4, AwayRedCards: 1},
+		},
+		{
+			name:         "encabezados antiguos HT, AT, HG y AG sin hora",
+			data:         "Div,Date,HT,AT,HG,AG,FTR\nSP1,27/08/00,Betis,Sevilla,0,0,D\n",
+			wantDate:     time.Date(2000, 8, 27, 0, 0, 0, 0, time.UTC),
+			wantDateOnly: true,
+			wantResult:   &internal.MatchResult{},
+		},
+		{
+			name:     "partido próximo sin resultado",
+			data:     header + "SP1,05/04/2025,15:00,Betis,Sevilla,,,,,,,,,,2.1\n",
+			wantDate: time.Date(2025, 4, 5, 14, 0, 0, 0, time.UTC),
+		},
+		{
+			name:       "tarjetas vacías en un partido con resultado",
+			data:       header + "SP1,05/04/2025,15:00,Betis,Sevilla,1,1,D,,,,,,,\n",
+			wantDate:   time.Date(2025, 4, 5, 14, 0, 0, 0, time.UTC),
+			wantResult: &internal.MatchResult{HomeGoals: 1, AwayGoals: 1},
+		},
+		{
+			name:     "goles fuera de rango",
+			data:     header + "SP1,05/04/2025,15:00,Betis,Sevilla,100,1,H,,,,,,,\n",
+			wantCode: "IMPORT_INVALID_NUMBER",
+		},
+		{
+			name:     "tarjetas negativas",
+			data:     header + "SP1,05/04/2025,15:00,Betis,Sevilla,1,1,D,0,0,-1,0,0,0,\n",
+			wantCode: "IMPORT_INVALID_NUMBER",
+		},
+		{
+			name:     "solo uno de los goles",
+			data:     header + "SP1,05/04/2025,15:00,Betis,Sevilla,1,,,,,,,,,\n",
+			wantCode: "IMPORT_INVALID_NUMBER",
+		},
+		{
+			name:     "equipo faltante",
+			data:     header + "SP1,05/04/2025,15:00,,Sevilla,1,1,D,,,,,,,\n",
+			wantCode: "IMPORT_MISSING_VALUE",
+		},
+		{
+			name:     "columna faltante",
+			data:     "Div,Date,HomeTeam\nSP1,05/04/2025,Betis\n",
+			wantCode: "IMPORT_MISSING_COLUMN",
+		},
+		{
+			name:     "solo encabezado",
+			data:     header,
+			wantCode: "IMPORT_NO_ROWS",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			records, toRow, err := readFootballDataRecords([]byte(tt.data))
+			var row internal.ImportRow
+			if err == nil {
+				row, err = toRow(records[0])
+			}
+			if tt.wantCode != "" {
+				if code, _ := errorCode(err); code != tt.wantCode {
+					t.Errorf("código = %q (%v), se esperaba %s", code, err, tt.wantCode)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if row.Line != 2 || row.Match.HomeTeam != "Betis" || row.Match.AwayTeam != "Sevilla" {
+				t.Errorf("fila = %+v, se esperaba Betis vs Sevilla en la línea 2", row)
+			}
+			if !row.Match.MatchDate.Equal(tt.wantDate) || row.Match.DateOnly != tt.wantDateOnly {
+				t.Errorf("fecha = %v (dateOnly %v), se esperaba %v (dateOnly %v)", row.Match.MatchDate, row.Match.DateOnly, tt.wantDate, tt.wantDateOnly)
+			}
+			switch {
+			case tt.wantResult == nil && row.Result != nil:
+				t.Errorf("resultado = %+v, se esperaba un partido programado", *row.Result)
+			case tt.wantResult != nil && (row.Result == nil || *row.Result != *tt.wantResult):
+				t.Errorf("resultado = %+v, se esperaba %+v", row.Result, *tt.wantResult)
+			}
+		})
+	}
+}
+
+func TestExportFootballData(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	matchRows := func() *sqlmock.Rows {
+		return sqlmock.NewRows([]string{"id", "home_team", "away_team", "match_date", "version",
+			"goals_match", "yellow_cards_match", "red_cards_match", "extra_time", "finished", "date_only"})
+	}
+	sideRows := func() *sqlmock.Rows {
+		return sqlmock.NewRows([]string{"match_id", "stat", "home", "away", "unattributed"})
+	}
+
+	tests := []struct {
+		name         string
+		query        string
+		setup        func(mock sqlmock.Sqlmock)
+		wantStatus   int
+		wantFilename string
+		wantBody     string
+	}{
+		{
+			name:  "resultado, horario de verano y partidos sin finalizar",
+			query: "?season=2024",
+			setup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`FROM matches WHERE deleted_at IS NULL AND match_date >= \$1`).
+					WithArgs(time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC), time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC)).
+					WillReturnRows(matchRows().
+						AddRow(1, "Betis", "Sevilla", time.Date(2025, 4, 5, 19, 0, 0, 0, time.UTC), 4, 3, 2, 1, 0, true, false).
+						AddRow(2, "Getafe", "Girona", time.Date(2025, 1, 11, 15, 0, 0, 0, time.UTC), 1, 0, 0, 0, 0, true, false).
+						AddRow(3, "Cádiz", "Elche", time.Date(2025, 5, 10, 0, 0, 0, 0, time.UTC), 1, 0, 0, 0, 0, false, true))
+				mock.ExpectQuery(`FROM match_events`).
+					WillReturnRows(sideRows().
+						AddRow(1, "goals", 2, 1, 0).
+						AddRow(1, internal.CardYellow, 3, 4, 0).
+						AddRow(1, internal.CardRed, 0, 1, 0))
+			},
+			wantStatus:   http.StatusOK,
+			wantFilename: "SP1-2024-25.csv",
+			wantBody: "Div,Date,Time,HomeTeam,AwayTeam,FTHG,FTAG,FTR,HY,AY,HR,AR\n" +
+				"SP1,05/04/2025,20:00,Betis,Sevilla,2,1,H,3,4,0,1\n" +
+				"SP1,11/01/2025,15:00,Getafe,Girona,0,0,D,0,0,0,0\n" +
+				"SP1,10/05/2025,,Cádiz,Elche,,,,,,,\n",
+		},
+		{
+			name:  "goles sin equipo dejan vacíos el marcador y el resultado",
+			query: "?division=E0",
+			setup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`FROM matches WHERE deleted_at IS NULL AND match_date >= \$1`).
+					WillReturnRows(matchRows().
+						AddRow(1, "Betis", "Sevilla", time.Date(2025, 4, 5, 19, 0, 0, 0, time.UTC), 4, 3, 1, 0, 0, true, false))
+				mock.ExpectQuery(`FROM match_events`).
+					WillReturnRows(sideRows().
+						AddRow(1, "goals", 1, 1, 1).
+						AddRow(1, internal.CardYellow, 0, 0, 1))
+			},
+			wantStatus:   http.StatusOK,
+			wantFilename: "E0.csv",
+			wantBody: "Div,Date,Time,HomeTeam,AwayTeam,FTHG,FTAG,FTR,HY,AY,HR,AR\n" +
+				"E0,05/04/2025,20:00,Betis,Sevilla,,,,,,0,0\n",
+		},
+		{
+			name:       "temporada inválida",
+			query:      "?season=24-25",
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := mockDB(t)
+			if tt.setup != nil {
+				tt.setup(mock)
+			}
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodGet, "/api/export/football-data"+tt.query, nil)
+
+			exportFootballData(c)
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, se esperaba %d: %s", w.Code, tt.wantStatus, w.Body.String())
+			}
+			if tt.wantBody != "" {
+				if got := w.Header().Get("Content-Disposition"); got != `attachment; filename="`+tt.wantFilename+`"` {
+					t.Errorf("Content-Disposition = %q, se esperaba %s", got, tt.wantFilename)
+				}
+				if got := w.Body.String(); got != tt.wantBody {
+					t.Errorf("CSV =\n%s\nse esperaba\n%s", got, tt.wantBody)
+				}
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
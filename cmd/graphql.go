@@ -0,0 +1,284 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/graphql-go/graphql/language/parser"
+	"github.com/graphql-go/graphql/language/source"
+	"golang.org/x/text/language"
+	"lab6/internal"
+)
+
+// graphqlRequest es el body de una operación GraphQL, por HTTP o dentro de un mensaje subscribe.
+type graphqlRequest struct {
+	Query         string         `json:"query" example:"{ matches(date: \"2025-04-01\") { id homeTeam { name } awayTeam { name } score { goals } } }"`
+	OperationName string         `json:"operationName,omitempty"`
+	Variables     map[string]any `json:"variables,omitempty"`
+}
+
+// graphqlResponse documenta la respuesta de /graphql.
+type graphqlResponse struct {
+	Data   map[string]any `json:"data,omitempty" swaggertype:"object"`
+	Errors []any          `json:"errors,omitempty" swaggertype:"array,object"`
+}
+
+// graphqlLoaders agrupan las consultas de los campos anidados de una operación, para que pedir
+// los equipos o los eventos de cada partido de una lista no genere una consulta por partido.
+type graphqlLoaders struct {
+	teams         *batchLoader[string, internal.Team]
+	teamMatches   *batchLoader[string, []internal.Match]
+	seasons       *batchLoader[int, internal.Season]
+	seasonMatches *batchLoader[int, []internal.Match]
+	events        *batchLoader[int, []internal.MatchEvent]
+}
+
+// newGraphQLLoaders crea los loaders de una operación.
+func newGraphQLLoaders() *graphqlLoaders {
+	return &graphqlLoaders{
+		teams: newBatchLoader(func(names []string) (map[string]internal.Team, error) {
+			teams, err := internal.GetTeamsByName(names)
+			if err != nil {
+				return nil, err
+			}
+			byName := make(map[string]internal.Team, len(teams))
+			for _, t := range teams {
+				byName[t.Name] = t
+			}
+			return byName, nil
+		}),
+		teamMatches: newBatchLoader(func(names []string) (map[string][]internal.Match, error) {
+			matches, err := internal.GetMatchesByTeams(names)
+			if err != nil {
+				return nil, err
+			}
+			byTeam := map[string][]internal.Match{}
+			for _, m := range matches {
+				byTeam[m.HomeTeam] = append(byTeam[m.HomeTeam], m)
+				if m.AwayTeam != m.HomeTeam {
+					byTeam[m.AwayTeam] = append(byTeam[m.AwayTeam], m)
+				}
+			}
+			return byTeam, nil
+		}),
+		seasons: newBatchLoader(func(years []int) (map[int]internal.Season, error) {
+			seasons, err := internal.GetSeasonsByYear(years)
+			if err != nil {
+				return nil, err
+			}
+			byYear := make(map[int]internal.Season, len(seasons))
+			for _, s := range seasons {
+				byYear[s.Year] = s
+			}
+			return byYear, nil
+		}),
+		seasonMatches: newBatchLoader(func(years []int) (map[int][]internal.Match, error) {
+			matches, err := internal.GetMatchesBySeasons(years)
+			if err != nil {
+				return nil, err
+			}
+			bySeason := map[int][]internal.Match{}
+			for _, m := range matches {
+				season := internal.SeasonOf(m.MatchDate)
+				bySeason[season] = append(bySeason[season], m)
+			}
+			return bySeason, nil
+		}),
+		events: newBatchLoader(func(ids []int) (map[int][]internal.MatchEvent, error) {
+			events, err := internal.GetEventsByMatches(ids)
+			if err != nil {
+				return nil, err
+			}
+			byMatch := map[int][]internal.MatchEvent{}
+			for _, e := range events {
+				byMatch[e.MatchID] = append(byMatch[e.MatchID], e)
+			}
+			return byMatch, nil
+		}),
+	}
+}
+
+// graphqlOperation son los datos de la solicitud que necesitan los resolvers.
+type graphqlOperation struct {
+	language language.Tag
+	audit    internal.AuditInfo
+	loaders  *graphqlLoaders
+}
+
+// graphqlContextKey es la clave bajo la que se guarda el graphqlOperation en el contexto.
+type graphqlContextKey struct{}
+
+// newGraphQLOperation toma de la solicitud el idioma de los mensajes y el autor de las mutaciones.
+func newGraphQLOperation(c *gin.Context) graphqlOperation {
+	return graphqlOperation{language: requestLanguage(c), audit: auditInfo(c)}
+}
+
+// withGraphQLOperation agrega al contexto los datos de la solicitud con loaders nuevos, de modo
+// que cada operación de una conexión WebSocket agrupe solo sus propias consultas.
+func withGraphQLOperation(ctx context.Context, op graphqlOperation) context.Context {
+	op.loaders = newGraphQLLoaders()
+	return context.WithValue(ctx, graphqlContextKey{}, &op)
+}
+
+// graphqlOperationFrom retorna los datos de la solicitud guardados por withGraphQLOperation.
+func graphqlOperationFrom(ctx context.Context) *graphqlOperation {
+	return ctx.Value(graphqlContextKey{}).(*graphqlOperation)
+}
+
+// graphqlLoadersFrom retorna los loaders de la operación.
+func graphqlLoadersFrom(ctx context.Context) *graphqlLoaders {
+	return graphqlOperationFrom(ctx).loaders
+}
+
+// graphqlError es un error de un resolver con el mensaje localizado y el código estable del
+// catálogo en extensions.code, igual que el campo code de las respuestas de error REST.
+type graphqlError struct {
+	message    string
+	extensions map[string]any
+}
+
+func (e *graphqlError) Error() string {
+	return e.message
+}
+
+// Extensions implementa gqlerrors.ExtendedError.
+func (e *graphqlError) Extensions() map[string]interface{} {
+	return e.extensions
+}
+
+// toGraphQLError traduce el error de una operación al error GraphQL correspondiente.
+func toGraphQLError(ctx context.Context, err error) *graphqlError {
+	code, args := errorCode(err)
+	extensions := map[string]any{"code": code}
+	var unknownTeam *internal.UnknownTeamError
+	switch {
+	case errors.As(err, &unknownTeam):
+		extensions["team"] = unknownTeam.Name
+		extensions["suggestions"] = unknownTeam.Suggestions
+	case code == "INTERNAL_ERROR":
+		extensions["detail"] = err.Error()
+	}
+	return &graphqlError{message: message(graphqlOperationFrom(ctx).language, code, args...), extensions: extensions}
+}
+
+// parseGraphQL analiza y valida la operación. Retorna el documento y el tipo de la operación que
+// se ejecutará (query, mutation o subscription), o el resultado con los errores de la consulta.
+func parseGraphQL(req graphqlRequest) (*ast.Document, string, *graphql.Result) {
+	doc, err := parser.Parse(parser.ParseParams{Source: source.NewSource(&source.Source{
+		Body: []byte(req.Query),
+		Name: "GraphQL request",
+	})})
+	if err != nil {
+		return nil, "", &graphql.Result{Errors: gqlerrors.FormatErrors(err)}
+	}
+	if validation := graphql.ValidateDocument(&graphqlSchema, doc, nil); !validation.IsValid {
+		return nil, "", &graphql.Result{Errors: validation.Errors}
+	}
+
+	// Sin operationName se ejecuta la única operación del documento. Si no hay una sola
+	// candidata el tipo queda vacío y graphql.Execute informa el error
+	var operations []*ast.OperationDefinition
+	for _, definition := range doc.Definitions {
+		def, ok := definition.(*ast.OperationDefinition)
+		if ok && (req.OperationName == "" || (def.Name != nil && def.Name.Value == req.OperationName)) {
+			operations = append(operations, def)
+		}
+	}
+	if len(operations) != 1 {
+		return doc, "", nil
+	}
+	return doc, operations[0].Operation, nil
+}
+
+// executeGraphQL ejecuta una query o una mutación ya validada.
+func executeGraphQL(ctx context.Context, doc *ast.Document, req graphqlRequest) *graphql.Result {
+	return graphql.Execute(graphql.ExecuteParams{
+		Schema:        graphqlSchema,
+		AST:           doc,
+		OperationName: req.OperationName,
+		Args:          req.Variables,
+		Context:       ctx,
+	})
+}
+
+// runGraphQL valida y ejecuta la operación leída de una solicitud HTTP.
+func runGraphQL(c *gin.Context, req graphqlRequest) {
+	if req.Query == "" {
+		respondError(c, http.StatusBadRequest, "GRAPHQL_MISSING_QUERY")
+		return
+	}
+	doc, operation, result := parseGraphQL(req)
+	if result != nil {
+		c.JSON(http.StatusBadRequest, result)
+		return
+	}
+	switch {
+	case operation == ast.OperationTypeSubscription:
+		respondError(c, http.StatusBadRequest, "GRAPHQL_SUBSCRIPTION_OVER_HTTP")
+		return
+	case operation == ast.OperationTypeMutation && c.Request.Method == http.MethodGet:
+		c.Header("Allow", http.MethodPost)
+		respondError(c, http.StatusMethodNotAllowed, "GRAPHQL_MUTATION_OVER_GET")
+		return
+	}
+	c.JSON(http.StatusOK, executeGraphQL(withGraphQLOperation(c.Request.Context(), newGraphQLOperation(c)), doc, req))
+}
+
+// serveGraphQL godoc
+// @Summary Ejecuta una operación GraphQL
+// @Description Ejecuta una query o una mutación sobre partidos, equipos, temporadas y eventos. El esquema se obtiene
+// @Description por introspección. Los errores de los resolvers incluyen el código del catálogo en extensions.code.
+// @Description Las mutaciones (createMatch, updateMatch, recordStat, finishMatch) reciben la versión del partido como
+// @Description argumento en lugar de If-Match; si no coincide, el error incluye extensions.currentVersion.
+// @Description Las suscripciones (matchChanged) se sirven por WebSocket en GET /api/graphql. La misma API se sirve también en /graphql.
+// @Tags GraphQL
+// @Accept json
+// @Produce json
+// @Param request body graphqlRequest true "Operación GraphQL"
+// @Param X-Actor header string false "Autor de las mutaciones registrado en la auditoría"
+// @Param Idempotency-Key header string false "Clave para reintentar la solicitud sin repetir la escritura"
+// @Success 200 {object} graphqlResponse
+// @Failure 400 {object} graphqlResponse
+// @Router /graphql [post]
+func serveGraphQL(c *gin.Context) {
+	var req graphqlRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "INVALID_BODY")
+		return
+	}
+	runGraphQL(c, req)
+}
+
+// serveGraphQLQuery godoc
+// @Summary Ejecuta una query GraphQL por GET o abre una suscripción
+// @Description Alternativa a POST para queries; las mutaciones responden 405. Con Upgrade: websocket abre una conexión
+// @Description con el subprotocolo graphql-transport-ws, por la que se envían queries, mutaciones y suscripciones.
+// @Tags GraphQL
+// @Produce json
+// @Param query query string false "Documento GraphQL (obligatorio salvo al abrir una conexión WebSocket)"
+// @Param operationName query string false "Operación a ejecutar si el documento tiene varias"
+// @Param variables query string false "Variables en JSON"
+// @Success 200 {object} graphqlResponse
+// @Failure 400 {object} graphqlResponse
+// @Failure 405 {object} map[string]string
+// @Router /graphql [get]
+func serveGraphQLQuery(c *gin.Context) {
+	if c.IsWebsocket() {
+		serveGraphQLWebSocket(c)
+		return
+	}
+	req := graphqlRequest{Query: c.Query("query"), OperationName: c.Query("operationName")}
+	if variables := c.Query("variables"); variables != "" {
+		if err := json.Unmarshal([]byte(variables), &req.Variables); err != nil {
+			respondError(c, http.StatusBadRequest, "INVALID_BODY")
+			return
+		}
+	}
+	runGraphQL(c, req)
+}
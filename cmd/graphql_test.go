@@ -0,0 +1,264 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	"lab6/internal"
+)
+
+// graphqlTestResponse es la respuesta de /graphql con los errores ya decodificados.
+type graphqlTestResponse struct {
+	Data   map[string]any `json:"data"`
+	Errors []struct {
+		Message    string         `json:"message"`
+		Extensions map[string]any `json:"extensions"`
+	} `json:"errors"`
+	Code string `json:"code"`
+}
+
+// doGraphQL envía la operación a /graphql en inglés y retorna el status y la respuesta.
+func doGraphQL(t *testing.T, method, query string) (*httptest.ResponseRecorder, graphqlTestResponse) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	if method == http.MethodGet {
+		c.Request = httptest.NewRequest(method, "/api/graphql?query="+url.QueryEscape(query), nil)
+	} else {
+		body, _ := json.Marshal(graphqlRequest{Query: query})
+		c.Request = httptest.NewRequest(method, "/api/graphql", strings.NewReader(string(body)))
+		c.Request.Header.Set("Content-Type", "application/json")
+	}
+	c.Set(languageContextKey, negotiateLanguage("en"))
+
+	if method == http.MethodGet {
+		serveGraphQLQuery(c)
+	} else {
+		serveGraphQL(c)
+	}
+	var response graphqlTestResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("respuesta inválida %q: %v", w.Body.String(), err)
+	}
+	return w, response
+}
+
+func graphqlMatchRows() *sqlmock.Rows {
+	return sqlmock.NewRows([]string{"id", "home_team", "away_team", "match_date", "version",
+		"goals_match", "yellow_cards_match", "red_cards_match", "extra_time", "finished", "date_only"})
+}
+
+// TestGraphQLBatchesNestedFields verifica que pedir los equipos y los eventos de cada partido de
+// una lista consulte una sola vez los equipos y una sola vez los eventos.
+func TestGraphQLBatchesNestedFields(t *testing.T) {
+	mock := mockDB(t)
+	mock.MatchExpectationsInOrder(false)
+	kickoff := time.Date(2025, 4, 5, 19, 0, 0, 0, time.UTC)
+	mock.ExpectQuery(`FROM matches WHERE deleted_at IS NULL AND match_date >= \$1`).
+		WithArgs(time.Date(2025, 4, 5, 0, 0, 0, 0, time.UTC), time.Date(2025, 4, 6, 0, 0, 0, 0, time.UTC)).
+		WillReturnRows(graphqlMatchRows().
+			AddRow(1, "Betis", "Sevilla", kickoff, 3, 1, 1, 0, false, false, false).
+			AddRow(2, "Getafe", "Betis", kickoff.Add(2*time.Hour), 1, 0, 0, 0, false, false, false))
+	// Getafe no está en el registro de equipos
+	mock.ExpectQuery(`FROM teams t\s+LEFT JOIN team_aliases a ON a.team_id = t.id AND a.alias <> t.name\s+WHERE t.name = ANY\(\$1\)`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "stadium", "aliases"}).
+			AddRow(1, "Betis", "", "{Real Betis}").
+			AddRow(2, "Sevilla", "", "{}"))
+	mock.ExpectQuery(`FROM match_events\s+WHERE match_id = ANY\(\$1\)`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "match_id", "sequence", "type", "data", "occurred_at", "clock_minute", "clock_stoppage"}).
+			AddRow(10, 1, 1, internal.EventMatchScheduled, []byte(`{}`), kickoff, nil, nil).
+			AddRow(11, 1, 2, internal.EventGoalScored, []byte(`{"team":"home"}`), kickoff, 23, 0).
+			AddRow(12, 1, 3, internal.EventCardShown, []byte(`{"color":"yellow"}`), kickoff, 45, 2))
+
+	w, response := doGraphQL(t, http.MethodPost, `{
+        matches(date: "2025-04-05") {
+            id
+            homeTeam { id name aliases }
+            awayTeam { id name }
+            events(types: ["GoalScored", "CardShown"]) { type data clock { display } }
+        }
+    }`)
+	if w.Code != http.StatusOK || len(response.Errors) != 0 {
+		t.Fatalf("status = %d, errores = %+v", w.Code, response.Errors)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+
+	got, _ := json.Marshal(response.Data)
+	want := `{"matches":[` +
+		`{"awayTeam":{"id":2,"name":"Sevilla"},` +
+		`"events":[{"clock":{"display":"23'"},"data":{"team":"home"},"type":"GoalScored"},{"clock":{"display":"45+2'"},"data":{"color":"yellow"},"type":"CardShown"}],` +
+		`"homeTeam":{"aliases":["Real Betis"],"id":1,"name":"Betis"},"id":1},` +
+		`{"awayTeam":{"id":1,"name":"Betis"},"events":[],"homeTeam":{"aliases":[],"id":null,"name":"Getafe"},"id":2}]}`
+	if string(got) != want {
+		t.Errorf("data =\n%s\nse esperaba\n%s", got, want)
+	}
+}
+
+func TestGraphQLResolverErrors(t *testing.T) {
+	tests := []struct {
+		name           string
+		query          string
+		setup          func(mock sqlmock.Sqlmock)
+		wantData       string
+		wantMessage    string
+		wantExtensions map[string]any
+	}{
+		{
+			name:  "partido inexistente es nulo sin error",
+			query: `{ match(id: 9) { id } }`,
+			setup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`FROM matches WHERE id = \$1 AND deleted_at IS NULL`).WithArgs(9).WillReturnError(sql.ErrNoRows)
+			},
+			wantData: `{"match":null}`,
+		},
+		{
+			name:  "equipo desconocido con sugerencias",
+			query: `{ team(name: "Betiz") { name } }`,
+			setup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`WHERE a.alias_key = team_key\(\$1\)`).WithArgs("Betiz").WillReturnError(sql.ErrNoRows)
+				mock.ExpectQuery(`similarity`).WillReturnRows(sqlmock.NewRows([]string{"name"}).AddRow("Betis"))
+			},
+			wantData:       `{"team":null}`,
+			wantMessage:    `Unknown team: "Betiz"`,
+			wantExtensions: map[string]any{"code": "UNKNOWN_TEAM", "team": "Betiz", "suggestions": []any{"Betis"}},
+		},
+		{
+			name:           "fecha inválida",
+			query:          `{ matches(date: "05/04/2025") { id } }`,
+			wantData:       `null`,
+			wantMessage:    "Invalid date, use the YYYY-MM-DD format",
+			wantExtensions: map[string]any{"code": "INVALID_DATE"},
+		},
+		{
+			name:  "error interno con el detalle",
+			query: `{ teams { name } }`,
+			setup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`FROM teams t`).WillReturnError(sql.ErrConnDone)
+			},
+			wantData:       `null`,
+			wantMessage:    "Internal server error",
+			wantExtensions: map[string]any{"code": "INTERNAL_ERROR", "detail": sql.ErrConnDone.Error()},
+		},
+		{
+			name:  "versión desactualizada informa la versión actual",
+			query: `mutation { finishMatch(id: 1, version: 3) { id } }`,
+			setup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery(regexp.QuoteMeta("SELECT to_jsonb(m)")).WithArgs(1).
+					WillReturnRows(sqlmock.NewRows([]string{"snapshot"}).AddRow([]byte(`{"id": 1, "version": 5}`)))
+				mock.ExpectQuery(`FROM matches WHERE id = \$1`).WithArgs(1).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "home_team", "away_team", "match_date", "version", "goals_match",
+						"yellow_cards_match", "red_cards_match", "extra_time", "finished", "date_only", "deleted_at",
+						"clock_period", "clock_started_at", "clock_stoppage"}).
+						AddRow(1, "Betis", "Sevilla", time.Date(2025, 4, 5, 19, 0, 0, 0, time.UTC), 5, 0, 0, 0, false, false, false, nil, "", nil, 0))
+				mock.ExpectRollback()
+				mock.ExpectQuery(`FROM matches WHERE id = \$1 AND deleted_at IS NULL`).WithArgs(1).
+					WillReturnRows(graphqlMatchRows().AddRow(1, "Betis", "Sevilla", time.Date(2025, 4, 5, 19, 0, 0, 0, time.UTC), 5, 0, 0, 0, false, false, false))
+			},
+			wantData:       `null`,
+			wantExtensions: map[string]any{"code": "VERSION_MISMATCH", "currentVersion": float64(5)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := mockDB(t)
+			if tt.setup != nil {
+				tt.setup(mock)
+			}
+
+			w, response := doGraphQL(t, http.MethodPost, tt.query)
+			if w.Code != http.StatusOK {
+				t.Fatalf("status = %d, se esperaba 200: %s", w.Code, w.Body.String())
+			}
+			if got, _ := json.Marshal(response.Data); string(got) != tt.wantData {
+				t.Errorf("data = %s, se esperaba %s", got, tt.wantData)
+			}
+			if tt.wantExtensions == nil {
+				if len(response.Errors) != 0 {
+					t.Errorf("errores = %+v, no se esperaba ninguno", response.Errors)
+				}
+			} else {
+				if len(response.Errors) != 1 {
+					t.Fatalf("errores = %+v, se esperaba uno", response.Errors)
+				}
+				gotExtensions, _ := json.Marshal(response.Errors[0].Extensions)
+				wantExtensions, _ := json.Marshal(tt.wantExtensions)
+				if string(gotExtensions) != string(wantExtensions) {
+					t.Errorf("extensions = %s, se esperaba %s", gotExtensions, wantExtensions)
+				}
+				if tt.wantMessage != "" && response.Errors[0].Message != tt.wantMessage {
+					t.Errorf("mensaje = %q, se esperaba %q", response.Errors[0].Message, tt.wantMessage)
+				}
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func TestGraphQLRequestErrors(t *testing.T) {
+	tests := []struct {
+		name       string
+		method     string
+		query      string
+		wantStatus int
+		wantCode   string
+		wantErrors bool
+	}{
+		{name: "sin query", method: http.MethodPost, wantStatus: http.StatusBadRequest, wantCode: "GRAPHQL_MISSING_QUERY"},
+		{name: "error de sintaxis", method: http.MethodPost, query: `{ matches { id }`, wantStatus: http.StatusBadRequest, wantErrors: true},
+		{name: "campo inexistente", method: http.MethodPost, query: `{ players { id } }`, wantStatus: http.StatusBadRequest, wantErrors: true},
+		{
+			name:       "suscripción por HTTP",
+			method:     http.MethodPost,
+			query:      `subscription { matchChanged { deleted } }`,
+			wantStatus: http.StatusBadRequest,
+			wantCode:   "GRAPHQL_SUBSCRIPTION_OVER_HTTP",
+		},
+		{
+			name:       "mutación por GET",
+			method:     http.MethodGet,
+			query:      `mutation { finishMatch(id: 1, version: 1) { id } }`,
+			wantStatus: http.StatusMethodNotAllowed,
+			wantCode:   "GRAPHQL_MUTATION_OVER_GET",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := mockDB(t)
+
+			w, response := doGraphQL(t, tt.method, tt.query)
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, se esperaba %d: %s", w.Code, tt.wantStatus, w.Body.String())
+			}
+			if response.Code != tt.wantCode {
+				t.Errorf("code = %q, se esperaba %q", response.Code, tt.wantCode)
+			}
+			if tt.wantErrors != (len(response.Errors) > 0) {
+				t.Errorf("errores = %+v", response.Errors)
+			}
+			if tt.method == http.MethodGet && w.Header().Get("Allow") != http.MethodPost {
+				t.Errorf("Allow = %q, se esperaba POST", w.Header().Get("Allow"))
+			}
+			// Ninguna de estas solicitudes llega a consultar la base
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
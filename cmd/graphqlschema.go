@@ -0,0 +1,579 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log"
+	"slices"
+	"time"
+
+	"github.com/graphql-go/graphql"
+	"lab6/internal"
+)
+
+// graphqlStats son los valores del enum Stat y la estadística interna a la que corresponden.
+var graphqlStats = map[string]string{
+	"GOALS":        internal.StatGoals,
+	"YELLOW_CARDS": internal.StatYellowCards,
+	"RED_CARDS":    internal.StatRedCards,
+	"EXTRA_TIME":   internal.StatExtraTime,
+}
+
+// graphqlSchema es el esquema servido en /graphql.
+var graphqlSchema = mustBuildGraphQLSchema()
+
+// jsonScalar entrega los datos de un evento tal como están en el log.
+var jsonScalar = graphql.NewScalar(graphql.ScalarConfig{
+	Name:        "JSON",
+	Description: "Valor JSON arbitrario, como los datos de un evento.",
+	Serialize: func(value interface{}) interface{} {
+		raw, ok := value.(json.RawMessage)
+		if !ok {
+			return value
+		}
+		var decoded any
+		if len(raw) == 0 || json.Unmarshal(raw, &decoded) != nil {
+			return nil
+		}
+		return decoded
+	},
+})
+
+// nonNullList es el tipo [T!]!.
+func nonNullList(t graphql.Type) *graphql.NonNull {
+	return graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(t)))
+}
+
+// field crea un campo no nulo que se resuelve a partir del valor de tipo S del objeto padre.
+func field[S any](t graphql.Output, resolve func(S) any) *graphql.Field {
+	return &graphql.Field{
+		Type: graphql.NewNonNull(t),
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			return resolve(p.Source.(S)), nil
+		},
+	}
+}
+
+// mustBuildGraphQLSchema arma el esquema. Los tipos se referencian entre sí (un partido tiene
+// equipos y un equipo tiene partidos), por eso sus campos se declaran con thunks.
+func mustBuildGraphQLSchema() graphql.Schema {
+	var teamType, seasonType, matchType *graphql.Object
+
+	clockType := graphql.NewObject(graphql.ObjectConfig{
+		Name:        "ClockMinute",
+		Description: "Minuto del reloj del partido, con el descuento si lo hay.",
+		Fields: graphql.Fields{
+			"minute":   field(graphql.Int, func(c internal.ClockMinute) any { return c.Minute }),
+			"stoppage": field(graphql.Int, func(c internal.ClockMinute) any { return c.Stoppage }),
+			"display": {
+				Type:        graphql.NewNonNull(graphql.String),
+				Description: "Minuto tal como se muestra en el marcador, por ejemplo 45+2'.",
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return p.Source.(internal.ClockMinute).String(), nil
+				},
+			},
+		},
+	})
+
+	eventType := graphql.NewObject(graphql.ObjectConfig{
+		Name:        "MatchEvent",
+		Description: "Evento del log de un partido. sequence coincide con la versión del partido después del evento.",
+		Fields: graphql.Fields{
+			"id":         field(graphql.Int, func(e internal.MatchEvent) any { return e.ID }),
+			"matchId":    field(graphql.Int, func(e internal.MatchEvent) any { return e.MatchID }),
+			"sequence":   field(graphql.Int, func(e internal.MatchEvent) any { return e.Sequence }),
+			"type":       field(graphql.String, func(e internal.MatchEvent) any { return e.Type }),
+			"data":       {Type: jsonScalar, Resolve: func(p graphql.ResolveParams) (interface{}, error) { return p.Source.(internal.MatchEvent).Data, nil }},
+			"occurredAt": field(graphql.DateTime, func(e internal.MatchEvent) any { return e.OccurredAt }),
+			"clock": {
+				Type:        clockType,
+				Description: "Minuto en que ocurrió el evento; nulo si el reloj no estaba en marcha.",
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					if clock := p.Source.(internal.MatchEvent).Clock; clock != nil {
+						return *clock, nil
+					}
+					return nil, nil
+				},
+			},
+		},
+	})
+
+	scoreType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Score",
+		Fields: graphql.Fields{
+			"goals": field(graphql.Int, func(m internal.Match) any { return m.Goals }),
+		},
+	})
+
+	cardsType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Cards",
+		Fields: graphql.Fields{
+			"yellow": field(graphql.Int, func(m internal.Match) any { return m.YellowCards }),
+			"red":    field(graphql.Int, func(m internal.Match) any { return m.RedCards }),
+		},
+	})
+
+	teamType = graphql.NewObject(graphql.ObjectConfig{
+		Name:        "Team",
+		Description: "Equipo con su nombre canónico y sus alias.",
+		Fields: graphql.FieldsThunk(func() graphql.Fields {
+			return graphql.Fields{
+				"id": {
+					Type:        graphql.Int,
+					Description: "Nulo si el nombre del partido no está en el registro de equipos.",
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						if id := p.Source.(internal.Team).ID; id != 0 {
+							return id, nil
+						}
+						return nil, nil
+					},
+				},
+				"name":    field(graphql.String, func(t internal.Team) any { return t.Name }),
+				"aliases": field(graphql.NewList(graphql.NewNonNull(graphql.String)), func(t internal.Team) any { return t.Aliases }),
+				"matches": {
+					Type:        nonNullList(matchType),
+					Description: "Partidos del equipo, de local o de visitante, ordenados por fecha.",
+					Args: graphql.FieldConfigArgument{
+						"season": {Type: graphql.Int, Description: "Solo los partidos de la temporada, por su año de inicio."},
+					},
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						season, bySeason := p.Args["season"].(int)
+						return graphqlLoadersFrom(p.Context).teamMatches.LoadThen(p.Source.(internal.Team).Name, func(matches []internal.Match) any {
+							result := []internal.Match{}
+							for _, m := range matches {
+								if !bySeason || internal.SeasonOf(m.MatchDate) == season {
+									result = append(result, m)
+								}
+							}
+							return result
+						}), nil
+					},
+				},
+			}
+		}),
+	})
+
+	seasonType = graphql.NewObject(graphql.ObjectConfig{
+		Name:        "Season",
+		Description: "Temporada de La Liga, de julio a junio, identificada por su año de inicio.",
+		Fields: graphql.FieldsThunk(func() graphql.Fields {
+			return graphql.Fields{
+				"year": field(graphql.Int, func(s internal.Season) any { return s.Year }),
+				"name": field(graphql.String, func(s internal.Season) any { return s.Name() }),
+				"from": field(graphql.DateTime, func(s internal.Season) any {
+					from, _ := internal.SeasonBounds(s.Year)
+					return from
+				}),
+				"to": field(graphql.DateTime, func(s internal.Season) any {
+					_, to := internal.SeasonBounds(s.Year)
+					return to
+				}),
+				"matchCount":    field(graphql.Int, func(s internal.Season) any { return s.Matches }),
+				"finishedCount": field(graphql.Int, func(s internal.Season) any { return s.Finished }),
+				"matches": {
+					Type:        nonNullList(matchType),
+					Description: "Partidos de la temporada, ordenados por fecha.",
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						return graphqlLoadersFrom(p.Context).seasonMatches.LoadThen(p.Source.(internal.Season).Year, nonNilMatches), nil
+					},
+				},
+			}
+		}),
+	})
+
+	// resolveTeam entrega el equipo de un partido; si el nombre no está en el registro se
+	// retorna solo el nombre.
+	resolveTeam := func(name func(internal.Match) string) graphql.FieldResolveFn {
+		return func(p graphql.ResolveParams) (interface{}, error) {
+			team := name(p.Source.(internal.Match))
+			return graphqlLoadersFrom(p.Context).teams.LoadThen(team, func(t internal.Team) any {
+				if t.Name == "" {
+					return internal.Team{Name: team, Aliases: []string{}}
+				}
+				return t
+			}), nil
+		}
+	}
+
+	matchType = graphql.NewObject(graphql.ObjectConfig{
+		Name:        "Match",
+		Description: "Partido de La Liga.",
+		Fields: graphql.FieldsThunk(func() graphql.Fields {
+			return graphql.Fields{
+				"id":       field(graphql.Int, func(m internal.Match) any { return m.ID }),
+				"version":  field(graphql.Int, func(m internal.Match) any { return m.Version }),
+				"homeTeam": {Type: graphql.NewNonNull(teamType), Resolve: resolveTeam(func(m internal.Match) string { return m.HomeTeam })},
+				"awayTeam": {Type: graphql.NewNonNull(teamType), Resolve: resolveTeam(func(m internal.Match) string { return m.AwayTeam })},
+				"kickoff":  field(graphql.DateTime, func(m internal.Match) any { return m.MatchDate }),
+				"season": {
+					Type: graphql.NewNonNull(seasonType),
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						year := internal.SeasonOf(p.Source.(internal.Match).MatchDate)
+						return graphqlLoadersFrom(p.Context).seasons.LoadThen(year, func(s internal.Season) any {
+							s.Year = year
+							return s
+						}), nil
+					},
+				},
+				"score":     field(scoreType, func(m internal.Match) any { return m }),
+				"cards":     field(cardsType, func(m internal.Match) any { return m }),
+				"extraTime": field(graphql.Boolean, func(m internal.Match) any { return m.ExtraTime }),
+				"finished":  field(graphql.Boolean, func(m internal.Match) any { return m.Finished }),
+				"events": {
+					Type:        nonNullList(eventType),
+					Description: "Log de eventos del partido, en orden.",
+					Args: graphql.FieldConfigArgument{
+						"types": {Type: graphql.NewList(graphql.NewNonNull(graphql.String)), Description: "Solo los eventos de estos tipos, por ejemplo GoalScored."},
+					},
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						types, _ := p.Args["types"].([]interface{})
+						return graphqlLoadersFrom(p.Context).events.LoadThen(p.Source.(internal.Match).ID, func(events []internal.MatchEvent) any {
+							result := []internal.MatchEvent{}
+							for _, e := range events {
+								if types == nil || slices.Contains(types, any(e.Type)) {
+									result = append(result, e)
+								}
+							}
+							return result
+						}), nil
+					},
+				},
+			}
+		}),
+	})
+
+	changeType := graphql.NewObject(graphql.ObjectConfig{
+		Name:        "MatchChange",
+		Description: "Evento nuevo de un partido junto con el estado del partido después de aplicarlo.",
+		Fields: graphql.Fields{
+			"event":   field(eventType, func(c internal.MatchChange) any { return c.Event }),
+			"match":   field(matchType, func(c internal.MatchChange) any { return c.Match }),
+			"deleted": field(graphql.Boolean, func(c internal.MatchChange) any { return c.Deleted }),
+		},
+	})
+
+	statValues := graphql.EnumValueConfigMap{}
+	for name, stat := range graphqlStats {
+		statValues[name] = &graphql.EnumValueConfig{Value: stat}
+	}
+	statEnum := graphql.NewEnum(graphql.EnumConfig{
+		Name:        "Stat",
+		Description: "Estadística que registra recordStat.",
+		Values:      statValues,
+	})
+
+	matchInput := graphql.NewInputObject(graphql.InputObjectConfig{
+		Name: "MatchInput",
+		Fields: graphql.InputObjectConfigFieldMap{
+			"homeTeam": {Type: graphql.NewNonNull(graphql.String), Description: "Nombre o alias del equipo local."},
+			"awayTeam": {Type: graphql.NewNonNull(graphql.String), Description: "Nombre o alias del equipo visitante."},
+			"kickoff":  {Type: graphql.NewNonNull(graphql.DateTime), Description: "Inicio del partido en RFC3339."},
+		},
+	})
+
+	writeArgs := graphql.FieldConfigArgument{
+		"id":      {Type: graphql.NewNonNull(graphql.Int)},
+		"version": {Type: graphql.NewNonNull(graphql.Int), Description: "Versión del partido leída por el cliente, como If-Match."},
+	}
+	withArgs := func(args graphql.FieldConfigArgument, extra graphql.FieldConfigArgument) graphql.FieldConfigArgument {
+		merged := graphql.FieldConfigArgument{}
+		for name, arg := range args {
+			merged[name] = arg
+		}
+		for name, arg := range extra {
+			merged[name] = arg
+		}
+		return merged
+	}
+
+	query := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"match": {
+				Type: matchType,
+				Args: graphql.FieldConfigArgument{"id": {Type: graphql.NewNonNull(graphql.Int)}},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					match, err := internal.GetMatchByID(p.Args["id"].(int))
+					if errors.Is(err, sql.ErrNoRows) {
+						return nil, nil
+					}
+					if err != nil {
+						return nil, toGraphQLError(p.Context, err)
+					}
+					return match, nil
+				},
+			},
+			"matches": {
+				Type:        nonNullList(matchType),
+				Description: "Partidos ordenados por fecha. Los filtros se combinan.",
+				Args: graphql.FieldConfigArgument{
+					"date":     {Type: graphql.String, Description: "Día del partido (YYYY-MM-DD, UTC), por ejemplo el de una jornada."},
+					"from":     {Type: graphql.DateTime, Description: "Partidos que comienzan en este instante o después."},
+					"to":       {Type: graphql.DateTime, Description: "Partidos que comienzan antes de este instante."},
+					"season":   {Type: graphql.Int, Description: "Temporada, por su año de inicio."},
+					"team":     {Type: graphql.String, Description: "Nombre o alias de un equipo."},
+					"finished": {Type: graphql.Boolean},
+				},
+				Resolve: resolveMatches,
+			},
+			"team": {
+				Type: teamType,
+				Args: graphql.FieldConfigArgument{"name": {Type: graphql.NewNonNull(graphql.String), Description: "Nombre o alias del equipo."}},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					name, err := internal.ResolveTeam(p.Args["name"].(string))
+					if err != nil {
+						return nil, toGraphQLError(p.Context, err)
+					}
+					return graphqlLoadersFrom(p.Context).teams.Load(name), nil
+				},
+			},
+			"teams": {
+				Type: nonNullList(teamType),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					teams, err := internal.GetTeams()
+					if err != nil {
+						return nil, toGraphQLError(p.Context, err)
+					}
+					return teams, nil
+				},
+			},
+			"season": {
+				Type:        seasonType,
+				Description: "Temporada por su año de inicio; nula si no tiene partidos.",
+				Args:        graphql.FieldConfigArgument{"year": {Type: graphql.NewNonNull(graphql.Int)}},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					seasons, err := internal.GetSeasonsByYear([]int{p.Args["year"].(int)})
+					if err != nil {
+						return nil, toGraphQLError(p.Context, err)
+					}
+					if len(seasons) == 0 {
+						return nil, nil
+					}
+					return seasons[0], nil
+				},
+			},
+			"seasons": {
+				Type:        nonNullList(seasonType),
+				Description: "Temporadas con partidos, de la más reciente a la más antigua.",
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					seasons, err := internal.GetSeasons()
+					if err != nil {
+						return nil, toGraphQLError(p.Context, err)
+					}
+					return seasons, nil
+				},
+			},
+		},
+	})
+
+	mutation := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Mutation",
+		Fields: graphql.Fields{
+			"createMatch": {
+				Type: graphql.NewNonNull(matchType),
+				Args: graphql.FieldConfigArgument{"input": {Type: graphql.NewNonNull(matchInput)}},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					m := toInternalMatchInput(p.Args["input"])
+					id, err := internal.CreateMatch(m, graphqlOperationFrom(p.Context).audit)
+					return writtenMatch(p, id, err)
+				},
+			},
+			"updateMatch": {
+				Type:        graphql.NewNonNull(matchType),
+				Description: "Reprograma el partido. Falla con VERSION_MISMATCH si version no es la actual.",
+				Args:        withArgs(writeArgs, graphql.FieldConfigArgument{"input": {Type: graphql.NewNonNull(matchInput)}}),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					m := toInternalMatchInput(p.Args["input"])
+					m.ID, m.Version = p.Args["id"].(int), p.Args["version"].(int)
+					return writtenMatch(p, m.ID, internal.UpdateMatch(m, graphqlOperationFrom(p.Context).audit))
+				},
+			},
+			"recordStat": {
+				Type:        graphql.NewNonNull(matchType),
+				Description: "Registra un gol, una tarjeta o el inicio del tiempo extra.",
+				Args:        withArgs(writeArgs, graphql.FieldConfigArgument{"stat": {Type: graphql.NewNonNull(statEnum)}}),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					id := p.Args["id"].(int)
+					err := internal.IncrementStat(p.Args["stat"].(string), id, p.Args["version"].(int), graphqlOperationFrom(p.Context).audit)
+					return writtenMatch(p, id, err)
+				},
+			},
+			"finishMatch": {
+				Type: graphql.NewNonNull(matchType),
+				Args: writeArgs,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					id := p.Args["id"].(int)
+					err := internal.FinishMatch(id, p.Args["version"].(int), graphqlOperationFrom(p.Context).audit)
+					return writtenMatch(p, id, err)
+				},
+			},
+		},
+	})
+
+	subscription := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Subscription",
+		Fields: graphql.Fields{
+			"matchChanged": {
+				Type:        graphql.NewNonNull(changeType),
+				Description: "Cada evento nuevo de un partido, o de todos si se omite matchId.",
+				Args:        graphql.FieldConfigArgument{"matchId": {Type: graphql.Int}},
+				Subscribe:   subscribeMatchChanges,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return p.Source, nil
+				},
+			},
+		},
+	})
+
+	schema, err := graphql.NewSchema(graphql.SchemaConfig{
+		Query:        query,
+		Mutation:     mutation,
+		Subscription: subscription,
+	})
+	if err != nil {
+		panic(err)
+	}
+	return schema
+}
+
+// nonNilMatches evita que una lista vacía de partidos se entregue como null.
+func nonNilMatches(matches []internal.Match) any {
+	if matches == nil {
+		return []internal.Match{}
+	}
+	return matches
+}
+
+// toInternalMatchInput convierte el argumento MatchInput al modelo interno.
+func toInternalMatchInput(arg interface{}) internal.Match {
+	input := arg.(map[string]interface{})
+	return internal.Match{
+		HomeTeam:  input["homeTeam"].(string),
+		AwayTeam:  input["awayTeam"].(string),
+		MatchDate: input["kickoff"].(time.Time),
+	}
+}
+
+// writtenMatch entrega el partido después de una mutación, o el error de la escritura. Si la
+// versión no coincide, el error informa la versión actual en extensions.currentVersion.
+func writtenMatch(p graphql.ResolveParams, id int, err error) (interface{}, error) {
+	if err != nil {
+		gqlErr := toGraphQLError(p.Context, err)
+		if errors.Is(err, internal.ErrVersionMismatch) {
+			if current, getErr := internal.GetMatchByID(id); getErr == nil {
+				gqlErr.extensions["currentVersion"] = current.Version
+			}
+		}
+		return nil, gqlErr
+	}
+	match, err := internal.GetMatchByID(id)
+	if err != nil {
+		return nil, toGraphQLError(p.Context, err)
+	}
+	return match, nil
+}
+
+// resolveMatches resuelve Query.matches: consulta por equipo o por intervalo de fechas y aplica
+// el resto de los filtros sobre el resultado.
+func resolveMatches(p graphql.ResolveParams) (interface{}, error) {
+	from, to := time.Time{}, time.Date(9999, time.January, 1, 0, 0, 0, 0, time.UTC)
+	narrow := func(f, t time.Time) {
+		if f.After(from) {
+			from = f
+		}
+		if t.Before(to) {
+			to = t
+		}
+	}
+	if season, ok := p.Args["season"].(int); ok {
+		narrow(internal.SeasonBounds(season))
+	}
+	if date, ok := p.Args["date"].(string); ok {
+		day, err := time.Parse(time.DateOnly, date)
+		if err != nil {
+			return nil, toGraphQLError(p.Context, newAPIError("INVALID_DATE"))
+		}
+		narrow(day, day.AddDate(0, 0, 1))
+	}
+	if value, ok := p.Args["from"].(time.Time); ok {
+		narrow(value, to)
+	}
+	if value, ok := p.Args["to"].(time.Time); ok {
+		narrow(from, value)
+	}
+
+	var matches []internal.Match
+	var err error
+	if name, ok := p.Args["team"].(string); ok {
+		team, resolveErr := internal.ResolveTeam(name)
+		if resolveErr != nil {
+			return nil, toGraphQLError(p.Context, resolveErr)
+		}
+		matches, err = internal.GetMatchesByTeam(team)
+	} else {
+		matches, err = internal.GetMatchesBetween(from, to)
+	}
+	if err != nil {
+		return nil, toGraphQLError(p.Context, err)
+	}
+
+	finished, byFinished := p.Args["finished"].(bool)
+	result := []internal.Match{}
+	for _, m := range matches {
+		if m.MatchDate.Before(from) || !m.MatchDate.Before(to) || (byFinished && m.Finished != finished) {
+			continue
+		}
+		result = append(result, m)
+	}
+	return result, nil
+}
+
+// subscribeMatchChanges entrega por el canal cada evento registrado después de la suscripción,
+// igual que el stream SSE, hasta que la operación se cancela.
+func subscribeMatchChanges(p graphql.ResolveParams) (interface{}, error) {
+	matchID, _ := p.Args["matchId"].(int)
+	after, err := internal.LatestEventID(matchID)
+	if err != nil {
+		return nil, toGraphQLError(p.Context, err)
+	}
+
+	// Se suscribe antes de ponerse al día para no perder avisos entre la consulta y la espera
+	changes, unsubscribe := internal.SubscribeChanges()
+	results := make(chan interface{})
+	go func() {
+		defer close(results)
+		defer unsubscribe()
+
+		heartbeat := time.NewTicker(streamHeartbeat)
+		defer heartbeat.Stop()
+		for {
+			for {
+				batch, err := internal.GetMatchChanges(after, matchID, streamBatchSize)
+				if err != nil {
+					log.Printf("error al consultar eventos para una suscripción GraphQL: %v", err)
+					return
+				}
+				for _, change := range batch {
+					select {
+					case results <- change:
+					case <-p.Context.Done():
+						return
+					}
+					after = change.Event.ID
+				}
+				if len(batch) < streamBatchSize {
+					break
+				}
+			}
+
+			select {
+			case <-p.Context.Done():
+				return
+			case <-changes:
+			case <-heartbeat.C:
+			}
+		}
+	}()
+	return results, nil
+}
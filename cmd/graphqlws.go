@@ -0,0 +1,281 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/language/ast"
+)
+
+const (
+	// graphqlWSProtocol es el subprotocolo de GraphQL sobre WebSocket que implementa /graphql.
+	graphqlWSProtocol = "graphql-transport-ws"
+
+	// graphqlWSInitTimeout es el tiempo que tiene el cliente para enviar connection_init.
+	graphqlWSInitTimeout = 10 * time.Second
+
+	// graphqlWSMaxMessageSize limita el tamaño de los mensajes del cliente, que incluyen la consulta.
+	graphqlWSMaxMessageSize = 64 << 10
+)
+
+// Códigos de cierre definidos por graphql-transport-ws.
+const (
+	graphqlWSInvalidMessage  = 4400
+	graphqlWSUnauthorized    = 4401
+	graphqlWSInitTimedOut    = 4408
+	graphqlWSDuplicateID     = 4409
+	graphqlWSTooManyInitReqs = 4429
+)
+
+// graphqlWSMessage es un mensaje de graphql-transport-ws, en cualquiera de los dos sentidos.
+type graphqlWSMessage struct {
+	ID      string          `json:"id,omitempty"`
+	Type    string          `json:"type"`
+	Payload json.RawMessage `json:"payload,omitempty"`
+}
+
+// graphqlUpgrader acepta los mismos orígenes que /ws y negocia graphql-transport-ws.
+var graphqlUpgrader = websocket.Upgrader{
+	ReadBufferSize:  wsUpgrader.ReadBufferSize,
+	WriteBufferSize: wsUpgrader.WriteBufferSize,
+	CheckOrigin:     wsUpgrader.CheckOrigin,
+	Subprotocols:    []string{graphqlWSProtocol},
+}
+
+// graphqlWSConn es una conexión GraphQL por WebSocket con sus operaciones en curso.
+type graphqlWSConn struct {
+	conn      *websocket.Conn
+	operation graphqlOperation
+
+	writeMu sync.Mutex
+	once    sync.Once
+
+	mu         sync.Mutex
+	operations map[string]context.CancelFunc
+}
+
+// send escribe un mensaje. Las operaciones escriben desde sus propias goroutines, por eso las
+// escrituras se serializan.
+func (g *graphqlWSConn) send(id, messageType string, payload any) error {
+	msg := graphqlWSMessage{ID: id, Type: messageType}
+	if payload != nil {
+		data, err := json.Marshal(payload)
+		if err != nil {
+			return err
+		}
+		msg.Payload = data
+	}
+
+	g.writeMu.Lock()
+	defer g.writeMu.Unlock()
+	g.conn.SetWriteDeadline(time.Now().Add(wsWriteTimeout))
+	return g.conn.WriteJSON(msg)
+}
+
+// close cancela las operaciones en curso y cierra la conexión con el código indicado.
+func (g *graphqlWSConn) close(code int, reason string) {
+	g.once.Do(func() {
+		g.mu.Lock()
+		for id, cancel := range g.operations {
+			cancel()
+			delete(g.operations, id)
+		}
+		g.mu.Unlock()
+
+		g.writeMu.Lock()
+		g.conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(code, reason), time.Now().Add(wsWriteTimeout))
+		g.writeMu.Unlock()
+		g.conn.Close()
+	})
+}
+
+// start inicia la operación id. Retorna false si ya hay una operación en curso con ese id.
+func (g *graphqlWSConn) start(id string, req graphqlRequest) bool {
+	g.mu.Lock()
+	if _, ok := g.operations[id]; ok {
+		g.mu.Unlock()
+		return false
+	}
+	if len(g.operations) >= wsMaxSubscriptions {
+		g.mu.Unlock()
+		err := newAPIError("WS_TOO_MANY_SUBSCRIPTIONS", wsMaxSubscriptions)
+		g.send(id, "error", []map[string]any{{
+			"message":    message(g.operation.language, err.Code, err.Args...),
+			"extensions": map[string]any{"code": err.Code},
+		}})
+		return true
+	}
+	ctx, cancel := context.WithCancel(withGraphQLOperation(context.Background(), g.operation))
+	g.operations[id] = cancel
+	g.mu.Unlock()
+
+	go g.run(ctx, id, req)
+	return true
+}
+
+// stop cancela la operación id, si sigue en curso.
+func (g *graphqlWSConn) stop(id string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if cancel, ok := g.operations[id]; ok {
+		cancel()
+		delete(g.operations, id)
+	}
+}
+
+// run ejecuta la operación y envía sus resultados: uno solo para queries y mutaciones, y uno por
+// evento para las suscripciones, hasta que el cliente envía complete o se cierra la conexión.
+func (g *graphqlWSConn) run(ctx context.Context, id string, req graphqlRequest) {
+	defer g.stop(id)
+
+	doc, operation, result := parseGraphQL(req)
+	if result != nil {
+		g.send(id, "error", result.Errors)
+		return
+	}
+	if operation != ast.OperationTypeSubscription {
+		if g.send(id, "next", executeGraphQL(ctx, doc, req)) == nil {
+			g.send(id, "complete", nil)
+		}
+		return
+	}
+
+	results := graphql.ExecuteSubscription(graphql.ExecuteParams{
+		Schema:        graphqlSchema,
+		AST:           doc,
+		OperationName: req.OperationName,
+		Args:          req.Variables,
+		Context:       ctx,
+	})
+	defer func() {
+		// La ejecución puede estar bloqueada entregando un resultado que ya nadie va a leer
+		go func() {
+			for range results {
+			}
+		}()
+	}()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case result, ok := <-results:
+			if !ok {
+				g.send(id, "complete", nil)
+				return
+			}
+			if err := g.send(id, "next", result); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// serveGraphQLWebSocket atiende una conexión graphql-transport-ws: el cliente envía connection_init,
+// luego subscribe con cada operación (query, mutation o subscription) y complete para cancelarla, y
+// recibe next con cada resultado y complete al terminar. No se documenta en Swagger porque OpenAPI 2
+// no describe WebSocket.
+func serveGraphQLWebSocket(c *gin.Context) {
+	conn, err := graphqlUpgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		// Upgrade ya respondió al cliente con el error
+		return
+	}
+	g := &graphqlWSConn{conn: conn, operation: newGraphQLOperation(c), operations: map[string]context.CancelFunc{}}
+	if conn.Subprotocol() != graphqlWSProtocol {
+		g.close(websocket.CloseProtocolError, "se requiere el subprotocolo "+graphqlWSProtocol)
+		return
+	}
+	defer g.close(websocket.CloseNormalClosure, "")
+
+	conn.SetReadLimit(graphqlWSMaxMessageSize)
+	conn.SetReadDeadline(time.Now().Add(graphqlWSInitTimeout))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(wsPongTimeout))
+	})
+
+	acknowledged := false
+	done := make(chan struct{})
+	defer close(done)
+	for {
+		var msg graphqlWSMessage
+		if err := conn.ReadJSON(&msg); err != nil {
+			var netErr net.Error
+			var syntaxErr *json.SyntaxError
+			var typeErr *json.UnmarshalTypeError
+			switch {
+			case !acknowledged && errors.As(err, &netErr) && netErr.Timeout():
+				g.close(graphqlWSInitTimedOut, "Connection initialisation timeout")
+			case errors.As(err, &syntaxErr) || errors.As(err, &typeErr):
+				g.close(graphqlWSInvalidMessage, "Invalid message received")
+			}
+			return
+		}
+		if acknowledged {
+			conn.SetReadDeadline(time.Now().Add(wsPongTimeout))
+		}
+
+		switch msg.Type {
+		case "connection_init":
+			if acknowledged {
+				g.close(graphqlWSTooManyInitReqs, "Too many initialisation requests")
+				return
+			}
+			acknowledged = true
+			conn.SetReadDeadline(time.Now().Add(wsPongTimeout))
+			if err := g.send("", "connection_ack", nil); err != nil {
+				return
+			}
+			go g.keepAlive(done)
+		case "ping":
+			if err := g.send("", "pong", nil); err != nil {
+				return
+			}
+		case "pong":
+		case "subscribe":
+			if !acknowledged {
+				g.close(graphqlWSUnauthorized, "Unauthorized")
+				return
+			}
+			var req graphqlRequest
+			if msg.ID == "" || json.Unmarshal(msg.Payload, &req) != nil {
+				g.close(graphqlWSInvalidMessage, "Invalid message received")
+				return
+			}
+			if !g.start(msg.ID, req) {
+				g.close(graphqlWSDuplicateID, "Subscriber for "+msg.ID+" already exists")
+				return
+			}
+		case "complete":
+			g.stop(msg.ID)
+		default:
+			g.close(graphqlWSInvalidMessage, "Invalid message received")
+			return
+		}
+	}
+}
+
+// keepAlive envía un ping periódico, como /ws, para detectar conexiones caídas.
+func (g *graphqlWSConn) keepAlive(done <-chan struct{}) {
+	ping := time.NewTicker(wsPingInterval)
+	defer ping.Stop()
+	for {
+		select {
+		case <-done:
+			return
+		case <-ping.C:
+			g.writeMu.Lock()
+			err := g.conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(wsWriteTimeout))
+			g.writeMu.Unlock()
+			if err != nil {
+				return
+			}
+		}
+	}
+}
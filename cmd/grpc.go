@@ -0,0 +1,406 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/text/language"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"lab6/internal"
+	matchv1 "lab6/proto/match/v1"
+)
+
+// defaultGRPCAddr es la dirección en la que escucha el servidor gRPC si no se configura GRPC_ADDR.
+const defaultGRPCAddr = ":9090"
+
+// errorDomain identifica a esta API en el campo domain de los google.rpc.ErrorInfo.
+const errorDomain = "lab6"
+
+// grpcAddr retorna la dirección del servidor gRPC configurada en GRPC_ADDR.
+func grpcAddr() string {
+	value := os.Getenv("GRPC_ADDR")
+	if value == "" {
+		return defaultGRPCAddr
+	}
+	if _, _, err := net.SplitHostPort(value); err != nil {
+		log.Printf("GRPC_ADDR inválido (%q), se usa %v", value, defaultGRPCAddr)
+		return defaultGRPCAddr
+	}
+	return value
+}
+
+// runGRPCServer sirve MatchService en addr.
+func runGRPCServer(addr string) {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		log.Fatalf("Error al iniciar el servidor gRPC: %v", err)
+	}
+	if err := newGRPCServer().Serve(listener); err != nil {
+		log.Fatalf("Error en el servidor gRPC: %v", err)
+	}
+}
+
+// newGRPCServer crea el servidor con MatchService y sus interceptores. La reflexión queda
+// habilitada para que grpcurl y herramientas similares descubran el servicio sin el archivo .proto.
+func newGRPCServer() *grpc.Server {
+	server := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(grpcUnaryInterceptor),
+		grpc.ChainStreamInterceptor(grpcStreamInterceptor),
+	)
+	matchv1.RegisterMatchServiceServer(server, matchServer{})
+	reflection.Register(server)
+	return server
+}
+
+// grpcCall son los datos de la llamada que en HTTP aportan los middlewares: el idioma de los
+// mensajes y el autor de las escrituras.
+type grpcCall struct {
+	language language.Tag
+	audit    internal.AuditInfo
+}
+
+// grpcCallKey es la clave bajo la que se guarda el grpcCall en el contexto.
+type grpcCallKey struct{}
+
+// newGRPCCall toma de los metadatos los mismos datos que los encabezados Accept-Language,
+// X-Actor y X-Request-ID de HTTP. Sin x-actor, el autor es la dirección del cliente.
+func newGRPCCall(ctx context.Context) grpcCall {
+	md, _ := metadata.FromIncomingContext(ctx)
+	get := func(key string) string {
+		if values := md.Get(key); len(values) > 0 {
+			return strings.TrimSpace(values[0])
+		}
+		return ""
+	}
+
+	actor := get("x-actor")
+	if actor == "" {
+		if p, ok := peer.FromContext(ctx); ok {
+			actor, _, _ = net.SplitHostPort(p.Addr.String())
+		}
+	}
+	return grpcCall{
+		language: negotiateLanguage(get("accept-language")),
+		audit:    internal.AuditInfo{Actor: truncateActor(actor), RequestID: requestID(get("x-request-id"))},
+	}
+}
+
+// withGRPCCall guarda los datos de la llamada en el contexto y retorna el ID de solicitud en
+// los metadatos de respuesta, como X-Request-ID en HTTP.
+func withGRPCCall(ctx context.Context) context.Context {
+	call := newGRPCCall(ctx)
+	grpc.SetHeader(ctx, metadata.Pairs("x-request-id", call.audit.RequestID, "content-language", call.language.String()))
+	return context.WithValue(ctx, grpcCallKey{}, call)
+}
+
+// grpcCallFrom retorna los datos de la llamada guardados por los interceptores.
+func grpcCallFrom(ctx context.Context) grpcCall {
+	return ctx.Value(grpcCallKey{}).(grpcCall)
+}
+
+// grpcUnaryInterceptor cumple para las llamadas unarias el papel de requestIDMiddleware y
+// localeMiddleware.
+func grpcUnaryInterceptor(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	return handler(withGRPCCall(ctx), req)
+}
+
+// grpcServerStream reemplaza el contexto de un stream por el que incluye los datos de la llamada.
+type grpcServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s grpcServerStream) Context() context.Context {
+	return s.ctx
+}
+
+// grpcStreamInterceptor es el equivalente de grpcUnaryInterceptor para los streams.
+func grpcStreamInterceptor(srv any, stream grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	return handler(srv, grpcServerStream{ServerStream: stream, ctx: withGRPCCall(stream.Context())})
+}
+
+// grpcError traduce el error de una operación al status gRPC correspondiente, con el mensaje
+// localizado y un google.rpc.ErrorInfo cuyo reason es el código estable del catálogo.
+func grpcError(ctx context.Context, err error) error {
+	return grpcErrorWithMetadata(ctx, err, nil)
+}
+
+// grpcErrorWithMetadata es como grpcError pero agrega metadata al ErrorInfo.
+func grpcErrorWithMetadata(ctx context.Context, err error, extra map[string]string) error {
+	code, args := errorCode(err)
+	info := &errdetails.ErrorInfo{Reason: code, Domain: errorDomain, Metadata: extra}
+
+	var grpcCode codes.Code
+	switch writeErrorStatus(err) {
+	case http.StatusUnprocessableEntity:
+		var unknownTeam *internal.UnknownTeamError
+		errors.As(err, &unknownTeam)
+		grpcCode = codes.InvalidArgument
+		info.Metadata = map[string]string{"team": unknownTeam.Name, "suggestions": strings.Join(unknownTeam.Suggestions, ",")}
+	case http.StatusPreconditionFailed:
+		grpcCode = codes.Aborted
+	case http.StatusConflict:
+		grpcCode = codes.FailedPrecondition
+	case http.StatusNotFound:
+		grpcCode = codes.NotFound
+	default:
+		var apiErr *apiError
+		if errors.As(err, &apiErr) {
+			grpcCode = codes.InvalidArgument
+		} else {
+			grpcCode = codes.Internal
+			info.Metadata = map[string]string{"detail": err.Error()}
+		}
+	}
+
+	msg := message(grpcCallFrom(ctx).language, code, args...)
+	st, detailErr := status.New(grpcCode, msg).WithDetails(info)
+	if detailErr != nil {
+		return status.Error(grpcCode, msg)
+	}
+	return st.Err()
+}
+
+// toMatchProto convierte el modelo interno al mensaje Match.
+func toMatchProto(m internal.Match) *matchv1.Match {
+	return &matchv1.Match{
+		Id:          int32(m.ID),
+		Version:     int32(m.Version),
+		HomeTeam:    m.HomeTeam,
+		AwayTeam:    m.AwayTeam,
+		Kickoff:     timestamppb.New(m.MatchDate),
+		Goals:       int32(m.Goals),
+		YellowCards: int32(m.YellowCards),
+		RedCards:    int32(m.RedCards),
+		ExtraTime:   m.ExtraTime,
+		Finished:    m.Finished,
+	}
+}
+
+// toMatchEventProto convierte un evento del log al mensaje MatchEvent.
+func toMatchEventProto(e internal.MatchEvent) *matchv1.MatchEvent {
+	event := &matchv1.MatchEvent{
+		Id:         e.ID,
+		MatchId:    int32(e.MatchID),
+		Sequence:   int32(e.Sequence),
+		Type:       e.Type,
+		DataJson:   string(e.Data),
+		OccurredAt: timestamppb.New(e.OccurredAt),
+	}
+	if e.Clock != nil {
+		event.Clock = &matchv1.ClockMinute{Minute: int32(e.Clock.Minute), Stoppage: int32(e.Clock.Stoppage)}
+	}
+	return event
+}
+
+// statNames son las estadísticas de internal que corresponden a cada valor del enum Stat.
+var statNames = map[matchv1.Stat]string{
+	matchv1.Stat_STAT_GOALS:        internal.StatGoals,
+	matchv1.Stat_STAT_YELLOW_CARDS: internal.StatYellowCards,
+	matchv1.Stat_STAT_RED_CARDS:    internal.StatRedCards,
+	matchv1.Stat_STAT_EXTRA_TIME:   internal.StatExtraTime,
+}
+
+// kickoff valida la fecha de inicio de un partido recibida en una escritura.
+func kickoff(ts *timestamppb.Timestamp) (time.Time, error) {
+	if ts == nil || ts.CheckValid() != nil {
+		return time.Time{}, newAPIError("GRPC_INVALID_KICKOFF")
+	}
+	return ts.AsTime(), nil
+}
+
+// matchServer implementa MatchService sobre las mismas operaciones de internal que la API REST.
+type matchServer struct {
+	matchv1.UnimplementedMatchServiceServer
+}
+
+func (matchServer) ListMatches(ctx context.Context, req *matchv1.ListMatchesRequest) (*matchv1.ListMatchesResponse, error) {
+	from, to := time.Time{}, time.Date(9999, time.January, 1, 0, 0, 0, 0, time.UTC)
+	if req.From != nil {
+		from = req.From.AsTime()
+	}
+	if req.To != nil {
+		to = req.To.AsTime()
+	}
+
+	var matches []internal.Match
+	var err error
+	if req.Team != "" {
+		team, resolveErr := internal.ResolveTeam(req.Team)
+		if resolveErr != nil {
+			return nil, grpcError(ctx, resolveErr)
+		}
+		matches, err = internal.GetMatchesByTeam(team)
+	} else {
+		matches, err = internal.GetMatchesBetween(from, to)
+	}
+	if err != nil {
+		return nil, grpcError(ctx, err)
+	}
+
+	resp := &matchv1.ListMatchesResponse{Matches: []*matchv1.Match{}}
+	for _, m := range matches {
+		if m.MatchDate.Before(from) || !m.MatchDate.Before(to) {
+			continue
+		}
+		resp.Matches = append(resp.Matches, toMatchProto(m))
+	}
+	return resp, nil
+}
+
+func (matchServer) GetMatch(ctx context.Context, req *matchv1.GetMatchRequest) (*matchv1.GetMatchResponse, error) {
+	match, err := internal.GetMatchByID(int(req.Id))
+	if err != nil {
+		return nil, grpcError(ctx, err)
+	}
+	return &matchv1.GetMatchResponse{Match: toMatchProto(match)}, nil
+}
+
+func (matchServer) CreateMatch(ctx context.Context, req *matchv1.CreateMatchRequest) (*matchv1.CreateMatchResponse, error) {
+	date, err := kickoff(req.Kickoff)
+	if err != nil {
+		return nil, grpcError(ctx, err)
+	}
+	m := internal.Match{HomeTeam: req.HomeTeam, AwayTeam: req.AwayTeam, MatchDate: date}
+	id, err := internal.CreateMatch(m, grpcCallFrom(ctx).audit)
+	match, err := writtenMatchProto(ctx, id, err)
+	if err != nil {
+		return nil, err
+	}
+	return &matchv1.CreateMatchResponse{Match: match}, nil
+}
+
+func (matchServer) UpdateMatch(ctx context.Context, req *matchv1.UpdateMatchRequest) (*matchv1.UpdateMatchResponse, error) {
+	date, err := kickoff(req.Kickoff)
+	if err != nil {
+		return nil, grpcError(ctx, err)
+	}
+	m := internal.Match{ID: int(req.Id), Version: int(req.Version), HomeTeam: req.HomeTeam, AwayTeam: req.AwayTeam, MatchDate: date}
+	match, err := writtenMatchProto(ctx, m.ID, internal.UpdateMatch(m, grpcCallFrom(ctx).audit))
+	if err != nil {
+		return nil, err
+	}
+	return &matchv1.UpdateMatchResponse{Match: match}, nil
+}
+
+func (matchServer) DeleteMatch(ctx context.Context, req *matchv1.DeleteMatchRequest) (*matchv1.DeleteMatchResponse, error) {
+	if err := internal.DeleteMatch(int(req.Id), int(req.Version), grpcCallFrom(ctx).audit); err != nil {
+		return nil, grpcError(ctx, err)
+	}
+	return &matchv1.DeleteMatchResponse{}, nil
+}
+
+func (matchServer) RecordStat(ctx context.Context, req *matchv1.RecordStatRequest) (*matchv1.RecordStatResponse, error) {
+	stat, ok := statNames[req.Stat]
+	if !ok {
+		return nil, grpcError(ctx, newAPIError("INVALID_STAT", req.Stat.String()))
+	}
+	err := internal.IncrementStat(stat, int(req.Id), int(req.Version), grpcCallFrom(ctx).audit)
+	match, err := writtenMatchProto(ctx, int(req.Id), err)
+	if err != nil {
+		return nil, err
+	}
+	return &matchv1.RecordStatResponse{Match: match}, nil
+}
+
+func (matchServer) FinishMatch(ctx context.Context, req *matchv1.FinishMatchRequest) (*matchv1.FinishMatchResponse, error) {
+	err := internal.FinishMatch(int(req.Id), int(req.Version), grpcCallFrom(ctx).audit)
+	match, err := writtenMatchProto(ctx, int(req.Id), err)
+	if err != nil {
+		return nil, err
+	}
+	return &matchv1.FinishMatchResponse{Match: match}, nil
+}
+
+// writtenMatchProto retorna el partido después de una escritura, o el error de la escritura. Si la
+// versión no coincide, el ErrorInfo informa la versión actual en metadata.currentVersion.
+func writtenMatchProto(ctx context.Context, id int, err error) (*matchv1.Match, error) {
+	if err != nil {
+		if errors.Is(err, internal.ErrVersionMismatch) {
+			if current, getErr := internal.GetMatchByID(id); getErr == nil {
+				return nil, grpcErrorWithMetadata(ctx, err, map[string]string{"currentVersion": strconv.Itoa(current.Version)})
+			}
+		}
+		return nil, grpcError(ctx, err)
+	}
+	match, err := internal.GetMatchByID(id)
+	if err != nil {
+		return nil, grpcError(ctx, err)
+	}
+	return toMatchProto(match), nil
+}
+
+// WatchMatch envía los eventos del partido igual que GET /matches/{id}/stream: se suscribe a los
+// avisos de cambios, consulta los eventos posteriores al último enviado y, sin avisos, vuelve a
+// consultar cada streamHeartbeat por si se perdió alguno.
+func (matchServer) WatchMatch(req *matchv1.WatchMatchRequest, stream matchv1.MatchService_WatchMatchServer) error {
+	ctx := stream.Context()
+	matchID := int(req.MatchId)
+	if matchID <= 0 {
+		return grpcError(ctx, newAPIError("INVALID_ID"))
+	}
+	if _, err := internal.GetMatchByID(matchID); err != nil {
+		return grpcError(ctx, err)
+	}
+	after := req.AfterEventId
+	if after < 0 {
+		return grpcError(ctx, newAPIError("GRPC_INVALID_AFTER_EVENT_ID"))
+	}
+	if after == 0 {
+		// Sin punto de reanudación solo se envían los eventos posteriores a la llamada
+		var err error
+		if after, err = internal.LatestEventID(matchID); err != nil {
+			return grpcError(ctx, err)
+		}
+	}
+
+	// Se suscribe antes de ponerse al día para no perder avisos entre la consulta y la espera
+	changes, unsubscribe := internal.SubscribeChanges()
+	defer unsubscribe()
+
+	heartbeat := time.NewTicker(streamHeartbeat)
+	defer heartbeat.Stop()
+	for {
+		for {
+			batch, err := internal.GetMatchChanges(after, matchID, streamBatchSize)
+			if err != nil {
+				return grpcError(ctx, err)
+			}
+			for _, change := range batch {
+				err := stream.Send(&matchv1.WatchMatchResponse{
+					Event:   toMatchEventProto(change.Event),
+					Match:   toMatchProto(change.Match),
+					Deleted: change.Deleted,
+				})
+				if err != nil {
+					return err
+				}
+				after = change.Event.ID
+			}
+			if len(batch) < streamBatchSize {
+				break
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-changes:
+		case <-heartbeat.C:
+		}
+	}
+}
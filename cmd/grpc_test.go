@@ -0,0 +1,349 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"net"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	matchv1 "lab6/proto/match/v1"
+)
+
+// newGRPCTestClient sirve MatchService en memoria con bufconn y retorna un cliente conectado.
+// Las llamadas se hacen en inglés, como si el cliente enviara accept-language.
+func newGRPCTestClient(t *testing.T) (matchv1.MatchServiceClient, context.Context) {
+	t.Helper()
+	listener := bufconn.Listen(1 << 20)
+	server := newGRPCServer()
+	go server.Serve(listener)
+
+	conn, err := grpc.NewClient("passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		conn.Close()
+		server.Stop()
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	t.Cleanup(cancel)
+	ctx = metadata.AppendToOutgoingContext(ctx, "accept-language", "en", "x-actor", "grpc-test")
+	return matchv1.NewMatchServiceClient(conn), ctx
+}
+
+var grpcKickoff = time.Date(2025, 4, 5, 19, 0, 0, 0, time.UTC)
+
+func grpcMatchRows() *sqlmock.Rows {
+	return sqlmock.NewRows([]string{"id", "home_team", "away_team", "match_date", "version",
+		"goals_match", "yellow_cards_match", "red_cards_match", "extra_time", "finished", "date_only"})
+}
+
+// expectGRPCMatchState prepara la lectura del estado de un partido dentro de una escritura.
+func expectGRPCMatchState(mock sqlmock.Sqlmock, id, version int, finished bool) {
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT to_jsonb(m)")).WithArgs(id).
+		WillReturnRows(sqlmock.NewRows([]string{"snapshot"}).AddRow([]byte(`{}`)))
+	mock.ExpectQuery(`FROM matches WHERE id = \$1$`).WithArgs(id).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "home_team", "away_team", "match_date", "version", "goals_match",
+			"yellow_cards_match", "red_cards_match", "extra_time", "finished", "date_only", "deleted_at",
+			"clock_period", "clock_started_at", "clock_stoppage"}).
+			AddRow(id, "Betis", "Sevilla", grpcKickoff, version, 0, 0, 0, false, finished, false, nil, "", nil, 0))
+	mock.ExpectRollback()
+}
+
+func TestGRPCGetAndListMatches(t *testing.T) {
+	mock := mockDB(t)
+	client, ctx := newGRPCTestClient(t)
+
+	mock.ExpectQuery(`FROM matches WHERE id = \$1 AND deleted_at IS NULL`).WithArgs(1).
+		WillReturnRows(grpcMatchRows().AddRow(1, "Betis", "Sevilla", grpcKickoff, 4, 2, 3, 1, true, false, false))
+	var header metadata.MD
+	got, err := client.GetMatch(ctx, &matchv1.GetMatchRequest{Id: 1}, grpc.Header(&header))
+	if err != nil {
+		t.Fatal(err)
+	}
+	match := got.GetMatch()
+	if match.GetId() != 1 || match.GetVersion() != 4 || match.GetHomeTeam() != "Betis" || match.GetGoals() != 2 ||
+		match.GetYellowCards() != 3 || match.GetRedCards() != 1 || !match.GetExtraTime() || !match.GetKickoff().AsTime().Equal(grpcKickoff) {
+		t.Errorf("GetMatch = %v", match)
+	}
+	if len(header.Get("x-request-id")) != 1 || header.Get("content-language")[0] != "en" {
+		t.Errorf("metadatos de respuesta = %v, se esperaba x-request-id y content-language en", header)
+	}
+
+	// El intervalo se consulta en la base y los límites se aplican también sobre el resultado
+	from, to := time.Date(2025, 4, 5, 0, 0, 0, 0, time.UTC), time.Date(2025, 4, 6, 0, 0, 0, 0, time.UTC)
+	mock.ExpectQuery(`FROM matches WHERE deleted_at IS NULL AND match_date >= \$1 AND match_date < \$2`).WithArgs(from, to).
+		WillReturnRows(grpcMatchRows().
+			AddRow(1, "Betis", "Sevilla", grpcKickoff, 4, 0, 0, 0, false, false, false).
+			AddRow(2, "Getafe", "Girona", grpcKickoff.Add(time.Hour), 1, 0, 0, 0, false, false, false))
+	list, err := client.ListMatches(ctx, &matchv1.ListMatchesRequest{From: timestamppb.New(from), To: timestamppb.New(to)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list.GetMatches()) != 2 || list.GetMatches()[1].GetHomeTeam() != "Getafe" {
+		t.Errorf("ListMatches por fecha = %v", list.GetMatches())
+	}
+
+	// Por equipo se resuelve el alias y se filtra por el intervalo
+	mock.ExpectQuery(`WHERE a.alias_key = team_key\(\$1\)`).WithArgs("Real Betis").
+		WillReturnRows(sqlmock.NewRows([]string{"name"}).AddRow("Betis"))
+	mock.ExpectQuery(`FROM matches WHERE deleted_at IS NULL AND \(home_team = \$1 OR away_team = \$1\)`).WithArgs("Betis").
+		WillReturnRows(grpcMatchRows().
+			AddRow(3, "Betis", "Cádiz", grpcKickoff.AddDate(0, -1, 0), 1, 0, 0, 0, false, false, false).
+			AddRow(1, "Betis", "Sevilla", grpcKickoff, 4, 0, 0, 0, false, false, false))
+	list, err = client.ListMatches(ctx, &matchv1.ListMatchesRequest{Team: "Real Betis", From: timestamppb.New(from)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list.GetMatches()) != 1 || list.GetMatches()[0].GetId() != 1 {
+		t.Errorf("ListMatches por equipo = %v, se esperaba solo el partido 1", list.GetMatches())
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestGRPCErrorCodes(t *testing.T) {
+	tests := []struct {
+		name         string
+		call         func(ctx context.Context, client matchv1.MatchServiceClient) error
+		setup        func(mock sqlmock.Sqlmock)
+		wantCode     codes.Code
+		wantReason   string
+		wantMessage  string
+		wantMetadata map[string]string
+	}{
+		{
+			name: "partido inexistente",
+			call: func(ctx context.Context, client matchv1.MatchServiceClient) error {
+				_, err := client.GetMatch(ctx, &matchv1.GetMatchRequest{Id: 9})
+				return err
+			},
+			setup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`FROM matches WHERE id = \$1`).WithArgs(9).WillReturnError(sql.ErrNoRows)
+			},
+			wantCode:   codes.NotFound,
+			wantReason: "MATCH_NOT_FOUND",
+		},
+		{
+			name: "equipo desconocido",
+			call: func(ctx context.Context, client matchv1.MatchServiceClient) error {
+				_, err := client.ListMatches(ctx, &matchv1.ListMatchesRequest{Team: "Betiz"})
+				return err
+			},
+			setup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`WHERE a.alias_key = team_key\(\$1\)`).WithArgs("Betiz").WillReturnError(sql.ErrNoRows)
+				mock.ExpectQuery(`similarity`).WillReturnRows(sqlmock.NewRows([]string{"name"}).AddRow("Betis").AddRow("Real Betis"))
+			},
+			wantCode:     codes.InvalidArgument,
+			wantReason:   "UNKNOWN_TEAM",
+			wantMessage:  `Unknown team: "Betiz"`,
+			wantMetadata: map[string]string{"team": "Betiz", "suggestions": "Betis,Real Betis"},
+		},
+		{
+			name: "versión desactualizada",
+			call: func(ctx context.Context, client matchv1.MatchServiceClient) error {
+				_, err := client.FinishMatch(ctx, &matchv1.FinishMatchRequest{Id: 1, Version: 3})
+				return err
+			},
+			setup: func(mock sqlmock.Sqlmock) {
+				expectGRPCMatchState(mock, 1, 5, false)
+				mock.ExpectQuery(`FROM matches WHERE id = \$1 AND deleted_at IS NULL`).WithArgs(1).
+					WillReturnRows(grpcMatchRows().AddRow(1, "Betis", "Sevilla", grpcKickoff, 5, 0, 0, 0, false, false, false))
+			},
+			wantCode:     codes.Aborted,
+			wantReason:   "VERSION_MISMATCH",
+			wantMetadata: map[string]string{"currentVersion": "5"},
+		},
+		{
+			name: "partido finalizado",
+			call: func(ctx context.Context, client matchv1.MatchServiceClient) error {
+				_, err := client.RecordStat(ctx, &matchv1.RecordStatRequest{Id: 1, Version: 5, Stat: matchv1.Stat_STAT_GOALS})
+				return err
+			},
+			setup: func(mock sqlmock.Sqlmock) {
+				expectGRPCMatchState(mock, 1, 5, true)
+			},
+			wantCode:   codes.FailedPrecondition,
+			wantReason: "MATCH_FINISHED",
+		},
+		{
+			name: "estadística sin especificar",
+			call: func(ctx context.Context, client matchv1.MatchServiceClient) error {
+				_, err := client.RecordStat(ctx, &matchv1.RecordStatRequest{Id: 1, Version: 5})
+				return err
+			},
+			wantCode:   codes.InvalidArgument,
+			wantReason: "INVALID_STAT",
+		},
+		{
+			name: "partido sin fecha de inicio",
+			call: func(ctx context.Context, client matchv1.MatchServiceClient) error {
+				_, err := client.CreateMatch(ctx, &matchv1.CreateMatchRequest{HomeTeam: "Betis", AwayTeam: "Sevilla"})
+				return err
+			},
+			wantCode:   codes.InvalidArgument,
+			wantReason: "GRPC_INVALID_KICKOFF",
+		},
+		{
+			name: "error interno con el detalle",
+			call: func(ctx context.Context, client matchv1.MatchServiceClient) error {
+				_, err := client.GetMatch(ctx, &matchv1.GetMatchRequest{Id: 1})
+				return err
+			},
+			setup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`FROM matches WHERE id = \$1`).WithArgs(1).WillReturnError(sql.ErrConnDone)
+			},
+			wantCode:     codes.Internal,
+			wantReason:   "INTERNAL_ERROR",
+			wantMessage:  "Internal server error",
+			wantMetadata: map[string]string{"detail": sql.ErrConnDone.Error()},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := mockDB(t)
+			if tt.setup != nil {
+				tt.setup(mock)
+			}
+			client, ctx := newGRPCTestClient(t)
+
+			st, _ := status.FromError(tt.call(ctx, client))
+			if st.Code() != tt.wantCode {
+				t.Fatalf("código = %v (%v), se esperaba %v", st.Code(), st.Message(), tt.wantCode)
+			}
+			if tt.wantMessage != "" && st.Message() != tt.wantMessage {
+				t.Errorf("mensaje = %q, se esperaba %q", st.Message(), tt.wantMessage)
+			}
+			var info *errdetails.ErrorInfo
+			for _, detail := range st.Details() {
+				if value, ok := detail.(*errdetails.ErrorInfo); ok {
+					info = value
+				}
+			}
+			if info == nil || info.GetReason() != tt.wantReason || info.GetDomain() != errorDomain {
+				t.Fatalf("ErrorInfo = %v, se esperaba el reason %s", info, tt.wantReason)
+			}
+			for key, want := range tt.wantMetadata {
+				if got := info.GetMetadata()[key]; got != want {
+					t.Errorf("metadata[%s] = %q, se esperaba %q", key, got, want)
+				}
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func TestGRPCWatchMatch(t *testing.T) {
+	t.Run("envía los eventos posteriores al indicado", func(t *testing.T) {
+		mock := mockDB(t)
+		client, ctx := newGRPCTestClient(t)
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+
+		mock.ExpectQuery(`FROM matches WHERE id = \$1 AND deleted_at IS NULL`).WithArgs(1).
+			WillReturnRows(grpcMatchRows().AddRow(1, "Betis", "Sevilla", grpcKickoff, 4, 1, 0, 0, false, false, false))
+		mock.ExpectQuery(`FROM match_events e, position p`).WithArgs(int64(10), 1, streamBatchSize).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "match_id", "sequence", "type", "data", "occurred_at", "clock_minute", "clock_stoppage", "state"}).
+				AddRow(11, 1, 3, "GoalScored", []byte(`{"team":"home"}`), grpcKickoff, 23, 0,
+					[]byte(`{"id":1,"home_team":"Betis","away_team":"Sevilla","match_date":"2025-04-05T19:00:00Z","version":3,"goals_match":1}`)).
+				AddRow(12, 1, 4, "MatchDeleted", []byte(`{}`), grpcKickoff, nil, nil,
+					[]byte(`{"id":1,"home_team":"Betis","away_team":"Sevilla","match_date":"2025-04-05T19:00:00Z","version":4,"goals_match":1,"deleted_at":"2025-04-05T21:00:00Z"}`)))
+
+		stream, err := client.WatchMatch(ctx, &matchv1.WatchMatchRequest{MatchId: 1, AfterEventId: 10})
+		if err != nil {
+			t.Fatal(err)
+		}
+		goal, err := stream.Recv()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if goal.GetEvent().GetId() != 11 || goal.GetEvent().GetClock().GetMinute() != 23 || goal.GetEvent().GetDataJson() != `{"team":"home"}` ||
+			goal.GetMatch().GetGoals() != 1 || goal.GetMatch().GetVersion() != 3 || goal.GetDeleted() {
+			t.Errorf("primer mensaje = %v", goal)
+		}
+		deleted, err := stream.Recv()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if deleted.GetEvent().GetId() != 12 || deleted.GetEvent().GetClock() != nil || !deleted.GetDeleted() {
+			t.Errorf("segundo mensaje = %v, se esperaba el partido eliminado", deleted)
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Error(err)
+		}
+	})
+
+	tests := []struct {
+		name       string
+		req        *matchv1.WatchMatchRequest
+		setup      func(mock sqlmock.Sqlmock)
+		wantCode   codes.Code
+		wantReason string
+	}{
+		{name: "ID inválido", req: &matchv1.WatchMatchRequest{}, wantCode: codes.InvalidArgument, wantReason: "INVALID_ID"},
+		{
+			name: "partido inexistente",
+			req:  &matchv1.WatchMatchRequest{MatchId: 9},
+			setup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`FROM matches WHERE id = \$1`).WithArgs(9).WillReturnError(sql.ErrNoRows)
+			},
+			wantCode:   codes.NotFound,
+			wantReason: "MATCH_NOT_FOUND",
+		},
+		{
+			name: "evento inicial negativo",
+			req:  &matchv1.WatchMatchRequest{MatchId: 1, AfterEventId: -1},
+			setup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`FROM matches WHERE id = \$1`).WithArgs(1).
+					WillReturnRows(grpcMatchRows().AddRow(1, "Betis", "Sevilla", grpcKickoff, 4, 0, 0, 0, false, false, false))
+			},
+			wantCode:   codes.InvalidArgument,
+			wantReason: "GRPC_INVALID_AFTER_EVENT_ID",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := mockDB(t)
+			if tt.setup != nil {
+				tt.setup(mock)
+			}
+			client, ctx := newGRPCTestClient(t)
+
+			stream, err := client.WatchMatch(ctx, tt.req)
+			if err == nil {
+				_, err = stream.Recv()
+			}
+			st, _ := status.FromError(err)
+			if st.Code() != tt.wantCode {
+				t.Fatalf("código = %v (%v), se esperaba %v", st.Code(), st.Message(), tt.wantCode)
+			}
+			if details := st.Details(); len(details) != 1 || details[0].(*errdetails.ErrorInfo).GetReason() != tt.wantReason {
+				t.Errorf("detalles = %v, se esperaba el reason %s", details, tt.wantReason)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
@@ -0,0 +1,190 @@
+package main
+
+import (
+	"database/sql"
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/text/language"
+	"lab6/internal"
+)
+
+// localeFiles contiene un catálogo de mensajes por idioma, con el código de error como clave.
+// Agregar un idioma solo requiere agregar su archivo en locales/ (por ejemplo, locales/fr.json).
+//
+//go:embed locales/*.json
+var localeFiles embed.FS
+
+// defaultLanguage es el idioma usado cuando el cliente no pide ninguno soportado.
+var defaultLanguage = language.Spanish
+
+// languageContextKey es la clave bajo la que localeMiddleware guarda el idioma negociado.
+const languageContextKey = "language"
+
+var (
+	catalogs        = map[language.Tag]map[string]string{}
+	languages       []language.Tag
+	languageMatcher language.Matcher
+)
+
+func init() {
+	entries, err := localeFiles.ReadDir("locales")
+	if err != nil {
+		panic(err)
+	}
+
+	// El idioma por defecto va primero para que el matcher lo use como respaldo
+	languages = []language.Tag{defaultLanguage}
+	for _, entry := range entries {
+		tag, err := language.Parse(strings.TrimSuffix(entry.Name(), path.Ext(entry.Name())))
+		if err != nil {
+			panic(fmt.Sprintf("catálogo de mensajes con nombre inválido %s: %v", entry.Name(), err))
+		}
+		data, err := localeFiles.ReadFile("locales/" + entry.Name())
+		if err != nil {
+			panic(err)
+		}
+		messages := map[string]string{}
+		if err := json.Unmarshal(data, &messages); err != nil {
+			panic(fmt.Sprintf("catálogo de mensajes inválido %s: %v", entry.Name(), err))
+		}
+		catalogs[tag] = messages
+		if tag != defaultLanguage {
+			languages = append(languages, tag)
+		}
+	}
+	languageMatcher = language.NewMatcher(languages)
+}
+
+// localeMiddleware negocia el idioma de los mensajes a partir del encabezado Accept-Language
+// y lo informa en Content-Language.
+func localeMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		tag := negotiateLanguage(c.GetHeader("Accept-Language"))
+		c.Set(languageContextKey, tag)
+		c.Header("Content-Language", tag.String())
+		c.Writer.Header().Add("Vary", "Accept-Language")
+		c.Next()
+	}
+}
+
+// negotiateLanguage retorna el idioma soportado que mejor se ajusta a un valor de Accept-Language.
+func negotiateLanguage(acceptLanguage string) language.Tag {
+	tags, _, _ := language.ParseAcceptLanguage(acceptLanguage)
+	_, index, _ := languageMatcher.Match(tags...)
+	return languages[index]
+}
+
+// requestLanguage retorna el idioma negociado para la solicitud.
+func requestLanguage(c *gin.Context) language.Tag {
+	if value, ok := c.Get(languageContextKey); ok {
+		return value.(language.Tag)
+	}
+	return defaultLanguage
+}
+
+// message retorna el mensaje del código en el idioma indicado. Si el idioma no lo define
+// se usa el idioma por defecto y, en último caso, el propio código.
+func message(tag language.Tag, code string, args ...any) string {
+	format, ok := catalogs[tag][code]
+	if !ok {
+		if format, ok = catalogs[defaultLanguage][code]; !ok {
+			return code
+		}
+	}
+	if len(args) == 0 {
+		return format
+	}
+	return fmt.Sprintf(format, args...)
+}
+
+// translate retorna el mensaje del código en el idioma de la solicitud.
+func translate(c *gin.Context, code string, args ...any) string {
+	return message(requestLanguage(c), code, args...)
+}
+
+// apiError es un error de validación identificado por un código estable del catálogo.
+type apiError struct {
+	Code string
+	Args []any
+}
+
+func (e *apiError) Error() string {
+	return message(defaultLanguage, e.Code, e.Args...)
+}
+
+// newAPIError crea un apiError con el código y los argumentos del mensaje.
+func newAPIError(code string, args ...any) *apiError {
+	return &apiError{Code: code, Args: args}
+}
+
+// errorCode retorna el código estable y los argumentos del mensaje que describen err.
+func errorCode(err error) (string, []any) {
+	var apiErr *apiError
+	var unknownTeam *internal.UnknownTeamError
+	switch {
+	case errors.As(err, &apiErr):
+		return apiErr.Code, apiErr.Args
+	case errors.As(err, &unknownTeam):
+		return "UNKNOWN_TEAM", []any{unknownTeam.Name}
+	case errors.Is(err, internal.ErrVersionMismatch):
+		return "VERSION_MISMATCH", nil
+	case errors.Is(err, internal.ErrBatchSkipped):
+		return "BATCH_SKIPPED", nil
+	case errors.Is(err, internal.ErrUnknownStat):
+		return "INVALID_STAT", nil
+	case errors.Is(err, internal.ErrMatchNotDeleted):
+		return "MATCH_NOT_DELETED", nil
+	case errors.Is(err, internal.ErrMatchFinished):
+		return "MATCH_FINISHED", nil
+	case errors.Is(err, internal.ErrTeamExists):
+		return "TEAM_EXISTS", nil
+	case errors.Is(err, internal.ErrAliasTaken):
+		return "ALIAS_TAKEN", nil
+	case errors.Is(err, internal.ErrDuplicateMatch):
+		return "DUPLICATE_MATCH", nil
+	case errors.Is(err, internal.ErrClockRunning):
+		return "CLOCK_RUNNING", nil
+	case errors.Is(err, internal.ErrClockStopped):
+		return "CLOCK_STOPPED", nil
+	case errors.Is(err, internal.ErrInvalidPeriod):
+		return "INVALID_PERIOD", nil
+	case errors.Is(err, sql.ErrNoRows):
+		return "MATCH_NOT_FOUND", nil
+	default:
+		return "INTERNAL_ERROR", nil
+	}
+}
+
+// errorBody construye el cuerpo de una respuesta de error con su código y mensaje localizado.
+func errorBody(c *gin.Context, code string, args ...any) gin.H {
+	return gin.H{"code": code, "error": translate(c, code, args...)}
+}
+
+// respondError responde con el código de error y su mensaje localizado.
+func respondError(c *gin.Context, status int, code string, args ...any) {
+	c.JSON(status, errorBody(c, code, args...))
+}
+
+// abortWithError es como respondError pero además detiene la cadena de middlewares.
+func abortWithError(c *gin.Context, status int, code string, args ...any) {
+	c.AbortWithStatusJSON(status, errorBody(c, code, args...))
+}
+
+// respondInternalError responde 500 con un mensaje localizado y el detalle del error original.
+func respondInternalError(c *gin.Context, err error) {
+	body := errorBody(c, "INTERNAL_ERROR")
+	body["detail"] = err.Error()
+	c.JSON(http.StatusInternalServerError, body)
+}
+
+// respondMessage responde 200 con un mensaje de éxito localizado.
+func respondMessage(c *gin.Context, code string) {
+	c.JSON(http.StatusOK, gin.H{"code": code, "message": translate(c, code)})
+}
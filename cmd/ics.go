@@ -0,0 +1,229 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"lab6/internal"
+)
+
+const (
+	// icsProductID identifica al generador del calendario (PRODID, RFC 5545 §3.7.3).
+	icsProductID = "-//LaLigaTracker//Calendario de partidos//ES"
+
+	// icsUIDDomain es el sufijo de los UID. Un partido conserva siempre el mismo UID para que
+	// los clientes actualicen el evento en lugar de duplicarlo.
+	icsUIDDomain = "laligatracker"
+
+	// icsMatchDuration es la duración estimada de un partido, usada para DTEND.
+	icsMatchDuration = 2 * time.Hour
+
+	// icsLineLimit es el largo máximo en octetos de una línea antes de plegarla (RFC 5545 §3.1).
+	icsLineLimit = 75
+
+	// icsDateTime es el formato DATE-TIME en UTC (RFC 5545 §3.3.5).
+	icsDateTime = "20060102T150405Z"
+
+	// icsDate es el formato DATE de los eventos de día completo (RFC 5545 §3.3.4).
+	icsDate = "20060102"
+)
+
+// icsEscaper escapa los caracteres especiales de un valor TEXT (RFC 5545 §3.3.11).
+var icsEscaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`)
+
+// icsWriter construye un archivo iCalendar con líneas terminadas en CRLF y plegadas a 75 octetos.
+type icsWriter struct {
+	b strings.Builder
+}
+
+// line agrega una propiedad, plegándola si supera el largo máximo sin cortar caracteres UTF-8.
+func (w *icsWriter) line(name, value string) {
+	content := name + ":" + value
+	for limit := icsLineLimit; len(content) > limit; limit = icsLineLimit - 1 {
+		cut := limit
+		for cut > 0 && !isRuneStart(content[cut]) {
+			cut--
+		}
+		w.b.WriteString(content[:cut])
+		w.b.WriteString("\r\n ")
+		content = content[cut:]
+	}
+	w.b.WriteString(content)
+	w.b.WriteString("\r\n")
+}
+
+// isRuneStart indica si el byte inicia un carácter UTF-8.
+func isRuneStart(b byte) bool {
+	return b&0xC0 != 0x80
+}
+
+// matchDescription describe el partido para DESCRIPTION: el resultado final si ya terminó. Los goles
+// por equipo salen de los eventos; si alguno se registró sin equipo, como los de PATCH /goals, el
+// marcador no se conoce y se informa el total de goles del partido.
+func matchDescription(m internal.Match, sides internal.MatchSides) string {
+	if !m.Finished {
+		return fmt.Sprintf("%s vs %s. Partido pendiente.", m.HomeTeam, m.AwayTeam)
+	}
+	description := fmt.Sprintf("Resultado final: %s %d - %d %s.", m.HomeTeam, sides.Goals.Home, sides.Goals.Away, m.AwayTeam)
+	if sides.Goals.Unattributed > 0 {
+		description = fmt.Sprintf("%s vs %s. Partido finalizado con %d goles en total.", m.HomeTeam, m.AwayTeam, m.Goals)
+	}
+	if m.ExtraTime {
+		description += " Con tiempo extra."
+	}
+	return description
+}
+
+// buildCalendar genera un VCALENDAR con un VEVENT por partido. Los partidos sin hora se publican
+// como eventos de día completo. venues asocia cada equipo con su estadio, que es el LOCATION de
+// los partidos en que juega de local.
+func buildCalendar(name string, matches []internal.Match, sides map[int]internal.MatchSides, venues map[string]string, now time.Time) string {
+	var w icsWriter
+	w.line("BEGIN", "VCALENDAR")
+	w.line("VERSION", "2.0")
+	w.line("PRODID", icsProductID)
+	w.line("CALSCALE", "GREGORIAN")
+	w.line("METHOD", "PUBLISH")
+	w.line("X-WR-CALNAME", icsEscaper.Replace(name))
+	for _, m := range matches {
+		start := m.MatchDate.UTC()
+		w.line("BEGIN", "VEVENT")
+		w.line("UID", fmt.Sprintf("match-%d@%s", m.ID, icsUIDDomain))
+		// La versión del partido aumenta con cada cambio, como exige SEQUENCE
+		w.line("SEQUENCE", strconv.Itoa(m.Version))
+		w.line("DTSTAMP", now.UTC().Format(icsDateTime))
+		if m.DateOnly {
+			w.line("DTSTART;VALUE=DATE", start.Format(icsDate))
+			w.line("DTEND;VALUE=DATE", start.AddDate(0, 0, 1).Format(icsDate))
+		} else {
+			w.line("DTSTART", start.Format(icsDateTime))
+			w.line("DTEND", start.Add(icsMatchDuration).Format(icsDateTime))
+		}
+		w.line("SUMMARY", icsEscaper.Replace(m.HomeTeam+" vs "+m.AwayTeam))
+		w.line("DESCRIPTION", icsEscaper.Replace(matchDescription(m, sides[m.ID])))
+		if venue := venues[m.HomeTeam]; venue != "" {
+			w.line("LOCATION", icsEscaper.Replace(venue))
+		}
+		if m.Finished {
+			w.line("STATUS", "CONFIRMED")
+		} else {
+			w.line("STATUS", "TENTATIVE")
+		}
+		w.line("END", "VEVENT")
+	}
+	w.line("END", "VCALENDAR")
+	return w.b.String()
+}
+
+// getTeamCalendar godoc
+// @Summary Calendario iCalendar de un equipo
+// @Description Retorna un feed .ics (RFC 5545) con los partidos del equipo, apto para suscribirse desde Google Calendar u Outlook.
+// @Description Cada partido tiene un UID estable y su versión como SEQUENCE, de modo que los cambios actualizan el evento existente.
+// @Description La descripción incluye el resultado final de los partidos terminados, con los goles de cada equipo.
+// @Description Los partidos sin hora se publican como eventos de día completo (DTSTART;VALUE=DATE).
+// @Description LOCATION es el estadio del equipo local, si está registrado.
+// @Tags Teams
+// @Produce text/calendar
+// @Param id path int true "ID del equipo"
+// @Success 200 {string} string "Calendario iCalendar"
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /teams/{id}/calendar.ics [get]
+func getTeamCalendar(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		respondError(c, http.StatusBadRequest, "INVALID_ID")
+		return
+	}
+
+	team, err := internal.GetTeamByID(id)
+	if errors.Is(err, sql.ErrNoRows) {
+		respondError(c, http.StatusNotFound, "TEAM_NOT_FOUND")
+		return
+	}
+	if err != nil {
+		respondInternalError(c, err)
+		return
+	}
+
+	matches, err := internal.GetMatchesByTeam(team.Name)
+	if err != nil {
+		respondInternalError(c, err)
+		return
+	}
+	serveCalendar(c, team.Name, fmt.Sprintf("team-%d.ics", team.ID), matches)
+}
+
+// getCompetitionCalendar godoc
+// @Summary Calendario iCalendar de una competición
+// @Description Retorna un feed .ics (RFC 5545) con todos los partidos de la competición, con el mismo formato que el calendario de un equipo.
+// @Description Un partido conserva su UID en ambos feeds, por lo que suscribirse a los dos no lo duplica en clientes que combinan calendarios.
+// @Tags Competitions
+// @Produce text/calendar
+// @Param id path int true "ID de la competición"
+// @Success 200 {string} string "Calendario iCalendar"
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /competitions/{id}/calendar.ics [get]
+func getCompetitionCalendar(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		respondError(c, http.StatusBadRequest, "INVALID_ID")
+		return
+	}
+
+	competition, err := internal.GetCompetitionByID(id)
+	if errors.Is(err, sql.ErrNoRows) {
+		respondError(c, http.StatusNotFound, "COMPETITION_NOT_FOUND")
+		return
+	}
+	if err != nil {
+		respondInternalError(c, err)
+		return
+	}
+
+	matches, err := internal.GetMatchesByCompetition(competition.ID)
+	if err != nil {
+		respondInternalError(c, err)
+		return
+	}
+	serveCalendar(c, competition.Name, fmt.Sprintf("competition-%d.ics", competition.ID), matches)
+}
+
+// serveCalendar responde el calendario de los partidos, con los goles por equipo de los terminados
+// y el estadio de cada equipo local.
+func serveCalendar(c *gin.Context, name, filename string, matches []internal.Match) {
+	var ids []int
+	var homeTeams []string
+	for _, m := range matches {
+		if m.Finished {
+			ids = append(ids, m.ID)
+		}
+		homeTeams = append(homeTeams, m.HomeTeam)
+	}
+	sides, err := internal.GetMatchSides(ids)
+	if err != nil {
+		respondInternalError(c, err)
+		return
+	}
+	teams, err := internal.GetTeamsByName(homeTeams)
+	if err != nil {
+		respondInternalError(c, err)
+		return
+	}
+	venues := make(map[string]string, len(teams))
+	for _, t := range teams {
+		venues[t.Name] = t.Stadium
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf(`inline; filename="%s"`, filename))
+	c.Data(http.StatusOK, "text/calendar; charset=utf-8", []byte(buildCalendar(name, matches, sides, venues, time.Now())))
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"lab6/internal"
+)
+
+func TestBuildCalendar(t *testing.T) {
+	now := time.Date(2025, 4, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		match   internal.Match
+		sides   internal.MatchSides
+		venues  map[string]string
+		want    []string
+		notWant []string
+	}{
+		{
+			name: "partido con hora",
+			match: internal.Match{ID: 7, Version: 3, HomeTeam: "Sevilla", AwayTeam: "Betis",
+				MatchDate: time.Date(2025, 4, 5, 19, 0, 0, 0, time.UTC)},
+			want: []string{"UID:match-7@laligatracker", "SEQUENCE:3", "DTSTART:20250405T190000Z",
+				"DTEND:20250405T210000Z", "DESCRIPTION:Sevilla vs Betis. Partido pendiente.", "STATUS:TENTATIVE"},
+		},
+		{
+			name: "partido sin hora como evento de día completo",
+			match: internal.Match{ID: 8, Version: 1, HomeTeam: "Getafe", AwayTeam: "Girona",
+				MatchDate: time.Date(2025, 4, 6, 0, 0, 0, 0, time.UTC), DateOnly: true},
+			want:    []string{"DTSTART;VALUE=DATE:20250406", "DTEND;VALUE=DATE:20250407"},
+			notWant: []string{"DTSTART:"},
+		},
+		{
+			name: "partido con hora a medianoche UTC",
+			match: internal.Match{ID: 8, Version: 1, HomeTeam: "Getafe", AwayTeam: "Girona",
+				MatchDate: time.Date(2025, 4, 6, 0, 0, 0, 0, time.UTC)},
+			want:    []string{"DTSTART:20250406T000000Z", "DTEND:20250406T020000Z"},
+			notWant: []string{"VALUE=DATE"},
+		},
+		{
+			name: "resultado final con los goles de cada equipo",
+			match: internal.Match{ID: 9, Version: 5, HomeTeam: "Sevilla", AwayTeam: "Betis", Finished: true, ExtraTime: true,
+				Goals: 3, MatchDate: time.Date(2025, 4, 5, 19, 0, 0, 0, time.UTC)},
+			sides: internal.MatchSides{Goals: internal.SideCount{Home: 2, Away: 1}},
+			want:  []string{"DESCRIPTION:Resultado final: Sevilla 2 - 1 Betis. Con tiempo extra.", "STATUS:CONFIRMED"},
+		},
+		{
+			name: "goles sin equipo informan el total",
+			match: internal.Match{ID: 10, Version: 2, HomeTeam: "Sevilla", AwayTeam: "Betis", Finished: true,
+				Goals: 2, MatchDate: time.Date(2025, 4, 5, 19, 0, 0, 0, time.UTC)},
+			sides:   internal.MatchSides{Goals: internal.SideCount{Home: 1, Unattributed: 1}},
+			want:    []string{"DESCRIPTION:Sevilla vs Betis. Partido finalizado con 2 goles en total."},
+			notWant: []string{"Resultado final"},
+		},
+		{
+			name: "partido terminado sin goles",
+			match: internal.Match{ID: 11, Version: 2, HomeTeam: "Sevilla", AwayTeam: "Betis", Finished: true,
+				MatchDate: time.Date(2025, 4, 5, 19, 0, 0, 0, time.UTC)},
+			want: []string{"DESCRIPTION:Resultado final: Sevilla 0 - 0 Betis."},
+		},
+		{
+			name: "estadio del equipo local",
+			match: internal.Match{ID: 12, Version: 1, HomeTeam: "Sevilla", AwayTeam: "Betis",
+				MatchDate: time.Date(2025, 4, 5, 19, 0, 0, 0, time.UTC)},
+			venues: map[string]string{"Sevilla": "Ramón Sánchez-Pizjuán", "Betis": "Benito Villamarín"},
+			want:   []string{"LOCATION:Ramón Sánchez-Pizjuán"},
+		},
+		{
+			name: "equipo local sin estadio",
+			match: internal.Match{ID: 13, Version: 1, HomeTeam: "Getafe", AwayTeam: "Betis",
+				MatchDate: time.Date(2025, 4, 5, 19, 0, 0, 0, time.UTC)},
+			venues:  map[string]string{"Getafe": "", "Betis": "Benito Villamarín"},
+			notWant: []string{"LOCATION"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calendar := buildCalendar("Sevilla", []internal.Match{tt.match},
+				map[int]internal.MatchSides{tt.match.ID: tt.sides}, tt.venues, now)
+			lines := strings.Split(strings.ReplaceAll(calendar, "\r\n ", ""), "\r\n")
+			for _, want := range tt.want {
+				if !slices.Contains(lines, want) {
+					t.Errorf("falta la línea %q en:\n%s", want, calendar)
+				}
+			}
+			for _, notWant := range tt.notWant {
+				if strings.Contains(calendar, notWant) {
+					t.Errorf("no se esperaba %q en:\n%s", notWant, calendar)
+				}
+			}
+		})
+	}
+}
+
+func TestICSLineFolding(t *testing.T) {
+	var w icsWriter
+	w.line("DESCRIPTION", strings.Repeat("á", 60))
+	for _, line := range strings.Split(strings.TrimSuffix(w.b.String(), "\r\n"), "\r\n") {
+		if len(line) > icsLineLimit {
+			t.Errorf("línea de %d octetos: %q", len(line), line)
+		}
+	}
+	if unfolded := strings.ReplaceAll(w.b.String(), "\r\n ", ""); unfolded != "DESCRIPTION:"+strings.Repeat("á", 60)+"\r\n" {
+		t.Errorf("al desplegar se obtuvo %q", unfolded)
+	}
+}
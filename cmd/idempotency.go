@@ -0,0 +1,205 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"hash"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"lab6/internal"
+)
+
+// defaultIdempotencyTTL es el tiempo que se conserva una clave si no se configura IDEMPOTENCY_TTL.
+const defaultIdempotencyTTL = 24 * time.Hour
+
+// replayedHeaders son los encabezados de la respuesta original que se guardan para reproducirla.
+var replayedHeaders = []string{"Content-Type", "ETag", "Location"}
+
+// idempotencyTTL lee la duración de las claves desde la variable de entorno IDEMPOTENCY_TTL.
+func idempotencyTTL() time.Duration {
+	value := os.Getenv("IDEMPOTENCY_TTL")
+	if value == "" {
+		return defaultIdempotencyTTL
+	}
+	ttl, err := time.ParseDuration(value)
+	if err != nil || ttl <= 0 {
+		log.Printf("IDEMPOTENCY_TTL inválido (%q), se usa %v", value, defaultIdempotencyTTL)
+		return defaultIdempotencyTTL
+	}
+	return ttl
+}
+
+// purgeIdempotencyKeys elimina periódicamente las claves expiradas.
+func purgeIdempotencyKeys(interval time.Duration) {
+	for range time.Tick(interval) {
+		if _, err := internal.PurgeExpiredIdempotencyKeys(); err != nil {
+			log.Printf("Error al purgar claves de idempotencia: %v", err)
+		}
+	}
+}
+
+// bodyRecorder copia el cuerpo de la respuesta mientras se escribe al cliente.
+type bodyRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *bodyRecorder) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *bodyRecorder) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
+
+// fingerprintBody calcula la huella de la solicitud a medida que el handler lee su cuerpo, de
+// modo que las importaciones multipart o XLSX no se carguen completas en memoria solo para
+// identificarlas.
+type fingerprintBody struct {
+	io.ReadCloser
+	hash hash.Hash
+}
+
+// newFingerprintBody envuelve el cuerpo de la solicitud. La query forma parte de la huella;
+// el método y la ruta no, porque ya aíslan la clave.
+func newFingerprintBody(r *http.Request) *fingerprintBody {
+	b := &fingerprintBody{ReadCloser: r.Body, hash: sha256.New()}
+	b.hash.Write([]byte(r.URL.RawQuery + "\n"))
+	return b
+}
+
+func (b *fingerprintBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.hash.Write(p[:n])
+	return n, err
+}
+
+// sum lee lo que el handler no haya consumido del cuerpo y retorna la huella completa.
+func (b *fingerprintBody) sum() (string, error) {
+	if _, err := io.Copy(io.Discard, b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b.hash.Sum(nil)), nil
+}
+
+// idempotencyKeyContextKey es la clave bajo la que se guarda la Idempotency-Key reservada, que
+// auditInfo entrega a las escrituras para marcarla en su misma transacción.
+const idempotencyKeyContextKey = "idempotencyKey"
+
+// idempotencyMiddleware aplica el encabezado Idempotency-Key a las rutas que no son GET.
+// La primera solicitud con una clave se ejecuta y su respuesta se almacena; los reintentos
+// con la misma clave reciben la respuesta original sin repetir la escritura. Las claves se
+// aíslan por método y ruta, y un reintento solo se reproduce si su query y su cuerpo coinciden
+// con los de la solicitud original.
+//
+// La escritura marca la clave como aplicada en su propia transacción, por lo que la clave
+// solo se libera si la escritura no llegó a confirmarse; si se confirmó pero su respuesta no
+// pudo guardarse, los reintentos reciben 409 en lugar de repetirla.
+func idempotencyMiddleware(ttl time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		header := c.GetHeader("Idempotency-Key")
+		method := c.Request.Method
+		if header == "" || method == http.MethodGet || method == http.MethodHead || method == http.MethodOptions {
+			c.Next()
+			return
+		}
+		if len(header) > 255 {
+			abortWithError(c, http.StatusBadRequest, "IDEMPOTENCY_KEY_TOO_LONG")
+			return
+		}
+
+		key := internal.IdempotencyKey{
+			Method: method,
+			Path:   c.Request.URL.Path,
+			Key:    header,
+		}
+		body := newFingerprintBody(c.Request)
+		record, reserved, err := internal.ReserveIdempotencyKey(key, ttl)
+		if err != nil {
+			respondInternalError(c, err)
+			c.Abort()
+			return
+		}
+		if !reserved {
+			replayIdempotentResponse(c, record, body)
+			return
+		}
+
+		// Si la escritura no llegó a confirmarse (error del servidor o pánico) la clave se libera
+		// para que el cliente pueda reintentar
+		saved := false
+		defer func() {
+			if saved {
+				return
+			}
+			if err := internal.ReleaseIdempotencyKey(key); err != nil {
+				log.Printf("Error al liberar Idempotency-Key %q: %v", key.Key, err)
+			}
+		}()
+
+		c.Set(idempotencyKeyContextKey, &key)
+		c.Request.Body = body
+		recorder := &bodyRecorder{ResponseWriter: c.Writer}
+		c.Writer = recorder
+		c.Next()
+
+		status := recorder.Status()
+		if status >= http.StatusInternalServerError {
+			return
+		}
+		fingerprint, err := body.sum()
+		if err != nil {
+			log.Printf("Error al leer el cuerpo de la solicitud con Idempotency-Key %q: %v", key.Key, err)
+			return
+		}
+
+		headers := make(map[string]string)
+		for _, name := range replayedHeaders {
+			if value := recorder.Header().Get(name); value != "" {
+				headers[name] = value
+			}
+		}
+		if err := internal.SaveIdempotencyResponse(key, fingerprint, status, headers, recorder.body.Bytes()); err != nil {
+			log.Printf("Error al guardar Idempotency-Key %q: %v", key.Key, err)
+			return
+		}
+		saved = true
+	}
+}
+
+// replayIdempotentResponse responde un reintento con la respuesta original, o con el error que
+// corresponde si la clave se usó con otra solicitud o su respuesta todavía no está disponible.
+func replayIdempotentResponse(c *gin.Context, record *internal.IdempotencyRecord, body *fingerprintBody) {
+	switch {
+	case !record.Completed && record.Committed:
+		abortWithError(c, http.StatusConflict, "IDEMPOTENCY_RESPONSE_UNAVAILABLE")
+		return
+	case !record.Completed:
+		abortWithError(c, http.StatusConflict, "IDEMPOTENCY_KEY_IN_PROGRESS")
+		return
+	}
+
+	fingerprint, err := body.sum()
+	if err != nil {
+		abortWithError(c, http.StatusBadRequest, "INVALID_BODY")
+		return
+	}
+	if fingerprint != record.Fingerprint {
+		abortWithError(c, http.StatusUnprocessableEntity, "IDEMPOTENCY_KEY_REUSED")
+		return
+	}
+	for name, value := range record.Headers {
+		c.Header(name, value)
+	}
+	c.Header("Idempotent-Replayed", "true")
+	c.Data(record.StatusCode, record.Headers["Content-Type"], record.Body)
+	c.Abort()
+}
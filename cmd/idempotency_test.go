@@ -0,0 +1,303 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	"lab6/internal"
+)
+
+// Fragmentos de las consultas de internal/idempotency.go que esperan las pruebas.
+const (
+	reserveCleanupSQL = `DELETE FROM idempotency_keys WHERE .* AND expires_at < NOW\(\)`
+	reserveInsertSQL  = `INSERT INTO idempotency_keys`
+	selectKeySQL      = `SELECT fingerprint, committed_at IS NOT NULL, status_code, headers, body`
+	saveResponseSQL   = `UPDATE idempotency_keys\s+SET fingerprint = \$4`
+	releaseSQL        = `DELETE FROM idempotency_keys WHERE .* AND committed_at IS NULL`
+)
+
+// testFingerprint es la huella que calcula el middleware para una solicitud sin query.
+func testFingerprint(body string) string {
+	sum := sha256.Sum256([]byte("\n" + body))
+	return hex.EncodeToString(sum[:])
+}
+
+// mockDB reemplaza internal.DB por una base simulada durante la prueba.
+func mockDB(t *testing.T) sqlmock.Sqlmock {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	previous := internal.DB
+	internal.DB = db
+	t.Cleanup(func() {
+		internal.DB = previous
+		db.Close()
+	})
+	return mock
+}
+
+// expectReserve prepara la reserva de la clave; si existing no es nil la clave ya estaba tomada.
+func expectReserve(mock sqlmock.Sqlmock, existing *sqlmock.Rows) {
+	mock.ExpectExec(reserveCleanupSQL).
+		WithArgs(http.MethodPost, "/api/matches", "clave-1").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	if existing == nil {
+		mock.ExpectExec(reserveInsertSQL).WillReturnResult(sqlmock.NewResult(0, 1))
+		return
+	}
+	mock.ExpectExec(reserveInsertSQL).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(selectKeySQL).WillReturnRows(existing)
+}
+
+func keyRows(fingerprint any, committed bool, status any, body []byte) *sqlmock.Rows {
+	headers := []byte(`{"Content-Type": "application/json; charset=utf-8"}`)
+	if status == nil {
+		headers = nil
+	}
+	return sqlmock.NewRows([]string{"fingerprint", "committed", "status_code", "headers", "body"}).
+		AddRow(fingerprint, committed, status, headers, body)
+}
+
+func TestIdempotencyMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name          string
+		key           string
+		body          string
+		setup         func(mock sqlmock.Sqlmock)
+		handlerStatus int
+		wantStatus    int
+		wantCode      string
+		wantHandler   bool
+		wantReplayed  bool
+	}{
+		{
+			name:          "sin clave no consulta la base",
+			body:          `{"homeTeam": "Sevilla"}`,
+			handlerStatus: http.StatusCreated,
+			wantStatus:    http.StatusCreated,
+			wantHandler:   true,
+		},
+		{
+			name:       "clave demasiado larga",
+			key:        strings.Repeat("x", 256),
+			wantStatus: http.StatusBadRequest,
+			wantCode:   "IDEMPOTENCY_KEY_TOO_LONG",
+		},
+		{
+			name: "primera solicitud guarda la huella y la respuesta",
+			key:  "clave-1",
+			body: `{"homeTeam": "Sevilla"}`,
+			setup: func(mock sqlmock.Sqlmock) {
+				expectReserve(mock, nil)
+				mock.ExpectExec(saveResponseSQL).
+					WithArgs(http.MethodPost, "/api/matches", "clave-1",
+						testFingerprint(`{"homeTeam": "Sevilla"}`), http.StatusCreated, sqlmock.AnyArg(), sqlmock.AnyArg()).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+			handlerStatus: http.StatusCreated,
+			wantStatus:    http.StatusCreated,
+			wantHandler:   true,
+		},
+		{
+			name: "reintento reproduce la respuesta sin ejecutar el handler",
+			key:  "clave-1",
+			body: `{"homeTeam": "Sevilla"}`,
+			setup: func(mock sqlmock.Sqlmock) {
+				expectReserve(mock, keyRows(testFingerprint(`{"homeTeam": "Sevilla"}`), true, 201, []byte(`{"id":7}`)))
+			},
+			wantStatus:   http.StatusCreated,
+			wantReplayed: true,
+		},
+		{
+			name: "reintento con otro cuerpo",
+			key:  "clave-1",
+			body: `{"homeTeam": "Betis"}`,
+			setup: func(mock sqlmock.Sqlmock) {
+				expectReserve(mock, keyRows(testFingerprint(`{"homeTeam": "Sevilla"}`), true, 201, []byte(`{"id":7}`)))
+			},
+			wantStatus: http.StatusUnprocessableEntity,
+			wantCode:   "IDEMPOTENCY_KEY_REUSED",
+		},
+		{
+			name: "reintento mientras la original sigue en curso",
+			key:  "clave-1",
+			body: `{"homeTeam": "Sevilla"}`,
+			setup: func(mock sqlmock.Sqlmock) {
+				expectReserve(mock, keyRows(nil, false, nil, nil))
+			},
+			wantStatus: http.StatusConflict,
+			wantCode:   "IDEMPOTENCY_KEY_IN_PROGRESS",
+		},
+		{
+			name: "reintento de una escritura aplicada cuya respuesta se perdió",
+			key:  "clave-1",
+			body: `{"homeTeam": "Sevilla"}`,
+			setup: func(mock sqlmock.Sqlmock) {
+				expectReserve(mock, keyRows(nil, true, nil, nil))
+			},
+			wantStatus: http.StatusConflict,
+			wantCode:   "IDEMPOTENCY_RESPONSE_UNAVAILABLE",
+		},
+		{
+			name: "error del servidor libera la clave si la escritura no se confirmó",
+			key:  "clave-1",
+			body: `{"homeTeam": "Sevilla"}`,
+			setup: func(mock sqlmock.Sqlmock) {
+				expectReserve(mock, nil)
+				mock.ExpectExec(releaseSQL).WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+			handlerStatus: http.StatusInternalServerError,
+			wantStatus:    http.StatusInternalServerError,
+			wantHandler:   true,
+		},
+		{
+			name: "si falla el guardado solo se libera una clave no confirmada",
+			key:  "clave-1",
+			body: `{"homeTeam": "Sevilla"}`,
+			setup: func(mock sqlmock.Sqlmock) {
+				expectReserve(mock, nil)
+				mock.ExpectExec(saveResponseSQL).WillReturnError(errors.New("conexión perdida"))
+				mock.ExpectExec(releaseSQL).WillReturnResult(sqlmock.NewResult(0, 0))
+			},
+			handlerStatus: http.StatusCreated,
+			wantStatus:    http.StatusCreated,
+			wantHandler:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := mockDB(t)
+			if tt.setup != nil {
+				tt.setup(mock)
+			}
+
+			handlerCalled := false
+			router := gin.New()
+			router.POST("/api/matches", idempotencyMiddleware(time.Hour), func(c *gin.Context) {
+				handlerCalled = true
+				info := auditInfo(c)
+				if tt.key != "" && (info.Idempotency == nil || info.Idempotency.Key != tt.key) {
+					t.Errorf("auditInfo no incluye la Idempotency-Key: %+v", info.Idempotency)
+				}
+				// El handler lee solo una parte del cuerpo; el resto debe entrar igual en la huella
+				buf := make([]byte, 4)
+				c.Request.Body.Read(buf)
+				c.JSON(tt.handlerStatus, gin.H{"id": 7})
+			})
+
+			req := httptest.NewRequest(http.MethodPost, "/api/matches", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("X-Actor", "tester")
+			if tt.key != "" {
+				req.Header.Set("Idempotency-Key", tt.key)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, se esperaba %d (body %s)", w.Code, tt.wantStatus, w.Body)
+			}
+			if handlerCalled != tt.wantHandler {
+				t.Errorf("handler ejecutado = %v, se esperaba %v", handlerCalled, tt.wantHandler)
+			}
+			if replayed := w.Header().Get("Idempotent-Replayed") == "true"; replayed != tt.wantReplayed {
+				t.Errorf("Idempotent-Replayed = %v, se esperaba %v", replayed, tt.wantReplayed)
+			}
+			if tt.wantCode != "" {
+				var body map[string]any
+				if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil || body["code"] != tt.wantCode {
+					t.Errorf("code = %v, se esperaba %s", body["code"], tt.wantCode)
+				}
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func TestIdempotencyKeysAreScoped(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mock := mockDB(t)
+
+	// La misma clave enviada a otra ruta se reserva con su propio ámbito, sin importar X-Actor
+	mock.ExpectExec(reserveCleanupSQL).
+		WithArgs(http.MethodDelete, "/api/matches/3", "clave-1").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(reserveInsertSQL).
+		WithArgs(http.MethodDelete, "/api/matches/3", "clave-1", int64(3600)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(saveResponseSQL).WillReturnResult(sqlmock.NewResult(0, 1))
+
+	router := gin.New()
+	router.DELETE("/api/matches/:id", idempotencyMiddleware(time.Hour), func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+	req := httptest.NewRequest(http.MethodDelete, "/api/matches/3", nil)
+	req.Header.Set("X-Actor", "otro")
+	req.Header.Set("Idempotency-Key", "clave-1")
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+// TestIdempotencyKeysIgnoreActor verifica que X-Actor, que envía el cliente, no permita obtener la
+// respuesta guardada de otra solicitud: otro actor con la misma clave solo recibe la respuesta
+// original si repite exactamente la misma solicitud.
+func TestIdempotencyKeysIgnoreActor(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name         string
+		actor        string
+		body         string
+		wantStatus   int
+		wantReplayed bool
+	}{
+		{name: "otro actor con la misma solicitud", actor: "intruso", body: `{"homeTeam": "Sevilla"}`, wantStatus: http.StatusCreated, wantReplayed: true},
+		{name: "otro actor con otro cuerpo", actor: "intruso", body: `{"homeTeam": "Betis"}`, wantStatus: http.StatusUnprocessableEntity},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := mockDB(t)
+			expectReserve(mock, keyRows(testFingerprint(`{"homeTeam": "Sevilla"}`), true, 201, []byte(`{"id":7}`)))
+
+			router := gin.New()
+			router.POST("/api/matches", idempotencyMiddleware(time.Hour), func(c *gin.Context) {
+				t.Error("el handler no debe ejecutarse")
+			})
+			req := httptest.NewRequest(http.MethodPost, "/api/matches", strings.NewReader(tt.body))
+			req.Header.Set("X-Actor", tt.actor)
+			req.Header.Set("Idempotency-Key", "clave-1")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, se esperaba %d", w.Code, tt.wantStatus)
+			}
+			if replayed := w.Header().Get("Idempotent-Replayed") == "true"; replayed != tt.wantReplayed {
+				t.Errorf("Idempotent-Replayed = %v, se esperaba %v", replayed, tt.wantReplayed)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
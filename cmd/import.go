@@ -0,0 +1,430 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"io"
+	"math"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xuri/excelize/v2"
+	"lab6/internal"
+)
+
+const (
+	// maxImportSize limita el tamaño del archivo de importación.
+	maxImportSize = 5 << 20
+
+	// maxImportRows limita la cantidad de filas de datos por importación.
+	maxImportRows = 1000
+
+	// defaultImportDateFormat es el formato de fecha usado si no se indica dateFormat.
+	defaultImportDateFormat = "YYYY-MM-DD"
+)
+
+// Formatos de archivo admitidos por /import/matches.
+const (
+	importFormatCSV          = "csv"
+	importFormatXLSX         = "xlsx"
+	importFormatFootballData = "football-data"
+)
+
+// Estados de una fila en el reporte de importación.
+const (
+	importRowValid    = "valid"
+	importRowInvalid  = "invalid"
+	importRowImported = "imported"
+)
+
+// importDateTokens traduce los componentes de dateFormat al layout de Go.
+// El orden importa: los tokens largos se reemplazan antes que sus prefijos.
+var importDateTokens = strings.NewReplacer("YYYY", "2006", "YY", "06", "MM", "01", "DD", "02", "HH", "15", "mm", "04", "ss", "05")
+
+// importRowResult es el resultado de una fila en el reporte de importación.
+type importRowResult struct {
+	Line        int      `json:"line"`
+	Status      string   `json:"status" example:"valid"`
+	ID          int      `json:"id,omitempty"`
+	HomeTeam    string   `json:"homeTeam,omitempty"`
+	AwayTeam    string   `json:"awayTeam,omitempty"`
+	MatchDate   string   `json:"matchDate,omitempty" example:"2025-04-01"`
+	Result      string   `json:"result,omitempty" example:"2-1"`
+	Code        string   `json:"code,omitempty"`
+	Error       string   `json:"error,omitempty"`
+	Suggestions []string `json:"suggestions,omitempty"`
+}
+
+// importResponse es la respuesta de /import/matches.
+type importResponse struct {
+	DryRun    bool              `json:"dryRun"`
+	Committed bool              `json:"committed"`
+	Total     int               `json:"total"`
+	Valid     int               `json:"valid"`
+	Invalid   int               `json:"invalid"`
+	Rows      []importRowResult `json:"rows"`
+}
+
+// importColumns son los nombres de las columnas del archivo que corresponden a cada campo del partido.
+type importColumns struct {
+	HomeTeam  string
+	AwayTeam  string
+	MatchDate string
+}
+
+// importRecord es una fila de datos del archivo junto con su número de línea.
+type importRecord struct {
+	Line   int
+	Values []string
+}
+
+// importFormat determina el formato del archivo a partir del parámetro format o de la extensión.
+// El formato de football-data.co.uk es un CSV, por lo que solo se usa si se indica explícitamente.
+func importFormat(requested, filename string) (string, bool) {
+	format := strings.ToLower(strings.TrimSpace(requested))
+	if format == "" {
+		format = strings.TrimPrefix(strings.ToLower(filepath.Ext(filename)), ".")
+	}
+	return format, format == importFormatCSV || format == importFormatXLSX || format == importFormatFootballData
+}
+
+// readImportRecords lee un CSV o XLSX con el mapeo de columnas y el formato de fecha del formulario,
+// y retorna sus filas de datos junto con la función que convierte cada una en partido.
+func readImportRecords(c *gin.Context, format string, data []byte) ([]importRecord, func(importRecord) (internal.ImportRow, error), error) {
+	columns := importColumns{
+		HomeTeam:  c.DefaultPostForm("homeTeamColumn", "homeTeam"),
+		AwayTeam:  c.DefaultPostForm("awayTeamColumn", "awayTeam"),
+		MatchDate: c.DefaultPostForm("matchDateColumn", "matchDate"),
+	}
+	dateFormat := c.DefaultPostForm("dateFormat", defaultImportDateFormat)
+	layout := importDateTokens.Replace(dateFormat)
+	if layout == dateFormat {
+		return nil, nil, newAPIError("IMPORT_INVALID_DATE_FORMAT")
+	}
+
+	var records [][]string
+	var date1904 bool
+	var err error
+	if format == importFormatXLSX {
+		records, date1904, err = readXLSXRecords(data, c.PostForm("sheet"))
+	} else {
+		records, err = readCSVRecords(data, c.PostForm("delimiter"))
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	positions, dataRecords, err := splitImportRecords(records, columns)
+	if err != nil {
+		return nil, nil, err
+	}
+	return dataRecords, func(record importRecord) (internal.ImportRow, error) {
+		return toImportRow(record, positions, columns, dateFormat, layout, format == importFormatXLSX, date1904)
+	}, nil
+}
+
+// readCSVRecords lee todas las filas de un CSV. Si delimiter está vacío se detecta entre coma,
+// punto y coma y tabulación según la primera línea.
+func readCSVRecords(data []byte, delimiter string) ([][]string, error) {
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+	comma := ','
+	if delimiter == "" {
+		firstLine, _, _ := bytes.Cut(data, []byte("\n"))
+		best := 0
+		for _, candidate := range []rune{',', ';', '\t'} {
+			if n := bytes.Count(firstLine, []byte(string(candidate))); n > best {
+				comma, best = candidate, n
+			}
+		}
+	} else {
+		runes := []rune(delimiter)
+		if delimiter == `\t` {
+			runes = []rune{'\t'}
+		}
+		if len(runes) != 1 {
+			return nil, newAPIError("IMPORT_INVALID_DELIMITER")
+		}
+		comma = runes[0]
+	}
+
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.Comma = comma
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, newAPIError("IMPORT_INVALID_FILE")
+	}
+	return records, nil
+}
+
+// readXLSXRecords lee las filas de una hoja del libro (la primera si sheet está vacío).
+// Las celdas se leen sin formato, de modo que las fechas llegan como número de serie de Excel.
+func readXLSXRecords(data []byte, sheet string) ([][]string, bool, error) {
+	book, err := excelize.OpenReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, false, newAPIError("IMPORT_INVALID_FILE")
+	}
+	defer book.Close()
+
+	if sheet == "" {
+		sheets := book.GetSheetList()
+		if len(sheets) == 0 {
+			return nil, false, newAPIError("IMPORT_NO_ROWS")
+		}
+		sheet = sheets[0]
+	}
+	records, err := book.GetRows(sheet, excelize.Options{RawCellValue: true})
+	if err != nil {
+		return nil, false, newAPIError("IMPORT_SHEET_NOT_FOUND", sheet)
+	}
+	return records, book.WorkBook.WorkbookPr != nil && book.WorkBook.WorkbookPr.Date1904, nil
+}
+
+// splitImportRecords separa el encabezado de las filas de datos, ignorando las filas vacías.
+// Retorna la posición de cada columna del mapeo dentro del encabezado.
+func splitImportRecords(records [][]string, columns importColumns) ([3]int, []importRecord, error) {
+	var positions [3]int
+	if len(records) == 0 {
+		return positions, nil, newAPIError("IMPORT_NO_ROWS")
+	}
+
+	header := importHeader(records[0])
+	for i, name := range []string{columns.HomeTeam, columns.AwayTeam, columns.MatchDate} {
+		position, ok := header[strings.ToLower(strings.TrimSpace(name))]
+		if !ok {
+			return positions, nil, newAPIError("IMPORT_MISSING_COLUMN", name)
+		}
+		positions[i] = position
+	}
+
+	rows, err := importDataRecords(records)
+	return positions, rows, err
+}
+
+// importHeader retorna la posición de cada columna del encabezado, por nombre en minúsculas.
+// Si un nombre se repite se usa la primera columna.
+func importHeader(names []string) map[string]int {
+	header := map[string]int{}
+	for i, name := range names {
+		key := strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		if _, ok := header[key]; !ok {
+			header[key] = i
+		}
+	}
+	return header
+}
+
+// importDataRecords retorna las filas de datos que siguen al encabezado, ignorando las vacías.
+func importDataRecords(records [][]string) ([]importRecord, error) {
+	var rows []importRecord
+	for i, values := range records[1:] {
+		if strings.TrimSpace(strings.Join(values, "")) == "" {
+			continue
+		}
+		rows = append(rows, importRecord{Line: i + 2, Values: values})
+	}
+	if len(rows) == 0 {
+		return nil, newAPIError("IMPORT_NO_ROWS")
+	}
+	if len(rows) > maxImportRows {
+		return nil, newAPIError("IMPORT_TOO_MANY_ROWS", maxImportRows)
+	}
+	return rows, nil
+}
+
+// parseImportDate interpreta la fecha de una fila e indica si no incluye hora. En XLSX acepta además
+// el número de serie de Excel, que no tiene hora si es entero.
+func parseImportDate(value, layout string, excelSerial, date1904 bool) (time.Time, bool, error) {
+	if excelSerial {
+		if serial, err := strconv.ParseFloat(value, 64); err == nil {
+			t, err := excelize.ExcelDateToTime(serial, date1904)
+			return t, serial == math.Trunc(serial), err
+		}
+	}
+	t, err := time.Parse(layout, value)
+	// La hora solo puede venir del token HH, que importDateTokens traduce a "15"
+	return t, !strings.Contains(layout, "15"), err
+}
+
+// toImportRow convierte una fila de datos en partido, o retorna el error de validación de la fila.
+func toImportRow(record importRecord, positions [3]int, columns importColumns, dateFormat, layout string, excelSerial, date1904 bool) (internal.ImportRow, error) {
+	field := func(i int) string {
+		if positions[i] < len(record.Values) {
+			return strings.TrimSpace(record.Values[positions[i]])
+		}
+		return ""
+	}
+
+	row := internal.ImportRow{Line: record.Line}
+	row.Match.HomeTeam, row.Match.AwayTeam = field(0), field(1)
+	dateValue := field(2)
+	for i, name := range []string{columns.HomeTeam, columns.AwayTeam, columns.MatchDate} {
+		if field(i) == "" {
+			return row, newAPIError("IMPORT_MISSING_VALUE", name)
+		}
+	}
+
+	matchDate, dateOnly, err := parseImportDate(dateValue, layout, excelSerial, date1904)
+	if err != nil {
+		return row, newAPIError("IMPORT_INVALID_DATE", dateValue, dateFormat)
+	}
+	row.Match.MatchDate, row.Match.DateOnly = matchDate, dateOnly
+	return row, nil
+}
+
+// formatImportDate formatea la fecha de una fila para el reporte, incluyendo la hora solo si la tiene.
+func formatImportDate(m internal.Match) string {
+	if m.DateOnly {
+		return m.MatchDate.Format(time.DateOnly)
+	}
+	return m.MatchDate.Format(time.RFC3339)
+}
+
+// importRowError completa el resultado de una fila inválida con el código y mensaje del error.
+func importRowError(c *gin.Context, item *importRowResult, err error) {
+	code, args := errorCode(err)
+	item.Status = importRowInvalid
+	item.Code = code
+	item.Error = translate(c, code, args...)
+
+	var unknownTeam *internal.UnknownTeamError
+	if errors.As(err, &unknownTeam) {
+		item.Suggestions = unknownTeam.Suggestions
+	}
+}
+
+// importMatches godoc
+// @Summary Importa partidos desde CSV o XLSX
+// @Description Recibe un archivo CSV o XLSX con una fila de encabezado y crea un partido por fila en una única transacción:
+// @Description si alguna fila es inválida no se importa ninguna. Cada fila se valida (fecha, equipos conocidos y
+// @Description partidos duplicados en la base o en el mismo archivo) y la respuesta incluye un reporte por fila.
+// @Description Con ?dryRun=true solo se valida, sin escribir. Los nombres de las columnas se configuran con
+// @Description homeTeamColumn, awayTeamColumn y matchDateColumn. Con format=football-data se lee el CSV de
+// @Description football-data.co.uk (Date, Time, HomeTeam, AwayTeam, FTHG, FTAG, HY, AY, HR, AR) y las filas con
+// @Description resultado se importan finalizadas, con cada gol y tarjeta atribuido a su equipo.
+// @Tags Import
+// @Accept multipart/form-data
+// @Produce json
+// @Param dryRun query bool false "Solo valida el archivo y retorna el reporte"
+// @Param file formData file true "Archivo CSV o XLSX"
+// @Param format formData string false "Formato del archivo; por defecto se deduce de la extensión" Enums(csv, xlsx, football-data)
+// @Param homeTeamColumn formData string false "Columna del equipo local" default(homeTeam)
+// @Param awayTeamColumn formData string false "Columna del equipo visitante" default(awayTeam)
+// @Param matchDateColumn formData string false "Columna de la fecha" default(matchDate)
+// @Param dateFormat formData string false "Formato de la fecha con YYYY, YY, MM, DD, HH, mm y ss" default(YYYY-MM-DD)
+// @Param delimiter formData string false "Separador del CSV; por defecto se detecta entre coma, punto y coma y tabulación"
+// @Param sheet formData string false "Hoja del XLSX; por defecto la primera"
+// @Param Idempotency-Key header string false "Clave para reintentar la solicitud sin repetir la escritura"
+// @Success 200 {object} importResponse "Importación confirmada o validación (dry-run)"
+// @Failure 400 {object} map[string]string
+// @Failure 413 {object} map[string]string
+// @Failure 422 {object} importResponse "Hay filas inválidas, no se importó ninguna"
+// @Failure 500 {object} map[string]string
+// @Router /import/matches [post]
+func importMatches(c *gin.Context) {
+	dryRun, err := strconv.ParseBool(c.DefaultQuery("dryRun", "false"))
+	if err != nil {
+		respondError(c, http.StatusBadRequest, "INVALID_DRY_RUN")
+		return
+	}
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxImportSize)
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			respondError(c, http.StatusRequestEntityTooLarge, "IMPORT_FILE_TOO_LARGE", maxImportSize>>20)
+			return
+		}
+		respondError(c, http.StatusBadRequest, "IMPORT_FILE_REQUIRED")
+		return
+	}
+	format, ok := importFormat(c.PostForm("format"), fileHeader.Filename)
+	if !ok {
+		respondError(c, http.StatusBadRequest, "IMPORT_UNSUPPORTED_FILE")
+		return
+	}
+	file, err := fileHeader.Open()
+	if err != nil {
+		respondInternalError(c, err)
+		return
+	}
+	defer file.Close()
+	data, err := io.ReadAll(file)
+	if err != nil {
+		respondInternalError(c, err)
+		return
+	}
+
+	var dataRecords []importRecord
+	var toRow func(importRecord) (internal.ImportRow, error)
+	if format == importFormatFootballData {
+		dataRecords, toRow, err = readFootballDataRecords(data)
+	} else {
+		dataRecords, toRow, err = readImportRecords(c, format, data)
+	}
+	if err != nil {
+		code, args := errorCode(err)
+		respondError(c, http.StatusBadRequest, code, args...)
+		return
+	}
+
+	// Las filas con errores de formato se reportan sin llegar a la base de datos; si hay
+	// alguna, el resto del archivo igualmente se valida pero no se importa
+	response := importResponse{DryRun: dryRun, Total: len(dataRecords), Rows: make([]importRowResult, len(dataRecords))}
+	var rows []internal.ImportRow
+	var rowIndexes []int
+	for i, record := range dataRecords {
+		response.Rows[i] = importRowResult{Line: record.Line}
+		row, err := toRow(record)
+		if err != nil {
+			importRowError(c, &response.Rows[i], err)
+			continue
+		}
+		rows = append(rows, row)
+		rowIndexes = append(rowIndexes, i)
+	}
+
+	results, committed, err := internal.ImportMatches(rows, dryRun || len(rows) < len(dataRecords), auditInfo(c))
+	if err != nil {
+		respondInternalError(c, err)
+		return
+	}
+	for i, result := range results {
+		item := &response.Rows[rowIndexes[i]]
+		item.HomeTeam = result.Match.HomeTeam
+		item.AwayTeam = result.Match.AwayTeam
+		item.MatchDate = formatImportDate(result.Match)
+		if score := rows[i].Result; score != nil {
+			item.Result = strconv.Itoa(score.HomeGoals) + "-" + strconv.Itoa(score.AwayGoals)
+		}
+		switch {
+		case result.Err != nil:
+			importRowError(c, item, result.Err)
+		case committed:
+			item.Status = importRowImported
+			item.ID = result.ID
+		default:
+			item.Status = importRowValid
+		}
+	}
+
+	for _, row := range response.Rows {
+		if row.Status == importRowInvalid {
+			response.Invalid++
+		} else {
+			response.Valid++
+		}
+	}
+	response.Committed = committed
+	if response.Invalid > 0 {
+		c.JSON(http.StatusUnprocessableEntity, response)
+		return
+	}
+	c.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestImportRows(t *testing.T) {
+	columns := importColumns{HomeTeam: "local", AwayTeam: "visita", MatchDate: "fecha"}
+
+	tests := []struct {
+		name       string
+		records    [][]string
+		dateFormat string
+		excel      bool
+		wantCode   string
+		wantRows   []time.Time
+		// wantDateOnly indica si las filas se registran sin hora
+		wantDateOnly bool
+	}{
+		{
+			name:         "mapeo de columnas sin distinguir mayúsculas y filas vacías ignoradas",
+			records:      [][]string{{"\ufeffFecha", "LOCAL", "Visita"}, {"05/04/2025", "Betis", "Getafe"}, {"", "", ""}, {"06/04/2025", "Sevilla", "Girona"}},
+			dateFormat:   "DD/MM/YYYY",
+			wantRows:     []time.Time{time.Date(2025, 4, 5, 0, 0, 0, 0, time.UTC), time.Date(2025, 4, 6, 0, 0, 0, 0, time.UTC)},
+			wantDateOnly: true,
+		},
+		{
+			name:       "fecha con hora",
+			records:    [][]string{{"local", "visita", "fecha"}, {"Betis", "Getafe", "2025-04-05 21:00"}},
+			dateFormat: "YYYY-MM-DD HH:mm",
+			wantRows:   []time.Time{time.Date(2025, 4, 5, 21, 0, 0, 0, time.UTC)},
+		},
+		{
+			name:       "hora a medianoche sigue teniendo hora",
+			records:    [][]string{{"local", "visita", "fecha"}, {"Betis", "Getafe", "2025-04-05 00:00"}},
+			dateFormat: "YYYY-MM-DD HH:mm",
+			wantRows:   []time.Time{time.Date(2025, 4, 5, 0, 0, 0, 0, time.UTC)},
+		},
+		{
+			name:         "número de serie de Excel",
+			records:      [][]string{{"local", "visita", "fecha"}, {"Betis", "Getafe", "45752"}},
+			dateFormat:   "YYYY-MM-DD",
+			excel:        true,
+			wantRows:     []time.Time{time.Date(2025, 4, 5, 0, 0, 0, 0, time.UTC)},
+			wantDateOnly: true,
+		},
+		{
+			name:       "número de serie de Excel con hora",
+			records:    [][]string{{"local", "visita", "fecha"}, {"Betis", "Getafe", "45752.875"}},
+			dateFormat: "YYYY-MM-DD",
+			excel:      true,
+			wantRows:   []time.Time{time.Date(2025, 4, 5, 21, 0, 0, 0, time.UTC)},
+		},
+		{
+			name:       "fecha con otro formato",
+			records:    [][]string{{"local", "visita", "fecha"}, {"Betis", "Getafe", "2025-04-05"}},
+			dateFormat: "DD/MM/YYYY",
+			wantCode:   "IMPORT_INVALID_DATE",
+		},
+		{
+			name:       "valor faltante",
+			records:    [][]string{{"local", "visita", "fecha"}, {"Betis", "", "05/04/2025"}},
+			dateFormat: "DD/MM/YYYY",
+			wantCode:   "IMPORT_MISSING_VALUE",
+		},
+		{
+			name:       "columna faltante",
+			records:    [][]string{{"local", "fecha"}, {"Betis", "05/04/2025"}},
+			dateFormat: "DD/MM/YYYY",
+			wantCode:   "IMPORT_MISSING_COLUMN",
+		},
+		{
+			name:       "solo encabezado",
+			records:    [][]string{{"local", "visita", "fecha"}, {"", "", ""}},
+			dateFormat: "DD/MM/YYYY",
+			wantCode:   "IMPORT_NO_ROWS",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			positions, records, err := splitImportRecords(tt.records, columns)
+			var got []time.Time
+			for _, record := range records {
+				if err != nil {
+					break
+				}
+				row, rowErr := toImportRow(record, positions, columns, tt.dateFormat, importDateTokens.Replace(tt.dateFormat), tt.excel, false)
+				if rowErr != nil {
+					err = rowErr
+					break
+				}
+				if row.Line != record.Line || row.Match.HomeTeam == "" || row.Match.AwayTeam == "" {
+					t.Errorf("fila %+v incompleta", row)
+				}
+				got = append(got, row.Match.MatchDate)
+				if row.Match.DateOnly != tt.wantDateOnly {
+					t.Errorf("dateOnly de la fila %d = %v, se esperaba %v", row.Line, row.Match.DateOnly, tt.wantDateOnly)
+				}
+			}
+
+			if tt.wantCode != "" {
+				if code, _ := errorCode(err); code != tt.wantCode {
+					t.Errorf("código = %q (%v), se esperaba %s", code, err, tt.wantCode)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(got) != len(tt.wantRows) {
+				t.Fatalf("filas = %v, se esperaban %v", got, tt.wantRows)
+			}
+			for i := range got {
+				if !got[i].Equal(tt.wantRows[i]) {
+					t.Errorf("fecha de la fila %d = %v, se esperaba %v", i, got[i], tt.wantRows[i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,68 @@
+package main
+
+import "sync"
+
+// batchLoader agrupa en una sola consulta las claves pedidas por los resolvers de GraphQL.
+// Load no consulta nada: anota la clave en el lote pendiente y retorna un thunk. graphql-go
+// resuelve los thunks de cada nivel de la respuesta después de recorrer el nivel completo, por
+// lo que el primer thunk que se evalúa consulta todas las claves del nivel de una vez.
+//
+// Los valores no se guardan entre lotes: cada nivel consulta lo que necesita, por lo que un
+// mismo loader puede reutilizarse en los sucesivos resultados de una suscripción.
+type batchLoader[K comparable, V any] struct {
+	fetch func(keys []K) (map[K]V, error)
+
+	mu      sync.Mutex
+	pending *loaderBatch[K, V]
+}
+
+// loaderBatch es un conjunto de claves que se consultan juntas.
+type loaderBatch[K comparable, V any] struct {
+	keys   []K
+	seen   map[K]bool
+	once   sync.Once
+	values map[K]V
+	err    error
+}
+
+// newBatchLoader crea un loader que obtiene los valores de cada lote con fetch. fetch puede
+// omitir claves en el resultado; Load retorna el valor cero de V para ellas.
+func newBatchLoader[K comparable, V any](fetch func(keys []K) (map[K]V, error)) *batchLoader[K, V] {
+	return &batchLoader[K, V]{fetch: fetch}
+}
+
+// Load anota key en el lote pendiente y retorna el thunk que entrega su valor.
+func (l *batchLoader[K, V]) Load(key K) func() (interface{}, error) {
+	return l.LoadThen(key, func(v V) any { return v })
+}
+
+// LoadThen es como Load, pero aplica then al valor antes de entregarlo al resolver.
+func (l *batchLoader[K, V]) LoadThen(key K, then func(V) any) func() (interface{}, error) {
+	l.mu.Lock()
+	batch := l.pending
+	if batch == nil {
+		batch = &loaderBatch[K, V]{seen: map[K]bool{}}
+		l.pending = batch
+	}
+	if !batch.seen[key] {
+		batch.seen[key] = true
+		batch.keys = append(batch.keys, key)
+	}
+	l.mu.Unlock()
+
+	return func() (interface{}, error) {
+		batch.once.Do(func() {
+			// Las claves anotadas desde ahora van a un lote nuevo
+			l.mu.Lock()
+			if l.pending == batch {
+				l.pending = nil
+			}
+			l.mu.Unlock()
+			batch.values, batch.err = l.fetch(batch.keys)
+		})
+		if batch.err != nil {
+			return nil, batch.err
+		}
+		return then(batch.values[key]), nil
+	}
+}
@@ -4,47 +4,58 @@ import (
 	"log"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
   _ "lab6/docs" // Asegúrate de que el path sea correcto
   ginSwagger "github.com/swaggo/gin-swagger"
   swaggerFiles "github.com/swaggo/files"
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
+	"lab6/db"
 	"lab6/internal"
 )
 
 // @title La Liga Tracker API
 // @version 1.0
-// @description API para gestionar los partidos de La Liga.
+// @description API para gestionar los partidos de La Liga. Los mensajes se traducen según el encabezado Accept-Language (es, en).
 // @contact.name Esteban Carcamo
 // @contact.email car23016@uvg.edu.gt
 // @host localhost:8080
 // @BasePath /api
+// @securityDefinitions.apikey AdminToken
+// @in header
+// @name Authorization
+// @description Token de administración con el formato "Bearer <ADMIN_TOKEN>".
 
 // getMatches godoc
 // @Summary Obtiene todos los partidos
 // @Description Retorna todos los partidos almacenados en la base de datos.
 // @Tags Matches
-// @Produce json
+// @Produce json,text/csv,xml,application/x-yaml,application/x-ndjson
+// @Param format query string false "Formato de la respuesta (alternativa a Accept)" Enums(json, csv, xml, yaml, ndjson)
 // @Success 200 {array} internal.Match
 // @Failure 500 {object} map[string]string
 // @Router /matches [get]
 func getMatches(c *gin.Context) {
 	matches, err := internal.GetMatches()
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondInternalError(c, err)
 		return
 	}
-	c.JSON(http.StatusOK, matches)
+	render(c, http.StatusOK, matches)
 }
 
 // getMatchID godoc
 // @Summary Obtiene un partido por ID
-// @Description Retorna el partido cuyo ID se especifica en la ruta.
+// @Description Retorna el partido cuyo ID se especifica en la ruta junto con su ETag.
+// @Description Con ?asOf= retorna el partido reconstruido a partir de sus eventos hasta ese instante, sin ETag.
 // @Tags Matches
-// @Produce json
+// @Produce json,text/csv,xml,application/x-yaml,application/x-ndjson
 // @Param id path int true "ID del partido"
+// @Param asOf query string false "Instante (RFC3339) en el que se consulta el partido"
+// @Param format query string false "Formato de la respuesta (alternativa a Accept)" Enums(json, csv, xml, yaml, ndjson)
 // @Success 200 {object} internal.Match
+// @Header 200 {string} ETag "Versión actual del partido"
 // @Failure 400 {object} map[string]string
 // @Failure 404 {object} map[string]string
 // @Router /matches/{id} [get]
@@ -52,16 +63,25 @@ func getMatchID(c *gin.Context) {
 	idParam := c.Param("id")
 	id, err := strconv.Atoi(idParam)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		respondError(c, http.StatusBadRequest, "INVALID_ID")
 		return
 	}
 
-	match, err := internal.GetMatchByID(id)
-	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "No se encontró el partido"})
+	match, historical, ok := lookupMatch(c, id)
+	if !ok {
 		return
 	}
-	c.JSON(http.StatusOK, match)
+	if !historical {
+		c.Header("ETag", matchETag(match.Version))
+	}
+	render(c, http.StatusOK, match)
+}
+
+// matchInput es el body de POST /matches y PUT /matches/{id} en la API v1.
+type matchInput struct {
+	HomeTeam  string `json:"homeTeam" binding:"required" example:"Real Madrid"`
+	AwayTeam  string `json:"awayTeam" binding:"required" example:"FC Barcelona"`
+	MatchDate string `json:"matchDate" binding:"required" format:"date" example:"2025-04-01"`
 }
 
 // createMatch godoc
@@ -70,21 +90,18 @@ func getMatchID(c *gin.Context) {
 // @Tags Matches
 // @Accept json
 // @Produce json
-// @Param match body object true "Datos del partido" 
+// @Param match body matchInput true "Datos del partido"
+// @Param Idempotency-Key header string false "Clave para reintentar la solicitud sin repetir la escritura"
 // @Success 201 {object} map[string]int "ID del partido creado"
 // @Failure 400 {object} map[string]string
 // @Failure 500 {object} map[string]string
 // @Router /matches [post]
 func createMatch(c *gin.Context) {
-	var requestBody struct {
-		HomeTeam  string `json:"homeTeam"`
-		AwayTeam  string `json:"awayTeam"`
-		MatchDate string `json:"matchDate"`
-	}
+	var requestBody matchInput
 
 	// Se realiza el binding del JSON enviado
 	if err := c.ShouldBindJSON(&requestBody); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Datos inválidos"})
+		respondError(c, http.StatusBadRequest, "INVALID_BODY")
 		return
 	}
 
@@ -92,7 +109,7 @@ func createMatch(c *gin.Context) {
 	layout := "2006-01-02"
 	parsedDate, err := time.Parse(layout, requestBody.MatchDate)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Fecha inválida, use formato YYYY-MM-DD"})
+		respondError(c, http.StatusBadRequest, "INVALID_DATE")
 		return
 	}
 
@@ -101,12 +118,13 @@ func createMatch(c *gin.Context) {
 		HomeTeam:  requestBody.HomeTeam,
 		AwayTeam:  requestBody.AwayTeam,
 		MatchDate: parsedDate,
+		DateOnly:  true,
 	}
 
 	// Se inserta el partido en la base de datos
-	newID, err := internal.CreateMatch(match)
+	newID, err := internal.CreateMatch(match, auditInfo(c))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondWriteError(c, 0, err)
 		return
 	}
 
@@ -121,9 +139,14 @@ func createMatch(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Param id path int true "ID del partido"
-// @Param match body object true "Datos del partido"
+// @Param If-Match header string true "ETag obtenido al consultar el partido"
+// @Param match body matchInput true "Datos del partido"
+// @Param Idempotency-Key header string false "Clave para reintentar la solicitud sin repetir la escritura"
 // @Success 200 {object} map[string]string "Mensaje de éxito"
 // @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 412 {object} internal.Match "Versión desactualizada, se retorna la actual"
+// @Failure 428 {object} map[string]string
 // @Failure 500 {object} map[string]string
 // @Router /matches/{id} [put]
 func updateMatch(c *gin.Context) {
@@ -131,19 +154,21 @@ func updateMatch(c *gin.Context) {
 	idParam := c.Param("id")
 	id, err := strconv.Atoi(idParam)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		respondError(c, http.StatusBadRequest, "INVALID_ID")
 		return
 	}
 
-	var requestBody struct {
-		HomeTeam  string `json:"homeTeam"`
-		AwayTeam  string `json:"awayTeam"`
-		MatchDate string `json:"matchDate"`
+	// Se obtiene la versión esperada desde If-Match
+	version, ok := requireIfMatch(c)
+	if !ok {
+		return
 	}
 
+	var requestBody matchInput
+
 	// Se realiza el binding del JSON enviado
 	if err := c.ShouldBindJSON(&requestBody); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Datos inválidos"})
+		respondError(c, http.StatusBadRequest, "INVALID_BODY")
 		return
 	}
 
@@ -151,50 +176,63 @@ func updateMatch(c *gin.Context) {
 	layout := "2006-01-02"
 	parsedDate, err := time.Parse(layout, requestBody.MatchDate)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Fecha inválida, use formato YYYY-MM-DD"})
+		respondError(c, http.StatusBadRequest, "INVALID_DATE")
 		return
 	}
 
-	// Se construye el objeto Match con el ID y la fecha parseada
+	// Se construye el objeto Match con el ID, la fecha parseada y la versión esperada
 	match := internal.Match{
 		ID:        id,
 		HomeTeam:  requestBody.HomeTeam,
 		AwayTeam:  requestBody.AwayTeam,
 		MatchDate: parsedDate,
+		Version:   version,
+		DateOnly:  true,
 	}
 
 	// Se actualiza el partido en la base de datos
-	if err := internal.UpdateMatch(match); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	if err := internal.UpdateMatch(match, auditInfo(c)); err != nil {
+		respondWriteError(c, id, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Partido actualizado correctamente"})
+	c.Header("ETag", matchETag(version+1))
+	respondMessage(c, "MATCH_UPDATED")
 }
 
 // deleteMatch godoc
 // @Summary Elimina un partido
-// @Description Elimina un partido de la base de datos según el ID proporcionado.
+// @Description Envía a la papelera el partido según el ID proporcionado. Puede restaurarse con POST /matches/{id}/restore.
 // @Tags Matches
 // @Produce json
 // @Param id path int true "ID del partido"
+// @Param If-Match header string true "ETag obtenido al consultar el partido"
+// @Param Idempotency-Key header string false "Clave para reintentar la solicitud sin repetir la escritura"
 // @Success 200 {object} map[string]string "Mensaje de éxito"
 // @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 412 {object} internal.Match "Versión desactualizada, se retorna la actual"
+// @Failure 428 {object} map[string]string
 // @Failure 500 {object} map[string]string
 // @Router /matches/{id} [delete]
 func deleteMatch(c *gin.Context) {
 	idParam := c.Param("id")
 	id, err := strconv.Atoi(idParam)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		respondError(c, http.StatusBadRequest, "INVALID_ID")
 		return
 	}
 
-	if err := internal.DeleteMatch(id); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	version, ok := requireIfMatch(c)
+	if !ok {
 		return
 	}
-	c.JSON(http.StatusOK, gin.H{"message": "Partido eliminado"})
+
+	if err := internal.DeleteMatch(id, version, auditInfo(c)); err != nil {
+		respondWriteError(c, id, err)
+		return
+	}
+	respondMessage(c, "MATCH_DELETED")
 }
 
 // updateGoals godoc
@@ -203,24 +241,36 @@ func deleteMatch(c *gin.Context) {
 // @Tags Matches
 // @Produce json
 // @Param id path int true "ID del partido"
+// @Param If-Match header string true "ETag obtenido al consultar el partido"
+// @Param Idempotency-Key header string false "Clave para reintentar la solicitud sin repetir la escritura"
 // @Success 200 {object} map[string]string "Mensaje de éxito"
 // @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 412 {object} internal.Match "Versión desactualizada, se retorna la actual"
+// @Failure 409 {object} map[string]string "El partido ya finalizó"
+// @Failure 428 {object} map[string]string
 // @Failure 500 {object} map[string]string
 // @Router /matches/{id}/goals [patch]
 func updateGoals(c *gin.Context) {
 	idStr := c.Param("id")
 	id, err := strconv.Atoi(idStr)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		respondError(c, http.StatusBadRequest, "INVALID_ID")
+		return
+	}
+
+	version, ok := requireIfMatch(c)
+	if !ok {
 		return
 	}
 
-	if err := internal.UpdateGoals(id); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	if err := internal.UpdateGoals(id, version, auditInfo(c)); err != nil {
+		respondWriteError(c, id, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Gol incrementado correctamente"})
+	c.Header("ETag", matchETag(version+1))
+	respondMessage(c, "GOAL_ADDED")
 }
 
 // updateYellowCards godoc
@@ -229,24 +279,36 @@ func updateGoals(c *gin.Context) {
 // @Tags Matches
 // @Produce json
 // @Param id path int true "ID del partido"
+// @Param If-Match header string true "ETag obtenido al consultar el partido"
+// @Param Idempotency-Key header string false "Clave para reintentar la solicitud sin repetir la escritura"
 // @Success 200 {object} map[string]string "Mensaje de éxito"
 // @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 412 {object} internal.Match "Versión desactualizada, se retorna la actual"
+// @Failure 409 {object} map[string]string "El partido ya finalizó"
+// @Failure 428 {object} map[string]string
 // @Failure 500 {object} map[string]string
 // @Router /matches/{id}/yellowcards [patch]
 func updateYellowCards(c *gin.Context) {
 	idStr := c.Param("id")
 	id, err := strconv.Atoi(idStr)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		respondError(c, http.StatusBadRequest, "INVALID_ID")
+		return
+	}
+
+	version, ok := requireIfMatch(c)
+	if !ok {
 		return
 	}
 
-	if err := internal.UpdateYellowCards(id); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	if err := internal.UpdateYellowCards(id, version, auditInfo(c)); err != nil {
+		respondWriteError(c, id, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Tarjeta amarilla incrementada correctamente"})
+	c.Header("ETag", matchETag(version+1))
+	respondMessage(c, "YELLOW_CARD_ADDED")
 }
 
 // updateRedCards godoc
@@ -255,24 +317,36 @@ func updateYellowCards(c *gin.Context) {
 // @Tags Matches
 // @Produce json
 // @Param id path int true "ID del partido"
+// @Param If-Match header string true "ETag obtenido al consultar el partido"
+// @Param Idempotency-Key header string false "Clave para reintentar la solicitud sin repetir la escritura"
 // @Success 200 {object} map[string]string "Mensaje de éxito"
 // @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 412 {object} internal.Match "Versión desactualizada, se retorna la actual"
+// @Failure 409 {object} map[string]string "El partido ya finalizó"
+// @Failure 428 {object} map[string]string
 // @Failure 500 {object} map[string]string
 // @Router /matches/{id}/redcards [patch]
 func updateRedCards(c *gin.Context) {
 	idStr := c.Param("id")
 	id, err := strconv.Atoi(idStr)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		respondError(c, http.StatusBadRequest, "INVALID_ID")
+		return
+	}
+
+	version, ok := requireIfMatch(c)
+	if !ok {
 		return
 	}
 
-	if err := internal.UpdateRedCards(id); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	if err := internal.UpdateRedCards(id, version, auditInfo(c)); err != nil {
+		respondWriteError(c, id, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Tarjeta roja incrementada correctamente"})
+	c.Header("ETag", matchETag(version+1))
+	respondMessage(c, "RED_CARD_ADDED")
 }
 
 // updateExtraTime godoc
@@ -281,44 +355,66 @@ func updateRedCards(c *gin.Context) {
 // @Tags Matches
 // @Produce json
 // @Param id path int true "ID del partido"
+// @Param If-Match header string true "ETag obtenido al consultar el partido"
+// @Param Idempotency-Key header string false "Clave para reintentar la solicitud sin repetir la escritura"
 // @Success 200 {object} map[string]string "Mensaje de éxito"
 // @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 412 {object} internal.Match "Versión desactualizada, se retorna la actual"
+// @Failure 409 {object} map[string]string "El partido ya finalizó"
+// @Failure 428 {object} map[string]string
 // @Failure 500 {object} map[string]string
 // @Router /matches/{id}/extratime [patch]
 func updateExtraTime(c *gin.Context) {
 	idStr := c.Param("id")
 	id, err := strconv.Atoi(idStr)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		respondError(c, http.StatusBadRequest, "INVALID_ID")
 		return
 	}
 
-	if err := internal.UpdateExtraTime(id); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	version, ok := requireIfMatch(c)
+	if !ok {
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Tiempo extra incrementado correctamente"})
+	if err := internal.UpdateExtraTime(id, version, auditInfo(c)); err != nil {
+		respondWriteError(c, id, err)
+		return
+	}
+
+	c.Header("ETag", matchETag(version+1))
+	respondMessage(c, "EXTRA_TIME_SET")
 }
 
+// allowedOrigins son los orígenes del frontend habilitados por CORS y para abrir /ws.
+var allowedOrigins = []string{"http://localhost:3000", "https://23016.escudevwork.lat"}
+
 func main() {
 	// Inicializa la conexión a la base de datos
 	if err := internal.InitDB(); err != nil {
 		log.Fatalf("Error al conectar a la base de datos: %v", err)
 	}
+	// init.sql es idempotente: actualiza las bases creadas con una versión anterior
+	if err := internal.MigrateSchema(db.InitSQL); err != nil {
+		log.Fatalf("Error al actualizar el esquema de la base de datos: %v", err)
+	}
+	if err := internal.CheckSchema(); err != nil {
+		log.Fatalf("Error en el esquema de la base de datos: %v", err)
+	}
 
 	router := gin.Default()
 
 	// Configurar CORS
 	router.Use(cors.New(cors.Config{
 		// Permite solicitudes desde los orígenes indicados. Puedes usar "*" para permitir cualquier origen, pero es más seguro especificar solo los necesarios.
-		AllowOrigins: []string{"http://localhost:3000", "https://23016.escudevwork.lat"},
+		AllowOrigins: allowedOrigins,
 		// Métodos HTTP permitidos.
 		AllowMethods: []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
 		// Encabezados permitidos en la solicitud.
-		AllowHeaders: []string{"Origin", "Content-Type", "Authorization"},
+		AllowHeaders: []string{"Origin", "Content-Type", "Authorization", "Accept-Language", "If-Match", "Idempotency-Key", "X-Request-ID", "X-Actor", "Last-Event-ID"},
 		// Encabezados que se exponen en la respuesta.
-		ExposeHeaders: []string{"Content-Length"},
+		ExposeHeaders: []string{"Content-Length", "Content-Language", "ETag", "Idempotent-Replayed", "Location", "Deprecation", "Sunset", "Link", "X-Request-ID"},
 		// Permite el envío de cookies, autenticación y otros encabezados de credenciales.
 		AllowCredentials: true,
 		// Tiempo máximo para que se considere válida una solicitud preflight.
@@ -327,21 +423,109 @@ func main() {
 	// Servir el archivo HTML en la raíz
 	router.StaticFile("/", "./LaLigaTracker.html")
 
+	// Las claves de idempotencia expiradas se purgan cada hora
+	go purgeIdempotencyKeys(time.Hour)
+
+	// Marcador en vivo por WebSocket, compartido por todas las conexiones
+	hub := newScoreboardHub()
+	go hub.run()
+	// Los eventos registrados por otras réplicas llegan por LISTEN/NOTIFY
+	go internal.ListenChanges()
+	// Las entregas de webhooks se generan y reintentan en segundo plano
+	go runWebhookDispatcher()
+	// MatchService por gRPC, en su propio puerto
+	go runGRPCServer(grpcAddr())
+	router.GET("/ws", localeMiddleware(), serveScoreboard(hub))
+
+	// GraphQL: queries y mutaciones por HTTP, suscripciones por WebSocket en la misma ruta. La ruta
+	// documentada en el spec es /api/graphql; /graphql se mantiene para los clientes que ya la usan
+	graphqlRoutes := router.Group("/graphql", requestIDMiddleware(), localeMiddleware(), idempotencyMiddleware(idempotencyTTL()))
+	graphqlRoutes.POST("", serveGraphQL)
+	graphqlRoutes.GET("", serveGraphQLQuery)
+
 	api := router.Group("/api")
+	api.Use(requestIDMiddleware(), localeMiddleware(), openAPIMiddleware(validateOpenAPIResponses()), idempotencyMiddleware(idempotencyTTL()))
+	api.POST("/graphql", serveGraphQL)
+	api.GET("/graphql", serveGraphQLQuery)
+	api.POST("/batch", runBatch)
+	api.GET("/search", search)
+	api.GET("/calendar", getCalendar)
+	api.POST("/import/matches", importMatches)
+	api.GET("/export/football-data", exportFootballData)
+	api.GET("/trash", getTrash)
+	api.POST("/matches/:id/restore", restoreMatch)
+	api.GET("/matches/:id/history", getMatchHistory)
+	api.GET("/matches/:id/events", getMatchEvents)
+	api.GET("/matches/:id/clock", getMatchClock)
+	api.POST("/matches/:id/clock", updateMatchClock)
+	api.GET("/matches/:id/commentary", getCommentary)
+	api.POST("/matches/:id/commentary", createCommentary)
+	api.PATCH("/matches/:id/commentary/:entryId", updateCommentary)
+	api.DELETE("/matches/:id/commentary/:entryId", deleteCommentary)
+	api.GET("/matches/stream", streamMatches)
+	api.GET("/matches/:id/stream", streamMatch)
+	api.GET("/teams", getTeams)
+	api.GET("/teams/resolve", resolveTeamName)
+	api.GET("/teams/:id", getTeam)
+	api.GET("/teams/:id/calendar.ics", getTeamCalendar)
+	api.GET("/competitions", getCompetitions)
+	api.GET("/competitions/:id", getCompetition)
+	api.GET("/competitions/:id/calendar.ics", getCompetitionCalendar)
+
+	// Rutas de administración, protegidas con ADMIN_TOKEN
+	admin := api.Group("/admin", adminMiddleware())
+	{
+		admin.POST("/teams", createTeam)
+		admin.POST("/teams/merge", mergeTeams)
+		admin.POST("/teams/:id/aliases", addTeamAlias)
+		admin.DELETE("/trash", purgeTrash)
+		admin.GET("/audit", getAuditLog)
+		admin.POST("/projections/rebuild", rebuildProjections)
+		admin.GET("/export", exportDataset)
+		admin.POST("/import", importDataset)
+		admin.POST("/webhooks", createWebhook)
+		admin.GET("/webhooks", getWebhooks)
+		admin.GET("/webhooks/dead-letters", getWebhookDeadLetters)
+		admin.POST("/webhooks/deliveries/:deliveryId/redeliver", redeliverWebhook)
+		admin.GET("/webhooks/:id", getWebhook)
+		admin.DELETE("/webhooks/:id", deleteWebhook)
+		admin.GET("/webhooks/:id/deliveries", getWebhookDeliveries)
+		admin.POST("/webhooks/:id/test", testWebhook)
+	}
+
+	// API v1: conserva su representación original y anuncia su retiro
+	v1 := api.Group("", deprecationMiddleware())
 	{
-		api.GET("/matches", getMatches)
-		api.GET("/matches/:id", getMatchID)
-		api.POST("/matches", createMatch)
-		api.PUT("/matches/:id", updateMatch)
-		api.DELETE("/matches/:id", deleteMatch)
-		api.PATCH("/matches/:id/goals", updateGoals)
-		api.PATCH("/matches/:id/yellowcards", updateYellowCards)
-		api.PATCH("/matches/:id/redcards", updateRedCards)
-		api.PATCH("/matches/:id/extratime", updateExtraTime)
+		v1.GET("/matches", getMatches)
+		v1.GET("/matches/:id", getMatchID)
+		v1.POST("/matches", createMatch)
+		v1.PUT("/matches/:id", updateMatch)
+		v1.DELETE("/matches/:id", deleteMatch)
+		v1.PATCH("/matches/:id/goals", updateGoals)
+		v1.PATCH("/matches/:id/yellowcards", updateYellowCards)
+		v1.PATCH("/matches/:id/redcards", updateRedCards)
+		v1.PATCH("/matches/:id/extratime", updateExtraTime)
+	}
+
+	// API v2: equipos anidados, marcador agrupado y kickoff RFC3339
+	v2 := api.Group("/v2")
+	{
+		v2.GET("/matches", getMatchesV2)
+		v2.GET("/matches/:id", getMatchV2)
+		v2.POST("/matches", createMatchV2)
+		v2.PUT("/matches/:id", updateMatchV2)
+		v2.DELETE("/matches/:id", deleteMatchV2)
+		v2.PATCH("/matches/:id/stats/:stat", updateStatV2)
+		v2.POST("/matches/:id/finish", finishMatchV2)
 	}
 
   router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 
+	// Todas las rutas de /api deben estar en el spec que las valida
+	if missing := undocumentedRoutes(router.Routes()); len(missing) > 0 {
+		log.Fatalf("Rutas sin documentar en el spec OpenAPI: %s", strings.Join(missing, ", "))
+	}
+
 	if err := router.Run(":8080"); err != nil {
 		log.Fatalf("Error al iniciar el servidor: %v", err)
 	}
@@ -0,0 +1,268 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"mime"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi2"
+	"github.com/getkin/kin-openapi/openapi2conv"
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/getkin/kin-openapi/routers/legacy"
+	"github.com/gin-gonic/gin"
+	"lab6/docs"
+)
+
+// maxValidatedResponseSize limita el tamaño de las respuestas cuyo body se valida; las más
+// grandes (por ejemplo los respaldos ZIP) solo se validan por código de estado y encabezados.
+const maxValidatedResponseSize = 1 << 20
+
+// openAPISpec es el spec de docs/swagger.yaml, tomado de docs.SwaggerInfo para no depender del
+// archivo en tiempo de ejecución y convertido a OpenAPI 3 para validarlo con kin-openapi.
+var openAPISpec = mustLoadOpenAPISpec()
+
+// openAPIRouter ubica la operación del spec que corresponde a cada solicitud.
+var openAPIRouter = mustBuildOpenAPIRouter(openAPISpec)
+
+// mustLoadOpenAPISpec carga el spec. Un spec inválido es un error de las anotaciones, por lo que
+// detiene el arranque en lugar de desactivar la validación.
+func mustLoadOpenAPISpec() *openapi3.T {
+	var doc2 openapi2.T
+	if err := json.Unmarshal([]byte(docs.SwaggerInfo.ReadDoc()), &doc2); err != nil {
+		panic(err)
+	}
+	doc, err := openapi2conv.ToV3(&doc2)
+	if err != nil {
+		panic(err)
+	}
+
+	// Las rutas del spec son relativas a basePath; se usan completas para ubicar la operación
+	// solo por la ruta de la solicitud, sin importar el host con que se acceda a la API
+	paths := openapi3.NewPaths()
+	for path, item := range doc.Paths.Map() {
+		paths.Set(doc2.BasePath+path, item)
+	}
+	doc.Paths = paths
+	doc.Servers = nil
+	return doc
+}
+
+// mustBuildOpenAPIRouter construye el router de kin-openapi para el spec.
+func mustBuildOpenAPIRouter(doc *openapi3.T) routers.Router {
+	router, err := legacy.NewRouter(doc)
+	if err != nil {
+		panic(err)
+	}
+	return router
+}
+
+// undocumentedRoutes retorna las rutas registradas bajo /api que no tienen una operación en el spec,
+// como "GET /api/matches/:id". El servidor no arranca si hay alguna, para que el spec y las rutas
+// no se desincronicen.
+func undocumentedRoutes(routes gin.RoutesInfo) []string {
+	var missing []string
+	for _, route := range routes {
+		if route.Path != "/api" && !strings.HasPrefix(route.Path, "/api/") {
+			continue
+		}
+		item := openAPISpec.Paths.Value(openAPIPath(route.Path))
+		if item == nil || item.GetOperation(route.Method) == nil {
+			missing = append(missing, route.Method+" "+route.Path)
+		}
+	}
+	return missing
+}
+
+// openAPIPath convierte una ruta de gin ("/matches/:id") al formato del spec ("/matches/{id}").
+func openAPIPath(path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, ":") || strings.HasPrefix(segment, "*") {
+			segments[i] = "{" + segment[1:] + "}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+// validateOpenAPIResponses indica si se validan también las respuestas. Se controla con
+// OPENAPI_VALIDATE_RESPONSES y por defecto está activo solo en el modo debug de gin.
+func validateOpenAPIResponses() bool {
+	value := os.Getenv("OPENAPI_VALIDATE_RESPONSES")
+	if value == "" {
+		return gin.IsDebugging()
+	}
+	enabled, err := strconv.ParseBool(value)
+	if err != nil {
+		log.Printf("OPENAPI_VALIDATE_RESPONSES inválido (%q), se usa %v", value, gin.IsDebugging())
+		return gin.IsDebugging()
+	}
+	return enabled
+}
+
+// openAPIMiddleware valida cada solicitud contra el spec antes de llegar al handler y responde
+// 400 si no lo cumple. Con validateResponses también valida la respuesta y registra en el log
+// las diferencias con el spec; la respuesta se entrega igual, porque ya se envió al cliente.
+// undocumentedRoutes impide arrancar con rutas sin documentar; si aun así una solicitud llega a una
+// ruta registrada que el spec no conoce, se rechaza con 500 en lugar de atenderla sin validar.
+// Las solicitudes a rutas inexistentes siguen hasta el 404 o 405 de gin.
+func openAPIMiddleware(validateResponses bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		route, pathParams, err := openAPIRouter.FindRoute(c.Request)
+		if err != nil {
+			if c.FullPath() == "" {
+				c.Next()
+				return
+			}
+			log.Printf("La ruta %s %s no está documentada en el spec: %v", c.Request.Method, c.FullPath(), err)
+			abortWithError(c, http.StatusInternalServerError, "UNDOCUMENTED_ROUTE")
+			return
+		}
+
+		requestOptions := &openapi3filter.Options{
+			// Solo se decodifican bodies JSON: los archivos de importación se validan en sus handlers
+			ExcludeRequestBody: !isJSONContentType(c.GetHeader("Content-Type")),
+			MultiError:         true,
+			// La autenticación la verifica adminMiddleware
+			AuthenticationFunc: openapi3filter.NoopAuthenticationFunc,
+		}
+		requestOptions.WithCustomSchemaErrorFunc(schemaErrorMessage)
+		input := &openapi3filter.RequestValidationInput{
+			Request:    c.Request,
+			PathParams: pathParams,
+			Route:      route,
+			Options:    requestOptions,
+		}
+		if err := requestValidationError(openapi3filter.ValidateRequest(c.Request.Context(), input)); err != nil {
+			code, args := openAPIErrorCode(err)
+			body := errorBody(c, code, args...)
+			body["detail"] = err.Error()
+			c.AbortWithStatusJSON(http.StatusBadRequest, body)
+			return
+		}
+
+		if !validateResponses || c.IsWebsocket() {
+			c.Next()
+			return
+		}
+		writer := &recordingWriter{ResponseWriter: c.Writer}
+		c.Writer = writer
+		c.Next()
+
+		contentType := writer.Header().Get("Content-Type")
+		if strings.HasPrefix(contentType, "text/event-stream") {
+			return
+		}
+		responseOptions := &openapi3filter.Options{
+			ExcludeResponseBody:   writer.overflow || !isJSONContentType(contentType),
+			IncludeResponseStatus: true,
+			MultiError:            true,
+		}
+		responseOptions.WithCustomSchemaErrorFunc(schemaErrorMessage)
+		response := &openapi3filter.ResponseValidationInput{
+			RequestValidationInput: input,
+			Status:                 writer.Status(),
+			Header:                 writer.Header(),
+			Options:                responseOptions,
+		}
+		response.SetBodyBytes(writer.body.Bytes())
+		if err := openapi3filter.ValidateResponse(c.Request.Context(), response); err != nil {
+			log.Printf("La respuesta %d de %s %s no cumple el spec (solicitud %s): %v",
+				writer.Status(), c.Request.Method, route.Path, c.GetString(requestIDContextKey), err)
+		}
+	}
+}
+
+// requestValidationError descarta de los errores de validación la falta de If-Match: las
+// escrituras condicionadas lo documentan como requerido, pero su ausencia la informa el handler
+// con 428, que es más preciso que un 400 genérico.
+func requestValidationError(err error) error {
+	var errs openapi3.MultiError
+	if !errors.As(err, &errs) {
+		return err
+	}
+	var remaining openapi3.MultiError
+	for _, e := range errs {
+		var reqErr *openapi3filter.RequestError
+		if errors.As(e, &reqErr) && reqErr.Parameter != nil && reqErr.Parameter.In == openapi3.ParameterInHeader &&
+			http.CanonicalHeaderKey(reqErr.Parameter.Name) == "If-Match" && errors.Is(reqErr.Err, openapi3filter.ErrInvalidRequired) {
+			continue
+		}
+		remaining = append(remaining, e)
+	}
+	if len(remaining) == 0 {
+		return nil
+	}
+	return remaining
+}
+
+// openAPIErrorCode retorna el código del catálogo que corresponde al primer error de validación:
+// INVALID_BODY para el body, que es el mismo que usan los handlers, e INVALID_PARAMETER con el
+// nombre del parámetro para la ruta, la query y los encabezados.
+func openAPIErrorCode(err error) (string, []any) {
+	var reqErr *openapi3filter.RequestError
+	if !errors.As(err, &reqErr) {
+		return "INVALID_REQUEST", nil
+	}
+	switch {
+	case reqErr.Parameter != nil:
+		return "INVALID_PARAMETER", []any{reqErr.Parameter.Name}
+	case reqErr.RequestBody != nil:
+		return "INVALID_BODY", nil
+	default:
+		return "INVALID_REQUEST", nil
+	}
+}
+
+// schemaErrorMessage describe un error de esquema con el campo y el motivo, sin el esquema ni el
+// valor completos que kin-openapi incluye por defecto.
+func schemaErrorMessage(err *openapi3.SchemaError) string {
+	if len(err.JSONPointer()) == 0 {
+		return err.Reason
+	}
+	return fmt.Sprintf("/%s: %s", strings.Join(err.JSONPointer(), "/"), err.Reason)
+}
+
+// isJSONContentType indica si el Content-Type corresponde a JSON.
+func isJSONContentType(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	return err == nil && (mediaType == "application/json" || strings.HasSuffix(mediaType, "+json"))
+}
+
+// recordingWriter conserva una copia del body escrito, hasta maxValidatedResponseSize, para
+// validar la respuesta después de enviarla.
+type recordingWriter struct {
+	gin.ResponseWriter
+	body     bytes.Buffer
+	overflow bool
+}
+
+func (w *recordingWriter) Write(data []byte) (int, error) {
+	w.record(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *recordingWriter) WriteString(s string) (int, error) {
+	w.record([]byte(s))
+	return w.ResponseWriter.WriteString(s)
+}
+
+func (w *recordingWriter) record(data []byte) {
+	if w.overflow {
+		return
+	}
+	if w.body.Len()+len(data) > maxValidatedResponseSize {
+		w.overflow = true
+		w.body.Reset()
+		return
+	}
+	w.body.Write(data)
+}
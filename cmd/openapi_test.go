@@ -0,0 +1,78 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestUndocumentedRoutes(t *testing.T) {
+	routes := gin.RoutesInfo{
+		{Method: http.MethodGet, Path: "/api/matches/:id"},
+		{Method: http.MethodPatch, Path: "/api/v2/matches/:id/stats/:stat"},
+		{Method: http.MethodPost, Path: "/api/admin/webhooks/deliveries/:deliveryId/redeliver"},
+		{Method: http.MethodGet, Path: "/api/teams/:id/calendar.ics"},
+		{Method: http.MethodGet, Path: "/swagger/*any"},
+		{Method: http.MethodGet, Path: "/ws"},
+		{Method: http.MethodGet, Path: "/api/matches/:id/lineups"},
+		{Method: http.MethodPatch, Path: "/api/teams/:id"},
+	}
+
+	got := undocumentedRoutes(routes)
+	want := []string{"GET /api/matches/:id/lineups", "PATCH /api/teams/:id"}
+	if !slices.Equal(got, want) {
+		t.Errorf("undocumentedRoutes = %v, se esperaba %v", got, want)
+	}
+}
+
+func TestOpenAPIMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(localeMiddleware(), openAPIMiddleware(false))
+	ok := func(c *gin.Context) { c.Status(http.StatusOK) }
+	router.GET("/api/search", ok)
+	router.POST("/api/v2/matches", ok)
+	router.GET("/api/matches/:id/lineups", ok)
+
+	tests := []struct {
+		name       string
+		method     string
+		target     string
+		body       string
+		wantStatus int
+		wantCode   string
+	}{
+		{name: "solicitud válida", method: http.MethodGet, target: "/api/search?q=real", wantStatus: http.StatusOK},
+		{name: "falta un parámetro requerido", method: http.MethodGet, target: "/api/search", wantStatus: http.StatusBadRequest, wantCode: "INVALID_PARAMETER"},
+		{name: "parámetro con tipo inválido", method: http.MethodGet, target: "/api/search?q=real&limit=diez", wantStatus: http.StatusBadRequest, wantCode: "INVALID_PARAMETER"},
+		{name: "body que no cumple el esquema", method: http.MethodPost, target: "/api/v2/matches", body: `{"homeTeam": 5}`, wantStatus: http.StatusBadRequest, wantCode: "INVALID_BODY"},
+		{name: "ruta registrada sin documentar", method: http.MethodGet, target: "/api/matches/3/lineups", wantStatus: http.StatusInternalServerError, wantCode: "UNDOCUMENTED_ROUTE"},
+		{name: "ruta inexistente", method: http.MethodGet, target: "/api/nada", wantStatus: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.target, strings.NewReader(tt.body))
+			if tt.body != "" {
+				req.Header.Set("Content-Type", "application/json")
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, se esperaba %d (body %s)", w.Code, tt.wantStatus, w.Body)
+			}
+			if tt.wantCode != "" {
+				var body map[string]any
+				if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil || body["code"] != tt.wantCode {
+					t.Errorf("code = %v, se esperaba %s", body["code"], tt.wantCode)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,415 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"unicode"
+
+	"github.com/gin-gonic/gin"
+	"gopkg.in/yaml.v3"
+)
+
+// Tipos de contenido que pueden negociar los endpoints de lectura.
+const (
+	mimeJSON   = "application/json"
+	mimeCSV    = "text/csv"
+	mimeXML    = "application/xml"
+	mimeYAML   = "application/x-yaml"
+	mimeNDJSON = "application/x-ndjson"
+)
+
+// formatAliases asocia los valores aceptados en ?format= con su tipo de contenido.
+var formatAliases = map[string]string{
+	"json":   mimeJSON,
+	"csv":    mimeCSV,
+	"xml":    mimeXML,
+	"yaml":   mimeYAML,
+	"yml":    mimeYAML,
+	"ndjson": mimeNDJSON,
+}
+
+// offeredFormats son los tipos ofrecidos en la negociación por Accept, en orden de preferencia.
+var offeredFormats = []string{mimeJSON, mimeCSV, mimeXML, mimeYAML, "text/yaml", mimeNDJSON}
+
+// encoders contiene un codificador por tipo de contenido. Todos trabajan sobre el árbol
+// genérico que produce toNode, de modo que cualquier recurso serializable a JSON
+// obtiene automáticamente todos los formatos.
+var encoders = map[string]func(io.Writer, *node) error{
+	mimeCSV:    encodeCSV,
+	mimeXML:    encodeXML,
+	mimeYAML:   encodeYAML,
+	mimeNDJSON: encodeNDJSON,
+}
+
+// responseFormat determina el tipo de contenido solicitado mediante ?format= o el encabezado Accept,
+// o JSON si no se solicita ninguno. Si el formato no se puede servir responde el error y retorna false.
+// Los endpoints de escritura lo llaman antes de escribir para no aplicar un cambio cuya respuesta
+// después no se podría entregar.
+func responseFormat(c *gin.Context) (string, bool) {
+	mime := mimeJSON
+	if format := c.Query("format"); format != "" {
+		var ok bool
+		if mime, ok = formatAliases[strings.ToLower(format)]; !ok {
+			respondError(c, http.StatusBadRequest, "UNSUPPORTED_FORMAT")
+			return "", false
+		}
+	} else if c.GetHeader("Accept") != "" {
+		if mime = c.NegotiateFormat(offeredFormats...); mime == "" {
+			respondError(c, http.StatusNotAcceptable, "NOT_ACCEPTABLE")
+			return "", false
+		}
+	}
+	if mime == "text/yaml" {
+		mime = mimeYAML
+	}
+	return mime, true
+}
+
+// render escribe data en el formato solicitado mediante ?format= o el encabezado Accept.
+// Si no se solicita ningún formato en particular se responde JSON.
+func render(c *gin.Context, status int, data any) {
+	c.Writer.Header().Add("Vary", "Accept")
+
+	mime, ok := responseFormat(c)
+	if !ok {
+		return
+	}
+
+	encode, ok := encoders[mime]
+	if !ok {
+		c.JSON(status, data)
+		return
+	}
+
+	tree, err := toNode(data)
+	if err != nil {
+		respondInternalError(c, err)
+		return
+	}
+	var buf bytes.Buffer
+	if err := encode(&buf, tree); err != nil {
+		respondInternalError(c, err)
+		return
+	}
+	c.Data(status, mime+"; charset=utf-8", buf.Bytes())
+}
+
+// nodeKind identifica el tipo de valor de un node.
+type nodeKind int
+
+const (
+	nodeNull nodeKind = iota
+	nodeBool
+	nodeNumber
+	nodeString
+	nodeArray
+	nodeObject
+)
+
+// node es un valor JSON genérico que conserva el orden de los campos de los objetos.
+type node struct {
+	kind   nodeKind
+	scalar string
+	items  []*node
+	keys   []string
+	fields map[string]*node
+}
+
+// toNode serializa data a JSON y lo convierte en un árbol de nodes.
+func toNode(data any) (*node, error) {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	return decodeNode(dec)
+}
+
+func decodeNode(dec *json.Decoder) (*node, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	switch v := tok.(type) {
+	case nil:
+		return &node{kind: nodeNull}, nil
+	case bool:
+		return &node{kind: nodeBool, scalar: fmt.Sprint(v)}, nil
+	case json.Number:
+		return &node{kind: nodeNumber, scalar: v.String()}, nil
+	case string:
+		return &node{kind: nodeString, scalar: v}, nil
+	case json.Delim:
+		if v == '[' {
+			n := &node{kind: nodeArray}
+			for dec.More() {
+				item, err := decodeNode(dec)
+				if err != nil {
+					return nil, err
+				}
+				n.items = append(n.items, item)
+			}
+			_, err := dec.Token()
+			return n, err
+		}
+		n := &node{kind: nodeObject, fields: make(map[string]*node)}
+		for dec.More() {
+			keyTok, err := dec.Token()
+			if err != nil {
+				return nil, err
+			}
+			key := keyTok.(string)
+			value, err := decodeNode(dec)
+			if err != nil {
+				return nil, err
+			}
+			n.keys = append(n.keys, key)
+			n.fields[key] = value
+		}
+		_, err := dec.Token()
+		return n, err
+	}
+	return nil, fmt.Errorf("token JSON inesperado: %v", tok)
+}
+
+// rows retorna los elementos que se escriben como filas en los formatos tabulares:
+// los elementos de un arreglo, ninguno para null, o el propio valor en otro caso.
+func (n *node) rows() []*node {
+	switch n.kind {
+	case nodeArray:
+		return n.items
+	case nodeNull:
+		return nil
+	}
+	return []*node{n}
+}
+
+// rawJSON vuelve a serializar un node como JSON compacto.
+func (n *node) rawJSON() []byte {
+	var buf bytes.Buffer
+	n.writeJSON(&buf)
+	return buf.Bytes()
+}
+
+func (n *node) writeJSON(buf *bytes.Buffer) {
+	switch n.kind {
+	case nodeNull:
+		buf.WriteString("null")
+	case nodeBool, nodeNumber:
+		buf.WriteString(n.scalar)
+	case nodeString:
+		encoded, _ := json.Marshal(n.scalar)
+		buf.Write(encoded)
+	case nodeArray:
+		buf.WriteByte('[')
+		for i, item := range n.items {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			item.writeJSON(buf)
+		}
+		buf.WriteByte(']')
+	case nodeObject:
+		buf.WriteByte('{')
+		for i, key := range n.keys {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			encoded, _ := json.Marshal(key)
+			buf.Write(encoded)
+			buf.WriteByte(':')
+			n.fields[key].writeJSON(buf)
+		}
+		buf.WriteByte('}')
+	}
+}
+
+// encodeNDJSON escribe un objeto JSON por línea. Un arreglo produce una línea por elemento.
+func encodeNDJSON(w io.Writer, n *node) error {
+	items := n.rows()
+	for _, item := range items {
+		if _, err := w.Write(append(item.rawJSON(), '\n')); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// encodeCSV escribe una fila por elemento. Los objetos anidados se aplanan usando
+// claves separadas por punto (por ejemplo "score.goals") y los arreglos se escriben como JSON.
+func encodeCSV(w io.Writer, n *node) error {
+	items := n.rows()
+
+	var columns []string
+	seen := make(map[string]bool)
+	rows := make([]map[string]string, len(items))
+	for i, item := range items {
+		rows[i] = make(map[string]string)
+		flatten(item, "", rows[i], func(column string) {
+			if !seen[column] {
+				seen[column] = true
+				columns = append(columns, column)
+			}
+		})
+	}
+
+	writer := csv.NewWriter(w)
+	if err := writer.Write(columns); err != nil {
+		return err
+	}
+	for _, row := range rows {
+		record := make([]string, len(columns))
+		for i, column := range columns {
+			record[i] = row[column]
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+func flatten(n *node, prefix string, row map[string]string, addColumn func(string)) {
+	if n.kind == nodeObject {
+		for _, key := range n.keys {
+			column := key
+			if prefix != "" {
+				column = prefix + "." + key
+			}
+			flatten(n.fields[key], column, row, addColumn)
+		}
+		return
+	}
+
+	column := prefix
+	if column == "" {
+		column = "value"
+	}
+	addColumn(column)
+	switch n.kind {
+	case nodeNull:
+		row[column] = ""
+	case nodeArray:
+		row[column] = string(n.rawJSON())
+	default:
+		row[column] = n.scalar
+	}
+}
+
+// encodeXML escribe el árbol como XML. Los arreglos se envuelven en <items> con un <item>
+// por elemento; las claves que no son nombres XML válidos se escriben como <entry key="...">.
+func encodeXML(w io.Writer, n *node) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	root := "response"
+	if n.kind == nodeArray {
+		root = "items"
+	}
+	if err := writeXMLElement(enc, root, n); err != nil {
+		return err
+	}
+	return enc.Flush()
+}
+
+func writeXMLElement(enc *xml.Encoder, name string, n *node) error {
+	start := xml.StartElement{Name: xml.Name{Local: name}}
+	if !isXMLName(name) {
+		start = xml.StartElement{
+			Name: xml.Name{Local: "entry"},
+			Attr: []xml.Attr{{Name: xml.Name{Local: "key"}, Value: name}},
+		}
+	}
+	if n.kind == nodeNull {
+		start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "nil"}, Value: "true"})
+	}
+	if err := enc.EncodeToken(start); err != nil {
+		return err
+	}
+
+	switch n.kind {
+	case nodeArray:
+		for _, item := range n.items {
+			if err := writeXMLElement(enc, "item", item); err != nil {
+				return err
+			}
+		}
+	case nodeObject:
+		for _, key := range n.keys {
+			if err := writeXMLElement(enc, key, n.fields[key]); err != nil {
+				return err
+			}
+		}
+	case nodeBool, nodeNumber, nodeString:
+		if err := enc.EncodeToken(xml.CharData(n.scalar)); err != nil {
+			return err
+		}
+	}
+	return enc.EncodeToken(start.End())
+}
+
+// isXMLName indica si name puede usarse como nombre de elemento XML.
+func isXMLName(name string) bool {
+	if name == "" || strings.HasPrefix(strings.ToLower(name), "xml") {
+		return false
+	}
+	for i, r := range name {
+		if unicode.IsLetter(r) || r == '_' {
+			continue
+		}
+		if i > 0 && (unicode.IsDigit(r) || r == '-' || r == '.') {
+			continue
+		}
+		return false
+	}
+	return true
+}
+
+// encodeYAML escribe el árbol como YAML conservando el orden de los campos.
+func encodeYAML(w io.Writer, n *node) error {
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(toYAMLNode(n)); err != nil {
+		return err
+	}
+	return enc.Close()
+}
+
+func toYAMLNode(n *node) *yaml.Node {
+	switch n.kind {
+	case nodeNull:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null", Value: "null"}
+	case nodeBool:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!bool", Value: n.scalar}
+	case nodeNumber:
+		tag := "!!int"
+		if strings.ContainsAny(n.scalar, ".eE") {
+			tag = "!!float"
+		}
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: tag, Value: n.scalar}
+	case nodeString:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: n.scalar}
+	case nodeArray:
+		seq := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+		for _, item := range n.items {
+			seq.Content = append(seq.Content, toYAMLNode(item))
+		}
+		return seq
+	}
+	mapping := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	for _, key := range n.keys {
+		mapping.Content = append(mapping.Content,
+			&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key},
+			toYAMLNode(n.fields[key]))
+	}
+	return mapping
+}
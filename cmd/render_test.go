@@ -0,0 +1,212 @@
+package main
+
+import (
+	"bytes"
+	"encoding/xml"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestResponseFormat(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name       string
+		query      string
+		accept     string
+		wantMime   string
+		wantStatus int
+	}{
+		{name: "sin preferencia responde JSON", wantMime: mimeJSON},
+		{name: "formato por query", query: "?format=CSV", wantMime: mimeCSV},
+		{name: "query tiene prioridad sobre Accept", query: "?format=yml", accept: mimeXML, wantMime: mimeYAML},
+		{name: "Accept con text/yaml", accept: "text/yaml", wantMime: mimeYAML},
+		{name: "Accept con varios tipos", accept: "application/pdf, text/csv", wantMime: mimeCSV},
+		{name: "formato desconocido", query: "?format=pdf", wantStatus: http.StatusBadRequest},
+		{name: "Accept sin formatos ofrecidos", accept: "application/pdf", wantStatus: http.StatusNotAcceptable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodGet, "/api/teams"+tt.query, nil)
+			if tt.accept != "" {
+				c.Request.Header.Set("Accept", tt.accept)
+			}
+
+			mime, ok := responseFormat(c)
+			if ok != (tt.wantStatus == 0) {
+				t.Fatalf("ok = %v, status %d", ok, w.Code)
+			}
+			if ok && mime != tt.wantMime {
+				t.Errorf("mime = %q, se esperaba %q", mime, tt.wantMime)
+			}
+			if !ok && w.Code != tt.wantStatus {
+				t.Errorf("status = %d, se esperaba %d", w.Code, tt.wantStatus)
+			}
+		})
+	}
+}
+
+// renderFixture tiene un objeto anidado, un arreglo, un puntero nulo y una clave que no es un
+// nombre XML válido, para cubrir todas las formas que aplanan o envuelven los codificadores.
+type renderFixture struct {
+	ID      int            `json:"id"`
+	Name    string         `json:"name"`
+	Score   renderScore    `json:"score"`
+	Tags    []string       `json:"tags"`
+	Referee *string        `json:"referee"`
+	Extra   map[string]int `json:"extra,omitempty"`
+}
+
+type renderScore struct {
+	Home int  `json:"home"`
+	Away int  `json:"away"`
+	Live bool `json:"live"`
+}
+
+func TestEncoders(t *testing.T) {
+	fixtures := []renderFixture{
+		{ID: 1, Name: "Sevilla, Betis", Score: renderScore{Home: 2, Away: 1}, Tags: []string{"derbi", "final"}},
+		{ID: 2, Name: "Cádiz", Score: renderScore{Live: true}, Extra: map[string]int{"1st": 3}},
+	}
+
+	tests := []struct {
+		name string
+		mime string
+		data any
+		want string
+	}{
+		{
+			name: "CSV aplana los objetos anidados y escribe los arreglos como JSON",
+			mime: mimeCSV,
+			data: fixtures,
+			want: "id,name,score.home,score.away,score.live,tags,referee,extra.1st\n" +
+				"1,\"Sevilla, Betis\",2,1,false,\"[\"\"derbi\"\",\"\"final\"\"]\",,\n" +
+				"2,Cádiz,0,0,true,,,3\n",
+		},
+		{
+			name: "CSV de un objeto escribe una sola fila",
+			mime: mimeCSV,
+			data: renderScore{Home: 1},
+			want: "home,away,live\n1,0,false\n",
+		},
+		{
+			name: "CSV de un valor escalar usa la columna value",
+			mime: mimeCSV,
+			data: []int{4, 5},
+			want: "value\n4\n5\n",
+		},
+		{
+			name: "CSV de null solo tiene encabezado vacío",
+			mime: mimeCSV,
+			data: nil,
+			want: "\n",
+		},
+		{
+			name: "NDJSON escribe una línea por elemento conservando el orden de los campos",
+			mime: mimeNDJSON,
+			data: fixtures,
+			want: `{"id":1,"name":"Sevilla, Betis","score":{"home":2,"away":1,"live":false},"tags":["derbi","final"],"referee":null}` + "\n" +
+				`{"id":2,"name":"Cádiz","score":{"home":0,"away":0,"live":true},"tags":null,"referee":null,"extra":{"1st":3}}` + "\n",
+		},
+		{
+			name: "NDJSON de un objeto escribe una línea",
+			mime: mimeNDJSON,
+			data: renderScore{Away: 3},
+			want: `{"home":0,"away":3,"live":false}` + "\n",
+		},
+		{
+			name: "XML envuelve los arreglos y marca los nulos",
+			mime: mimeXML,
+			data: fixtures[1:],
+			want: xml.Header + `<items><item><id>2</id><name>Cádiz</name><score><home>0</home><away>0</away><live>true</live></score>` +
+				`<tags nil="true"></tags><referee nil="true"></referee><extra><entry key="1st">3</entry></extra></item></items>`,
+		},
+		{
+			name: "XML escapa el texto",
+			mime: mimeXML,
+			data: map[string]string{"name": "<Betis & Sevilla>"},
+			want: xml.Header + `<response><name>&lt;Betis &amp; Sevilla&gt;</name></response>`,
+		},
+		{
+			name: "YAML conserva el orden de los campos y los tipos",
+			mime: mimeYAML,
+			data: fixtures[:1],
+			want: "- id: 1\n" +
+				"  name: Sevilla, Betis\n" +
+				"  score:\n" +
+				"    home: 2\n" +
+				"    away: 1\n" +
+				"    live: false\n" +
+				"  tags:\n" +
+				"    - derbi\n" +
+				"    - final\n" +
+				"  referee: null\n",
+		},
+		{
+			name: "YAML cita las cadenas que parecen otro tipo",
+			mime: mimeYAML,
+			data: map[string]any{"minute": "90", "ratio": 1.5, "live": "true"},
+			want: "live: \"true\"\nminute: \"90\"\nratio: 1.5\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tree, err := toNode(tt.data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var buf bytes.Buffer
+			if err := encoders[tt.mime](&buf, tree); err != nil {
+				t.Fatal(err)
+			}
+			if got := buf.String(); got != tt.want {
+				t.Errorf("salida =\n%s\nse esperaba\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRenderSetsContentType(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name      string
+		query     string
+		wantType  string
+		wantStart string
+	}{
+		{name: "JSON", wantType: "application/json; charset=utf-8", wantStart: `{"home":1`},
+		{name: "CSV", query: "?format=csv", wantType: "text/csv; charset=utf-8", wantStart: "home,away,live"},
+		{name: "YAML", query: "?format=yaml", wantType: "application/x-yaml; charset=utf-8", wantStart: "home: 1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodGet, "/api/teams"+tt.query, nil)
+
+			render(c, http.StatusCreated, renderScore{Home: 1})
+			if w.Code != http.StatusCreated {
+				t.Errorf("status = %d, se esperaba %d", w.Code, http.StatusCreated)
+			}
+			if got := w.Header().Get("Content-Type"); got != tt.wantType {
+				t.Errorf("Content-Type = %q, se esperaba %q", got, tt.wantType)
+			}
+			if got := w.Header().Get("Vary"); got != "Accept" {
+				t.Errorf("Vary = %q, se esperaba Accept", got)
+			}
+			if !strings.HasPrefix(w.Body.String(), tt.wantStart) {
+				t.Errorf("cuerpo = %q, se esperaba que empiece con %q", w.Body.String(), tt.wantStart)
+			}
+		})
+	}
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"lab6/internal"
+)
+
+// Límites de resultados por tipo de recurso en /search.
+const (
+	defaultSearchLimit = 10
+	maxSearchLimit     = 50
+)
+
+// searchResponse es la respuesta de /search.
+type searchResponse struct {
+	Query   string                          `json:"query"`
+	Results map[string][]internal.SearchHit `json:"results"`
+}
+
+// search godoc
+// @Summary Búsqueda de texto completo
+// @Description Busca sin distinguir acentos ni mayúsculas en equipos, partidos y estadios. Cada término se trata como prefijo.
+// @Description Los resultados se agrupan por tipo de recurso y se ordenan por relevancia: coincidencia de términos más similitud con el texto buscado.
+// @Description Cada estadio se informa con el ID del equipo que juega de local en él. La API no tiene jugadores, por lo que no se incluyen en la búsqueda.
+// @Tags Search
+// @Produce json,text/csv,xml,application/x-yaml,application/x-ndjson
+// @Param q query string true "Texto a buscar"
+// @Param limit query int false "Máximo de resultados por tipo de recurso (1-50)"
+// @Param format query string false "Formato de la respuesta (alternativa a Accept)" Enums(json, csv, xml, yaml, ndjson)
+// @Success 200 {object} searchResponse
+// @Failure 400 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /search [get]
+func search(c *gin.Context) {
+	query := strings.TrimSpace(c.Query("q"))
+	if internal.BuildSearchQuery(query) == "" {
+		respondError(c, http.StatusBadRequest, "SEARCH_QUERY_REQUIRED")
+		return
+	}
+
+	limit := defaultSearchLimit
+	if value := c.Query("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 || parsed > maxSearchLimit {
+			respondError(c, http.StatusBadRequest, "INVALID_LIMIT", maxSearchLimit)
+			return
+		}
+		limit = parsed
+	}
+
+	results, err := internal.Search(query, limit)
+	if err != nil {
+		respondInternalError(c, err)
+		return
+	}
+	render(c, http.StatusOK, searchResponse{Query: query, Results: results})
+}
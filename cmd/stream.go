@@ -0,0 +1,193 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"lab6/internal"
+)
+
+const (
+	// streamHeartbeat es el intervalo de los comentarios que mantienen abierta la conexión SSE.
+	// En cada latido también se consultan eventos nuevos, por si se perdió algún aviso.
+	streamHeartbeat = 15 * time.Second
+
+	// streamRetry es el tiempo en milisegundos que el navegador espera antes de reconectarse.
+	streamRetry = 3000
+
+	// streamBatchSize limita la cantidad de eventos leídos por consulta al ponerse al día.
+	streamBatchSize = 500
+)
+
+// streamEvent son los datos de cada evento SSE: el evento del log y el partido resultante.
+type streamEvent struct {
+	ID         int64                 `json:"id"`
+	MatchID    int                   `json:"matchId"`
+	Sequence   int                   `json:"sequence"`
+	Type       string                `json:"type" example:"GoalScored"`
+	Data       json.RawMessage       `json:"data" swaggertype:"object"`
+	OccurredAt time.Time             `json:"occurredAt"`
+	Clock      *internal.ClockMinute `json:"clock,omitempty"`
+	Deleted    bool                  `json:"deleted"`
+	Match      matchV2               `json:"match"`
+}
+
+// toStreamEvent convierte un cambio del log a los datos del evento SSE.
+func toStreamEvent(change internal.MatchChange) streamEvent {
+	return streamEvent{
+		ID:         change.Event.ID,
+		MatchID:    change.Event.MatchID,
+		Sequence:   change.Event.Sequence,
+		Type:       change.Event.Type,
+		Data:       change.Event.Data,
+		OccurredAt: change.Event.OccurredAt,
+		Clock:      change.Event.Clock,
+		Deleted:    change.Deleted,
+		Match:      toMatchV2(change.Match),
+	}
+}
+
+// lastEventID retorna el ID desde el que se reanuda el stream: el encabezado Last-Event-ID que
+// envía el navegador al reconectarse o, en la primera conexión, el parámetro lastEventId.
+func lastEventID(c *gin.Context) (int64, bool, error) {
+	value := c.GetHeader("Last-Event-ID")
+	if value == "" {
+		value = c.Query("lastEventId")
+	}
+	if value == "" {
+		return 0, false, nil
+	}
+	id, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || id < 0 {
+		return 0, false, newAPIError("INVALID_LAST_EVENT_ID")
+	}
+	return id, true, nil
+}
+
+// streamMatchEvents envía por SSE los eventos de matchID (o de todos los partidos si es 0)
+// posteriores a Last-Event-ID, y luego cada evento nuevo hasta que el cliente se desconecta.
+func streamMatchEvents(c *gin.Context, matchID int) {
+	after, resume, err := lastEventID(c)
+	if err != nil {
+		code, args := errorCode(err)
+		respondError(c, http.StatusBadRequest, code, args...)
+		return
+	}
+	if !resume {
+		// Sin punto de reanudación solo se envían los eventos posteriores a la conexión
+		if after, err = internal.LatestEventID(matchID); err != nil {
+			respondInternalError(c, err)
+			return
+		}
+	}
+
+	// Se suscribe antes de ponerse al día para no perder avisos entre la consulta y la espera
+	changes, unsubscribe := internal.SubscribeChanges()
+	defer unsubscribe()
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+	fmt.Fprintf(c.Writer, "retry: %d\n\n", streamRetry)
+	c.Writer.Flush()
+
+	heartbeat := time.NewTicker(streamHeartbeat)
+	defer heartbeat.Stop()
+	for {
+		for {
+			batch, err := internal.GetMatchChanges(after, matchID, streamBatchSize)
+			if err != nil {
+				fmt.Fprintf(c.Writer, "event: error\ndata: %s\n\n", translate(c, "INTERNAL_ERROR"))
+				c.Writer.Flush()
+				return
+			}
+			for _, change := range batch {
+				if err := writeStreamEvent(c.Writer, toStreamEvent(change)); err != nil {
+					return
+				}
+				after = change.Event.ID
+			}
+			c.Writer.Flush()
+			if len(batch) < streamBatchSize {
+				break
+			}
+		}
+
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case <-changes:
+		case <-heartbeat.C:
+			if _, err := io.WriteString(c.Writer, ": ping\n\n"); err != nil {
+				return
+			}
+			c.Writer.Flush()
+		}
+	}
+}
+
+// writeStreamEvent escribe un evento SSE con su ID, su tipo y sus datos en una línea JSON.
+func writeStreamEvent(w io.Writer, event streamEvent) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", event.ID, event.Type, data)
+	return err
+}
+
+// streamMatches godoc
+// @Summary Stream de eventos de todos los partidos
+// @Description Server-Sent Events con cada cambio de cualquier partido (goles, tarjetas, tiempo extra, reprogramaciones,
+// @Description finalización, eliminación y restauración). El nombre de cada evento SSE es el tipo del evento del log
+// @Description (GoalScored, CardShown, ...) y sus datos incluyen el partido resultante. El ID de cada evento permite
+// @Description reanudar con Last-Event-ID (o ?lastEventId= en la primera conexión) sin perder cambios.
+// @Tags Stream
+// @Produce text/event-stream
+// @Param Last-Event-ID header string false "ID del último evento recibido"
+// @Param lastEventId query int false "ID del último evento recibido, si no se puede enviar el encabezado"
+// @Success 200 {object} streamEvent "Un evento por cambio"
+// @Failure 400 {object} map[string]string
+// @Router /matches/stream [get]
+func streamMatches(c *gin.Context) {
+	streamMatchEvents(c, 0)
+}
+
+// streamMatch godoc
+// @Summary Stream de eventos de un partido
+// @Description Server-Sent Events con cada cambio del partido indicado, con el mismo formato y reanudación que /matches/stream.
+// @Tags Stream
+// @Produce text/event-stream
+// @Param id path int true "ID del partido"
+// @Param Last-Event-ID header string false "ID del último evento recibido"
+// @Param lastEventId query int false "ID del último evento recibido, si no se puede enviar el encabezado"
+// @Success 200 {object} streamEvent "Un evento por cambio"
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /matches/{id}/stream [get]
+func streamMatch(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		respondError(c, http.StatusBadRequest, "INVALID_ID")
+		return
+	}
+	_, err = internal.GetMatchByID(id)
+	if errors.Is(err, sql.ErrNoRows) {
+		respondError(c, http.StatusNotFound, "MATCH_NOT_FOUND")
+		return
+	}
+	if err != nil {
+		respondInternalError(c, err)
+		return
+	}
+	streamMatchEvents(c, id)
+}
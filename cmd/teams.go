@@ -0,0 +1,231 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"lab6/internal"
+)
+
+// getTeams godoc
+// @Summary Obtiene todos los equipos
+// @Description Retorna los equipos canónicos junto con sus alias.
+// @Tags Teams
+// @Produce json,text/csv,xml,application/x-yaml,application/x-ndjson
+// @Param format query string false "Formato de la respuesta (alternativa a Accept)" Enums(json, csv, xml, yaml, ndjson)
+// @Success 200 {array} internal.Team
+// @Failure 500 {object} map[string]string
+// @Router /teams [get]
+func getTeams(c *gin.Context) {
+	teams, err := internal.GetTeams()
+	if err != nil {
+		respondInternalError(c, err)
+		return
+	}
+	render(c, http.StatusOK, teams)
+}
+
+// getTeam godoc
+// @Summary Obtiene un equipo por ID
+// @Description Retorna el equipo canónico y sus alias.
+// @Tags Teams
+// @Produce json,text/csv,xml,application/x-yaml,application/x-ndjson
+// @Param id path int true "ID del equipo"
+// @Param format query string false "Formato de la respuesta (alternativa a Accept)" Enums(json, csv, xml, yaml, ndjson)
+// @Success 200 {object} internal.Team
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /teams/{id} [get]
+func getTeam(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		respondError(c, http.StatusBadRequest, "INVALID_ID")
+		return
+	}
+
+	team, err := internal.GetTeamByID(id)
+	if errors.Is(err, sql.ErrNoRows) {
+		respondError(c, http.StatusNotFound, "TEAM_NOT_FOUND")
+		return
+	}
+	if err != nil {
+		respondInternalError(c, err)
+		return
+	}
+	render(c, http.StatusOK, team)
+}
+
+// resolveTeamName godoc
+// @Summary Resuelve un nombre de equipo
+// @Description Retorna el nombre canónico al que corresponde un nombre o alias. Si no se reconoce responde 422 con sugerencias.
+// @Tags Teams
+// @Produce json,text/csv,xml,application/x-yaml,application/x-ndjson
+// @Param name query string true "Nombre o alias del equipo"
+// @Param format query string false "Formato de la respuesta (alternativa a Accept)" Enums(json, csv, xml, yaml, ndjson)
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Failure 422 {object} map[string]interface{}
+// @Router /teams/resolve [get]
+func resolveTeamName(c *gin.Context) {
+	name := strings.TrimSpace(c.Query("name"))
+	if name == "" {
+		respondError(c, http.StatusBadRequest, "NAME_REQUIRED")
+		return
+	}
+
+	canonical, err := internal.ResolveTeam(name)
+	if err != nil {
+		respondWriteError(c, 0, err)
+		return
+	}
+	render(c, http.StatusOK, gin.H{"name": name, "canonical": canonical})
+}
+
+// teamInput es el body de POST /admin/teams.
+type teamInput struct {
+	Name    string   `json:"name" binding:"required" example:"Athletic Club"`
+	Stadium string   `json:"stadium" example:"San Mamés"`
+	Aliases []string `json:"aliases" example:"Athletic Bilbao,Athletic"`
+}
+
+// teamAliasInput es el body de POST /admin/teams/{id}/aliases.
+type teamAliasInput struct {
+	Alias string `json:"alias" binding:"required" example:"Athletic Bilbao"`
+}
+
+// teamMergeInput es el body de POST /admin/teams/merge.
+type teamMergeInput struct {
+	Target     string   `json:"target" binding:"required" example:"Athletic Club"`
+	Duplicates []string `json:"duplicates" binding:"required,min=1" example:"Athletic Bilbao"`
+}
+
+// createTeam godoc
+// @Summary Registra un equipo
+// @Description Registra un equipo canónico y, opcionalmente, su estadio y sus alias.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Security AdminToken
+// @Param team body teamInput true "Nombre canónico, estadio y alias"
+// @Success 201 {object} internal.Team
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /admin/teams [post]
+func createTeam(c *gin.Context) {
+	var requestBody teamInput
+	if err := c.ShouldBindJSON(&requestBody); err != nil || strings.TrimSpace(requestBody.Name) == "" {
+		respondError(c, http.StatusBadRequest, "INVALID_BODY")
+		return
+	}
+
+	id, err := internal.CreateTeam(strings.TrimSpace(requestBody.Name), strings.TrimSpace(requestBody.Stadium), requestBody.Aliases, auditInfo(c))
+	if errors.Is(err, internal.ErrTeamExists) || errors.Is(err, internal.ErrAliasTaken) {
+		code, _ := errorCode(err)
+		respondError(c, http.StatusConflict, code)
+		return
+	}
+	if err != nil {
+		respondInternalError(c, err)
+		return
+	}
+
+	team, err := internal.GetTeamByID(id)
+	if err != nil {
+		respondInternalError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, team)
+}
+
+// addTeamAlias godoc
+// @Summary Agrega un alias a un equipo
+// @Description Registra un alias para el equipo indicado. Falla si el alias ya pertenece a otro equipo.
+// @Tags Admin
+// @Accept json
+// @Produce json,text/csv,xml,application/x-yaml,application/x-ndjson
+// @Security AdminToken
+// @Param id path int true "ID del equipo"
+// @Param alias body teamAliasInput true "Alias"
+// @Param format query string false "Formato de la respuesta (alternativa a Accept)" Enums(json, csv, xml, yaml, ndjson)
+// @Success 200 {object} internal.Team
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /admin/teams/{id}/aliases [post]
+func addTeamAlias(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		respondError(c, http.StatusBadRequest, "INVALID_ID")
+		return
+	}
+	if _, ok := responseFormat(c); !ok {
+		return
+	}
+
+	var requestBody teamAliasInput
+	if err := c.ShouldBindJSON(&requestBody); err != nil || strings.TrimSpace(requestBody.Alias) == "" {
+		respondError(c, http.StatusBadRequest, "INVALID_BODY")
+		return
+	}
+
+	err = internal.AddTeamAlias(id, strings.TrimSpace(requestBody.Alias), auditInfo(c))
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		respondError(c, http.StatusNotFound, "TEAM_NOT_FOUND")
+		return
+	case errors.Is(err, internal.ErrAliasTaken):
+		respondError(c, http.StatusConflict, "ALIAS_TAKEN")
+		return
+	case err != nil:
+		respondInternalError(c, err)
+		return
+	}
+
+	team, err := internal.GetTeamByID(id)
+	if err != nil {
+		respondInternalError(c, err)
+		return
+	}
+	render(c, http.StatusOK, team)
+}
+
+// mergeTeams godoc
+// @Summary Unifica nombres de equipos duplicados
+// @Description Registra los nombres duplicados como alias del equipo destino (creándolo si no existe),
+// @Description absorbe los equipos registrados con esos nombres y reescribe los partidos que los usan.
+// @Tags Admin
+// @Accept json
+// @Produce json,text/csv,xml,application/x-yaml,application/x-ndjson
+// @Security AdminToken
+// @Param merge body teamMergeInput true "Destino y duplicados"
+// @Param format query string false "Formato de la respuesta (alternativa a Accept)" Enums(json, csv, xml, yaml, ndjson)
+// @Success 200 {object} internal.TeamMergeResult
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /admin/teams/merge [post]
+func mergeTeams(c *gin.Context) {
+	var requestBody teamMergeInput
+	if err := c.ShouldBindJSON(&requestBody); err != nil || strings.TrimSpace(requestBody.Target) == "" || len(requestBody.Duplicates) == 0 {
+		respondError(c, http.StatusBadRequest, "INVALID_BODY")
+		return
+	}
+	if _, ok := responseFormat(c); !ok {
+		return
+	}
+
+	result, err := internal.MergeTeamNames(strings.TrimSpace(requestBody.Target), requestBody.Duplicates, auditInfo(c))
+	if err != nil {
+		respondInternalError(c, err)
+		return
+	}
+	render(c, http.StatusOK, result)
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"lab6/internal"
+)
+
+// defaultTrashRetention es el tiempo que un partido permanece en la papelera si no se configura TRASH_RETENTION.
+const defaultTrashRetention = 30 * 24 * time.Hour
+
+// trashPurgeResponse es la respuesta de la purga de la papelera.
+type trashPurgeResponse struct {
+	Retention string `json:"retention" example:"720h0m0s"`
+	Purged    int64  `json:"purged"`
+}
+
+// trashRetention lee el tiempo de retención de la papelera desde la variable de entorno TRASH_RETENTION.
+func trashRetention() time.Duration {
+	value := os.Getenv("TRASH_RETENTION")
+	if value == "" {
+		return defaultTrashRetention
+	}
+	retention, err := time.ParseDuration(value)
+	if err != nil || retention < 0 {
+		log.Printf("TRASH_RETENTION inválido (%q), se usa %v", value, defaultTrashRetention)
+		return defaultTrashRetention
+	}
+	return retention
+}
+
+// getTrash godoc
+// @Summary Obtiene la papelera
+// @Description Retorna los partidos eliminados que todavía pueden restaurarse, del más reciente al más antiguo.
+// @Tags Trash
+// @Produce json,text/csv,xml,application/x-yaml,application/x-ndjson
+// @Param format query string false "Formato de la respuesta (alternativa a Accept)" Enums(json, csv, xml, yaml, ndjson)
+// @Success 200 {array} internal.DeletedMatch
+// @Failure 500 {object} map[string]string
+// @Router /trash [get]
+func getTrash(c *gin.Context) {
+	matches, err := internal.GetTrash()
+	if err != nil {
+		respondInternalError(c, err)
+		return
+	}
+	render(c, http.StatusOK, matches)
+}
+
+// restoreMatch godoc
+// @Summary Restaura un partido de la papelera
+// @Description Saca el partido de la papelera si su versión coincide con If-Match y retorna su representación actual.
+// @Tags Trash
+// @Produce json
+// @Param id path int true "ID del partido"
+// @Param If-Match header string true "ETag del partido según la papelera"
+// @Param Idempotency-Key header string false "Clave para reintentar la solicitud sin repetir la escritura"
+// @Success 200 {object} internal.Match
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string "El partido no está en la papelera"
+// @Failure 412 {object} map[string]string
+// @Failure 428 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /matches/{id}/restore [post]
+func restoreMatch(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		respondError(c, http.StatusBadRequest, "INVALID_ID")
+		return
+	}
+
+	version, ok := requireIfMatch(c)
+	if !ok {
+		return
+	}
+
+	if err := internal.RestoreMatch(id, version, auditInfo(c)); err != nil {
+		respondWriteError(c, id, err)
+		return
+	}
+
+	match, err := internal.GetMatchByID(id)
+	if err != nil {
+		respondInternalError(c, err)
+		return
+	}
+	c.Header("ETag", matchETag(match.Version))
+	c.JSON(http.StatusOK, match)
+}
+
+// purgeTrash godoc
+// @Summary Purga la papelera
+// @Description Elimina definitivamente los partidos que llevan en la papelera más que el período de retención.
+// @Description Por defecto se usa TRASH_RETENTION (720h si no está definido).
+// @Tags Admin
+// @Produce json
+// @Security AdminToken
+// @Param olderThan query string false "Período de retención en formato de duración de Go (por ejemplo 720h)"
+// @Success 200 {object} trashPurgeResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /admin/trash [delete]
+func purgeTrash(c *gin.Context) {
+	retention := trashRetention()
+	if value := c.Query("olderThan"); value != "" {
+		parsed, err := time.ParseDuration(value)
+		if err != nil || parsed < 0 {
+			respondError(c, http.StatusBadRequest, "INVALID_RETENTION")
+			return
+		}
+		retention = parsed
+	}
+
+	purged, err := internal.PurgeTrash(retention, auditInfo(c))
+	if err != nil {
+		respondInternalError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, trashPurgeResponse{Retention: retention.String(), Purged: purged})
+}
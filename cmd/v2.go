@@ -0,0 +1,249 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"lab6/internal"
+)
+
+// Fechas de retiro de la API v1, anunciadas en los encabezados Deprecation y Sunset.
+var (
+	v1DeprecatedAt = time.Date(2026, time.October, 19, 0, 0, 0, 0, time.UTC)
+	v1SunsetAt     = time.Date(2027, time.June, 30, 0, 0, 0, 0, time.UTC)
+)
+
+// deprecationMiddleware agrega a las respuestas de v1 los encabezados que anuncian su retiro.
+func deprecationMiddleware() gin.HandlerFunc {
+	deprecation := "@" + strconv.FormatInt(v1DeprecatedAt.Unix(), 10)
+	sunset := v1SunsetAt.Format(http.TimeFormat)
+	return func(c *gin.Context) {
+		c.Header("Deprecation", deprecation)
+		c.Header("Sunset", sunset)
+		c.Header("Link", `</api/v2/matches>; rel="successor-version"`)
+		c.Next()
+	}
+}
+
+// bindMatchV2 lee y valida el body v2 de un partido.
+// Si los datos no son válidos responde al cliente y retorna false.
+func bindMatchV2(c *gin.Context) (internal.Match, bool) {
+	var input matchInputV2
+	if err := c.ShouldBindJSON(&input); err != nil || input.HomeTeam.Name == "" || input.AwayTeam.Name == "" {
+		respondError(c, http.StatusBadRequest, "INVALID_BODY")
+		return internal.Match{}, false
+	}
+	match, err := input.toInternalMatch()
+	if err != nil {
+		respondError(c, http.StatusBadRequest, "INVALID_KICKOFF")
+		return internal.Match{}, false
+	}
+	return match, true
+}
+
+// respondMatchV2 consulta el partido y lo retorna en formato v2 junto con su ETag.
+func respondMatchV2(c *gin.Context, status, id int) {
+	match, err := internal.GetMatchByID(id)
+	if errors.Is(err, sql.ErrNoRows) {
+		respondError(c, http.StatusNotFound, "MATCH_NOT_FOUND")
+		return
+	}
+	if err != nil {
+		respondInternalError(c, err)
+		return
+	}
+	c.Header("ETag", matchETag(match.Version))
+	render(c, status, toMatchV2(match))
+}
+
+// getMatchesV2 godoc
+// @Summary Obtiene todos los partidos (v2)
+// @Description Retorna todos los partidos con equipos anidados, marcador, tarjetas y kickoff RFC3339.
+// @Tags Matches v2
+// @Produce json,text/csv,xml,application/x-yaml,application/x-ndjson
+// @Param format query string false "Formato de la respuesta (alternativa a Accept)" Enums(json, csv, xml, yaml, ndjson)
+// @Success 200 {array} matchV2
+// @Failure 500 {object} map[string]string
+// @Router /v2/matches [get]
+func getMatchesV2(c *gin.Context) {
+	matches, err := internal.GetMatches()
+	if err != nil {
+		respondInternalError(c, err)
+		return
+	}
+	render(c, http.StatusOK, toMatchesV2(matches))
+}
+
+// getMatchV2 godoc
+// @Summary Obtiene un partido por ID (v2)
+// @Description Retorna el partido cuyo ID se especifica en la ruta junto con su ETag.
+// @Description Con ?asOf= retorna el partido reconstruido a partir de sus eventos hasta ese instante, sin ETag.
+// @Tags Matches v2
+// @Produce json,text/csv,xml,application/x-yaml,application/x-ndjson
+// @Param id path int true "ID del partido"
+// @Param asOf query string false "Instante (RFC3339) en el que se consulta el partido"
+// @Param format query string false "Formato de la respuesta (alternativa a Accept)" Enums(json, csv, xml, yaml, ndjson)
+// @Success 200 {object} matchV2
+// @Header 200 {string} ETag "Versión actual del partido"
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /v2/matches/{id} [get]
+func getMatchV2(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		respondError(c, http.StatusBadRequest, "INVALID_ID")
+		return
+	}
+
+	match, historical, ok := lookupMatch(c, id)
+	if !ok {
+		return
+	}
+	if !historical {
+		c.Header("ETag", matchETag(match.Version))
+	}
+	render(c, http.StatusOK, toMatchV2(match))
+}
+
+// createMatchV2 godoc
+// @Summary Crea un nuevo partido (v2)
+// @Description Crea un partido a partir de los equipos y el kickoff RFC3339, y retorna el partido creado.
+// @Tags Matches v2
+// @Accept json
+// @Produce json
+// @Param Idempotency-Key header string false "Clave para reintentar la solicitud sin repetir la escritura"
+// @Param match body matchInputV2 true "Datos del partido"
+// @Success 201 {object} matchV2
+// @Failure 400 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /v2/matches [post]
+func createMatchV2(c *gin.Context) {
+	match, ok := bindMatchV2(c)
+	if !ok {
+		return
+	}
+
+	newID, err := internal.CreateMatch(match, auditInfo(c))
+	if err != nil {
+		respondWriteErrorAs(c, 0, err, presentMatchV2)
+		return
+	}
+
+	c.Header("Location", "/api/v2/matches/"+strconv.Itoa(newID))
+	respondMatchV2(c, http.StatusCreated, newID)
+}
+
+// updateMatchV2 godoc
+// @Summary Actualiza un partido existente (v2)
+// @Description Reemplaza equipos y kickoff del partido y retorna su nueva representación.
+// @Tags Matches v2
+// @Accept json
+// @Produce json
+// @Param id path int true "ID del partido"
+// @Param If-Match header string true "ETag obtenido al consultar el partido"
+// @Param Idempotency-Key header string false "Clave para reintentar la solicitud sin repetir la escritura"
+// @Param match body matchInputV2 true "Datos del partido"
+// @Success 200 {object} matchV2
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 412 {object} matchV2 "Versión desactualizada, se retorna la actual"
+// @Failure 428 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /v2/matches/{id} [put]
+func updateMatchV2(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		respondError(c, http.StatusBadRequest, "INVALID_ID")
+		return
+	}
+	version, ok := requireIfMatch(c)
+	if !ok {
+		return
+	}
+	match, ok := bindMatchV2(c)
+	if !ok {
+		return
+	}
+
+	match.ID, match.Version = id, version
+	if err := internal.UpdateMatch(match, auditInfo(c)); err != nil {
+		respondWriteErrorAs(c, id, err, presentMatchV2)
+		return
+	}
+	respondMatchV2(c, http.StatusOK, id)
+}
+
+// deleteMatchV2 godoc
+// @Summary Elimina un partido (v2)
+// @Description Envía a la papelera el partido indicado si su versión coincide con If-Match.
+// @Tags Matches v2
+// @Param id path int true "ID del partido"
+// @Param If-Match header string true "ETag obtenido al consultar el partido"
+// @Param Idempotency-Key header string false "Clave para reintentar la solicitud sin repetir la escritura"
+// @Success 204
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 412 {object} matchV2 "Versión desactualizada, se retorna la actual"
+// @Failure 428 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /v2/matches/{id} [delete]
+func deleteMatchV2(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		respondError(c, http.StatusBadRequest, "INVALID_ID")
+		return
+	}
+	version, ok := requireIfMatch(c)
+	if !ok {
+		return
+	}
+
+	if err := internal.DeleteMatch(id, version, auditInfo(c)); err != nil {
+		respondWriteErrorAs(c, id, err, presentMatchV2)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// updateStatV2 godoc
+// @Description Registra el evento GoalScored, CardShown o ExtraTimeStarted correspondiente y retorna la nueva representación del partido.
+// @Description Incrementa goles o tarjetas, o activa el tiempo extra, y retorna la nueva representación del partido.
+// @Tags Matches v2
+// @Produce json
+// @Param id path int true "ID del partido"
+// @Param stat path string true "Estadística" Enums(goals, yellowcards, redcards, extratime)
+// @Param If-Match header string true "ETag obtenido al consultar el partido"
+// @Param Idempotency-Key header string false "Clave para reintentar la solicitud sin repetir la escritura"
+// @Success 200 {object} matchV2
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string "El partido ya finalizó"
+// @Failure 412 {object} matchV2 "Versión desactualizada, se retorna la actual"
+// @Failure 428 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /v2/matches/{id}/stats/{stat} [patch]
+func updateStatV2(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		respondError(c, http.StatusBadRequest, "INVALID_ID")
+		return
+	}
+	version, ok := requireIfMatch(c)
+	if !ok {
+		return
+	}
+
+	if err := internal.IncrementStat(c.Param("stat"), id, version, auditInfo(c)); err != nil {
+		if errors.Is(err, internal.ErrUnknownStat) {
+			respondError(c, http.StatusBadRequest, "INVALID_STAT", c.Param("stat"))
+			return
+		}
+		respondWriteErrorAs(c, id, err, presentMatchV2)
+		return
+	}
+	respondMatchV2(c, http.StatusOK, id)
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"time"
+
+	"lab6/internal"
+)
+
+// teamV2 representa un equipo en la API v2.
+type teamV2 struct {
+	Name string `json:"name" binding:"required" example:"Barcelona"`
+}
+
+// scoreV2 agrupa el marcador de un partido en la API v2.
+type scoreV2 struct {
+	Goals int `json:"goals"`
+}
+
+// cardsV2 agrupa las tarjetas mostradas en un partido en la API v2.
+type cardsV2 struct {
+	Yellow int `json:"yellow"`
+	Red    int `json:"red"`
+}
+
+// matchV2 es la representación de un partido en la API v2.
+type matchV2 struct {
+	ID        int     `json:"id"`
+	Version   int     `json:"version"`
+	HomeTeam  teamV2  `json:"homeTeam"`
+	AwayTeam  teamV2  `json:"awayTeam"`
+	Kickoff   string  `json:"kickoff" example:"2025-04-01T21:00:00Z"`
+	Score     scoreV2 `json:"score"`
+	Cards     cardsV2 `json:"cards"`
+	ExtraTime bool    `json:"extraTime"`
+	Finished  bool    `json:"finished"`
+}
+
+// matchInputV2 es el body aceptado por POST y PUT en la API v2.
+type matchInputV2 struct {
+	HomeTeam teamV2 `json:"homeTeam" binding:"required"`
+	AwayTeam teamV2 `json:"awayTeam" binding:"required"`
+	Kickoff  string `json:"kickoff" example:"2025-04-01T21:00:00Z"`
+}
+
+// toMatchV2 convierte el modelo interno a la representación v2.
+func toMatchV2(m internal.Match) matchV2 {
+	return matchV2{
+		ID:        m.ID,
+		Version:   m.Version,
+		HomeTeam:  teamV2{Name: m.HomeTeam},
+		AwayTeam:  teamV2{Name: m.AwayTeam},
+		Kickoff:   m.MatchDate.Format(time.RFC3339),
+		Score:     scoreV2{Goals: m.Goals},
+		Cards:     cardsV2{Yellow: m.YellowCards, Red: m.RedCards},
+		ExtraTime: m.ExtraTime,
+		Finished:  m.Finished,
+	}
+}
+
+// toMatchesV2 convierte una lista de partidos a la representación v2.
+func toMatchesV2(matches []internal.Match) []matchV2 {
+	result := make([]matchV2, 0, len(matches))
+	for _, m := range matches {
+		result = append(result, toMatchV2(m))
+	}
+	return result
+}
+
+// presentMatchV2 adapta toMatchV2 a la firma que espera respondWriteErrorAs.
+func presentMatchV2(m internal.Match) any {
+	return toMatchV2(m)
+}
+
+// toInternalMatch convierte el body v2 al modelo interno validando el kickoff RFC3339.
+func (in matchInputV2) toInternalMatch() (internal.Match, error) {
+	kickoff, err := time.Parse(time.RFC3339, in.Kickoff)
+	if err != nil {
+		return internal.Match{}, err
+	}
+	return internal.Match{
+		HomeTeam:  in.HomeTeam.Name,
+		AwayTeam:  in.AwayTeam.Name,
+		MatchDate: kickoff,
+	}, nil
+}
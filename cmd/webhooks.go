@@ -0,0 +1,521 @@
+package main
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"lab6/internal"
+)
+
+const (
+	// webhookTimeout limita la duración de cada intento de entrega.
+	webhookTimeout = 10 * time.Second
+
+	// webhookMaxAttempts es la cantidad de intentos tras la cual una entrega pasa a la lista de muertas.
+	webhookMaxAttempts = 8
+
+	// defaultWebhookRetryBase es la espera antes del primer reintento si no se configura WEBHOOK_RETRY_BASE.
+	// Cada reintento siguiente espera el doble, hasta webhookMaxBackoff.
+	defaultWebhookRetryBase = 30 * time.Second
+
+	// webhookMaxBackoff limita la espera entre reintentos.
+	webhookMaxBackoff = 6 * time.Hour
+
+	// webhookPollInterval es cada cuánto se buscan entregas cuyo reintento venció.
+	webhookPollInterval = 2 * time.Second
+
+	// webhookBatchSize es la cantidad de eventos o entregas que se procesan por vuelta.
+	webhookBatchSize = 50
+
+	// webhookResponseLimit es la cantidad de bytes de la respuesta que se guardan en el registro.
+	webhookResponseLimit = 1 << 10
+
+	// webhookPingEvent es el tipo de las entregas de prueba.
+	webhookPingEvent = "Ping"
+
+	// defaultWebhookDeliveriesLimit y maxWebhookDeliveriesLimit acotan el listado de entregas.
+	defaultWebhookDeliveriesLimit = 50
+	maxWebhookDeliveriesLimit     = 500
+)
+
+// webhookEventTypes son los tipos de evento a los que puede suscribirse un webhook.
+var webhookEventTypes = []string{
+	internal.EventMatchScheduled, internal.EventGoalScored, internal.EventCardShown,
+	internal.EventExtraTimeStarted, internal.EventMatchFinished, internal.EventMatchDeleted,
+	internal.EventMatchRestored, internal.EventTeamsRenamed, internal.EventPeriodStarted,
+	internal.EventPeriodEnded, internal.EventStoppageAnnounced,
+}
+
+// webhookClient envía las entregas. No sigue redirecciones: el suscriptor debe registrar la URL final.
+var webhookClient = &http.Client{
+	Timeout: webhookTimeout,
+	CheckRedirect: func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	},
+}
+
+// webhookInput es el body aceptado para crear una suscripción.
+type webhookInput struct {
+	URL        string   `json:"url" example:"https://partner.example.com/hooks/laliga"`
+	EventTypes []string `json:"eventTypes" example:"GoalScored,MatchFinished"`
+	Secret     string   `json:"secret,omitempty"`
+}
+
+// webhookRetryBase lee la espera antes del primer reintento desde la variable de entorno WEBHOOK_RETRY_BASE.
+func webhookRetryBase() time.Duration {
+	value := os.Getenv("WEBHOOK_RETRY_BASE")
+	if value == "" {
+		return defaultWebhookRetryBase
+	}
+	base, err := time.ParseDuration(value)
+	if err != nil || base <= 0 {
+		log.Printf("WEBHOOK_RETRY_BASE inválido (%q), se usa %v", value, defaultWebhookRetryBase)
+		return defaultWebhookRetryBase
+	}
+	return base
+}
+
+// webhookBackoff retorna la espera antes del reintento que sigue al intento número attempt (desde 1).
+func webhookBackoff(base time.Duration, attempt int) time.Duration {
+	delay := base
+	for i := 1; i < attempt && delay < webhookMaxBackoff; i++ {
+		delay *= 2
+	}
+	return min(delay, webhookMaxBackoff)
+}
+
+// signWebhook calcula la firma HMAC-SHA256 de una entrega. Se firma "<timestamp>.<body>" para que
+// el receptor pueda rechazar entregas antiguas reenviadas por terceros.
+func signWebhook(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// sendWebhook realiza un intento de entrega y retorna su registro y si el receptor lo aceptó (2xx).
+func sendWebhook(job internal.WebhookJob) (internal.WebhookAttempt, bool) {
+	attempt := internal.WebhookAttempt{AttemptedAt: time.Now().UTC()}
+	timestamp := strconv.FormatInt(attempt.AttemptedAt.Unix(), 10)
+
+	req, err := http.NewRequest(http.MethodPost, job.URL, bytes.NewReader(job.Payload))
+	if err != nil {
+		attempt.Error = err.Error()
+		return attempt, false
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "LaLigaTracker-Webhooks/1")
+	req.Header.Set("X-Webhook-Id", strconv.FormatInt(job.DeliveryID, 10))
+	req.Header.Set("X-Webhook-Event", job.EventType)
+	req.Header.Set("X-Webhook-Timestamp", timestamp)
+	req.Header.Set("X-Webhook-Signature", signWebhook(job.Secret, timestamp, job.Payload))
+
+	resp, err := webhookClient.Do(req)
+	attempt.DurationMS = int(time.Since(attempt.AttemptedAt).Milliseconds())
+	if err != nil {
+		attempt.Error = err.Error()
+		return attempt, false
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, webhookResponseLimit))
+	attempt.StatusCode = &resp.StatusCode
+	attempt.ResponseBody = strings.ToValidUTF8(string(body), "")
+	return attempt, resp.StatusCode >= 200 && resp.StatusCode < 300
+}
+
+// deliverWebhook envía una entrega y registra el resultado, reprogramándola o pasándola a la lista
+// de muertas si falló.
+func deliverWebhook(job internal.WebhookJob, retryBase time.Duration) (internal.WebhookAttempt, bool, error) {
+	attempt, delivered := sendWebhook(job)
+	var retryAt *time.Time
+	if !delivered && job.Attempts+1 < webhookMaxAttempts && job.EventType != webhookPingEvent {
+		next := time.Now().Add(webhookBackoff(retryBase, job.Attempts+1))
+		retryAt = &next
+	}
+	return attempt, delivered, internal.RecordWebhookAttempt(job.DeliveryID, attempt, delivered, retryAt)
+}
+
+// webhookPayload construye el cuerpo de la entrega de un evento, con el mismo formato que /matches/stream.
+func webhookPayload(change internal.MatchChange) ([]byte, error) {
+	return json.Marshal(toStreamEvent(change))
+}
+
+// runWebhookDispatcher genera las entregas de los eventos nuevos y envía las pendientes.
+// Todas las réplicas pueden ejecutarlo: el cursor y las entregas se toman con bloqueos en la base.
+func runWebhookDispatcher() {
+	changes, unsubscribe := internal.SubscribeChanges()
+	defer unsubscribe()
+	retryBase := webhookRetryBase()
+
+	poll := time.NewTicker(webhookPollInterval)
+	defer poll.Stop()
+	for {
+		select {
+		case <-changes:
+		case <-poll.C:
+		}
+
+		for {
+			n, err := internal.EnqueueWebhookDeliveries(webhookBatchSize, webhookPayload)
+			if err != nil {
+				log.Printf("error al generar entregas de webhooks: %v", err)
+			}
+			if err != nil || n < webhookBatchSize {
+				break
+			}
+		}
+
+		jobs, err := internal.ClaimWebhookJobs(webhookBatchSize, 2*webhookTimeout)
+		if err != nil {
+			log.Printf("error al tomar entregas de webhooks: %v", err)
+			continue
+		}
+		var wg sync.WaitGroup
+		for _, job := range jobs {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if _, _, err := deliverWebhook(job, retryBase); err != nil {
+					log.Printf("error al registrar la entrega %d: %v", job.DeliveryID, err)
+				}
+			}()
+		}
+		wg.Wait()
+	}
+}
+
+// newWebhookSecret genera un secreto aleatorio para una suscripción.
+func newWebhookSecret() (string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(secret), nil
+}
+
+// createWebhook godoc
+// @Summary Crea una suscripción de webhook
+// @Description Registra una URL que recibirá un POST por cada evento de los tipos indicados (todos si eventTypes está vacío).
+// @Description Cada entrega se firma con HMAC-SHA256 en X-Webhook-Signature ("sha256=" + hex de HMAC(secret, timestamp + "." + body)),
+// @Description con el timestamp en X-Webhook-Timestamp. Si no se envía secret se genera uno; solo se muestra en esta respuesta.
+// @Tags Webhooks
+// @Accept json
+// @Produce json
+// @Security AdminToken
+// @Param webhook body webhookInput true "Datos de la suscripción"
+// @Success 201 {object} internal.WebhookSubscription
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /admin/webhooks [post]
+func createWebhook(c *gin.Context) {
+	var input webhookInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		respondError(c, http.StatusBadRequest, "INVALID_BODY")
+		return
+	}
+	target, err := url.Parse(input.URL)
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+		respondError(c, http.StatusBadRequest, "WEBHOOK_INVALID_URL")
+		return
+	}
+	eventTypes := []string{}
+	for _, eventType := range input.EventTypes {
+		if !slices.Contains(webhookEventTypes, eventType) {
+			respondError(c, http.StatusBadRequest, "WEBHOOK_INVALID_EVENT_TYPE", eventType)
+			return
+		}
+		if !slices.Contains(eventTypes, eventType) {
+			eventTypes = append(eventTypes, eventType)
+		}
+	}
+	if input.Secret == "" {
+		if input.Secret, err = newWebhookSecret(); err != nil {
+			respondInternalError(c, err)
+			return
+		}
+	} else if len(input.Secret) < 16 || len(input.Secret) > 128 {
+		respondError(c, http.StatusBadRequest, "WEBHOOK_INVALID_SECRET")
+		return
+	}
+
+	subscription, err := internal.CreateWebhookSubscription(target.String(), eventTypes, input.Secret, auditInfo(c))
+	if err != nil {
+		respondInternalError(c, err)
+		return
+	}
+	c.Header("Location", "/api/admin/webhooks/"+strconv.Itoa(subscription.ID))
+	c.JSON(http.StatusCreated, subscription)
+}
+
+// getWebhooks godoc
+// @Summary Lista las suscripciones de webhooks
+// @Description Retorna todas las suscripciones, sin sus secretos.
+// @Tags Webhooks
+// @Produce json,text/csv,xml,application/x-yaml,application/x-ndjson
+// @Security AdminToken
+// @Param format query string false "Formato de la respuesta (alternativa a Accept)" Enums(json, csv, xml, yaml, ndjson)
+// @Success 200 {array} internal.WebhookSubscription
+// @Failure 401 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /admin/webhooks [get]
+func getWebhooks(c *gin.Context) {
+	subscriptions, err := internal.GetWebhookSubscriptions()
+	if err != nil {
+		respondInternalError(c, err)
+		return
+	}
+	render(c, http.StatusOK, subscriptions)
+}
+
+// webhookID lee el ID de la suscripción de la ruta y verifica que exista.
+// Si no es válido o no existe responde al cliente y retorna false.
+func webhookID(c *gin.Context) (internal.WebhookSubscription, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		respondError(c, http.StatusBadRequest, "INVALID_ID")
+		return internal.WebhookSubscription{}, false
+	}
+	subscription, err := internal.GetWebhookSubscription(id)
+	if errors.Is(err, sql.ErrNoRows) {
+		respondError(c, http.StatusNotFound, "WEBHOOK_NOT_FOUND")
+		return subscription, false
+	}
+	if err != nil {
+		respondInternalError(c, err)
+		return subscription, false
+	}
+	return subscription, true
+}
+
+// getWebhook godoc
+// @Summary Obtiene una suscripción de webhook
+// @Tags Webhooks
+// @Produce json,text/csv,xml,application/x-yaml,application/x-ndjson
+// @Security AdminToken
+// @Param id path int true "ID de la suscripción"
+// @Param format query string false "Formato de la respuesta (alternativa a Accept)" Enums(json, csv, xml, yaml, ndjson)
+// @Success 200 {object} internal.WebhookSubscription
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /admin/webhooks/{id} [get]
+func getWebhook(c *gin.Context) {
+	if subscription, ok := webhookID(c); ok {
+		render(c, http.StatusOK, subscription)
+	}
+}
+
+// deleteWebhook godoc
+// @Summary Elimina una suscripción de webhook
+// @Description Elimina la suscripción junto con sus entregas pendientes y su registro.
+// @Tags Webhooks
+// @Security AdminToken
+// @Param id path int true "ID de la suscripción"
+// @Success 204
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /admin/webhooks/{id} [delete]
+func deleteWebhook(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		respondError(c, http.StatusBadRequest, "INVALID_ID")
+		return
+	}
+	err = internal.DeleteWebhookSubscription(id, auditInfo(c))
+	if errors.Is(err, sql.ErrNoRows) {
+		respondError(c, http.StatusNotFound, "WEBHOOK_NOT_FOUND")
+		return
+	}
+	if err != nil {
+		respondInternalError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// webhookDeliveryFilter lee los parámetros de paginación y estado de los listados de entregas.
+func webhookDeliveryFilter(c *gin.Context, filter *internal.WebhookDeliveryFilter) bool {
+	filter.Limit = defaultWebhookDeliveriesLimit
+	if value := c.Query("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit < 1 || limit > maxWebhookDeliveriesLimit {
+			respondError(c, http.StatusBadRequest, "INVALID_LIMIT", maxWebhookDeliveriesLimit)
+			return false
+		}
+		filter.Limit = limit
+	}
+	if value := c.Query("offset"); value != "" {
+		offset, err := strconv.Atoi(value)
+		if err != nil || offset < 0 {
+			respondError(c, http.StatusBadRequest, "INVALID_OFFSET")
+			return false
+		}
+		filter.Offset = offset
+	}
+	return true
+}
+
+// getWebhookDeliveries godoc
+// @Summary Registro de entregas de una suscripción
+// @Description Retorna las entregas de la suscripción, de la más reciente a la más antigua, con cada intento y su respuesta.
+// @Tags Webhooks
+// @Produce json,text/csv,xml,application/x-yaml,application/x-ndjson
+// @Security AdminToken
+// @Param id path int true "ID de la suscripción"
+// @Param status query string false "Estado de la entrega" Enums(pending, delivered, dead)
+// @Param limit query int false "Máximo de entregas (1-500)"
+// @Param offset query int false "Entregas a omitir"
+// @Param format query string false "Formato de la respuesta (alternativa a Accept)" Enums(json, csv, xml, yaml, ndjson)
+// @Success 200 {array} internal.WebhookDelivery
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /admin/webhooks/{id}/deliveries [get]
+func getWebhookDeliveries(c *gin.Context) {
+	subscription, ok := webhookID(c)
+	if !ok {
+		return
+	}
+	filter := internal.WebhookDeliveryFilter{SubscriptionID: subscription.ID, Status: c.Query("status")}
+	switch filter.Status {
+	case "", internal.WebhookPending, internal.WebhookDelivered, internal.WebhookDead:
+	default:
+		respondError(c, http.StatusBadRequest, "WEBHOOK_INVALID_STATUS", filter.Status)
+		return
+	}
+	if !webhookDeliveryFilter(c, &filter) {
+		return
+	}
+
+	deliveries, err := internal.GetWebhookDeliveries(filter)
+	if err != nil {
+		respondInternalError(c, err)
+		return
+	}
+	render(c, http.StatusOK, deliveries)
+}
+
+// getWebhookDeadLetters godoc
+// @Summary Lista de entregas muertas
+// @Description Retorna las entregas de todas las suscripciones que agotaron sus reintentos, con su registro de intentos.
+// @Tags Webhooks
+// @Produce json,text/csv,xml,application/x-yaml,application/x-ndjson
+// @Security AdminToken
+// @Param limit query int false "Máximo de entregas (1-500)"
+// @Param offset query int false "Entregas a omitir"
+// @Param format query string false "Formato de la respuesta (alternativa a Accept)" Enums(json, csv, xml, yaml, ndjson)
+// @Success 200 {array} internal.WebhookDelivery
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /admin/webhooks/dead-letters [get]
+func getWebhookDeadLetters(c *gin.Context) {
+	filter := internal.WebhookDeliveryFilter{Status: internal.WebhookDead}
+	if !webhookDeliveryFilter(c, &filter) {
+		return
+	}
+	deliveries, err := internal.GetWebhookDeliveries(filter)
+	if err != nil {
+		respondInternalError(c, err)
+		return
+	}
+	render(c, http.StatusOK, deliveries)
+}
+
+// redeliverWebhook godoc
+// @Summary Reenvía una entrega
+// @Description Vuelve a poner en cola una entrega muerta o ya entregada para enviarla de inmediato, con un nuevo ciclo de reintentos.
+// @Tags Webhooks
+// @Produce json
+// @Security AdminToken
+// @Param deliveryId path int true "ID de la entrega"
+// @Success 202 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /admin/webhooks/deliveries/{deliveryId}/redeliver [post]
+func redeliverWebhook(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("deliveryId"), 10, 64)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, "INVALID_ID")
+		return
+	}
+	err = internal.RedeliverWebhook(id, auditInfo(c))
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		respondError(c, http.StatusNotFound, "WEBHOOK_DELIVERY_NOT_FOUND")
+	case errors.Is(err, internal.ErrDeliveryPending):
+		respondError(c, http.StatusConflict, "WEBHOOK_DELIVERY_PENDING")
+	case err != nil:
+		respondInternalError(c, err)
+	default:
+		c.JSON(http.StatusAccepted, gin.H{"code": "WEBHOOK_REDELIVERY_QUEUED", "message": translate(c, "WEBHOOK_REDELIVERY_QUEUED")})
+	}
+}
+
+// testWebhook godoc
+// @Summary Envía una entrega de prueba
+// @Description Envía de inmediato un evento "Ping" firmado a la URL de la suscripción y retorna el resultado del intento.
+// @Description La entrega de prueba queda en el registro y no se reintenta; si falla pasa a la lista de entregas muertas.
+// @Tags Webhooks
+// @Produce json
+// @Security AdminToken
+// @Param id path int true "ID de la suscripción"
+// @Success 200 {object} internal.WebhookAttempt "El receptor aceptó la entrega"
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 502 {object} internal.WebhookAttempt "El receptor rechazó la entrega o no respondió"
+// @Router /admin/webhooks/{id}/test [post]
+func testWebhook(c *gin.Context) {
+	subscription, ok := webhookID(c)
+	if !ok {
+		return
+	}
+	payload, err := json.Marshal(gin.H{"type": webhookPingEvent, "subscriptionId": subscription.ID, "occurredAt": time.Now().UTC()})
+	if err != nil {
+		respondInternalError(c, err)
+		return
+	}
+	job, err := internal.CreateWebhookTestDelivery(subscription.ID, webhookPingEvent, payload, auditInfo(c))
+	if err != nil {
+		respondInternalError(c, err)
+		return
+	}
+
+	attempt, delivered, err := deliverWebhook(job, webhookRetryBase())
+	if err != nil {
+		respondInternalError(c, err)
+		return
+	}
+	if !delivered {
+		c.JSON(http.StatusBadGateway, attempt)
+		return
+	}
+	c.JSON(http.StatusOK, attempt)
+}
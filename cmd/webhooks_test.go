@@ -0,0 +1,67 @@
+package main
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+	"time"
+)
+
+func TestSignWebhook(t *testing.T) {
+	tests := []struct {
+		name      string
+		secret    string
+		timestamp string
+		body      string
+	}{
+		{name: "entrega con cuerpo", secret: "s3cr3t", timestamp: "1740859200", body: `{"type":"GoalScored","matchId":7}`},
+		{name: "cuerpo vacío", secret: "s3cr3t", timestamp: "1740859200", body: ""},
+		{name: "secreto vacío", secret: "", timestamp: "1", body: `{}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mac := hmac.New(sha256.New, []byte(tt.secret))
+			mac.Write([]byte(tt.timestamp + "." + tt.body))
+			want := "sha256=" + hex.EncodeToString(mac.Sum(nil))
+
+			if got := signWebhook(tt.secret, tt.timestamp, []byte(tt.body)); got != want {
+				t.Errorf("firma = %s, se esperaba %s", got, want)
+			}
+		})
+	}
+
+	// La firma cubre el timestamp: reenviar el mismo cuerpo con otro timestamp no la reutiliza
+	body := []byte(`{"type":"GoalScored"}`)
+	if signWebhook("s3cr3t", "1", body) == signWebhook("s3cr3t", "2", body) {
+		t.Error("la firma no depende del timestamp")
+	}
+	if signWebhook("s3cr3t", "1", body) == signWebhook("otro", "1", body) {
+		t.Error("la firma no depende del secreto")
+	}
+}
+
+func TestWebhookBackoff(t *testing.T) {
+	tests := []struct {
+		name    string
+		base    time.Duration
+		attempt int
+		want    time.Duration
+	}{
+		{name: "primer reintento espera la base", base: 30 * time.Second, attempt: 1, want: 30 * time.Second},
+		{name: "segundo reintento duplica", base: 30 * time.Second, attempt: 2, want: time.Minute},
+		{name: "quinto reintento", base: 30 * time.Second, attempt: 5, want: 8 * time.Minute},
+		{name: "se limita al máximo", base: 30 * time.Second, attempt: 20, want: webhookMaxBackoff},
+		{name: "base mayor que el máximo", base: 12 * time.Hour, attempt: 1, want: webhookMaxBackoff},
+		{name: "intentos muy altos no desbordan", base: time.Second, attempt: 1000, want: webhookMaxBackoff},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := webhookBackoff(tt.base, tt.attempt); got != tt.want {
+				t.Errorf("webhookBackoff(%v, %d) = %v, se esperaba %v", tt.base, tt.attempt, got, tt.want)
+			}
+		})
+	}
+}
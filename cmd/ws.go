@@ -0,0 +1,446 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	"lab6/internal"
+)
+
+const (
+	// wsPingInterval es el intervalo de los ping que el servidor envía a cada conexión.
+	wsPingInterval = 30 * time.Second
+
+	// wsPongTimeout es el tiempo sin recibir mensajes ni pong tras el cual se cierra la conexión.
+	wsPongTimeout = 2 * wsPingInterval
+
+	// wsWriteTimeout limita el tiempo de cada escritura en la conexión.
+	wsWriteTimeout = 10 * time.Second
+
+	// wsSendBuffer es la cantidad de mensajes pendientes por cliente. Si un cliente lento lo llena,
+	// se lo desconecta en lugar de frenar al resto de los espectadores.
+	wsSendBuffer = 256
+
+	// wsMaxMessageSize limita el tamaño de los mensajes que envía el cliente.
+	wsMaxMessageSize = 4 << 10
+
+	// wsMaxSubscriptions limita los canales a los que puede suscribirse una conexión.
+	wsMaxSubscriptions = 100
+
+	// wsPollInterval es el intervalo con el que el hub consulta eventos nuevos aunque no reciba avisos.
+	wsPollInterval = 15 * time.Second
+)
+
+// Acciones que el cliente envía por /ws.
+const (
+	wsActionSubscribe   = "subscribe"
+	wsActionUnsubscribe = "unsubscribe"
+)
+
+// Prefijos de los canales de suscripción.
+const (
+	wsChannelAll         = "matches"
+	wsChannelMatch       = "match:"
+	wsChannelTeam        = "team:"
+	wsChannelCompetition = "competition:"
+)
+
+// wsCommand es un mensaje del cliente.
+type wsCommand struct {
+	Action  string `json:"action" example:"subscribe"`
+	Channel string `json:"channel" example:"match:1"`
+}
+
+// wsMessage es un mensaje del servidor. Type es "subscribed", "unsubscribed", "event", "commentary" o "error".
+type wsMessage struct {
+	Type       string                    `json:"type"`
+	Channel    string                    `json:"channel,omitempty"`
+	Channels   []string                  `json:"channels,omitempty"`
+	Event      *streamEvent              `json:"event,omitempty"`
+	Commentary *internal.CommentaryEntry `json:"commentary,omitempty"`
+	Code       string                    `json:"code,omitempty"`
+	Error      string                    `json:"error,omitempty"`
+}
+
+// wsClient es una conexión al hub. Los mensajes se encolan en send y los escribe writePump,
+// de modo que una conexión lenta nunca bloquea al hub. Lo mismo ocurre con el cierre: close
+// cierra done y writePump envía closeMessage y cierra la conexión.
+type wsClient struct {
+	hub  *scoreboardHub
+	conn *websocket.Conn
+	send chan []byte
+	lang func(code string, args ...any) string
+
+	done         chan struct{}
+	closeMessage []byte
+
+	// channels son los canales suscritos, con la clave interna que usa el hub para cada uno.
+	// Solo readPump los modifica, siempre con el lock del hub tomado.
+	channels map[string]string
+	closed   bool
+	once     sync.Once
+}
+
+// scoreboardHub reparte los cambios de partidos a los clientes suscritos por WebSocket.
+// Una única goroutine lee el log de eventos y lo difunde, sin importar cuántos clientes haya.
+type scoreboardHub struct {
+	mu sync.RWMutex
+	// subscribers indexa los clientes por clave interna de canal ("matches", "match:1", "team:Barcelona",
+	// "competition:1").
+	subscribers map[string]map[*wsClient]string
+}
+
+// newScoreboardHub crea el hub. run debe ejecutarse en una goroutine para que reciba cambios.
+func newScoreboardHub() *scoreboardHub {
+	return &scoreboardHub{subscribers: map[string]map[*wsClient]string{}}
+}
+
+// run lee los eventos nuevos del log y los comentarios nuevos, editados o eliminados, y los difunde a los
+// suscriptores de sus canales.
+func (h *scoreboardHub) run() {
+	changes, unsubscribe := internal.SubscribeChanges()
+	defer unsubscribe()
+
+	var after int64
+	var afterCommentary internal.CommentaryCursor
+	for {
+		var err error
+		if after, err = internal.LatestEventID(0); err == nil {
+			if afterCommentary, err = internal.LatestCommentaryCursor(); err == nil {
+				break
+			}
+		}
+		log.Printf("error al iniciar el hub de WebSocket: %v", err)
+		time.Sleep(wsPollInterval)
+	}
+
+	poll := time.NewTicker(wsPollInterval)
+	defer poll.Stop()
+	for {
+		select {
+		case <-changes:
+		case <-poll.C:
+		}
+		for {
+			batch, err := internal.GetMatchChanges(after, 0, streamBatchSize)
+			var competitions map[int]int
+			if err == nil {
+				ids := make([]int, len(batch))
+				for i, change := range batch {
+					ids[i] = change.Event.MatchID
+				}
+				competitions, err = internal.GetMatchCompetitions(ids)
+			}
+			if err != nil {
+				log.Printf("error al leer eventos para el hub de WebSocket: %v", err)
+				break
+			}
+			for _, change := range batch {
+				event := toStreamEvent(change)
+				h.broadcast(change.Event.MatchID, competitions[change.Event.MatchID], change.Match.HomeTeam, change.Match.AwayTeam,
+					wsMessage{Type: "event", Event: &event})
+				after = change.Event.ID
+			}
+			if len(batch) < streamBatchSize {
+				break
+			}
+		}
+		for {
+			batch, err := internal.GetCommentaryChanges(afterCommentary, streamBatchSize)
+			var competitions map[int]int
+			if err == nil {
+				ids := make([]int, len(batch))
+				for i, change := range batch {
+					ids[i] = change.Entry.MatchID
+				}
+				competitions, err = internal.GetMatchCompetitions(ids)
+			}
+			if err != nil {
+				log.Printf("error al leer comentarios para el hub de WebSocket: %v", err)
+				break
+			}
+			for _, change := range batch {
+				h.broadcast(change.Entry.MatchID, competitions[change.Entry.MatchID], change.HomeTeam, change.AwayTeam,
+					wsMessage{Type: "commentary", Commentary: &change.Entry})
+				afterCommentary = change.Cursor
+			}
+			if len(batch) < streamBatchSize {
+				break
+			}
+		}
+	}
+}
+
+// broadcast envía el mensaje de un partido a cada cliente suscrito a alguno de sus canales,
+// una sola vez por cliente y con la lista de canales que coincidieron.
+func (h *scoreboardHub) broadcast(matchID, competitionID int, homeTeam, awayTeam string, message wsMessage) {
+	keys := []string{
+		wsChannelAll,
+		wsChannelMatch + strconv.Itoa(matchID),
+		wsChannelTeam + homeTeam,
+		wsChannelTeam + awayTeam,
+		wsChannelCompetition + strconv.Itoa(competitionID),
+	}
+
+	h.mu.RLock()
+	targets := map[*wsClient][]string{}
+	for _, key := range keys {
+		for client, channel := range h.subscribers[key] {
+			if !slices.Contains(targets[client], channel) {
+				targets[client] = append(targets[client], channel)
+			}
+		}
+	}
+	h.mu.RUnlock()
+
+	for client, channels := range targets {
+		slices.Sort(channels)
+		message.Channels = channels
+		client.enqueue(message)
+	}
+}
+
+// subscribe registra el cliente en la clave interna del canal, salvo que ya se haya desconectado.
+func (h *scoreboardHub) subscribe(client *wsClient, channel, key string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if client.closed {
+		return
+	}
+	client.channels[channel] = key
+	if h.subscribers[key] == nil {
+		h.subscribers[key] = map[*wsClient]string{}
+	}
+	h.subscribers[key][client] = channel
+}
+
+// unsubscribe quita el cliente del canal.
+func (h *scoreboardHub) unsubscribe(client *wsClient, channel string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	key, ok := client.channels[channel]
+	if !ok {
+		return
+	}
+	delete(client.channels, channel)
+	delete(h.subscribers[key], client)
+	if len(h.subscribers[key]) == 0 {
+		delete(h.subscribers, key)
+	}
+}
+
+// resolveChannel valida un canal y retorna su clave interna. Los equipos se suscriben por ID
+// pero se indexan por nombre canónico, que es como los partidos los referencian; las competiciones
+// se indexan por ID.
+func resolveChannel(channel string) (string, error) {
+	switch {
+	case channel == wsChannelAll:
+		return channel, nil
+	case strings.HasPrefix(channel, wsChannelMatch):
+		id, err := strconv.Atoi(strings.TrimPrefix(channel, wsChannelMatch))
+		if err != nil || id <= 0 {
+			return "", newAPIError("WS_INVALID_CHANNEL", channel)
+		}
+		return wsChannelMatch + strconv.Itoa(id), nil
+	case strings.HasPrefix(channel, wsChannelTeam):
+		id, err := strconv.Atoi(strings.TrimPrefix(channel, wsChannelTeam))
+		if err != nil || id <= 0 {
+			return "", newAPIError("WS_INVALID_CHANNEL", channel)
+		}
+		team, err := internal.GetTeamByID(id)
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", newAPIError("TEAM_NOT_FOUND")
+		}
+		if err != nil {
+			return "", err
+		}
+		return wsChannelTeam + team.Name, nil
+	case strings.HasPrefix(channel, wsChannelCompetition):
+		id, err := strconv.Atoi(strings.TrimPrefix(channel, wsChannelCompetition))
+		if err != nil || id <= 0 {
+			return "", newAPIError("WS_INVALID_CHANNEL", channel)
+		}
+		competition, err := internal.GetCompetitionByID(id)
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", newAPIError("COMPETITION_NOT_FOUND")
+		}
+		if err != nil {
+			return "", err
+		}
+		return wsChannelCompetition + strconv.Itoa(competition.ID), nil
+	default:
+		return "", newAPIError("WS_INVALID_CHANNEL", channel)
+	}
+}
+
+// enqueue encola un mensaje para el cliente. Si su buffer está lleno el cliente se desconecta.
+func (c *wsClient) enqueue(message wsMessage) {
+	data, err := json.Marshal(message)
+	if err != nil {
+		log.Printf("error al serializar mensaje de WebSocket: %v", err)
+		return
+	}
+	select {
+	case c.send <- data:
+	default:
+		c.close(websocket.ClosePolicyViolation, "slow consumer")
+	}
+}
+
+// enqueueError encola un mensaje de error localizado.
+func (c *wsClient) enqueueError(channel string, err error) {
+	code, args := errorCode(err)
+	c.enqueue(wsMessage{Type: "error", Channel: channel, Code: code, Error: c.lang(code, args...)})
+}
+
+// close quita al cliente de todos sus canales y pide a writePump que cierre la conexión con el
+// código indicado. No escribe en la conexión, por lo que el hub puede llamarla sin bloquearse.
+func (c *wsClient) close(code int, reason string) {
+	c.once.Do(func() {
+		c.hub.mu.Lock()
+		c.closed = true
+		for _, key := range c.channels {
+			delete(c.hub.subscribers[key], c)
+			if len(c.hub.subscribers[key]) == 0 {
+				delete(c.hub.subscribers, key)
+			}
+		}
+		c.hub.mu.Unlock()
+
+		c.closeMessage = websocket.FormatCloseMessage(code, reason)
+		close(c.done)
+	})
+}
+
+// readPump procesa los comandos del cliente hasta que la conexión se cierra.
+func (c *wsClient) readPump() {
+	defer c.close(websocket.CloseNormalClosure, "")
+
+	c.conn.SetReadLimit(wsMaxMessageSize)
+	c.conn.SetReadDeadline(time.Now().Add(wsPongTimeout))
+	c.conn.SetPongHandler(func(string) error {
+		return c.conn.SetReadDeadline(time.Now().Add(wsPongTimeout))
+	})
+
+	for {
+		var command wsCommand
+		if err := c.conn.ReadJSON(&command); err != nil {
+			var syntaxErr *json.SyntaxError
+			var typeErr *json.UnmarshalTypeError
+			if errors.As(err, &syntaxErr) || errors.As(err, &typeErr) {
+				c.enqueueError("", newAPIError("INVALID_BODY"))
+				continue
+			}
+			return
+		}
+		c.conn.SetReadDeadline(time.Now().Add(wsPongTimeout))
+		c.handle(command)
+	}
+}
+
+// handle aplica un comando de suscripción.
+func (c *wsClient) handle(command wsCommand) {
+	channel := strings.TrimSpace(command.Channel)
+	switch command.Action {
+	case wsActionSubscribe:
+		if _, ok := c.channels[channel]; ok {
+			c.enqueue(wsMessage{Type: "subscribed", Channel: channel})
+			return
+		}
+		if len(c.channels) >= wsMaxSubscriptions {
+			c.enqueueError(channel, newAPIError("WS_TOO_MANY_SUBSCRIPTIONS", wsMaxSubscriptions))
+			return
+		}
+		key, err := resolveChannel(channel)
+		if err != nil {
+			c.enqueueError(channel, err)
+			return
+		}
+		c.hub.subscribe(c, channel, key)
+		c.enqueue(wsMessage{Type: "subscribed", Channel: channel})
+	case wsActionUnsubscribe:
+		c.hub.unsubscribe(c, channel)
+		c.enqueue(wsMessage{Type: "unsubscribed", Channel: channel})
+	default:
+		c.enqueueError(channel, newAPIError("INVALID_OPERATION", command.Action))
+	}
+}
+
+// writePump escribe los mensajes encolados y envía un ping periódico como latido. Es la única
+// goroutine que escribe en la conexión: cuando el cliente se cierra envía el mensaje de cierre y
+// cierra la conexión, lo que también termina readPump.
+func (c *wsClient) writePump() {
+	ping := time.NewTicker(wsPingInterval)
+	defer ping.Stop()
+	defer c.conn.Close()
+
+	for {
+		select {
+		case data := <-c.send:
+			c.conn.SetWriteDeadline(time.Now().Add(wsWriteTimeout))
+			if err := c.conn.WriteMessage(websocket.TextMessage, data); err != nil {
+				c.close(websocket.CloseGoingAway, "")
+				return
+			}
+		case <-ping.C:
+			if err := c.conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(wsWriteTimeout)); err != nil {
+				c.close(websocket.CloseGoingAway, "")
+				return
+			}
+		case <-c.done:
+			c.conn.WriteControl(websocket.CloseMessage, c.closeMessage, time.Now().Add(wsWriteTimeout))
+			return
+		}
+	}
+}
+
+// wsUpgrader acepta conexiones del mismo origen o de los orígenes permitidos por CORS.
+var wsUpgrader = websocket.Upgrader{
+	ReadBufferSize:  1024,
+	WriteBufferSize: 1024,
+	CheckOrigin: func(r *http.Request) bool {
+		origin := r.Header.Get("Origin")
+		return origin == "" || slices.Contains(allowedOrigins, origin) ||
+			strings.TrimPrefix(strings.TrimPrefix(origin, "https://"), "http://") == r.Host
+	},
+}
+
+// serveScoreboard abre una conexión WebSocket del marcador en vivo. El cliente envía
+// {"action": "subscribe"|"unsubscribe", "channel": ...} con los canales "matches" (todos), "match:<id>",
+// "team:<id>" o "competition:<id>", y recibe {"type": "event", "channels": [...], "event": {...}} con cada cambio de un
+// partido de sus canales, en el mismo formato que /matches/stream, y {"type": "commentary", ...}
+// con cada comentario nuevo, editado o eliminado. No se documenta en Swagger porque
+// OpenAPI 2 no describe WebSocket.
+func serveScoreboard(hub *scoreboardHub) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		conn, err := wsUpgrader.Upgrade(c.Writer, c.Request, nil)
+		if err != nil {
+			// Upgrade ya respondió al cliente con el error
+			return
+		}
+
+		tag := requestLanguage(c)
+		client := &wsClient{
+			hub:      hub,
+			conn:     conn,
+			send:     make(chan []byte, wsSendBuffer),
+			done:     make(chan struct{}),
+			channels: map[string]string{},
+			lang: func(code string, args ...any) string {
+				return message(tag, code, args...)
+			},
+		}
+		go client.writePump()
+		client.readPump()
+	}
+}
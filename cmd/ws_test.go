@@ -0,0 +1,169 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gorilla/websocket"
+)
+
+func TestResolveChannel(t *testing.T) {
+	tests := []struct {
+		name     string
+		channel  string
+		mock     func(mock sqlmock.Sqlmock)
+		wantKey  string
+		wantCode string
+	}{
+		{name: "todos los partidos", channel: "matches", wantKey: "matches"},
+		{name: "partido", channel: "match:007", wantKey: "match:7"},
+		{name: "partido inválido", channel: "match:abc", wantCode: "WS_INVALID_CHANNEL"},
+		{
+			name:    "equipo por su nombre canónico",
+			channel: "team:3",
+			mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`FROM teams t`).WithArgs(3).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "name", "stadium", "aliases"}).AddRow(3, "Sevilla", "", "{}"))
+			},
+			wantKey: "team:Sevilla",
+		},
+		{
+			name:    "competición",
+			channel: "competition:1",
+			mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`FROM competitions WHERE id = \$1`).WithArgs(1).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "name", "country"}).AddRow(1, "La Liga", "España"))
+			},
+			wantKey: "competition:1",
+		},
+		{
+			name:    "competición inexistente",
+			channel: "competition:9",
+			mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`FROM competitions WHERE id = \$1`).WithArgs(9).WillReturnError(sql.ErrNoRows)
+			},
+			wantCode: "COMPETITION_NOT_FOUND",
+		},
+		{name: "competición inválida", channel: "competition:0", wantCode: "WS_INVALID_CHANNEL"},
+		{name: "canal desconocido", channel: "season:2024", wantCode: "WS_INVALID_CHANNEL"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := mockDB(t)
+			if tt.mock != nil {
+				tt.mock(mock)
+			}
+
+			key, err := resolveChannel(tt.channel)
+			if tt.wantCode != "" {
+				if code, _ := errorCode(err); code != tt.wantCode {
+					t.Fatalf("error = %v, se esperaba %s", err, tt.wantCode)
+				}
+			} else if err != nil || key != tt.wantKey {
+				t.Fatalf("resolveChannel(%q) = %q, %v; se esperaba %q", tt.channel, key, err, tt.wantKey)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+// newTestClient crea un cliente sin conexión suscrito a los canales indicados. Como no tiene
+// conexión, cualquier escritura fuera de writePump falla la prueba con un panic.
+func newTestClient(hub *scoreboardHub, buffer int, channels map[string]string) *wsClient {
+	client := &wsClient{
+		hub:      hub,
+		send:     make(chan []byte, buffer),
+		done:     make(chan struct{}),
+		channels: map[string]string{},
+	}
+	for channel, key := range channels {
+		hub.subscribe(client, channel, key)
+	}
+	return client
+}
+
+func TestScoreboardBroadcast(t *testing.T) {
+	hub := newScoreboardHub()
+	competition := newTestClient(hub, 1, map[string]string{"competition:1": "competition:1"})
+	team := newTestClient(hub, 1, map[string]string{"team:3": "team:Sevilla", "match:7": "match:7"})
+	other := newTestClient(hub, 1, map[string]string{"competition:2": "competition:2"})
+
+	hub.broadcast(7, 1, "Sevilla", "Betis", wsMessage{Type: "event"})
+
+	var message wsMessage
+	if err := json.Unmarshal(<-competition.send, &message); err != nil || len(message.Channels) != 1 || message.Channels[0] != "competition:1" {
+		t.Errorf("mensaje de la competición = %+v, %v", message, err)
+	}
+	if err := json.Unmarshal(<-team.send, &message); err != nil || len(message.Channels) != 2 {
+		t.Errorf("el cliente suscrito al equipo y al partido recibió %+v, %v; se esperaba un mensaje con ambos canales", message, err)
+	}
+	if len(other.send) != 0 {
+		t.Error("el cliente de otra competición recibió el mensaje")
+	}
+}
+
+func TestWSClientCloseDoesNotWrite(t *testing.T) {
+	hub := newScoreboardHub()
+	slow := newTestClient(hub, 1, map[string]string{"matches": "matches"})
+
+	// El primer mensaje llena el buffer y el segundo desconecta al cliente lento desde el hub,
+	// que no debe escribir en la conexión
+	hub.broadcast(7, 1, "Sevilla", "Betis", wsMessage{Type: "event"})
+	hub.broadcast(7, 1, "Sevilla", "Betis", wsMessage{Type: "event"})
+
+	select {
+	case <-slow.done:
+	default:
+		t.Fatal("el cliente lento no se cerró")
+	}
+	if len(hub.subscribers) != 0 {
+		t.Errorf("el hub conserva suscriptores: %v", hub.subscribers)
+	}
+	if string(slow.closeMessage) != string(websocket.FormatCloseMessage(websocket.ClosePolicyViolation, "slow consumer")) {
+		t.Errorf("mensaje de cierre = %q", slow.closeMessage)
+	}
+
+	// Un segundo cierre no cambia el código ni vuelve a cerrar done
+	slow.close(websocket.CloseNormalClosure, "")
+	if string(slow.closeMessage) != string(websocket.FormatCloseMessage(websocket.ClosePolicyViolation, "slow consumer")) {
+		t.Errorf("el segundo cierre cambió el mensaje a %q", slow.closeMessage)
+	}
+}
+
+func TestWSWritePumpSendsClose(t *testing.T) {
+	hub := newScoreboardHub()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := wsUpgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		client := newTestClient(hub, 1, nil)
+		client.conn = conn
+		go client.writePump()
+		client.close(websocket.ClosePolicyViolation, "slow consumer")
+	}))
+	defer server.Close()
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+
+	_, _, err = conn.ReadMessage()
+	var closeErr *websocket.CloseError
+	if !errors.As(err, &closeErr) || closeErr.Code != websocket.ClosePolicyViolation || closeErr.Text != "slow consumer" {
+		t.Fatalf("error = %v, se esperaba el cierre 1008 de writePump", err)
+	}
+}
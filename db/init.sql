@@ -9,50 +9,588 @@ Este script crea la tabla "matches" en PostgreSQL, la cual almacena la informaci
 de los partidos de La Liga. Además, inserta datos iniciales para poblar la tabla y
 facilitar las pruebas en el desarrollo del backend.
 
+Postgres lo ejecuta al crear un volumen vacío y la aplicación lo vuelve a ejecutar en
+cada arranque, por lo que todos los pasos deben poder repetirse: las tablas nuevas se
+crean con IF NOT EXISTS, las columnas agregadas después de la primera versión se
+agregan con ADD COLUMN IF NOT EXISTS para actualizar las bases existentes, y los datos
+iniciales solo se insertan en una base que nunca tuvo registros.
+
 Estructura de la Tabla:
   - id                : Identificador único del partido (SERIAL, PRIMARY KEY)
+  - competition_id    : Competición del partido (INT, NOT NULL, DEFAULT 1: La Liga)
   - home_team         : Nombre del equipo local (VARCHAR(100), NOT NULL)
   - away_team         : Nombre del equipo visitante (VARCHAR(100), NOT NULL)
-  - match_date        : Fecha del partido (DATE, NOT NULL)
-  - goals_match       : Total de goles anotados en el partido (INT, DEFAULT 0)
-  - yellow_cards_match: Total de tarjetas amarillas (INT, DEFAULT 0)
-  - red_cards_match   : Total de tarjetas rojas (INT, DEFAULT 0)
-  - extra_time        : Indica si se jugó tiempo extra (BOOLEAN, DEFAULT FALSE)
+  - match_date        : Fecha y hora de inicio del partido (TIMESTAMPTZ, NOT NULL)
+  - date_only         : El partido se registró sin hora; match_date queda a medianoche UTC (BOOLEAN)
+  - goals_match       : Total de goles anotados en el partido (INT, NOT NULL, DEFAULT 0)
+  - yellow_cards_match: Total de tarjetas amarillas (INT, NOT NULL, DEFAULT 0)
+  - red_cards_match   : Total de tarjetas rojas (INT, NOT NULL, DEFAULT 0)
+  - extra_time        : Indica si se jugó tiempo extra (BOOLEAN, NOT NULL, DEFAULT FALSE)
+  - finished          : Indica si el partido finalizó (BOOLEAN, NOT NULL, DEFAULT FALSE)
+  - version           : Versión del registro para control de concurrencia (INT, DEFAULT 1)
+  - search_vector     : Documento de búsqueda de texto completo con ambos equipos (TSVECTOR, generado)
+  - deleted_at        : Fecha de eliminación; NULL si el partido no está en la papelera (TIMESTAMPTZ)
+  - clock_period      : Último periodo de juego iniciado; NULL o vacío antes del saque inicial (VARCHAR(20))
+  - clock_started_at  : Inicio del periodo en curso; NULL si el reloj está detenido (TIMESTAMPTZ)
+  - clock_stoppage    : Minutos de descuento anunciados en el periodo en curso (INT)
 
 ========================================================================
 */
 
+/*
+Configuración de búsqueda de texto completo "es_unaccent": elimina acentos
+con la extensión unaccent antes de indexar, de modo que "Atletico" encuentre
+"Atlético". Se usa el diccionario simple porque se indexan nombres propios.
+*/
+CREATE EXTENSION IF NOT EXISTS unaccent;
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_ts_config WHERE cfgname = 'es_unaccent') THEN
+        CREATE TEXT SEARCH CONFIGURATION es_unaccent (COPY = simple);
+        ALTER TEXT SEARCH CONFIGURATION es_unaccent
+            ALTER MAPPING FOR hword, hword_part, word WITH unaccent, simple;
+    END IF;
+END
+$$;
+
+/*========================================================================
+   Tabla "competitions"
+========================================================================*/
+
+/*
+Competiciones a las que pertenecen los partidos. Son datos de referencia que solo
+crea este script: La Liga tiene el ID 1, que es el valor por defecto de
+matches.competition_id.
+  - competitions.id      : Identificador de la competición (SERIAL, PRIMARY KEY)
+  - competitions.name    : Nombre de la competición (VARCHAR(100), UNIQUE)
+  - competitions.country : País de la competición (VARCHAR(100))
+*/
+CREATE TABLE IF NOT EXISTS competitions (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(100) NOT NULL UNIQUE,
+    country VARCHAR(100) NOT NULL
+);
+
+DO $$
+BEGIN
+IF NOT (SELECT is_called FROM competitions_id_seq) THEN
+    INSERT INTO competitions (name, country) VALUES ('La Liga', 'España');
+END IF;
+END
+$$;
+
 /* Crear la tabla "matches" si no existe */
 CREATE TABLE IF NOT EXISTS matches (
     id SERIAL PRIMARY KEY,
+    competition_id INT NOT NULL DEFAULT 1 REFERENCES competitions(id),
     home_team VARCHAR(100) NOT NULL,
     away_team VARCHAR(100) NOT NULL,
-    match_date DATE NOT NULL,
-    goals_match INT DEFAULT 0,
-    yellow_cards_match INT DEFAULT 0,
-    red_cards_match INT DEFAULT 0,
-    extra_time BOOLEAN DEFAULT FALSE
+    match_date TIMESTAMPTZ NOT NULL,
+    date_only BOOLEAN NOT NULL DEFAULT FALSE,
+    goals_match INT NOT NULL DEFAULT 0,
+    yellow_cards_match INT NOT NULL DEFAULT 0,
+    red_cards_match INT NOT NULL DEFAULT 0,
+    extra_time BOOLEAN NOT NULL DEFAULT FALSE,
+    finished BOOLEAN NOT NULL DEFAULT FALSE,
+    version INT NOT NULL DEFAULT 1,
+    search_vector TSVECTOR GENERATED ALWAYS AS (
+        to_tsvector('es_unaccent', home_team || ' ' || away_team)
+    ) STORED,
+    deleted_at TIMESTAMPTZ,
+    clock_period VARCHAR(20),
+    clock_started_at TIMESTAMPTZ,
+    clock_stoppage INT
 );
 
+/* Bases creadas con la primera versión: match_date era DATE y los contadores admitían NULL */
+DO $$
+BEGIN
+    IF (SELECT data_type FROM information_schema.columns
+        WHERE table_schema = current_schema() AND table_name = 'matches' AND column_name = 'match_date') = 'date' THEN
+        ALTER TABLE matches ALTER COLUMN match_date TYPE TIMESTAMPTZ;
+    END IF;
+END
+$$;
+
+UPDATE matches
+SET goals_match = COALESCE(goals_match, 0),
+    yellow_cards_match = COALESCE(yellow_cards_match, 0),
+    red_cards_match = COALESCE(red_cards_match, 0),
+    extra_time = COALESCE(extra_time, FALSE)
+WHERE goals_match IS NULL OR yellow_cards_match IS NULL OR red_cards_match IS NULL OR extra_time IS NULL;
+
+ALTER TABLE matches
+    ALTER COLUMN goals_match SET NOT NULL,
+    ALTER COLUMN yellow_cards_match SET NOT NULL,
+    ALTER COLUMN red_cards_match SET NOT NULL,
+    ALTER COLUMN extra_time SET NOT NULL,
+    ADD COLUMN IF NOT EXISTS finished BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN IF NOT EXISTS version INT NOT NULL DEFAULT 1,
+    ADD COLUMN IF NOT EXISTS search_vector TSVECTOR GENERATED ALWAYS AS (
+        to_tsvector('es_unaccent', home_team || ' ' || away_team)
+    ) STORED,
+    ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ,
+    ADD COLUMN IF NOT EXISTS clock_period VARCHAR(20),
+    ADD COLUMN IF NOT EXISTS clock_started_at TIMESTAMPTZ,
+    ADD COLUMN IF NOT EXISTS clock_stoppage INT,
+    ADD COLUMN IF NOT EXISTS competition_id INT NOT NULL DEFAULT 1 REFERENCES competitions(id);
+
+/*
+Las versiones anteriores no distinguían los partidos sin hora. Al agregar date_only se marcan los
+que están a medianoche UTC, la única forma en que v1 y las importaciones sin hora los guardaban,
+y se agrega el mismo dato a sus eventos MatchScheduled para que una reconstrucción lo conserve.
+Los partidos registrados después llevan el dato explícito.
+*/
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM information_schema.columns
+                   WHERE table_schema = current_schema() AND table_name = 'matches'
+                     AND column_name = 'date_only') THEN
+        ALTER TABLE matches ADD COLUMN IF NOT EXISTS date_only BOOLEAN NOT NULL DEFAULT FALSE;
+        UPDATE matches SET date_only = TRUE WHERE (match_date AT TIME ZONE 'UTC')::time = '00:00';
+        IF to_regclass('match_events') IS NOT NULL THEN
+            UPDATE match_events
+            SET data = data || '{"dateOnly": true}'
+            WHERE type = 'MatchScheduled' AND ((data->>'matchDate')::timestamptz AT TIME ZONE 'UTC')::time = '00:00';
+        END IF;
+    END IF;
+END
+$$;
+
+CREATE INDEX IF NOT EXISTS idx_matches_search_vector ON matches USING GIN (search_vector);
+
+CREATE INDEX IF NOT EXISTS idx_matches_competition_id ON matches (competition_id, match_date);
+
+/* Índice para listar y purgar la papelera */
+CREATE INDEX IF NOT EXISTS idx_matches_deleted_at ON matches (deleted_at) WHERE deleted_at IS NOT NULL;
+
 /*========================================================================
    Insertar datos iniciales en la tabla "matches"
 ========================================================================*/
 
 /*
-Se insertan 10 registros de ejemplo con partidos de La Liga.
+Se insertan 10 registros de ejemplo con partidos de La Liga, solo si la secuencia de
+"matches" nunca se usó: así no reaparecen al reiniciar después de borrarlos o purgarlos.
+Los equipos usan el nombre canónico de la tabla "teams" (con acentos, como "Atlético Madrid"),
+porque las escrituras y las consultas por equipo comparan contra ese nombre; "Atletico Madrid"
+y "Cadiz" siguen funcionando como alias.
 La fecha debe cumplir con el formato 'YYYY-MM-DD'.
 Los campos de goles, tarjetas y tiempo extra utilizarán los valores por defecto.
 */
-INSERT INTO matches (home_team, away_team, match_date)
-VALUES
+INSERT INTO matches (home_team, away_team, match_date, date_only)
+SELECT home_team, away_team, match_date::date, TRUE
+FROM (VALUES
   ('Barcelona', 'Real Madrid', '2026-04-01'),
-  ('Atletico Madrid', 'Sevilla', '2025-04-02'),
+  ('Atlético Madrid', 'Sevilla', '2025-04-02'),
   ('Valencia', 'Villarreal', '2025-04-03'),
   ('Real Sociedad', 'Athletic Club', '2025-04-04'),
   ('Betis', 'Getafe', '2025-04-05'),
   ('Espanyol', 'Celta de Vigo', '2025-04-06'),
   ('Levante', 'Real Valladolid', '2025-04-07'),
   ('Granada', 'Mallorca', '2025-04-08'),
-  ('Cadiz', 'Elche', '2025-04-09'),
-  ('Almeria', 'Osasuna', '2025-04-10');
+  ('Cádiz', 'Elche', '2025-04-09'),
+  ('Almería', 'Osasuna', '2025-04-10')
+) AS seed(home_team, away_team, match_date)
+WHERE NOT (SELECT is_called FROM matches_id_seq);
+
+/*========================================================================
+   Tabla "match_events"
+========================================================================*/
+
+/*
+Log de eventos de los partidos, del que "matches" es una proyección. El estado de
+cada partido se obtiene aplicando sus eventos en orden de secuencia, por lo que la
+proyección puede reconstruirse en cualquier momento. No tiene llave foránea hacia
+"matches" porque la proyección se deriva del log.
+  - id          : Identificador del evento (BIGSERIAL, PRIMARY KEY)
+  - match_id    : Partido al que pertenece el evento
+  - sequence    : Posición del evento en el log del partido; coincide con su versión
+  - type        : MatchScheduled, GoalScored, CardShown, ExtraTimeStarted, MatchFinished,
+                  MatchDeleted, MatchRestored, TeamsRenamed, PeriodStarted, PeriodEnded o
+                  StoppageTimeAnnounced
+  - data        : Datos del evento (JSONB)
+  - occurred_at : Fecha del evento, usada para reconstruir el partido en un instante (?asOf=)
+  - clock_minute   : Minuto del reloj del partido en que ocurrió el evento; NULL si estaba detenido
+  - clock_stoppage : Minuto de descuento dentro del periodo (por ejemplo 2 en el 45+2)
+  - state       : Fila de "matches" después de aplicar el evento (JSONB), que reciben los streams
+                  sin repetir el log; NULL solo en eventos importados de respaldos anteriores o
+                  registrados antes de que existiera la columna
+  - tx_id       : Transacción que registró el evento. Los streams recorren el log en orden de
+                  (tx_id, id) hasta la transacción más antigua en curso, de modo que no se saltan
+                  eventos confirmados tarde sin necesidad de serializar las escrituras
+*/
+CREATE TABLE IF NOT EXISTS match_events (
+    id BIGSERIAL PRIMARY KEY,
+    match_id INT NOT NULL,
+    sequence INT NOT NULL,
+    type VARCHAR(30) NOT NULL,
+    data JSONB NOT NULL DEFAULT '{}',
+    occurred_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    clock_minute INT,
+    clock_stoppage INT,
+    state JSONB,
+    tx_id XID8 NOT NULL DEFAULT pg_current_xact_id(),
+    UNIQUE (match_id, sequence)
+);
+
+ALTER TABLE match_events
+    ADD COLUMN IF NOT EXISTS clock_minute INT,
+    ADD COLUMN IF NOT EXISTS clock_stoppage INT,
+    ADD COLUMN IF NOT EXISTS state JSONB,
+    ADD COLUMN IF NOT EXISTS tx_id XID8 NOT NULL DEFAULT pg_current_xact_id();
+
+CREATE INDEX IF NOT EXISTS idx_match_events_occurred_at ON match_events (match_id, occurred_at);
+CREATE INDEX IF NOT EXISTS idx_match_events_stream ON match_events (tx_id, id);
+
+/*
+Los partidos sin eventos (los iniciales y los de bases anteriores al log) comienzan su log con
+MatchScheduled seguido de los eventos que reproducen sus contadores, de modo que la proyección
+pueda reconstruirse sin perderlos. La versión del partido pasa a ser la secuencia de su último
+evento, que guarda la fila resultante.
+*/
+WITH pending AS (
+    SELECT m.* FROM matches m
+    WHERE NOT EXISTS (SELECT 1 FROM match_events e WHERE e.match_id = m.id)
+), bootstrap AS (
+    SELECT id AS match_id, 0 AS step,
+           'MatchScheduled' AS type,
+           jsonb_build_object('homeTeam', home_team, 'awayTeam', away_team, 'matchDate', match_date,
+                              'dateOnly', date_only) AS data
+    FROM pending
+    UNION ALL SELECT id, 1, 'GoalScored', '{}' FROM pending, generate_series(1, goals_match)
+    UNION ALL SELECT id, 2, 'CardShown', '{"color": "yellow"}' FROM pending, generate_series(1, yellow_cards_match)
+    UNION ALL SELECT id, 3, 'CardShown', '{"color": "red"}' FROM pending, generate_series(1, red_cards_match)
+    UNION ALL SELECT id, 4, 'ExtraTimeStarted', '{}' FROM pending WHERE extra_time
+    UNION ALL SELECT id, 5, 'MatchFinished', '{}' FROM pending WHERE finished
+    UNION ALL SELECT id, 6, 'MatchDeleted', '{}' FROM pending WHERE deleted_at IS NOT NULL
+)
+INSERT INTO match_events (match_id, sequence, type, data)
+SELECT match_id, row_number() OVER (PARTITION BY match_id ORDER BY step), type, data
+FROM bootstrap;
+
+UPDATE matches m
+SET version = e.sequence
+FROM (SELECT match_id, max(sequence) AS sequence FROM match_events GROUP BY match_id) e
+WHERE e.match_id = m.id AND m.version < e.sequence;
+
+UPDATE match_events e
+SET state = to_jsonb(m) - 'search_vector'
+FROM matches m
+WHERE e.match_id = m.id AND e.sequence = m.version AND e.state IS NULL;
+
+
+/*========================================================================
+   Tabla "idempotency_keys"
+========================================================================*/
+
+/*
+Almacena las claves enviadas en el encabezado Idempotency-Key junto con la
+respuesta original, de modo que un reintento reproduzca la misma respuesta
+sin repetir la escritura. Cada clave se aísla por método y ruta (no por actor,
+porque X-Actor lo envía el cliente) y un reintento solo se reproduce si su
+huella coincide. La escritura la marca en committed_at dentro de su propia
+transacción. Los registros expiran según expires_at.
+  - method          : Método HTTP de la solicitud
+  - path            : Ruta de la solicitud, sin la query
+  - idempotency_key : Clave enviada por el cliente (VARCHAR(255))
+  - fingerprint     : Hash de la query y el cuerpo de la solicitud original (NULL mientras se procesa)
+  - committed_at    : Fecha en que se confirmó la escritura (NULL si todavía no se aplicó)
+  - status_code     : Código HTTP de la respuesta original (NULL mientras se procesa)
+  - headers         : Encabezados relevantes de la respuesta original (JSONB)
+  - body            : Cuerpo de la respuesta original (BYTEA)
+  - created_at      : Fecha de creación del registro
+  - expires_at      : Fecha a partir de la cual la clave deja de ser válida
+*/
+/*
+Las versiones anteriores aislaban las claves solo por clave o también por actor, con otra llave
+primaria; como las claves expiran, se descartan en lugar de convertirlas
+*/
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM information_schema.columns
+                   WHERE table_schema = current_schema() AND table_name = 'idempotency_keys'
+                     AND column_name = 'committed_at')
+       OR EXISTS (SELECT 1 FROM information_schema.columns
+                  WHERE table_schema = current_schema() AND table_name = 'idempotency_keys'
+                    AND column_name = 'actor') THEN
+        DROP TABLE IF EXISTS idempotency_keys;
+    END IF;
+END
+$$;
+
+CREATE TABLE IF NOT EXISTS idempotency_keys (
+    method VARCHAR(10) NOT NULL,
+    path TEXT NOT NULL,
+    idempotency_key VARCHAR(255) NOT NULL,
+    fingerprint VARCHAR(64),
+    committed_at TIMESTAMP,
+    status_code INT,
+    headers JSONB,
+    body BYTEA,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    expires_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (method, path, idempotency_key)
+);
+
+CREATE INDEX IF NOT EXISTS idx_idempotency_keys_expires_at ON idempotency_keys (expires_at);
+
+/*========================================================================
+   Tabla "audit_log"
+========================================================================*/
+
+/*
+Registra cada escritura sobre la tabla "matches". No tiene llave foránea hacia
+"matches" para que el historial se conserve cuando un partido se purga.
+  - id         : Identificador de la entrada (BIGSERIAL, PRIMARY KEY)
+  - match_id   : Partido modificado
+  - actor      : Quién realizó el cambio (X-Actor, IP del cliente o "admin")
+  - request_id : ID de la solicitud (X-Request-ID)
+  - operation  : create, update, delete, restore, increment, merge o purge
+  - before     : Registro completo antes del cambio (NULL si no existía)
+  - after      : Registro completo después del cambio (NULL si se purgó)
+  - diff       : Columnas modificadas con sus valores "before" y "after"
+  - created_at : Fecha del cambio
+*/
+CREATE TABLE IF NOT EXISTS audit_log (
+    id BIGSERIAL PRIMARY KEY,
+    match_id INT NOT NULL,
+    actor VARCHAR(100) NOT NULL,
+    request_id VARCHAR(64) NOT NULL,
+    operation VARCHAR(20) NOT NULL,
+    before JSONB,
+    after JSONB,
+    diff JSONB NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_log_match_id ON audit_log (match_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_audit_log_created_at ON audit_log (created_at);
+
+/*========================================================================
+   Tablas "teams" y "team_aliases"
+========================================================================*/
+
+/*
+Registro de equipos canónicos y de los alias con los que llegan desde otras
+fuentes ("Athletic Bilbao", "Ath Bilbao" -> "Athletic Club"). Todas las
+escrituras de partidos resuelven los nombres contra este registro.
+  - teams.id              : Identificador del equipo (SERIAL, PRIMARY KEY)
+  - teams.name            : Nombre canónico del equipo (VARCHAR(100), UNIQUE)
+  - teams.stadium         : Estadio donde juega de local; NULL si no se conoce (VARCHAR(100))
+  - team_aliases.alias_key: Alias normalizado con team_key() (PRIMARY KEY)
+  - team_aliases.alias    : Alias tal como se registró
+  - team_aliases.team_id  : Equipo al que pertenece el alias
+
+team_key() normaliza un nombre quitando acentos, mayúsculas y espacios repetidos,
+de modo que "Cadiz" y "Cádiz" se consideren el mismo alias. pg_trgm permite
+sugerir equipos parecidos cuando un nombre no se reconoce.
+*/
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+CREATE OR REPLACE FUNCTION team_key(name TEXT) RETURNS TEXT AS $$
+    SELECT regexp_replace(lower(unaccent(trim(name))), '\s+', ' ', 'g')
+$$ LANGUAGE SQL STABLE;
+
+CREATE TABLE IF NOT EXISTS teams (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(100) NOT NULL UNIQUE,
+    stadium VARCHAR(100)
+);
+
+ALTER TABLE teams ADD COLUMN IF NOT EXISTS stadium VARCHAR(100);
+
+CREATE TABLE IF NOT EXISTS team_aliases (
+    alias_key VARCHAR(100) PRIMARY KEY,
+    alias VARCHAR(100) NOT NULL,
+    team_id INT NOT NULL REFERENCES teams(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_team_aliases_trgm ON team_aliases USING GIN (alias_key gin_trgm_ops);
+
+/*
+Equipos canónicos y alias iniciales. Como los partidos, solo se insertan si la secuencia de
+"teams" nunca se usó, para que los equipos unificados o los alias eliminados no reaparezcan.
+*/
+DO $$
+BEGIN
+IF NOT (SELECT is_called FROM teams_id_seq) THEN
+
+/* Equipos canónicos de los partidos iniciales */
+INSERT INTO teams (name)
+VALUES
+  ('Barcelona'), ('Real Madrid'), ('Atlético Madrid'), ('Sevilla'), ('Valencia'),
+  ('Villarreal'), ('Real Sociedad'), ('Athletic Club'), ('Betis'), ('Getafe'),
+  ('Espanyol'), ('Celta de Vigo'), ('Levante'), ('Real Valladolid'), ('Granada'),
+  ('Mallorca'), ('Cádiz'), ('Elche'), ('Almería'), ('Osasuna'),
+  /* Otros equipos de La Liga en las últimas temporadas, para importar resultados históricos */
+  ('Girona'), ('Alavés'), ('Rayo Vallecano'), ('Las Palmas'), ('Leganés'), ('Eibar'),
+  ('Huesca'), ('Málaga'), ('Deportivo La Coruña'), ('Sporting Gijón')
+ON CONFLICT (name) DO NOTHING;
+
+/* Cada nombre canónico es también un alias de su equipo */
+INSERT INTO team_aliases (alias_key, alias, team_id)
+SELECT team_key(name), name, id FROM teams
+ON CONFLICT (alias_key) DO NOTHING;
+
+/* Alias habituales en las fuentes externas */
+INSERT INTO team_aliases (alias_key, alias, team_id)
+SELECT team_key(a.alias), a.alias, t.id
+FROM (VALUES
+  ('FC Barcelona', 'Barcelona'), ('Barça', 'Barcelona'),
+  ('Real Madrid CF', 'Real Madrid'),
+  ('Atlético de Madrid', 'Atlético Madrid'), ('Ath Madrid', 'Atlético Madrid'), ('Atleti', 'Atlético Madrid'),
+  ('Sevilla FC', 'Sevilla'), ('Valencia CF', 'Valencia'), ('Villarreal CF', 'Villarreal'),
+  ('Sociedad', 'Real Sociedad'),
+  ('Athletic Bilbao', 'Athletic Club'), ('Ath Bilbao', 'Athletic Club'),
+  ('Real Betis', 'Betis'),
+  ('RCD Espanyol', 'Espanyol'), ('Espanol', 'Espanyol'),
+  ('Celta', 'Celta de Vigo'), ('Celta Vigo', 'Celta de Vigo'),
+  ('Valladolid', 'Real Valladolid'),
+  ('RCD Mallorca', 'Mallorca'),
+  ('CA Osasuna', 'Osasuna'),
+  ('Vallecano', 'Rayo Vallecano'), ('La Coruna', 'Deportivo La Coruña'), ('Sp Gijon', 'Sporting Gijón')
+) AS a(alias, team)
+JOIN teams t ON t.name = a.team
+ON CONFLICT (alias_key) DO NOTHING;
+
+END IF;
+END
+$$;
+
+/*
+Estadios de los equipos canónicos. Solo se completan los que no tienen estadio, de modo que
+las bases creadas antes de la columna también los reciban.
+*/
+UPDATE teams t
+SET stadium = s.stadium
+FROM (VALUES
+  ('Barcelona', 'Estadi Olímpic Lluís Companys'), ('Real Madrid', 'Santiago Bernabéu'),
+  ('Atlético Madrid', 'Riyadh Air Metropolitano'), ('Sevilla', 'Ramón Sánchez-Pizjuán'),
+  ('Valencia', 'Mestalla'), ('Villarreal', 'Estadio de la Cerámica'), ('Real Sociedad', 'Reale Arena'),
+  ('Athletic Club', 'San Mamés'), ('Betis', 'Benito Villamarín'), ('Getafe', 'Coliseum'),
+  ('Espanyol', 'RCDE Stadium'), ('Celta de Vigo', 'Abanca-Balaídos'), ('Levante', 'Ciutat de València'),
+  ('Real Valladolid', 'José Zorrilla'), ('Granada', 'Nuevo Los Cármenes'), ('Mallorca', 'Son Moix'),
+  ('Cádiz', 'Nuevo Mirandilla'), ('Elche', 'Martínez Valero'), ('Almería', 'Estadio de los Juegos Mediterráneos'),
+  ('Osasuna', 'El Sadar'), ('Girona', 'Montilivi'), ('Alavés', 'Mendizorroza'),
+  ('Rayo Vallecano', 'Estadio de Vallecas'), ('Las Palmas', 'Estadio de Gran Canaria'), ('Leganés', 'Butarque'),
+  ('Eibar', 'Ipurua'), ('Huesca', 'El Alcoraz'), ('Málaga', 'La Rosaleda'),
+  ('Deportivo La Coruña', 'Riazor'), ('Sporting Gijón', 'El Molinón')
+) AS s(name, stadium)
+WHERE t.name = s.name AND t.stadium IS NULL;
+
+/*========================================================================
+   Tablas de webhooks
+========================================================================*/
+/*
+Suscripciones de sitios externos que reciben un POST firmado con HMAC-SHA256
+por cada evento de los partidos.
+  - webhook_subscriptions.url         : URL que recibe los eventos
+  - webhook_subscriptions.event_types : Tipos de evento suscritos; vacío para todos
+  - webhook_subscriptions.secret      : Secreto de la firma X-Webhook-Signature
+  - webhook_subscriptions.active      : Si está desactivada no se generan entregas nuevas
+Cada evento genera una entrega por suscripción. Las entregas fallidas se
+reintentan con backoff exponencial hasta agotar los intentos, momento en el
+que pasan al estado "dead" (dead letter) y solo se reenvían manualmente.
+  - webhook_deliveries.status          : pending, delivered o dead
+  - webhook_deliveries.next_attempt_at : Momento del próximo intento
+webhook_attempts registra cada intento con su respuesta, y webhook_cursor
+guarda el último evento del log para el que ya se generaron entregas.
+webhook_skipped_transactions guarda las transacciones de importación de
+respaldos: sus eventos ya ocurrieron en otra base, por lo que no generan
+entregas. Se eliminan cuando el cursor las supera.
+*/
+CREATE TABLE IF NOT EXISTS webhook_subscriptions (
+    id SERIAL PRIMARY KEY,
+    url TEXT NOT NULL,
+    event_types TEXT[] NOT NULL DEFAULT '{}',
+    secret VARCHAR(128) NOT NULL,
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+    id BIGSERIAL PRIMARY KEY,
+    subscription_id INT NOT NULL REFERENCES webhook_subscriptions(id) ON DELETE CASCADE,
+    event_id BIGINT,
+    event_type VARCHAR(30) NOT NULL,
+    payload JSONB NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    attempts INT NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    delivered_at TIMESTAMPTZ,
+    UNIQUE (subscription_id, event_id)
+);
+
+/* Índice para tomar las entregas pendientes en orden */
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_due ON webhook_deliveries (next_attempt_at) WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_subscription ON webhook_deliveries (subscription_id, id);
+
+CREATE TABLE IF NOT EXISTS webhook_attempts (
+    id BIGSERIAL PRIMARY KEY,
+    delivery_id BIGINT NOT NULL REFERENCES webhook_deliveries(id) ON DELETE CASCADE,
+    attempted_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    status_code INT,
+    error TEXT,
+    response_body TEXT,
+    duration_ms INT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_webhook_attempts_delivery ON webhook_attempts (delivery_id, id);
+
+CREATE TABLE IF NOT EXISTS webhook_cursor (
+    id BOOLEAN PRIMARY KEY DEFAULT TRUE CHECK (id),
+    last_event_id BIGINT NOT NULL
+);
+
+/* Las suscripciones solo reciben los eventos posteriores a la creación de la base */
+INSERT INTO webhook_cursor (last_event_id)
+SELECT COALESCE(max(id), 0) FROM match_events
+ON CONFLICT (id) DO NOTHING;
+
+CREATE TABLE IF NOT EXISTS webhook_skipped_transactions (
+    tx_id XID8 PRIMARY KEY
+);
+
+/*========================================================================
+   Tabla "match_commentary"
+========================================================================*/
+/*
+Comentarios minuto a minuto de los partidos, escritos por los redactores.
+  - minute, stoppage : Minuto del partido al que se refiere el comentario (por ejemplo 45 y 2)
+  - event_id         : Evento del log al que se refiere el comentario, opcional
+  - pinned           : Si el comentario está destacado
+  - author           : Quién escribió el comentario (X-Actor o IP del cliente)
+  - revision         : Número creciente que cambia con cada alta, edición o eliminación
+  - deleted_at       : Fecha de eliminación; NULL si el comentario está publicado
+  - tx_id            : Transacción de la última escritura. El hub de WebSocket recorre los
+                       comentarios por (tx_id, revision), como el log de eventos, para difundir
+                       los nuevos, editados o eliminados sin serializar las escrituras
+*/
+CREATE SEQUENCE IF NOT EXISTS match_commentary_revision_seq;
+
+CREATE TABLE IF NOT EXISTS match_commentary (
+    id SERIAL PRIMARY KEY,
+    match_id INT NOT NULL REFERENCES matches(id) ON DELETE CASCADE,
+    minute INT,
+    stoppage INT,
+    text TEXT NOT NULL,
+    event_id BIGINT,
+    pinned BOOLEAN NOT NULL DEFAULT FALSE,
+    author VARCHAR(100) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    revision BIGINT NOT NULL DEFAULT nextval('match_commentary_revision_seq'),
+    deleted_at TIMESTAMPTZ,
+    tx_id XID8 NOT NULL DEFAULT pg_current_xact_id()
+);
+
+ALTER TABLE match_commentary
+    ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ,
+    ADD COLUMN IF NOT EXISTS tx_id XID8 NOT NULL DEFAULT pg_current_xact_id();
 
+CREATE INDEX IF NOT EXISTS idx_match_commentary_match ON match_commentary (match_id, id DESC);
+CREATE INDEX IF NOT EXISTS idx_match_commentary_revision ON match_commentary (revision);
+CREATE INDEX IF NOT EXISTS idx_match_commentary_tx ON match_commentary (tx_id, revision);
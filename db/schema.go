@@ -0,0 +1,11 @@
+// Package db contiene el script de la base de datos, que la aplicación incluye en el binario
+// para aplicarlo al arrancar.
+package db
+
+import _ "embed"
+
+// InitSQL es el contenido de init.sql. Se puede ejecutar sobre una base vacía o sobre una creada
+// con una versión anterior, a la que agrega las tablas y columnas que le faltan.
+//
+//go:embed init.sql
+var InitSQL string
@@ -18,22 +18,128 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/matches": {
+        "/admin/audit": {
             "get": {
-                "description": "Realiza una consulta a la tabla \"matches\" y retorna una lista de partidos.",
-                "summary": "Obtiene todos los partidos",
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "description": "Retorna las entradas de auditoría que cumplen los filtros, de la más reciente a la más antigua.",
+                "produces": [
+                    "application/json",
+                    "text/csv",
+                    "text/xml",
+                    "application/x-yaml",
+                    "application/x-ndjson"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "Consulta la auditoría",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID del partido",
+                        "name": "matchId",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Actor que realizó el cambio",
+                        "name": "actor",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "create",
+                            "update",
+                            "delete",
+                            "restore",
+                            "increment",
+                            "merge",
+                            "purge",
+                            "clock"
+                        ],
+                        "type": "string",
+                        "description": "Operación",
+                        "name": "operation",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID de la solicitud (X-Request-ID)",
+                        "name": "requestId",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Desde (RFC3339, inclusive)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Hasta (RFC3339, exclusivo)",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Máximo de entradas (1-500)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Entradas a omitir",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "json",
+                            "csv",
+                            "xml",
+                            "yaml",
+                            "ndjson"
+                        ],
+                        "type": "string",
+                        "description": "Formato de la respuesta (alternativa a Accept)",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
                 "responses": {
                     "200": {
-                        "description": "Lista de partidos",
+                        "description": "OK",
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/internal.Match"
+                                "$ref": "#/definitions/internal.AuditEntry"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
                             }
                         }
                     },
                     "500": {
-                        "description": "Error interno",
+                        "description": "Internal Server Error",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -42,30 +148,41 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "post": {
-                "description": "Inserta en la tabla \"matches\" un nuevo registro y retorna su ID.",
-                "summary": "Crea un nuevo partido",
-                "parameters": [
+            }
+        },
+        "/admin/export": {
+            "get": {
+                "security": [
                     {
-                        "description": "Objeto Match sin ID",
-                        "name": "m",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/internal.Match"
-                        }
+                        "AdminToken": []
                     }
                 ],
+                "description": "Genera un archivo ZIP con un JSON por tabla (equipos, alias, partidos, eventos y auditoría) y un\nmanifest.json con la versión del formato, la cantidad de registros y el SHA-256 de cada tabla.\nTodas las tablas se leen en el mismo instante y el archivo se transmite a medida que se genera.",
+                "produces": [
+                    "application/zip"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "Exporta todos los datos",
                 "responses": {
-                    "201": {
-                        "description": "ID del partido creado",
+                    "200": {
+                        "description": "Respaldo en formato ZIP",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
-                            "type": "int"
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
                         }
                     },
                     "500": {
-                        "description": "Error al crear el partido",
+                        "description": "Internal Server Error",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -76,54 +193,73 @@ const docTemplate = `{
                 }
             }
         },
-        "/matches/{id}": {
-            "get": {
-                "description": "Realiza una consulta para obtener un partido específico mediante su ID.",
-                "summary": "Obtiene un partido por ID",
+        "/admin/import": {
+            "post": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "description": "Importa en una única transacción un archivo generado por /admin/export. El parámetro conflict indica\nqué hacer con los registros que ya existen: \"fail\" (por defecto) revierte la importación con 409,\n\"skip\" conserva los existentes, \"overwrite\" los reemplaza y \"replace\" vacía las tablas antes de importar.\nCon skip y overwrite, una llave existente que corresponde a otro registro (por ejemplo otro partido con\nel mismo ID o un evento distinto) responde 409 sin importar nada. La proyección de los partidos\nimportados se reconstruye a partir de su log de eventos en la misma transacción.\nLos eventos importados no generan entregas de webhooks; los eventos anteriores pendientes sí las generan.\nResponde 413 si el archivo supera 100 MB o si su contenido descomprimido supera 1 GB.",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "Restaura un respaldo",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "ID del partido",
-                        "name": "id",
-                        "in": "path",
+                        "type": "file",
+                        "description": "Respaldo en formato ZIP",
+                        "name": "file",
+                        "in": "formData",
                         "required": true
+                    },
+                    {
+                        "enum": [
+                            "fail",
+                            "skip",
+                            "overwrite",
+                            "replace"
+                        ],
+                        "type": "string",
+                        "default": "fail",
+                        "description": "Manejo de registros existentes",
+                        "name": "conflict",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Partido encontrado",
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/internal.Match"
+                            "$ref": "#/definitions/main.datasetImportResponse"
                         }
                     },
-                    "404": {
-                        "description": "Partido no encontrado",
+                    "400": {
+                        "description": "Bad Request",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
                                 "type": "string"
                             }
                         }
-                    }
-                }
-            },
-            "put": {
-                "description": "Actualiza los campos home_team, away_team y match_date para un partido dado.",
-                "summary": "Actualiza un partido",
-                "parameters": [
-                    {
-                        "description": "Objeto Match con ID y datos actualizados",
-                        "name": "m",
-                        "in": "body",
-                        "required": true,
+                    },
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
-                            "$ref": "#/definitions/internal.Match"
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
                         }
-                    }
-                ],
-                "responses": {
-                    "200": {
-                        "description": "Partido actualizado correctamente",
+                    },
+                    "409": {
+                        "description": "Conflict",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -131,32 +267,17 @@ const docTemplate = `{
                             }
                         }
                     },
-                    "500": {
-                        "description": "Error al actualizar el partido",
+                    "413": {
+                        "description": "Request Entity Too Large",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
                                 "type": "string"
                             }
                         }
-                    }
-                }
-            },
-            "delete": {
-                "description": "Elimina el registro de la tabla \"matches\" correspondiente al ID proporcionado.",
-                "summary": "Elimina un partido",
-                "parameters": [
-                    {
-                        "type": "integer",
-                        "description": "ID del partido",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    }
-                ],
-                "responses": {
-                    "200": {
-                        "description": "Partido eliminado",
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -165,7 +286,7 @@ const docTemplate = `{
                         }
                     },
                     "500": {
-                        "description": "Error al eliminar el partido",
+                        "description": "Internal Server Error",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -176,22 +297,30 @@ const docTemplate = `{
                 }
             }
         },
-        "/matches/{id}/extratime": {
-            "patch": {
-                "description": "Establece el valor de \"extra_time\" en TRUE para el partido especificado.",
-                "summary": "Activa tiempo extra",
-                "parameters": [
+        "/admin/projections/rebuild": {
+            "post": {
+                "security": [
                     {
-                        "type": "integer",
-                        "description": "ID del partido",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "AdminToken": []
                     }
                 ],
+                "description": "Repite el log completo de eventos y reescribe la tabla de partidos a partir de él.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "Reconstruye las proyecciones",
                 "responses": {
                     "200": {
-                        "description": "Tiempo extra establecido correctamente",
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.projectionRebuildResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -200,7 +329,7 @@ const docTemplate = `{
                         }
                     },
                     "500": {
-                        "description": "Error al establecer tiempo extra",
+                        "description": "Internal Server Error",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -211,22 +340,62 @@ const docTemplate = `{
                 }
             }
         },
-        "/matches/{id}/goals": {
-            "patch": {
-                "description": "Incrementa el valor de \"goals_match\" en 1 para el partido especificado.",
-                "summary": "Incrementa goles del partido",
+        "/admin/teams": {
+            "post": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "description": "Registra un equipo canónico y, opcionalmente, su estadio y sus alias.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "Registra un equipo",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "ID del partido",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "Nombre canónico, estadio y alias",
+                        "name": "team",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/main.teamInput"
+                        }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "Gol incrementado correctamente",
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/internal.Team"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -235,7 +404,7 @@ const docTemplate = `{
                         }
                     },
                     "500": {
-                        "description": "Error al incrementar goles",
+                        "description": "Internal Server Error",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -246,22 +415,70 @@ const docTemplate = `{
                 }
             }
         },
-        "/matches/{id}/redcards": {
-            "patch": {
-                "description": "Incrementa el valor de \"red_cards_match\" en 1 para el partido especificado.",
-                "summary": "Incrementa tarjetas rojas",
+        "/admin/teams/merge": {
+            "post": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "description": "Registra los nombres duplicados como alias del equipo destino (creándolo si no existe),\nabsorbe los equipos registrados con esos nombres y reescribe los partidos que los usan.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "text/csv",
+                    "text/xml",
+                    "application/x-yaml",
+                    "application/x-ndjson"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "Unifica nombres de equipos duplicados",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "ID del partido",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "Destino y duplicados",
+                        "name": "merge",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/main.teamMergeInput"
+                        }
+                    },
+                    {
+                        "enum": [
+                            "json",
+                            "csv",
+                            "xml",
+                            "yaml",
+                            "ndjson"
+                        ],
+                        "type": "string",
+                        "description": "Formato de la respuesta (alternativa a Accept)",
+                        "name": "format",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Tarjeta roja incrementada correctamente",
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal.TeamMergeResult"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -270,7 +487,7 @@ const docTemplate = `{
                         }
                     },
                     "500": {
-                        "description": "Error al incrementar tarjeta roja",
+                        "description": "Internal Server Error",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -281,22 +498,95 @@ const docTemplate = `{
                 }
             }
         },
-        "/matches/{id}/yellowcards": {
-            "patch": {
-                "description": "Incrementa el valor de \"yellow_cards_match\" en 1 para el partido especificado.",
-                "summary": "Incrementa tarjetas amarillas",
+        "/admin/teams/{id}/aliases": {
+            "post": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "description": "Registra un alias para el equipo indicado. Falla si el alias ya pertenece a otro equipo.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "text/csv",
+                    "text/xml",
+                    "application/x-yaml",
+                    "application/x-ndjson"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "Agrega un alias a un equipo",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "ID del partido",
+                        "description": "ID del equipo",
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "description": "Alias",
+                        "name": "alias",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/main.teamAliasInput"
+                        }
+                    },
+                    {
+                        "enum": [
+                            "json",
+                            "csv",
+                            "xml",
+                            "yaml",
+                            "ndjson"
+                        ],
+                        "type": "string",
+                        "description": "Formato de la respuesta (alternativa a Accept)",
+                        "name": "format",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Tarjeta amarilla incrementada correctamente",
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal.Team"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -305,7 +595,7 @@ const docTemplate = `{
                         }
                     },
                     "500": {
-                        "description": "Error al incrementar tarjeta amarilla",
+                        "description": "Internal Server Error",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -315,27 +605,4763 @@ const docTemplate = `{
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "internal.Match": {
-            "description": "Objeto que modela un partido, incluyendo equipos y fecha.",
-            "type": "object",
-            "properties": {
+        },
+        "/admin/trash": {
+            "delete": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "description": "Elimina definitivamente los partidos que llevan en la papelera más que el período de retención.\nPor defecto se usa TRASH_RETENTION (720h si no está definido).",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "Purga la papelera",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Período de retención en formato de duración de Go (por ejemplo 720h)",
+                        "name": "olderThan",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.trashPurgeResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/webhooks": {
+            "get": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "description": "Retorna todas las suscripciones, sin sus secretos.",
+                "produces": [
+                    "application/json",
+                    "text/csv",
+                    "text/xml",
+                    "application/x-yaml",
+                    "application/x-ndjson"
+                ],
+                "tags": [
+                    "Webhooks"
+                ],
+                "summary": "Lista las suscripciones de webhooks",
+                "parameters": [
+                    {
+                        "enum": [
+                            "json",
+                            "csv",
+                            "xml",
+                            "yaml",
+                            "ndjson"
+                        ],
+                        "type": "string",
+                        "description": "Formato de la respuesta (alternativa a Accept)",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/internal.WebhookSubscription"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "description": "Registra una URL que recibirá un POST por cada evento de los tipos indicados (todos si eventTypes está vacío).\nCada entrega se firma con HMAC-SHA256 en X-Webhook-Signature (\"sha256=\" + hex de HMAC(secret, timestamp + \".\" + body)),\ncon el timestamp en X-Webhook-Timestamp. Si no se envía secret se genera uno; solo se muestra en esta respuesta.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Webhooks"
+                ],
+                "summary": "Crea una suscripción de webhook",
+                "parameters": [
+                    {
+                        "description": "Datos de la suscripción",
+                        "name": "webhook",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/main.webhookInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/internal.WebhookSubscription"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/webhooks/dead-letters": {
+            "get": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "description": "Retorna las entregas de todas las suscripciones que agotaron sus reintentos, con su registro de intentos.",
+                "produces": [
+                    "application/json",
+                    "text/csv",
+                    "text/xml",
+                    "application/x-yaml",
+                    "application/x-ndjson"
+                ],
+                "tags": [
+                    "Webhooks"
+                ],
+                "summary": "Lista de entregas muertas",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Máximo de entregas (1-500)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Entregas a omitir",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "json",
+                            "csv",
+                            "xml",
+                            "yaml",
+                            "ndjson"
+                        ],
+                        "type": "string",
+                        "description": "Formato de la respuesta (alternativa a Accept)",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/internal.WebhookDelivery"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/webhooks/deliveries/{deliveryId}/redeliver": {
+            "post": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "description": "Vuelve a poner en cola una entrega muerta o ya entregada para enviarla de inmediato, con un nuevo ciclo de reintentos.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Webhooks"
+                ],
+                "summary": "Reenvía una entrega",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID de la entrega",
+                        "name": "deliveryId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/webhooks/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "produces": [
+                    "application/json",
+                    "text/csv",
+                    "text/xml",
+                    "application/x-yaml",
+                    "application/x-ndjson"
+                ],
+                "tags": [
+                    "Webhooks"
+                ],
+                "summary": "Obtiene una suscripción de webhook",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID de la suscripción",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "json",
+                            "csv",
+                            "xml",
+                            "yaml",
+                            "ndjson"
+                        ],
+                        "type": "string",
+                        "description": "Formato de la respuesta (alternativa a Accept)",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal.WebhookSubscription"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "description": "Elimina la suscripción junto con sus entregas pendientes y su registro.",
+                "tags": [
+                    "Webhooks"
+                ],
+                "summary": "Elimina una suscripción de webhook",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID de la suscripción",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/webhooks/{id}/deliveries": {
+            "get": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "description": "Retorna las entregas de la suscripción, de la más reciente a la más antigua, con cada intento y su respuesta.",
+                "produces": [
+                    "application/json",
+                    "text/csv",
+                    "text/xml",
+                    "application/x-yaml",
+                    "application/x-ndjson"
+                ],
+                "tags": [
+                    "Webhooks"
+                ],
+                "summary": "Registro de entregas de una suscripción",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID de la suscripción",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "pending",
+                            "delivered",
+                            "dead"
+                        ],
+                        "type": "string",
+                        "description": "Estado de la entrega",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Máximo de entregas (1-500)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Entregas a omitir",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "json",
+                            "csv",
+                            "xml",
+                            "yaml",
+                            "ndjson"
+                        ],
+                        "type": "string",
+                        "description": "Formato de la respuesta (alternativa a Accept)",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/internal.WebhookDelivery"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/webhooks/{id}/test": {
+            "post": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "description": "Envía de inmediato un evento \"Ping\" firmado a la URL de la suscripción y retorna el resultado del intento.\nLa entrega de prueba queda en el registro y no se reintenta; si falla pasa a la lista de entregas muertas.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Webhooks"
+                ],
+                "summary": "Envía una entrega de prueba",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID de la suscripción",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "El receptor aceptó la entrega",
+                        "schema": {
+                            "$ref": "#/definitions/internal.WebhookAttempt"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "502": {
+                        "description": "El receptor rechazó la entrega o no respondió",
+                        "schema": {
+                            "$ref": "#/definitions/internal.WebhookAttempt"
+                        }
+                    }
+                }
+            }
+        },
+        "/batch": {
+            "post": {
+                "description": "Ejecuta en orden y dentro de una única transacción una lista de operaciones create, update, delete e increment.\nEn modo \"atomic\" (por defecto) cualquier falla revierte todo el lote; en modo \"bestEffort\" solo se revierte la operación fallida.\nLas operaciones distintas de create requieren \"id\" y \"version\" del partido.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Batch"
+                ],
+                "summary": "Ejecuta un lote de operaciones",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Clave para reintentar la solicitud sin repetir la escritura",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Operaciones del lote",
+                        "name": "batch",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/main.batchRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Lote confirmado",
+                        "schema": {
+                            "$ref": "#/definitions/main.batchResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "422": {
+                        "description": "Lote revertido",
+                        "schema": {
+                            "$ref": "#/definitions/main.batchResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/calendar": {
+            "get": {
+                "description": "Retorna los partidos del mes (?month=2025-04) o de la semana ISO (?week=2025-W14) agrupados por día,\ncon la cantidad de partidos de cada día. Los días se calculan en la zona horaria indicada en ?tz=\n(por defecto UTC), de modo que un partido nocturno aparece en la fecha local correcta.\nLos partidos registrados sin hora (como los de v1) conservan su fecha en cualquier zona horaria; un partido\nde v2 a medianoche UTC tiene hora y se muestra en la fecha local.",
+                "produces": [
+                    "application/json",
+                    "text/csv",
+                    "text/xml",
+                    "application/x-yaml",
+                    "application/x-ndjson"
+                ],
+                "tags": [
+                    "Calendar"
+                ],
+                "summary": "Calendario de partidos por mes o semana",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Mes con formato YYYY-MM",
+                        "name": "month",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Semana ISO con formato YYYY-Www",
+                        "name": "week",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Zona horaria IANA, por ejemplo Europe/Madrid",
+                        "name": "tz",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "json",
+                            "csv",
+                            "xml",
+                            "yaml",
+                            "ndjson"
+                        ],
+                        "type": "string",
+                        "description": "Formato de la respuesta (alternativa a Accept)",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.calendarResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/competitions": {
+            "get": {
+                "description": "Retorna las competiciones registradas. Los partidos pertenecen a La Liga (ID 1) salvo que se indique otra.",
+                "produces": [
+                    "application/json",
+                    "text/csv",
+                    "text/xml",
+                    "application/x-yaml",
+                    "application/x-ndjson"
+                ],
+                "tags": [
+                    "Competitions"
+                ],
+                "summary": "Obtiene todas las competiciones",
+                "parameters": [
+                    {
+                        "enum": [
+                            "json",
+                            "csv",
+                            "xml",
+                            "yaml",
+                            "ndjson"
+                        ],
+                        "type": "string",
+                        "description": "Formato de la respuesta (alternativa a Accept)",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/internal.Competition"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/competitions/{id}": {
+            "get": {
+                "description": "Retorna el nombre y el país de la competición.",
+                "produces": [
+                    "application/json",
+                    "text/csv",
+                    "text/xml",
+                    "application/x-yaml",
+                    "application/x-ndjson"
+                ],
+                "tags": [
+                    "Competitions"
+                ],
+                "summary": "Obtiene una competición por ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID de la competición",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "json",
+                            "csv",
+                            "xml",
+                            "yaml",
+                            "ndjson"
+                        ],
+                        "type": "string",
+                        "description": "Formato de la respuesta (alternativa a Accept)",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal.Competition"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/competitions/{id}/calendar.ics": {
+            "get": {
+                "description": "Retorna un feed .ics (RFC 5545) con todos los partidos de la competición, con el mismo formato que el calendario de un equipo.\nUn partido conserva su UID en ambos feeds, por lo que suscribirse a los dos no lo duplica en clientes que combinan calendarios.",
+                "produces": [
+                    "text/calendar"
+                ],
+                "tags": [
+                    "Competitions"
+                ],
+                "summary": "Calendario iCalendar de una competición",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID de la competición",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Calendario iCalendar",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/export/football-data": {
+            "get": {
+                "description": "Genera un CSV con las columnas Div, Date, Time, HomeTeam, AwayTeam, FTHG, FTAG, FTR, HY, AY, HR y AR.\nLos partidos sin finalizar se exportan sin resultado. Los goles y tarjetas por equipo se obtienen del log\nde eventos: si alguno se registró sin indicar el equipo (por ejemplo con PATCH /matches/{id}/goals),\nlas columnas de esa estadística quedan vacías. Date y Time están en hora del Reino Unido.",
+                "produces": [
+                    "text/csv"
+                ],
+                "tags": [
+                    "Import"
+                ],
+                "summary": "Exporta partidos en el formato de football-data.co.uk",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Temporada, por su año de inicio (2024 es la 2024-25, de julio a junio)",
+                        "name": "season",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "default": "SP1",
+                        "description": "Código de la columna Div",
+                        "name": "division",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "CSV de football-data.co.uk",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/graphql": {
+            "get": {
+                "description": "Alternativa a POST para queries; las mutaciones responden 405. Con Upgrade: websocket abre una conexión\ncon el subprotocolo graphql-transport-ws, por la que se envían queries, mutaciones y suscripciones.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "GraphQL"
+                ],
+                "summary": "Ejecuta una query GraphQL por GET o abre una suscripción",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Documento GraphQL (obligatorio salvo al abrir una conexión WebSocket)",
+                        "name": "query",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Operación a ejecutar si el documento tiene varias",
+                        "name": "operationName",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Variables en JSON",
+                        "name": "variables",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.graphqlResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/main.graphqlResponse"
+                        }
+                    },
+                    "405": {
+                        "description": "Method Not Allowed",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Ejecuta una query o una mutación sobre partidos, equipos, temporadas y eventos. El esquema se obtiene\npor introspección. Los errores de los resolvers incluyen el código del catálogo en extensions.code.\nLas mutaciones (createMatch, updateMatch, recordStat, finishMatch) reciben la versión del partido como\nargumento en lugar de If-Match; si no coincide, el error incluye extensions.currentVersion.\nLas suscripciones (matchChanged) se sirven por WebSocket en GET /api/graphql. La misma API se sirve también en /graphql.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "GraphQL"
+                ],
+                "summary": "Ejecuta una operación GraphQL",
+                "parameters": [
+                    {
+                        "description": "Operación GraphQL",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/main.graphqlRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Autor de las mutaciones registrado en la auditoría",
+                        "name": "X-Actor",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Clave para reintentar la solicitud sin repetir la escritura",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.graphqlResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/main.graphqlResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/import/matches": {
+            "post": {
+                "description": "Recibe un archivo CSV o XLSX con una fila de encabezado y crea un partido por fila en una única transacción:\nsi alguna fila es inválida no se importa ninguna. Cada fila se valida (fecha, equipos conocidos y\npartidos duplicados en la base o en el mismo archivo) y la respuesta incluye un reporte por fila.\nCon ?dryRun=true solo se valida, sin escribir. Los nombres de las columnas se configuran con\nhomeTeamColumn, awayTeamColumn y matchDateColumn. Con format=football-data se lee el CSV de\nfootball-data.co.uk (Date, Time, HomeTeam, AwayTeam, FTHG, FTAG, HY, AY, HR, AR) y las filas con\nresultado se importan finalizadas, con cada gol y tarjeta atribuido a su equipo.",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Import"
+                ],
+                "summary": "Importa partidos desde CSV o XLSX",
+                "parameters": [
+                    {
+                        "type": "boolean",
+                        "description": "Solo valida el archivo y retorna el reporte",
+                        "name": "dryRun",
+                        "in": "query"
+                    },
+                    {
+                        "type": "file",
+                        "description": "Archivo CSV o XLSX",
+                        "name": "file",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "csv",
+                            "xlsx",
+                            "football-data"
+                        ],
+                        "type": "string",
+                        "description": "Formato del archivo; por defecto se deduce de la extensión",
+                        "name": "format",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "default": "homeTeam",
+                        "description": "Columna del equipo local",
+                        "name": "homeTeamColumn",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "default": "awayTeam",
+                        "description": "Columna del equipo visitante",
+                        "name": "awayTeamColumn",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "default": "matchDate",
+                        "description": "Columna de la fecha",
+                        "name": "matchDateColumn",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "default": "YYYY-MM-DD",
+                        "description": "Formato de la fecha con YYYY, YY, MM, DD, HH, mm y ss",
+                        "name": "dateFormat",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Separador del CSV; por defecto se detecta entre coma, punto y coma y tabulación",
+                        "name": "delimiter",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Hoja del XLSX; por defecto la primera",
+                        "name": "sheet",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Clave para reintentar la solicitud sin repetir la escritura",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Importación confirmada o validación (dry-run)",
+                        "schema": {
+                            "$ref": "#/definitions/main.importResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "422": {
+                        "description": "Hay filas inválidas, no se importó ninguna",
+                        "schema": {
+                            "$ref": "#/definitions/main.importResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/matches": {
+            "get": {
+                "description": "Retorna todos los partidos almacenados en la base de datos.",
+                "produces": [
+                    "application/json",
+                    "text/csv",
+                    "text/xml",
+                    "application/x-yaml",
+                    "application/x-ndjson"
+                ],
+                "tags": [
+                    "Matches"
+                ],
+                "summary": "Obtiene todos los partidos",
+                "parameters": [
+                    {
+                        "enum": [
+                            "json",
+                            "csv",
+                            "xml",
+                            "yaml",
+                            "ndjson"
+                        ],
+                        "type": "string",
+                        "description": "Formato de la respuesta (alternativa a Accept)",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/internal.Match"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Crea un partido nuevo a partir de los datos enviados en el body.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Matches"
+                ],
+                "summary": "Crea un nuevo partido",
+                "parameters": [
+                    {
+                        "description": "Datos del partido",
+                        "name": "match",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/main.matchInput"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Clave para reintentar la solicitud sin repetir la escritura",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "ID del partido creado",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "integer"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/matches/stream": {
+            "get": {
+                "description": "Server-Sent Events con cada cambio de cualquier partido (goles, tarjetas, tiempo extra, reprogramaciones,\nfinalización, eliminación y restauración). El nombre de cada evento SSE es el tipo del evento del log\n(GoalScored, CardShown, ...) y sus datos incluyen el partido resultante. El ID de cada evento permite\nreanudar con Last-Event-ID (o ?lastEventId= en la primera conexión) sin perder cambios.",
+                "produces": [
+                    "text/event-stream"
+                ],
+                "tags": [
+                    "Stream"
+                ],
+                "summary": "Stream de eventos de todos los partidos",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID del último evento recibido",
+                        "name": "Last-Event-ID",
+                        "in": "header"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID del último evento recibido, si no se puede enviar el encabezado",
+                        "name": "lastEventId",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Un evento por cambio",
+                        "schema": {
+                            "$ref": "#/definitions/main.streamEvent"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/matches/{id}": {
+            "get": {
+                "description": "Retorna el partido cuyo ID se especifica en la ruta junto con su ETag.\nCon ?asOf= retorna el partido reconstruido a partir de sus eventos hasta ese instante, sin ETag.",
+                "produces": [
+                    "application/json",
+                    "text/csv",
+                    "text/xml",
+                    "application/x-yaml",
+                    "application/x-ndjson"
+                ],
+                "tags": [
+                    "Matches"
+                ],
+                "summary": "Obtiene un partido por ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID del partido",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Instante (RFC3339) en el que se consulta el partido",
+                        "name": "asOf",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "json",
+                            "csv",
+                            "xml",
+                            "yaml",
+                            "ndjson"
+                        ],
+                        "type": "string",
+                        "description": "Formato de la respuesta (alternativa a Accept)",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal.Match"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Versión actual del partido"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Actualiza los datos de un partido existente usando el ID de la ruta.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Matches"
+                ],
+                "summary": "Actualiza un partido existente",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID del partido",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag obtenido al consultar el partido",
+                        "name": "If-Match",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Datos del partido",
+                        "name": "match",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/main.matchInput"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Clave para reintentar la solicitud sin repetir la escritura",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Mensaje de éxito",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "412": {
+                        "description": "Versión desactualizada, se retorna la actual",
+                        "schema": {
+                            "$ref": "#/definitions/internal.Match"
+                        }
+                    },
+                    "428": {
+                        "description": "Precondition Required",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Envía a la papelera el partido según el ID proporcionado. Puede restaurarse con POST /matches/{id}/restore.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Matches"
+                ],
+                "summary": "Elimina un partido",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID del partido",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag obtenido al consultar el partido",
+                        "name": "If-Match",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Clave para reintentar la solicitud sin repetir la escritura",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Mensaje de éxito",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "412": {
+                        "description": "Versión desactualizada, se retorna la actual",
+                        "schema": {
+                            "$ref": "#/definitions/internal.Match"
+                        }
+                    },
+                    "428": {
+                        "description": "Precondition Required",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/matches/{id}/clock": {
+            "get": {
+                "description": "Retorna el periodo en curso y el minuto actual calculado por el servidor (por ejemplo \"45+2'\"),\njunto con el descuento anunciado, el inicio del periodo y la hora del servidor para que el cliente\npueda avanzar el reloj localmente. Fuera de juego display es \"HT\" en el descanso y \"FT\" al finalizar.",
+                "produces": [
+                    "application/json",
+                    "text/csv",
+                    "text/xml",
+                    "application/x-yaml",
+                    "application/x-ndjson"
+                ],
+                "tags": [
+                    "Clock"
+                ],
+                "summary": "Obtiene el reloj de un partido",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID del partido",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "json",
+                            "csv",
+                            "xml",
+                            "yaml",
+                            "ndjson"
+                        ],
+                        "type": "string",
+                        "description": "Formato de la respuesta (alternativa a Accept)",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal.MatchClock"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Registra un evento del reloj: \"start\" inicia el periodo indicado, que debe ser el siguiente\n(first_half es el saque inicial; extra_time_first marca el tiempo extra), \"end\" termina el periodo en curso\ny \"stoppage\" anuncia los minutos de descuento (1-30). El pitazo final se registra con POST /v2/matches/{id}/finish.\nLos goles y tarjetas registrados con el reloj en marcha quedan con su minuto en el log de eventos.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Clock"
+                ],
+                "summary": "Controla el reloj de un partido",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID del partido",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag obtenido al consultar el partido",
+                        "name": "If-Match",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Clave para reintentar la solicitud sin repetir la escritura",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Acción sobre el reloj",
+                        "name": "clock",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/main.clockInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal.MatchClock"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Nueva versión del partido"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "El partido finalizó, el reloj no está en el estado requerido o el periodo no es el siguiente",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "412": {
+                        "description": "Versión desactualizada, se retorna la actual",
+                        "schema": {
+                            "$ref": "#/definitions/internal.Match"
+                        }
+                    },
+                    "428": {
+                        "description": "Precondition Required",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/matches/{id}/commentary": {
+            "get": {
+                "description": "Retorna los comentarios minuto a minuto del partido, del más reciente al más antiguo.\nEl total de comentarios que cumplen el filtro se informa en X-Total-Count.",
+                "produces": [
+                    "application/json",
+                    "text/csv",
+                    "text/xml",
+                    "application/x-yaml",
+                    "application/x-ndjson"
+                ],
+                "tags": [
+                    "Commentary"
+                ],
+                "summary": "Obtiene los comentarios de un partido",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID del partido",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Solo los comentarios destacados",
+                        "name": "pinned",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Máximo de comentarios (1-200, por defecto 20)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Comentarios a omitir",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "json",
+                            "csv",
+                            "xml",
+                            "yaml",
+                            "ndjson"
+                        ],
+                        "type": "string",
+                        "description": "Formato de la respuesta (alternativa a Accept)",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/internal.CommentaryEntry"
+                            }
+                        },
+                        "headers": {
+                            "X-Total-Count": {
+                                "type": "integer",
+                                "description": "Cantidad total de comentarios"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Agrega un comentario al partido. Si no se indica minute se usa el minuto del evento enlazado\nen eventId o, si no hay evento, el minuto actual del reloj del partido. Los suscriptores de\n/ws reciben el comentario en un mensaje {\"type\": \"commentary\"}.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Commentary"
+                ],
+                "summary": "Publica un comentario",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID del partido",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Autor del comentario (por defecto la IP del cliente)",
+                        "name": "X-Actor",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Clave para reintentar la solicitud sin repetir la escritura",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Comentario",
+                        "name": "commentary",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/main.commentaryInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/internal.CommentaryEntry"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/matches/{id}/commentary/{entryId}": {
+            "delete": {
+                "description": "Quita el comentario del listado del partido. Los suscriptores de /ws reciben el comentario con deletedAt.",
+                "tags": [
+                    "Commentary"
+                ],
+                "summary": "Elimina un comentario",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID del partido",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID del comentario",
+                        "name": "entryId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Clave para reintentar la solicitud sin repetir la escritura",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "description": "Modifica el texto, el minuto, el evento enlazado o el destacado de un comentario. Los campos\nomitidos no cambian. Los suscriptores de /ws reciben el comentario editado con su nueva revisión.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Commentary"
+                ],
+                "summary": "Edita un comentario",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID del partido",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID del comentario",
+                        "name": "entryId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Clave para reintentar la solicitud sin repetir la escritura",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Cambios del comentario",
+                        "name": "commentary",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/main.commentaryPatchInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal.CommentaryEntry"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/matches/{id}/events": {
+            "get": {
+                "description": "Retorna en orden los eventos del partido (MatchScheduled, GoalScored, CardShown, ExtraTimeStarted,\nMatchFinished, MatchDeleted, MatchRestored, TeamsRenamed, PeriodStarted, PeriodEnded, StoppageTimeAnnounced).\nAplicarlos en orden reproduce su estado actual. Los eventos ocurridos con el reloj en marcha incluyen su minuto en clock.",
+                "produces": [
+                    "application/json",
+                    "text/csv",
+                    "text/xml",
+                    "application/x-yaml",
+                    "application/x-ndjson"
+                ],
+                "tags": [
+                    "Events"
+                ],
+                "summary": "Obtiene el log de eventos de un partido",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID del partido",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "json",
+                            "csv",
+                            "xml",
+                            "yaml",
+                            "ndjson"
+                        ],
+                        "type": "string",
+                        "description": "Formato de la respuesta (alternativa a Accept)",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/internal.MatchEvent"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/matches/{id}/extratime": {
+            "patch": {
+                "description": "Activa el campo extra_time (lo establece en TRUE) para el partido especificado.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Matches"
+                ],
+                "summary": "Establece tiempo extra para el partido",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID del partido",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag obtenido al consultar el partido",
+                        "name": "If-Match",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Clave para reintentar la solicitud sin repetir la escritura",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Mensaje de éxito",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "El partido ya finalizó",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "412": {
+                        "description": "Versión desactualizada, se retorna la actual",
+                        "schema": {
+                            "$ref": "#/definitions/internal.Match"
+                        }
+                    },
+                    "428": {
+                        "description": "Precondition Required",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/matches/{id}/goals": {
+            "patch": {
+                "description": "Incrementa en 1 el campo goals_match para el partido especificado.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Matches"
+                ],
+                "summary": "Incrementa los goles del partido",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID del partido",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag obtenido al consultar el partido",
+                        "name": "If-Match",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Clave para reintentar la solicitud sin repetir la escritura",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Mensaje de éxito",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "El partido ya finalizó",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "412": {
+                        "description": "Versión desactualizada, se retorna la actual",
+                        "schema": {
+                            "$ref": "#/definitions/internal.Match"
+                        }
+                    },
+                    "428": {
+                        "description": "Precondition Required",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/matches/{id}/history": {
+            "get": {
+                "description": "Retorna los cambios registrados del partido, del más antiguo al más reciente, con el actor,\nla operación, el ID de solicitud y el diff de cada cambio. Incluye partidos en la papelera o purgados.",
+                "produces": [
+                    "application/json",
+                    "text/csv",
+                    "text/xml",
+                    "application/x-yaml",
+                    "application/x-ndjson"
+                ],
+                "tags": [
+                    "Audit"
+                ],
+                "summary": "Obtiene el historial de un partido",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID del partido",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "json",
+                            "csv",
+                            "xml",
+                            "yaml",
+                            "ndjson"
+                        ],
+                        "type": "string",
+                        "description": "Formato de la respuesta (alternativa a Accept)",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/internal.AuditEntry"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/matches/{id}/redcards": {
+            "patch": {
+                "description": "Incrementa en 1 el campo red_cards_matchs para el partido especificado.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Matches"
+                ],
+                "summary": "Incrementa las tarjetas rojas del partido",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID del partido",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag obtenido al consultar el partido",
+                        "name": "If-Match",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Clave para reintentar la solicitud sin repetir la escritura",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Mensaje de éxito",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "El partido ya finalizó",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "412": {
+                        "description": "Versión desactualizada, se retorna la actual",
+                        "schema": {
+                            "$ref": "#/definitions/internal.Match"
+                        }
+                    },
+                    "428": {
+                        "description": "Precondition Required",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/matches/{id}/restore": {
+            "post": {
+                "description": "Saca el partido de la papelera si su versión coincide con If-Match y retorna su representación actual.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Trash"
+                ],
+                "summary": "Restaura un partido de la papelera",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID del partido",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag del partido según la papelera",
+                        "name": "If-Match",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Clave para reintentar la solicitud sin repetir la escritura",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal.Match"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "El partido no está en la papelera",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "428": {
+                        "description": "Precondition Required",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/matches/{id}/stream": {
+            "get": {
+                "description": "Server-Sent Events con cada cambio del partido indicado, con el mismo formato y reanudación que /matches/stream.",
+                "produces": [
+                    "text/event-stream"
+                ],
+                "tags": [
+                    "Stream"
+                ],
+                "summary": "Stream de eventos de un partido",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID del partido",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID del último evento recibido",
+                        "name": "Last-Event-ID",
+                        "in": "header"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID del último evento recibido, si no se puede enviar el encabezado",
+                        "name": "lastEventId",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Un evento por cambio",
+                        "schema": {
+                            "$ref": "#/definitions/main.streamEvent"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/matches/{id}/yellowcards": {
+            "patch": {
+                "description": "Incrementa en 1 el campo yellow_cards_match para el partido especificado.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Matches"
+                ],
+                "summary": "Incrementa las tarjetas amarillas del partido",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID del partido",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag obtenido al consultar el partido",
+                        "name": "If-Match",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Clave para reintentar la solicitud sin repetir la escritura",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Mensaje de éxito",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "El partido ya finalizó",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "412": {
+                        "description": "Versión desactualizada, se retorna la actual",
+                        "schema": {
+                            "$ref": "#/definitions/internal.Match"
+                        }
+                    },
+                    "428": {
+                        "description": "Precondition Required",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/search": {
+            "get": {
+                "description": "Busca sin distinguir acentos ni mayúsculas en equipos, partidos y estadios. Cada término se trata como prefijo.\nLos resultados se agrupan por tipo de recurso y se ordenan por relevancia: coincidencia de términos más similitud con el texto buscado.\nCada estadio se informa con el ID del equipo que juega de local en él. La API no tiene jugadores, por lo que no se incluyen en la búsqueda.",
+                "produces": [
+                    "application/json",
+                    "text/csv",
+                    "text/xml",
+                    "application/x-yaml",
+                    "application/x-ndjson"
+                ],
+                "tags": [
+                    "Search"
+                ],
+                "summary": "Búsqueda de texto completo",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Texto a buscar",
+                        "name": "q",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Máximo de resultados por tipo de recurso (1-50)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "json",
+                            "csv",
+                            "xml",
+                            "yaml",
+                            "ndjson"
+                        ],
+                        "type": "string",
+                        "description": "Formato de la respuesta (alternativa a Accept)",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.searchResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/teams": {
+            "get": {
+                "description": "Retorna los equipos canónicos junto con sus alias.",
+                "produces": [
+                    "application/json",
+                    "text/csv",
+                    "text/xml",
+                    "application/x-yaml",
+                    "application/x-ndjson"
+                ],
+                "tags": [
+                    "Teams"
+                ],
+                "summary": "Obtiene todos los equipos",
+                "parameters": [
+                    {
+                        "enum": [
+                            "json",
+                            "csv",
+                            "xml",
+                            "yaml",
+                            "ndjson"
+                        ],
+                        "type": "string",
+                        "description": "Formato de la respuesta (alternativa a Accept)",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/internal.Team"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/teams/resolve": {
+            "get": {
+                "description": "Retorna el nombre canónico al que corresponde un nombre o alias. Si no se reconoce responde 422 con sugerencias.",
+                "produces": [
+                    "application/json",
+                    "text/csv",
+                    "text/xml",
+                    "application/x-yaml",
+                    "application/x-ndjson"
+                ],
+                "tags": [
+                    "Teams"
+                ],
+                "summary": "Resuelve un nombre de equipo",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Nombre o alias del equipo",
+                        "name": "name",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "json",
+                            "csv",
+                            "xml",
+                            "yaml",
+                            "ndjson"
+                        ],
+                        "type": "string",
+                        "description": "Formato de la respuesta (alternativa a Accept)",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/teams/{id}": {
+            "get": {
+                "description": "Retorna el equipo canónico y sus alias.",
+                "produces": [
+                    "application/json",
+                    "text/csv",
+                    "text/xml",
+                    "application/x-yaml",
+                    "application/x-ndjson"
+                ],
+                "tags": [
+                    "Teams"
+                ],
+                "summary": "Obtiene un equipo por ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID del equipo",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "json",
+                            "csv",
+                            "xml",
+                            "yaml",
+                            "ndjson"
+                        ],
+                        "type": "string",
+                        "description": "Formato de la respuesta (alternativa a Accept)",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal.Team"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/teams/{id}/calendar.ics": {
+            "get": {
+                "description": "Retorna un feed .ics (RFC 5545) con los partidos del equipo, apto para suscribirse desde Google Calendar u Outlook.\nCada partido tiene un UID estable y su versión como SEQUENCE, de modo que los cambios actualizan el evento existente.\nLa descripción incluye el resultado final de los partidos terminados, con los goles de cada equipo.\nLos partidos sin hora se publican como eventos de día completo (DTSTART;VALUE=DATE).\nLOCATION es el estadio del equipo local, si está registrado.",
+                "produces": [
+                    "text/calendar"
+                ],
+                "tags": [
+                    "Teams"
+                ],
+                "summary": "Calendario iCalendar de un equipo",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID del equipo",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Calendario iCalendar",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/trash": {
+            "get": {
+                "description": "Retorna los partidos eliminados que todavía pueden restaurarse, del más reciente al más antiguo.",
+                "produces": [
+                    "application/json",
+                    "text/csv",
+                    "text/xml",
+                    "application/x-yaml",
+                    "application/x-ndjson"
+                ],
+                "tags": [
+                    "Trash"
+                ],
+                "summary": "Obtiene la papelera",
+                "parameters": [
+                    {
+                        "enum": [
+                            "json",
+                            "csv",
+                            "xml",
+                            "yaml",
+                            "ndjson"
+                        ],
+                        "type": "string",
+                        "description": "Formato de la respuesta (alternativa a Accept)",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/internal.DeletedMatch"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/matches": {
+            "get": {
+                "description": "Retorna todos los partidos con equipos anidados, marcador, tarjetas y kickoff RFC3339.",
+                "produces": [
+                    "application/json",
+                    "text/csv",
+                    "text/xml",
+                    "application/x-yaml",
+                    "application/x-ndjson"
+                ],
+                "tags": [
+                    "Matches v2"
+                ],
+                "summary": "Obtiene todos los partidos (v2)",
+                "parameters": [
+                    {
+                        "enum": [
+                            "json",
+                            "csv",
+                            "xml",
+                            "yaml",
+                            "ndjson"
+                        ],
+                        "type": "string",
+                        "description": "Formato de la respuesta (alternativa a Accept)",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/main.matchV2"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Crea un partido a partir de los equipos y el kickoff RFC3339, y retorna el partido creado.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Matches v2"
+                ],
+                "summary": "Crea un nuevo partido (v2)",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Clave para reintentar la solicitud sin repetir la escritura",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Datos del partido",
+                        "name": "match",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/main.matchInputV2"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/main.matchV2"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/matches/{id}": {
+            "get": {
+                "description": "Retorna el partido cuyo ID se especifica en la ruta junto con su ETag.\nCon ?asOf= retorna el partido reconstruido a partir de sus eventos hasta ese instante, sin ETag.",
+                "produces": [
+                    "application/json",
+                    "text/csv",
+                    "text/xml",
+                    "application/x-yaml",
+                    "application/x-ndjson"
+                ],
+                "tags": [
+                    "Matches v2"
+                ],
+                "summary": "Obtiene un partido por ID (v2)",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID del partido",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Instante (RFC3339) en el que se consulta el partido",
+                        "name": "asOf",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "json",
+                            "csv",
+                            "xml",
+                            "yaml",
+                            "ndjson"
+                        ],
+                        "type": "string",
+                        "description": "Formato de la respuesta (alternativa a Accept)",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.matchV2"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Versión actual del partido"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Reemplaza equipos y kickoff del partido y retorna su nueva representación.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Matches v2"
+                ],
+                "summary": "Actualiza un partido existente (v2)",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID del partido",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag obtenido al consultar el partido",
+                        "name": "If-Match",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Clave para reintentar la solicitud sin repetir la escritura",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Datos del partido",
+                        "name": "match",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/main.matchInputV2"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.matchV2"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "412": {
+                        "description": "Versión desactualizada, se retorna la actual",
+                        "schema": {
+                            "$ref": "#/definitions/main.matchV2"
+                        }
+                    },
+                    "428": {
+                        "description": "Precondition Required",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Envía a la papelera el partido indicado si su versión coincide con If-Match.",
+                "tags": [
+                    "Matches v2"
+                ],
+                "summary": "Elimina un partido (v2)",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID del partido",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag obtenido al consultar el partido",
+                        "name": "If-Match",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Clave para reintentar la solicitud sin repetir la escritura",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "412": {
+                        "description": "Versión desactualizada, se retorna la actual",
+                        "schema": {
+                            "$ref": "#/definitions/main.matchV2"
+                        }
+                    },
+                    "428": {
+                        "description": "Precondition Required",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/matches/{id}/finish": {
+            "post": {
+                "description": "Registra el evento MatchFinished si la versión coincide con If-Match. Un partido finalizado\nno admite más goles, tarjetas ni tiempo extra.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Matches v2"
+                ],
+                "summary": "Finaliza un partido (v2)",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID del partido",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag obtenido al consultar el partido",
+                        "name": "If-Match",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Clave para reintentar la solicitud sin repetir la escritura",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.matchV2"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "El partido ya finalizó",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "412": {
+                        "description": "Versión desactualizada, se retorna la actual",
+                        "schema": {
+                            "$ref": "#/definitions/main.matchV2"
+                        }
+                    },
+                    "428": {
+                        "description": "Precondition Required",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/matches/{id}/stats/{stat}": {
+            "patch": {
+                "description": "Registra el evento GoalScored, CardShown o ExtraTimeStarted correspondiente y retorna la nueva representación del partido.\nIncrementa goles o tarjetas, o activa el tiempo extra, y retorna la nueva representación del partido.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Matches v2"
+                ],
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID del partido",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "goals",
+                            "yellowcards",
+                            "redcards",
+                            "extratime"
+                        ],
+                        "type": "string",
+                        "description": "Estadística",
+                        "name": "stat",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag obtenido al consultar el partido",
+                        "name": "If-Match",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Clave para reintentar la solicitud sin repetir la escritura",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.matchV2"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "El partido ya finalizó",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "412": {
+                        "description": "Versión desactualizada, se retorna la actual",
+                        "schema": {
+                            "$ref": "#/definitions/main.matchV2"
+                        }
+                    },
+                    "428": {
+                        "description": "Precondition Required",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "internal.AuditEntry": {
+            "type": "object",
+            "properties": {
+                "actor": {
+                    "type": "string"
+                },
+                "after": {
+                    "type": "object"
+                },
+                "before": {
+                    "type": "object"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "diff": {
+                    "type": "object"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "matchId": {
+                    "type": "integer"
+                },
+                "operation": {
+                    "type": "string"
+                },
+                "requestId": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal.ClockMinute": {
+            "type": "object",
+            "properties": {
+                "minute": {
+                    "type": "integer"
+                },
+                "stoppage": {
+                    "type": "integer"
+                }
+            }
+        },
+        "internal.CommentaryEntry": {
+            "type": "object",
+            "properties": {
+                "author": {
+                    "type": "string"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "deletedAt": {
+                    "description": "DeletedAt solo se informa en los cambios que recibe el hub cuando se elimina el comentario.",
+                    "type": "string"
+                },
+                "eventId": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "matchId": {
+                    "type": "integer"
+                },
+                "minute": {
+                    "type": "integer",
+                    "example": 45
+                },
+                "pinned": {
+                    "type": "boolean"
+                },
+                "revision": {
+                    "description": "Revision cambia con cada alta, edición o eliminación del comentario.",
+                    "type": "integer"
+                },
+                "stoppage": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "text": {
+                    "type": "string",
+                    "example": "Disparo al palo desde fuera del área"
+                },
+                "updatedAt": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal.Competition": {
+            "type": "object",
+            "properties": {
+                "country": {
+                    "type": "string",
+                    "example": "España"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "name": {
+                    "type": "string",
+                    "example": "La Liga"
+                }
+            }
+        },
+        "internal.DatasetTableResult": {
+            "type": "object",
+            "properties": {
+                "imported": {
+                    "type": "integer"
+                },
+                "rows": {
+                    "type": "integer"
+                },
+                "skipped": {
+                    "type": "integer"
+                },
+                "table": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal.DeletedMatch": {
+            "type": "object",
+            "properties": {
+                "awayTeam": {
+                    "type": "string"
+                },
+                "deletedAt": {
+                    "type": "string"
+                },
+                "homeTeam": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "matchDate": {
+                    "type": "string"
+                },
+                "version": {
+                    "type": "integer"
+                }
+            }
+        },
+        "internal.Match": {
+            "description": "Objeto que modela un partido, incluyendo equipos y fecha.",
+            "type": "object",
+            "properties": {
+                "awayTeam": {
+                    "type": "string"
+                },
+                "homeTeam": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "matchDate": {
+                    "type": "string"
+                },
+                "version": {
+                    "type": "integer"
+                }
+            }
+        },
+        "internal.MatchClock": {
+            "type": "object",
+            "properties": {
+                "announcedStoppage": {
+                    "description": "AnnouncedStoppage son los minutos de descuento anunciados en el periodo en curso.",
+                    "type": "integer"
+                },
+                "display": {
+                    "type": "string",
+                    "example": "45+2'"
+                },
+                "matchId": {
+                    "type": "integer"
+                },
+                "minute": {
+                    "description": "Minute es el minuto actual mientras el reloj está en marcha.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/internal.ClockMinute"
+                        }
+                    ]
+                },
+                "period": {
+                    "description": "Period es el periodo en curso o, durante un descanso, el último disputado.",
+                    "type": "string",
+                    "enum": [
+                        "first_half",
+                        "second_half",
+                        "extra_time_first",
+                        "extra_time_second"
+                    ]
+                },
+                "periodStartedAt": {
+                    "type": "string"
+                },
+                "running": {
+                    "type": "boolean"
+                },
+                "serverTime": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string",
+                    "enum": [
+                        "pre_match",
+                        "in_play",
+                        "break",
+                        "finished"
+                    ]
+                }
+            }
+        },
+        "internal.MatchEvent": {
+            "type": "object",
+            "properties": {
+                "clock": {
+                    "$ref": "#/definitions/internal.ClockMinute"
+                },
+                "data": {
+                    "type": "object"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "matchId": {
+                    "type": "integer"
+                },
+                "occurredAt": {
+                    "type": "string"
+                },
+                "sequence": {
+                    "type": "integer"
+                },
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal.SearchHit": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "integer"
+                },
+                "rank": {
+                    "type": "number"
+                },
+                "title": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal.Team": {
+            "type": "object",
+            "properties": {
+                "aliases": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "stadium": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal.TeamMergeResult": {
+            "type": "object",
+            "properties": {
+                "aliases": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "target": {
+                    "type": "string"
+                },
+                "updatedMatches": {
+                    "type": "integer"
+                }
+            }
+        },
+        "internal.WebhookAttempt": {
+            "type": "object",
+            "properties": {
+                "attemptedAt": {
+                    "type": "string"
+                },
+                "durationMs": {
+                    "type": "integer"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "responseBody": {
+                    "type": "string"
+                },
+                "statusCode": {
+                    "type": "integer"
+                }
+            }
+        },
+        "internal.WebhookDelivery": {
+            "type": "object",
+            "properties": {
+                "attempts": {
+                    "type": "integer"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "deliveredAt": {
+                    "type": "string"
+                },
+                "eventId": {
+                    "type": "integer"
+                },
+                "eventType": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "log": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/internal.WebhookAttempt"
+                    }
+                },
+                "nextAttemptAt": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "subscriptionId": {
+                    "type": "integer"
+                }
+            }
+        },
+        "internal.WebhookSubscription": {
+            "type": "object",
+            "properties": {
+                "active": {
+                    "type": "boolean"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "eventTypes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "secret": {
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string"
+                }
+            }
+        },
+        "main.batchOperationRequest": {
+            "type": "object",
+            "properties": {
+                "awayTeam": {
+                    "type": "string",
+                    "example": "Real Madrid"
+                },
+                "homeTeam": {
+                    "type": "string",
+                    "example": "Barcelona"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "matchDate": {
+                    "type": "string",
+                    "example": "2025-04-01"
+                },
+                "op": {
+                    "type": "string",
+                    "example": "create"
+                },
+                "stat": {
+                    "type": "string",
+                    "example": "goals"
+                },
+                "version": {
+                    "type": "integer"
+                }
+            }
+        },
+        "main.batchOperationResult": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "index": {
+                    "type": "integer"
+                },
+                "op": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "integer"
+                },
+                "version": {
+                    "type": "integer"
+                }
+            }
+        },
+        "main.batchRequest": {
+            "type": "object",
+            "properties": {
+                "mode": {
+                    "type": "string",
+                    "example": "atomic"
+                },
+                "operations": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/main.batchOperationRequest"
+                    }
+                }
+            }
+        },
+        "main.batchResponse": {
+            "type": "object",
+            "properties": {
+                "committed": {
+                    "type": "boolean"
+                },
+                "mode": {
+                    "type": "string"
+                },
+                "results": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/main.batchOperationResult"
+                    }
+                }
+            }
+        },
+        "main.calendarDay": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer"
+                },
+                "date": {
+                    "type": "string",
+                    "example": "2025-04-01"
+                },
+                "matches": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/main.matchV2"
+                    }
+                }
+            }
+        },
+        "main.calendarResponse": {
+            "type": "object",
+            "properties": {
+                "days": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/main.calendarDay"
+                    }
+                },
+                "from": {
+                    "type": "string",
+                    "example": "2025-04-01"
+                },
+                "timeZone": {
+                    "type": "string",
+                    "example": "Europe/Madrid"
+                },
+                "to": {
+                    "type": "string",
+                    "example": "2025-04-30"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "main.cardsV2": {
+            "type": "object",
+            "properties": {
+                "red": {
+                    "type": "integer"
+                },
+                "yellow": {
+                    "type": "integer"
+                }
+            }
+        },
+        "main.clockInput": {
+            "type": "object",
+            "required": [
+                "action"
+            ],
+            "properties": {
+                "action": {
+                    "description": "Action es start (inicia Period), end (termina el periodo en curso) o stoppage (anuncia Minutes de descuento).",
+                    "type": "string",
+                    "enum": [
+                        "start",
+                        "end",
+                        "stoppage"
+                    ],
+                    "example": "start"
+                },
+                "minutes": {
+                    "type": "integer",
+                    "example": 3
+                },
+                "period": {
+                    "type": "string",
+                    "enum": [
+                        "first_half",
+                        "second_half",
+                        "extra_time_first",
+                        "extra_time_second"
+                    ],
+                    "example": "first_half"
+                }
+            }
+        },
+        "main.commentaryInput": {
+            "type": "object",
+            "properties": {
+                "eventId": {
+                    "type": "integer"
+                },
+                "minute": {
+                    "description": "Minute es opcional: si se omite se usa el minuto del evento enlazado o el del reloj del partido.",
+                    "type": "integer",
+                    "example": 45
+                },
+                "pinned": {
+                    "type": "boolean"
+                },
+                "stoppage": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "text": {
+                    "type": "string",
+                    "example": "Disparo al palo desde fuera del área"
+                }
+            }
+        },
+        "main.commentaryPatchInput": {
+            "type": "object",
+            "properties": {
+                "eventId": {
+                    "type": "integer"
+                },
+                "minute": {
+                    "type": "integer"
+                },
+                "pinned": {
+                    "type": "boolean"
+                },
+                "stoppage": {
+                    "type": "integer"
+                },
+                "text": {
+                    "type": "string"
+                }
+            }
+        },
+        "main.datasetImportResponse": {
+            "type": "object",
+            "properties": {
+                "conflict": {
+                    "type": "string",
+                    "example": "fail"
+                },
+                "tables": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/internal.DatasetTableResult"
+                    }
+                },
+                "version": {
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "main.graphqlRequest": {
+            "type": "object",
+            "properties": {
+                "operationName": {
+                    "type": "string"
+                },
+                "query": {
+                    "type": "string",
+                    "example": "{ matches(date: \"2025-04-01\") { id homeTeam { name } awayTeam { name } score { goals } } }"
+                },
+                "variables": {
+                    "type": "object",
+                    "additionalProperties": {}
+                }
+            }
+        },
+        "main.graphqlResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "object"
+                },
+                "errors": {
+                    "type": "array",
+                    "items": {
+                        "type": "object"
+                    }
+                }
+            }
+        },
+        "main.importResponse": {
+            "type": "object",
+            "properties": {
+                "committed": {
+                    "type": "boolean"
+                },
+                "dryRun": {
+                    "type": "boolean"
+                },
+                "invalid": {
+                    "type": "integer"
+                },
+                "rows": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/main.importRowResult"
+                    }
+                },
+                "total": {
+                    "type": "integer"
+                },
+                "valid": {
+                    "type": "integer"
+                }
+            }
+        },
+        "main.importRowResult": {
+            "type": "object",
+            "properties": {
                 "awayTeam": {
                     "type": "string"
                 },
+                "code": {
+                    "type": "string"
+                },
+                "error": {
+                    "type": "string"
+                },
                 "homeTeam": {
                     "type": "string"
                 },
                 "id": {
                     "type": "integer"
                 },
+                "line": {
+                    "type": "integer"
+                },
+                "matchDate": {
+                    "type": "string",
+                    "example": "2025-04-01"
+                },
+                "result": {
+                    "type": "string",
+                    "example": "2-1"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "valid"
+                },
+                "suggestions": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "main.matchInput": {
+            "type": "object",
+            "required": [
+                "awayTeam",
+                "homeTeam",
+                "matchDate"
+            ],
+            "properties": {
+                "awayTeam": {
+                    "type": "string",
+                    "example": "FC Barcelona"
+                },
+                "homeTeam": {
+                    "type": "string",
+                    "example": "Real Madrid"
+                },
                 "matchDate": {
+                    "type": "string",
+                    "format": "date",
+                    "example": "2025-04-01"
+                }
+            }
+        },
+        "main.matchInputV2": {
+            "type": "object",
+            "required": [
+                "awayTeam",
+                "homeTeam"
+            ],
+            "properties": {
+                "awayTeam": {
+                    "$ref": "#/definitions/main.teamV2"
+                },
+                "homeTeam": {
+                    "$ref": "#/definitions/main.teamV2"
+                },
+                "kickoff": {
+                    "type": "string",
+                    "example": "2025-04-01T21:00:00Z"
+                }
+            }
+        },
+        "main.matchV2": {
+            "type": "object",
+            "properties": {
+                "awayTeam": {
+                    "$ref": "#/definitions/main.teamV2"
+                },
+                "cards": {
+                    "$ref": "#/definitions/main.cardsV2"
+                },
+                "extraTime": {
+                    "type": "boolean"
+                },
+                "finished": {
+                    "type": "boolean"
+                },
+                "homeTeam": {
+                    "$ref": "#/definitions/main.teamV2"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "kickoff": {
+                    "type": "string",
+                    "example": "2025-04-01T21:00:00Z"
+                },
+                "score": {
+                    "$ref": "#/definitions/main.scoreV2"
+                },
+                "version": {
+                    "type": "integer"
+                }
+            }
+        },
+        "main.projectionRebuildResponse": {
+            "type": "object",
+            "properties": {
+                "matches": {
+                    "type": "integer"
+                }
+            }
+        },
+        "main.scoreV2": {
+            "type": "object",
+            "properties": {
+                "goals": {
+                    "type": "integer"
+                }
+            }
+        },
+        "main.searchResponse": {
+            "type": "object",
+            "properties": {
+                "query": {
                     "type": "string"
+                },
+                "results": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "array",
+                        "items": {
+                            "$ref": "#/definitions/internal.SearchHit"
+                        }
+                    }
+                }
+            }
+        },
+        "main.streamEvent": {
+            "type": "object",
+            "properties": {
+                "clock": {
+                    "$ref": "#/definitions/internal.ClockMinute"
+                },
+                "data": {
+                    "type": "object"
+                },
+                "deleted": {
+                    "type": "boolean"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "match": {
+                    "$ref": "#/definitions/main.matchV2"
+                },
+                "matchId": {
+                    "type": "integer"
+                },
+                "occurredAt": {
+                    "type": "string"
+                },
+                "sequence": {
+                    "type": "integer"
+                },
+                "type": {
+                    "type": "string",
+                    "example": "GoalScored"
+                }
+            }
+        },
+        "main.teamAliasInput": {
+            "type": "object",
+            "required": [
+                "alias"
+            ],
+            "properties": {
+                "alias": {
+                    "type": "string",
+                    "example": "Athletic Bilbao"
+                }
+            }
+        },
+        "main.teamInput": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "aliases": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "Athletic Bilbao",
+                        "Athletic"
+                    ]
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Athletic Club"
+                },
+                "stadium": {
+                    "type": "string",
+                    "example": "San Mamés"
+                }
+            }
+        },
+        "main.teamMergeInput": {
+            "type": "object",
+            "required": [
+                "duplicates",
+                "target"
+            ],
+            "properties": {
+                "duplicates": {
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "Athletic Bilbao"
+                    ]
+                },
+                "target": {
+                    "type": "string",
+                    "example": "Athletic Club"
+                }
+            }
+        },
+        "main.teamV2": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "name": {
+                    "type": "string",
+                    "example": "Barcelona"
+                }
+            }
+        },
+        "main.trashPurgeResponse": {
+            "type": "object",
+            "properties": {
+                "purged": {
+                    "type": "integer"
+                },
+                "retention": {
+                    "type": "string",
+                    "example": "720h0m0s"
+                }
+            }
+        },
+        "main.webhookInput": {
+            "type": "object",
+            "properties": {
+                "eventTypes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "GoalScored",
+                        "MatchFinished"
+                    ]
+                },
+                "secret": {
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string",
+                    "example": "https://partner.example.com/hooks/laliga"
                 }
             }
         }
+    },
+    "securityDefinitions": {
+        "AdminToken": {
+            "description": "Token de administración con el formato \"Bearer \u003cADMIN_TOKEN\u003e\".",
+            "type": "apiKey",
+            "name": "Authorization",
+            "in": "header"
+        }
     }
 }`
 
@@ -346,7 +5372,7 @@ var SwaggerInfo = &swag.Spec{
 	BasePath:         "/api",
 	Schemes:          []string{},
 	Title:            "La Liga Tracker API",
-	Description:      "API para gestionar los partidos de La Liga.",
+	Description:      "API para gestionar los partidos de La Liga. Los mensajes se traducen según el encabezado Accept-Language (es, en).",
 	InfoInstanceName: "swagger",
 	SwaggerTemplate:  docTemplate,
 	LeftDelim:        "{{",
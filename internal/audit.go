@@ -0,0 +1,238 @@
+package internal
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// Operaciones registradas en la auditoría de partidos.
+const (
+	AuditCreate    = "create"
+	AuditUpdate    = "update"
+	AuditDelete    = "delete"
+	AuditRestore   = "restore"
+	AuditIncrement = "increment"
+	AuditFinish    = "finish"
+	AuditMerge     = "merge"
+	AuditPurge     = "purge"
+	AuditClock     = "clock"
+)
+
+// AuditInfo identifica quién realiza una escritura y en qué solicitud. Idempotency es la
+// Idempotency-Key de la solicitud, o nil si no envió una.
+type AuditInfo struct {
+	Actor       string
+	RequestID   string
+	Idempotency *IdempotencyKey
+}
+
+// AuditEntry es un cambio registrado sobre un partido.
+// Before y After son el registro completo antes y después del cambio (null si no existía);
+// Diff contiene solo las columnas modificadas con sus valores "before" y "after".
+type AuditEntry struct {
+	ID        int64           `json:"id"`
+	MatchID   int             `json:"matchId"`
+	Actor     string          `json:"actor"`
+	RequestID string          `json:"requestId"`
+	Operation string          `json:"operation"`
+	Before    json.RawMessage `json:"before" swaggertype:"object"`
+	After     json.RawMessage `json:"after" swaggertype:"object"`
+	Diff      json.RawMessage `json:"diff" swaggertype:"object"`
+	CreatedAt time.Time       `json:"createdAt"`
+}
+
+// AuditFilter restringe las entradas que retorna GetAuditLog. Los campos vacíos no filtran.
+type AuditFilter struct {
+	MatchID   int
+	Actor     string
+	Operation string
+	RequestID string
+	From      time.Time
+	To        time.Time
+	Limit     int
+	Offset    int
+}
+
+// matchSnapshotQuery obtiene el registro completo de un partido como JSON y lo bloquea
+// hasta el fin de la transacción. La columna generada de búsqueda no forma parte del historial.
+const matchSnapshotQuery = "SELECT to_jsonb(m) - 'search_vector' FROM matches m WHERE m.id = $1 FOR UPDATE"
+
+// snapshotMatch retorna el registro del partido como JSON, o nil si no existe.
+func snapshotMatch(q querier, id int) (json.RawMessage, error) {
+	var snapshot []byte
+	err := q.QueryRow(matchSnapshotQuery, id).Scan(&snapshot)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error al leer el partido para auditoría: %v", err)
+	}
+	return snapshot, nil
+}
+
+// recordAudit registra el cambio del partido a partir de su estado anterior y el actual.
+func recordAudit(q querier, info AuditInfo, op string, id int, before json.RawMessage) error {
+	after, err := snapshotMatch(q, id)
+	if err != nil {
+		return err
+	}
+	return insertAudit(q, info, op, id, before, after)
+}
+
+// insertAudit guarda una entrada de auditoría con el diff entre before y after y marca como
+// aplicada la Idempotency-Key de la solicitud en la misma transacción.
+func insertAudit(q querier, info AuditInfo, op string, id int, before, after json.RawMessage) error {
+	if err := completeIdempotencyKey(q, info); err != nil {
+		return err
+	}
+	diff, err := diffSnapshots(before, after)
+	if err != nil {
+		return err
+	}
+	query := `
+        INSERT INTO audit_log (match_id, actor, request_id, operation, before, after, diff)
+        VALUES ($1, $2, $3, $4, $5, $6, $7)
+    `
+	if _, err := q.Exec(query, id, info.Actor, info.RequestID, op, nullJSON(before), nullJSON(after), diff); err != nil {
+		return fmt.Errorf("error al registrar auditoría: %v", err)
+	}
+	return nil
+}
+
+// nullJSON convierte un JSON vacío en NULL para la base de datos.
+func nullJSON(data json.RawMessage) any {
+	if len(data) == 0 {
+		return nil
+	}
+	return []byte(data)
+}
+
+// diffSnapshots retorna las columnas que cambian entre before y after, con ambos valores.
+func diffSnapshots(before, after json.RawMessage) ([]byte, error) {
+	var old, current map[string]any
+	if len(before) > 0 {
+		if err := json.Unmarshal(before, &old); err != nil {
+			return nil, err
+		}
+	}
+	if len(after) > 0 {
+		if err := json.Unmarshal(after, &current); err != nil {
+			return nil, err
+		}
+	}
+
+	type change struct {
+		Before any `json:"before"`
+		After  any `json:"after"`
+	}
+	diff := map[string]change{}
+	for column, value := range old {
+		if !reflect.DeepEqual(value, current[column]) {
+			diff[column] = change{Before: value, After: current[column]}
+		}
+	}
+	for column, value := range current {
+		if _, ok := old[column]; !ok {
+			diff[column] = change{Before: nil, After: value}
+		}
+	}
+	return json.Marshal(diff)
+}
+
+// auditColumns son las columnas que se leen de "audit_log", en el orden que espera scanAudit.
+const auditColumns = "id, match_id, actor, request_id, operation, before, after, diff, created_at"
+
+// scanAudit lee una entrada de auditoría a partir de una fila obtenida con auditColumns.
+func scanAudit(row rowScanner) (AuditEntry, error) {
+	var e AuditEntry
+	var before, after, diff []byte
+	err := row.Scan(&e.ID, &e.MatchID, &e.Actor, &e.RequestID, &e.Operation, &before, &after, &diff, &e.CreatedAt)
+	e.Before, e.After, e.Diff = jsonOrNull(before), jsonOrNull(after), jsonOrNull(diff)
+	return e, err
+}
+
+// jsonOrNull retorna el JSON recibido o null si la columna era NULL.
+func jsonOrNull(data []byte) json.RawMessage {
+	if data == nil {
+		return json.RawMessage("null")
+	}
+	return data
+}
+
+// queryAudit ejecuta una consulta sobre audit_log y retorna las entradas encontradas.
+func queryAudit(query string, args ...any) ([]AuditEntry, error) {
+	rows, err := DB.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error al consultar auditoría: %v", err)
+	}
+	defer rows.Close()
+
+	entries := []AuditEntry{}
+	for rows.Next() {
+		e, err := scanAudit(rows)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
+
+// GetMatchHistory obtiene los cambios registrados de un partido, del más antiguo al más reciente.
+// Retorna sql.ErrNoRows si el partido no existe ni tiene historial.
+func GetMatchHistory(id int) ([]AuditEntry, error) {
+	entries, err := queryAudit("SELECT "+auditColumns+" FROM audit_log WHERE match_id = $1 ORDER BY created_at, id", id)
+	if err != nil || len(entries) > 0 {
+		return entries, err
+	}
+
+	var exists bool
+	if err := DB.QueryRow("SELECT EXISTS(SELECT 1 FROM matches WHERE id = $1)", id).Scan(&exists); err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, sql.ErrNoRows
+	}
+	return entries, nil
+}
+
+// GetAuditLog obtiene las entradas de auditoría que cumplen el filtro, de la más reciente a la más antigua.
+func GetAuditLog(filter AuditFilter) ([]AuditEntry, error) {
+	var conditions []string
+	var args []any
+	where := func(condition string, value any) {
+		args = append(args, value)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+	if filter.MatchID > 0 {
+		where("match_id = $%d", filter.MatchID)
+	}
+	if filter.Actor != "" {
+		where("actor = $%d", filter.Actor)
+	}
+	if filter.Operation != "" {
+		where("operation = $%d", filter.Operation)
+	}
+	if filter.RequestID != "" {
+		where("request_id = $%d", filter.RequestID)
+	}
+	if !filter.From.IsZero() {
+		where("created_at >= $%d", filter.From)
+	}
+	if !filter.To.IsZero() {
+		where("created_at < $%d", filter.To)
+	}
+
+	query := "SELECT " + auditColumns + " FROM audit_log"
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	args = append(args, filter.Limit, filter.Offset)
+	query += fmt.Sprintf(" ORDER BY created_at DESC, id DESC LIMIT $%d OFFSET $%d", len(args)-1, len(args))
+	return queryAudit(query, args...)
+}
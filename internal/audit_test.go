@@ -0,0 +1,248 @@
+package internal
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestDiffSnapshots(t *testing.T) {
+	tests := []struct {
+		name    string
+		before  string
+		after   string
+		want    string
+		wantErr bool
+	}{
+		{
+			name:   "solo las columnas que cambian",
+			before: `{"id": 1, "home_team": "Betis", "goals_match": 1, "version": 2, "finished": false}`,
+			after:  `{"id": 1, "home_team": "Betis", "goals_match": 2, "version": 3, "finished": false}`,
+			want:   `{"goals_match":{"before":1,"after":2},"version":{"before":2,"after":3}}`,
+		},
+		{
+			name:  "creación sin estado anterior",
+			after: `{"id": 1, "home_team": "Betis"}`,
+			want:  `{"home_team":{"before":null,"after":"Betis"},"id":{"before":null,"after":1}}`,
+		},
+		{
+			name:   "purga sin estado posterior",
+			before: `{"id": 1, "deleted_at": "2025-03-02T10:00:00Z"}`,
+			want:   `{"deleted_at":{"before":"2025-03-02T10:00:00Z","after":null},"id":{"before":1,"after":null}}`,
+		},
+		{
+			name:   "columna que pasa a null",
+			before: `{"id": 1, "deleted_at": "2025-03-02T10:00:00Z"}`,
+			after:  `{"id": 1, "deleted_at": null}`,
+			want:   `{"deleted_at":{"before":"2025-03-02T10:00:00Z","after":null}}`,
+		},
+		{
+			name:   "columna nueva en el estado posterior",
+			before: `{"id": 1}`,
+			after:  `{"id": 1, "date_only": true}`,
+			want:   `{"date_only":{"before":null,"after":true}}`,
+		},
+		{
+			name:   "sin cambios",
+			before: `{"id": 1, "clock_period": "first_half"}`,
+			after:  `{"id": 1, "clock_period": "first_half"}`,
+			want:   `{}`,
+		},
+		{name: "JSON inválido", before: `{"id":`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := diffSnapshots(json.RawMessage(tt.before), json.RawMessage(tt.after))
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("diff = %s, se esperaba un error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("diff = %s, se esperaba %s", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestRecordAudit verifica que la entrada guarde el estado anterior, el posterior leído en la
+// misma transacción y el diff entre ambos, junto con el actor y la solicitud.
+func TestRecordAudit(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	before := json.RawMessage(`{"id": 1, "goals_match": 0, "version": 1}`)
+	after := []byte(`{"id": 1, "goals_match": 1, "version": 2}`)
+	mock.ExpectQuery(regexp.QuoteMeta(matchSnapshotQuery)).WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"snapshot"}).AddRow(after))
+	mock.ExpectExec("INSERT INTO audit_log").
+		WithArgs(1, "mesa-de-control", "req-1", AuditIncrement, []byte(before), after,
+			[]byte(`{"goals_match":{"before":0,"after":1},"version":{"before":1,"after":2}}`)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	if err := recordAudit(db, AuditInfo{Actor: "mesa-de-control", RequestID: "req-1"}, AuditIncrement, 1, before); err != nil {
+		t.Fatal(err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestGetAuditLog(t *testing.T) {
+	from := time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2025, 5, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		filter    AuditFilter
+		wantWhere string
+		wantArgs  []any
+	}{
+		{
+			name:      "sin filtros",
+			filter:    AuditFilter{Limit: 100},
+			wantWhere: `FROM audit_log ORDER BY created_at DESC, id DESC LIMIT $1 OFFSET $2`,
+			wantArgs:  []any{100, 0},
+		},
+		{
+			name:      "actor",
+			filter:    AuditFilter{Actor: "admin", Limit: 100},
+			wantWhere: `FROM audit_log WHERE actor = $1 ORDER BY created_at DESC, id DESC LIMIT $2 OFFSET $3`,
+			wantArgs:  []any{"admin", 100, 0},
+		},
+		{
+			name:      "operación",
+			filter:    AuditFilter{Operation: AuditDelete, Limit: 10, Offset: 20},
+			wantWhere: `FROM audit_log WHERE operation = $1 ORDER BY created_at DESC, id DESC LIMIT $2 OFFSET $3`,
+			wantArgs:  []any{AuditDelete, 10, 20},
+		},
+		{
+			name:      "intervalo de fechas",
+			filter:    AuditFilter{From: from, To: to, Limit: 100},
+			wantWhere: `FROM audit_log WHERE created_at >= $1 AND created_at < $2 ORDER BY created_at DESC, id DESC LIMIT $3 OFFSET $4`,
+			wantArgs:  []any{from, to, 100, 0},
+		},
+		{
+			name:      "todos los filtros combinados",
+			filter:    AuditFilter{MatchID: 7, Actor: "admin", Operation: AuditRestore, RequestID: "req-1", From: from, To: to, Limit: 5},
+			wantWhere: `FROM audit_log WHERE match_id = $1 AND actor = $2 AND operation = $3 AND request_id = $4 AND created_at >= $5 AND created_at < $6 ORDER BY created_at DESC, id DESC LIMIT $7 OFFSET $8`,
+			wantArgs:  []any{7, "admin", AuditRestore, "req-1", from, to, 5, 0},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer db.Close()
+			previous := DB
+			DB = db
+			defer func() { DB = previous }()
+
+			args := make([]driver.Value, len(tt.wantArgs))
+			for i, arg := range tt.wantArgs {
+				args[i] = arg
+			}
+			createdAt := time.Date(2025, 4, 2, 9, 0, 0, 0, time.UTC)
+			mock.ExpectQuery(regexp.QuoteMeta("SELECT " + auditColumns + " " + tt.wantWhere)).WithArgs(args...).
+				WillReturnRows(sqlmock.NewRows([]string{"id", "match_id", "actor", "request_id", "operation", "before", "after", "diff", "created_at"}).
+					AddRow(3, 7, "admin", "req-1", AuditRestore, []byte(`{"deleted_at": "2025-04-01T00:00:00Z"}`), []byte(`{"deleted_at": null}`),
+						[]byte(`{"deleted_at":{"before":"2025-04-01T00:00:00Z","after":null}}`), createdAt))
+
+			entries, err := GetAuditLog(tt.filter)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(entries) != 1 || entries[0].Actor != "admin" || entries[0].Operation != AuditRestore || !entries[0].CreatedAt.Equal(createdAt) {
+				t.Errorf("entradas = %+v", entries)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func TestGetMatchHistory(t *testing.T) {
+	historySQL := regexp.QuoteMeta("SELECT " + auditColumns + " FROM audit_log WHERE match_id = $1 ORDER BY created_at, id")
+	auditRows := func() *sqlmock.Rows {
+		return sqlmock.NewRows([]string{"id", "match_id", "actor", "request_id", "operation", "before", "after", "diff", "created_at"})
+	}
+
+	tests := []struct {
+		name        string
+		setup       func(mock sqlmock.Sqlmock)
+		wantEntries int
+		wantErr     error
+	}{
+		{
+			name: "creación y eliminación, con la creación sin estado anterior",
+			setup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(historySQL).WithArgs(1).WillReturnRows(auditRows().
+					AddRow(1, 1, "192.0.2.1", "req-1", AuditCreate, nil, []byte(`{"id": 1}`), []byte(`{"id":{"before":null,"after":1}}`), time.Now()).
+					AddRow(2, 1, "admin", "req-2", AuditPurge, []byte(`{"id": 1}`), nil, []byte(`{"id":{"before":1,"after":null}}`), time.Now()))
+			},
+			wantEntries: 2,
+		},
+		{
+			name: "partido sin historial",
+			setup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(historySQL).WithArgs(1).WillReturnRows(auditRows())
+				mock.ExpectQuery(`SELECT EXISTS\(SELECT 1 FROM matches WHERE id = \$1\)`).WithArgs(1).
+					WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+			},
+		},
+		{
+			name: "partido inexistente",
+			setup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(historySQL).WithArgs(1).WillReturnRows(auditRows())
+				mock.ExpectQuery(`SELECT EXISTS`).WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+			},
+			wantErr: sql.ErrNoRows,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer db.Close()
+			previous := DB
+			DB = db
+			defer func() { DB = previous }()
+			tt.setup(mock)
+
+			entries, err := GetMatchHistory(1)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("error = %v, se esperaba %v", err, tt.wantErr)
+			}
+			if err == nil && len(entries) != tt.wantEntries {
+				t.Errorf("entradas = %d, se esperaban %d", len(entries), tt.wantEntries)
+			}
+			if tt.wantEntries > 0 && (string(entries[0].Before) != "null" || string(entries[1].After) != "null") {
+				t.Errorf("los estados ausentes deben ser null: %s, %s", entries[0].Before, entries[1].After)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
@@ -0,0 +1,111 @@
+package internal
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+// Tipos de operación admitidos por RunBatch.
+const (
+	BatchCreate    = "create"
+	BatchUpdate    = "update"
+	BatchDelete    = "delete"
+	BatchIncrement = "increment"
+)
+
+// ErrBatchSkipped indica que una operación no se ejecutó porque el lote ya se había revertido.
+var ErrBatchSkipped = errors.New("operación omitida porque el lote se revirtió")
+
+// BatchOperation describe una operación dentro de un lote.
+// Match lleva los datos del partido para create y update; ID y Version identifican
+// el partido para el resto de operaciones.
+type BatchOperation struct {
+	Op      string
+	Stat    string
+	ID      int
+	Version int
+	Match   Match
+}
+
+// BatchResult es el resultado de una operación del lote.
+// Err es nil si la operación se aplicó correctamente.
+type BatchResult struct {
+	ID      int
+	Version int
+	Err     error
+}
+
+// RunBatch ejecuta las operaciones en orden dentro de una única transacción.
+// En modo atómico la primera falla revierte todo el lote y las operaciones restantes
+// se marcan con ErrBatchSkipped. En modo best-effort cada operación se aísla con un
+// SAVEPOINT, de modo que una falla solo revierte esa operación.
+// Cada operación aplicada se registra en la auditoría con info.
+// El booleano retornado indica si la transacción se confirmó.
+func RunBatch(ops []BatchOperation, atomic bool, info AuditInfo) ([]BatchResult, bool, error) {
+	tx, err := DB.Begin()
+	if err != nil {
+		return nil, false, fmt.Errorf("error al iniciar la transacción: %v", err)
+	}
+	defer tx.Rollback()
+
+	results := make([]BatchResult, len(ops))
+	for i, op := range ops {
+		if atomic {
+			results[i] = runBatchOperation(tx, op, info)
+			if results[i].Err != nil {
+				for j := i + 1; j < len(ops); j++ {
+					results[j] = BatchResult{ID: ops[j].ID, Err: ErrBatchSkipped}
+				}
+				return results, false, nil
+			}
+			continue
+		}
+
+		if _, err := tx.Exec("SAVEPOINT batch_op"); err != nil {
+			return nil, false, fmt.Errorf("error al crear savepoint: %v", err)
+		}
+		results[i] = runBatchOperation(tx, op, info)
+		release := "RELEASE SAVEPOINT batch_op"
+		if results[i].Err != nil {
+			release = "ROLLBACK TO SAVEPOINT batch_op"
+		}
+		if _, err := tx.Exec(release); err != nil {
+			return nil, false, fmt.Errorf("error al cerrar savepoint: %v", err)
+		}
+	}
+
+	if err := commit(tx); err != nil {
+		return nil, false, fmt.Errorf("error al confirmar la transacción: %v", err)
+	}
+	return results, true, nil
+}
+
+// runBatchOperation aplica una operación usando la transacción recibida.
+func runBatchOperation(tx *sql.Tx, op BatchOperation, info AuditInfo) BatchResult {
+	switch op.Op {
+	case BatchCreate:
+		id, err := createMatch(tx, op.Match, info)
+		return BatchResult{ID: id, Version: 1, Err: err}
+	case BatchUpdate:
+		m := op.Match
+		m.ID, m.Version = op.ID, op.Version
+		return versionedResult(op, updateMatch(tx, m, info))
+	case BatchDelete:
+		if err := deleteMatch(tx, op.ID, op.Version, info); err != nil {
+			return BatchResult{ID: op.ID, Err: err}
+		}
+		return BatchResult{ID: op.ID}
+	case BatchIncrement:
+		return versionedResult(op, incrementStat(tx, op.Stat, op.ID, op.Version, info))
+	}
+	return BatchResult{ID: op.ID, Err: fmt.Errorf("operación desconocida: %q", op.Op)}
+}
+
+// versionedResult construye el resultado de una escritura que incrementa la versión del partido.
+func versionedResult(op BatchOperation, err error) BatchResult {
+	if err != nil {
+		return BatchResult{ID: op.ID, Err: err}
+	}
+	return BatchResult{ID: op.ID, Version: op.Version + 1}
+}
@@ -0,0 +1,123 @@
+package internal
+
+import (
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+// expectMatchState prepara las lecturas con las que appendEvent carga el partido.
+func expectMatchState(mock sqlmock.Sqlmock, id, version int) {
+	mock.ExpectQuery(regexp.QuoteMeta(matchSnapshotQuery)).WithArgs(id).
+		WillReturnRows(sqlmock.NewRows([]string{"snapshot"}).AddRow([]byte(`{"id": 1, "version": 1}`)))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT " + stateColumns + " FROM matches WHERE id = $1")).WithArgs(id).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "home_team", "away_team", "match_date", "version", "goals_match",
+			"yellow_cards_match", "red_cards_match", "extra_time", "finished", "date_only", "deleted_at",
+			"clock_period", "clock_started_at", "clock_stoppage"}).
+			AddRow(id, "Sevilla", "Betis", time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC), version, 0, 0, 0, false, false, true, nil, "", nil, 0))
+}
+
+// expectDeleteApplied prepara las escrituras de un MatchDeleted que se aplica correctamente.
+func expectDeleteApplied(mock sqlmock.Sqlmock, id, version int) {
+	expectMatchState(mock, id, version)
+	mock.ExpectExec("INSERT INTO match_events").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO matches").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(regexp.QuoteMeta(matchSnapshotQuery)).WithArgs(id).
+		WillReturnRows(sqlmock.NewRows([]string{"snapshot"}).AddRow([]byte(`{"id": 1, "version": 2}`)))
+	mock.ExpectExec("INSERT INTO audit_log").WillReturnResult(sqlmock.NewResult(0, 1))
+}
+
+func TestRunBatch(t *testing.T) {
+	ops := []BatchOperation{
+		{Op: BatchDelete, ID: 1, Version: 3},
+		{Op: BatchDelete, ID: 2, Version: 4},
+		{Op: BatchDelete, ID: 3, Version: 1},
+	}
+
+	tests := []struct {
+		name          string
+		atomic        bool
+		setup         func(mock sqlmock.Sqlmock)
+		wantCommitted bool
+		wantErrs      []error
+	}{
+		{
+			name:   "atómico sin fallas confirma el lote",
+			atomic: true,
+			setup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				expectDeleteApplied(mock, 1, 3)
+				expectDeleteApplied(mock, 2, 4)
+				expectDeleteApplied(mock, 3, 1)
+				mock.ExpectCommit()
+			},
+			wantCommitted: true,
+			wantErrs:      []error{nil, nil, nil},
+		},
+		{
+			name:   "atómico con una falla revierte todo y omite el resto",
+			atomic: true,
+			setup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				expectDeleteApplied(mock, 1, 3)
+				expectMatchState(mock, 2, 5)
+				mock.ExpectRollback()
+			},
+			wantErrs: []error{nil, ErrVersionMismatch, ErrBatchSkipped},
+		},
+		{
+			name: "best-effort revierte solo la operación fallida",
+			setup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec("^SAVEPOINT batch_op$").WillReturnResult(sqlmock.NewResult(0, 0))
+				expectDeleteApplied(mock, 1, 3)
+				mock.ExpectExec("^RELEASE SAVEPOINT batch_op$").WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec("^SAVEPOINT batch_op$").WillReturnResult(sqlmock.NewResult(0, 0))
+				expectMatchState(mock, 2, 5)
+				mock.ExpectExec("^ROLLBACK TO SAVEPOINT batch_op$").WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec("^SAVEPOINT batch_op$").WillReturnResult(sqlmock.NewResult(0, 0))
+				expectDeleteApplied(mock, 3, 1)
+				mock.ExpectExec("^RELEASE SAVEPOINT batch_op$").WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectCommit()
+			},
+			wantCommitted: true,
+			wantErrs:      []error{nil, ErrVersionMismatch, nil},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer db.Close()
+			previous := DB
+			DB = db
+			defer func() { DB = previous }()
+			tt.setup(mock)
+
+			results, committed, err := RunBatch(ops, tt.atomic, AuditInfo{Actor: "tester"})
+			if err != nil {
+				t.Fatalf("error inesperado: %v", err)
+			}
+			if committed != tt.wantCommitted {
+				t.Errorf("committed = %v, se esperaba %v", committed, tt.wantCommitted)
+			}
+			for i, want := range tt.wantErrs {
+				if !errors.Is(results[i].Err, want) || (want == nil) != (results[i].Err == nil) {
+					t.Errorf("operación %d: error = %v, se esperaba %v", i, results[i].Err, want)
+				}
+				if results[i].ID != ops[i].ID {
+					t.Errorf("operación %d: id = %d, se esperaba %d", i, results[i].ID, ops[i].ID)
+				}
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
@@ -0,0 +1,220 @@
+package internal
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// MatchChange es un evento del log junto con el estado del partido después de aplicarlo.
+type MatchChange struct {
+	Event   MatchEvent
+	Match   Match
+	Deleted bool
+}
+
+// changeSubscribers son los canales de quienes esperan eventos nuevos.
+var changeSubscribers = struct {
+	sync.Mutex
+	channels map[chan struct{}]struct{}
+}{channels: map[chan struct{}]struct{}{}}
+
+// SubscribeChanges retorna un canal que recibe un aviso cada vez que se confirman eventos nuevos,
+// y la función que cancela la suscripción. Los avisos no se acumulan: si el suscriptor todavía no
+// leyó el anterior, el nuevo se descarta, por lo que al recibirlo debe consultar todos los eventos
+// posteriores al último que procesó.
+func SubscribeChanges() (<-chan struct{}, func()) {
+	ch := make(chan struct{}, 1)
+	changeSubscribers.Lock()
+	changeSubscribers.channels[ch] = struct{}{}
+	changeSubscribers.Unlock()
+
+	return ch, func() {
+		changeSubscribers.Lock()
+		delete(changeSubscribers.channels, ch)
+		changeSubscribers.Unlock()
+	}
+}
+
+// notifyChanges avisa a los suscriptores que puede haber eventos nuevos.
+func notifyChanges() {
+	changeSubscribers.Lock()
+	defer changeSubscribers.Unlock()
+	for ch := range changeSubscribers.channels {
+		select {
+		case ch <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// commit confirma la transacción y, si tuvo éxito, avisa a los suscriptores de cambios.
+func commit(tx *sql.Tx) error {
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	notifyChanges()
+	return nil
+}
+
+// streamHorizon limita las lecturas del log a los eventos de transacciones anteriores a la más
+// antigua que sigue en curso. Los eventos se recorren en orden de (tx_id, id): una transacción que
+// se confirma después tiene un tx_id mayor que el de todo lo ya leído, por lo que un lector que
+// avanza con un cursor no se salta eventos confirmados tarde, sin serializar las escrituras.
+const streamHorizon = "tx_id < pg_snapshot_xmin(pg_current_snapshot())"
+
+// LatestEventID retorna el ID del último evento del log, en el orden en que lo recorre
+// GetMatchChanges, o 0 si no hay eventos. Si matchID es distinto de 0 se consideran solo los
+// eventos de ese partido.
+func LatestEventID(matchID int) (int64, error) {
+	var id int64
+	err := DB.QueryRow(`
+        SELECT COALESCE((
+            SELECT id FROM match_events
+            WHERE `+streamHorizon+` AND ($1 = 0 OR match_id = $1)
+            ORDER BY tx_id DESC, id DESC
+            LIMIT 1
+        ), 0)
+    `, matchID).Scan(&id)
+	return id, err
+}
+
+// GetMatchChanges retorna hasta limit eventos posteriores al evento afterID, en orden de
+// confirmación, junto con el estado de su partido después de cada uno. Si matchID es distinto de
+// 0 se consideran solo los eventos de ese partido. El estado es el que se guardó con el evento;
+// solo los eventos sin estado guardado, importados de respaldos anteriores, repiten el log.
+func GetMatchChanges(afterID int64, matchID int, limit int) ([]MatchChange, error) {
+	rows, err := DB.Query(`
+        WITH position AS (
+            SELECT COALESCE((SELECT tx_id FROM match_events WHERE id <= $1 ORDER BY id DESC LIMIT 1), '0'::xid8) AS tx_id
+        )
+        SELECT e.id, e.match_id, e.sequence, e.type, e.data, e.occurred_at, e.clock_minute, e.clock_stoppage, e.state
+        FROM match_events e, position p
+        WHERE e.`+streamHorizon+`
+          AND (e.tx_id > p.tx_id OR (e.tx_id = p.tx_id AND e.id > $1))
+          AND ($2 = 0 OR e.match_id = $2)
+        ORDER BY e.tx_id, e.id
+        LIMIT $3
+    `, afterID, matchID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("error al consultar eventos: %v", err)
+	}
+	defer rows.Close()
+
+	var changes []MatchChange
+	missing := map[int64]int{}
+	for rows.Next() {
+		var (
+			e        MatchEvent
+			data     []byte
+			minute   sql.NullInt64
+			stoppage sql.NullInt64
+			state    []byte
+		)
+		if err := rows.Scan(&e.ID, &e.MatchID, &e.Sequence, &e.Type, &data, &e.OccurredAt, &minute, &stoppage, &state); err != nil {
+			return nil, err
+		}
+		e.Data = data
+		if minute.Valid {
+			e.Clock = &ClockMinute{Minute: int(minute.Int64), Stoppage: int(stoppage.Int64)}
+		}
+		change := MatchChange{Event: e}
+		if state == nil {
+			missing[e.ID] = len(changes)
+		} else {
+			var row projectionRow
+			if err := json.Unmarshal(state, &row); err != nil {
+				return nil, fmt.Errorf("estado inválido en el evento %d: %v", e.ID, err)
+			}
+			change.Match, change.Deleted = row.state().Match, row.DeletedAt != nil
+		}
+		changes = append(changes, change)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(missing) > 0 {
+		if err := replayChanges(changes, missing); err != nil {
+			return nil, err
+		}
+	}
+	return changes, nil
+}
+
+// replayChanges completa el estado de los cambios cuyo evento no lo tiene guardado repitiendo el
+// log de sus partidos. missing asocia el ID de cada evento con su posición en changes.
+func replayChanges(changes []MatchChange, missing map[int64]int) error {
+	var matchIDs []int64
+	seen := map[int]bool{}
+	for _, i := range missing {
+		if id := changes[i].Event.MatchID; !seen[id] {
+			seen[id] = true
+			matchIDs = append(matchIDs, int64(id))
+		}
+	}
+	events, err := queryEvents(DB, "WHERE match_id = ANY($1)", pq.Array(matchIDs))
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+	var state matchState
+	for i, e := range events {
+		if i == 0 || e.MatchID != events[i-1].MatchID {
+			state = matchState{}
+		}
+		if err := state.apply(e); err != nil {
+			return fmt.Errorf("error al aplicar el evento %d del partido %d: %w", e.Sequence, e.MatchID, err)
+		}
+		if j, ok := missing[e.ID]; ok {
+			changes[j].Match, changes[j].Deleted = state.Match, state.DeletedAt != nil
+		}
+	}
+	return nil
+}
+
+// changesChannel es el canal de LISTEN/NOTIFY en el que se anuncian los eventos nuevos.
+// El payload es un JSON con eventId, matchId y type, {"commentaryId": ...} para los comentarios, o
+// {"import": true} después de restaurar un respaldo; los lectores igualmente consultan el log
+// a partir del último evento procesado, por lo que el payload solo sirve para diagnóstico.
+const changesChannel = "match_changes"
+
+// ListenChanges escucha changesChannel y avisa a los suscriptores locales de cada evento confirmado,
+// incluidos los que registran otras réplicas. Si la conexión se pierde, pq.Listener se reconecta y
+// se emite un aviso para que los suscriptores recuperen lo ocurrido durante el corte.
+// Bloquea hasta que el proceso termina, por lo que debe ejecutarse en una goroutine.
+func ListenChanges() {
+	listener := pq.NewListener(connectionString(), time.Second, time.Minute, func(event pq.ListenerEventType, err error) {
+		switch event {
+		case pq.ListenerEventConnectionAttemptFailed, pq.ListenerEventDisconnected:
+			log.Printf("conexión de LISTEN %s perdida: %v", changesChannel, err)
+		case pq.ListenerEventReconnected:
+			log.Printf("conexión de LISTEN %s restablecida", changesChannel)
+		}
+	})
+	defer listener.Close()
+
+	for {
+		err := listener.Listen(changesChannel)
+		if err == nil || errors.Is(err, pq.ErrChannelAlreadyOpen) {
+			break
+		}
+		log.Printf("error al escuchar %s: %v", changesChannel, err)
+		time.Sleep(time.Second)
+	}
+
+	for {
+		select {
+		case <-listener.Notify:
+			// Una notificación nil indica una reconexión, después de la cual también hay que ponerse al día
+			notifyChanges()
+		case <-time.After(90 * time.Second):
+			// El ping detecta conexiones caídas que no generaron errores
+			go listener.Ping()
+		}
+	}
+}
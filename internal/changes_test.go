@@ -0,0 +1,80 @@
+package internal
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestProjectionRow(t *testing.T) {
+	started := time.Date(2025, 3, 1, 20, 5, 0, 0, time.UTC)
+	deleted := time.Date(2025, 3, 2, 9, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name  string
+		state matchState
+	}{
+		{
+			name: "partido programado",
+			state: matchState{Match: Match{ID: 1, HomeTeam: "Sevilla", AwayTeam: "Betis",
+				MatchDate: time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC), Version: 1, DateOnly: true}},
+		},
+		{
+			name: "partido en juego con reloj",
+			state: matchState{
+				Match: Match{ID: 2, HomeTeam: "Real Madrid", AwayTeam: "Barcelona",
+					MatchDate: time.Date(2025, 3, 1, 20, 0, 0, 0, time.UTC), Version: 7,
+					Goals: 3, YellowCards: 2, RedCards: 1, ExtraTime: true},
+				Clock: matchClock{Period: PeriodSecondHalf, StartedAt: &started, Stoppage: 4},
+			},
+		},
+		{
+			name: "partido finalizado en la papelera",
+			state: matchState{
+				Match:     Match{ID: 3, HomeTeam: "Getafe", AwayTeam: "Girona", Version: 9, Finished: true},
+				DeletedAt: &deleted,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			encoded, err := json.Marshal(newProjectionRow(tt.state))
+			if err != nil {
+				t.Fatal(err)
+			}
+			var row projectionRow
+			if err := json.Unmarshal(encoded, &row); err != nil {
+				t.Fatal(err)
+			}
+			if got := row.state(); !reflect.DeepEqual(got, tt.state) {
+				t.Errorf("estado = %+v, se esperaba %+v", got, tt.state)
+			}
+		})
+	}
+}
+
+// TestProjectionRowFromDatabase verifica que se lea el estado que guarda init.sql con to_jsonb,
+// que usa el formato de fechas de PostgreSQL e incluye columnas que la proyección no usa.
+func TestProjectionRowFromDatabase(t *testing.T) {
+	stored := `{"id": 4, "home_team": "Valencia", "away_team": "Villarreal",
+		"match_date": "2024-08-18T00:00:00+00:00", "goals_match": 0, "yellow_cards_match": 0,
+		"red_cards_match": 0, "extra_time": false, "finished": false, "date_only": true, "version": 1,
+		"deleted_at": null, "clock_period": null, "clock_started_at": null, "clock_stoppage": null}`
+
+	var row projectionRow
+	if err := json.Unmarshal([]byte(stored), &row); err != nil {
+		t.Fatal(err)
+	}
+	got := row.state()
+	want := matchState{Match: Match{ID: 4, HomeTeam: "Valencia", AwayTeam: "Villarreal",
+		MatchDate: time.Date(2024, 8, 18, 0, 0, 0, 0, time.UTC), Version: 1, DateOnly: true}}
+	if !got.MatchDate.Equal(want.MatchDate) {
+		t.Errorf("matchDate = %v, se esperaba %v", got.MatchDate, want.MatchDate)
+	}
+	got.MatchDate = want.MatchDate
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("estado = %+v, se esperaba %+v", got, want)
+	}
+}
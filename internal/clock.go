@@ -0,0 +1,236 @@
+package internal
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// Periodos de juego del reloj del partido, en el orden en que se disputan.
+const (
+	PeriodFirstHalf       = "first_half"
+	PeriodSecondHalf      = "second_half"
+	PeriodExtraTimeFirst  = "extra_time_first"
+	PeriodExtraTimeSecond = "extra_time_second"
+)
+
+// Estados del reloj informados por GetMatchClock.
+const (
+	ClockPreMatch = "pre_match"
+	ClockInPlay   = "in_play"
+	ClockBreak    = "break"
+	ClockFinished = "finished"
+)
+
+// MaxStoppageMinutes es el máximo de minutos de descuento que puede anunciarse en un periodo.
+const MaxStoppageMinutes = 30
+
+// clockPeriod describe un periodo de juego: el minuto en que comienza y su duración reglamentaria.
+type clockPeriod struct {
+	Name   string
+	Start  int
+	Length int
+}
+
+// clockPeriods son los periodos de un partido en orden. Los dos tiempos extra solo se juegan si
+// se inician explícitamente después del segundo tiempo.
+var clockPeriods = []clockPeriod{
+	{Name: PeriodFirstHalf, Start: 0, Length: 45},
+	{Name: PeriodSecondHalf, Start: 45, Length: 45},
+	{Name: PeriodExtraTimeFirst, Start: 90, Length: 15},
+	{Name: PeriodExtraTimeSecond, Start: 105, Length: 15},
+}
+
+// Errores de los comandos del reloj.
+var (
+	ErrClockRunning    = errors.New("el reloj del partido ya está en marcha")
+	ErrClockStopped    = errors.New("el reloj del partido está detenido")
+	ErrInvalidPeriod   = errors.New("el periodo no corresponde al siguiente del partido")
+	ErrInvalidStoppage = errors.New("minutos de descuento inválidos")
+)
+
+// PeriodData son los datos de PeriodStarted y PeriodEnded.
+type PeriodData struct {
+	Period string `json:"period"`
+}
+
+// StoppageTimeData son los datos de StoppageTimeAnnounced: los minutos de descuento del periodo en curso.
+type StoppageTimeData struct {
+	Minutes int `json:"minutes"`
+}
+
+// ClockMinute es un minuto del reloj del partido. Stoppage es el minuto de descuento dentro del
+// periodo; por ejemplo el 45+2 es {Minute: 45, Stoppage: 2}.
+type ClockMinute struct {
+	Minute   int `json:"minute"`
+	Stoppage int `json:"stoppage,omitempty"`
+}
+
+// String retorna el minuto en la notación habitual, por ejemplo "23'" o "90+4'".
+func (m ClockMinute) String() string {
+	if m.Stoppage > 0 {
+		return strconv.Itoa(m.Minute) + "+" + strconv.Itoa(m.Stoppage) + "'"
+	}
+	return strconv.Itoa(m.Minute) + "'"
+}
+
+// matchClock es el estado del reloj de un partido reconstruido a partir de sus eventos.
+type matchClock struct {
+	// Period es el último periodo iniciado; vacío antes del saque inicial.
+	Period string
+	// StartedAt es el inicio del periodo en curso; nil si el reloj está detenido.
+	StartedAt *time.Time
+	// Stoppage son los minutos de descuento anunciados en el periodo en curso.
+	Stoppage int
+}
+
+// findPeriod retorna la posición del periodo en clockPeriods, o -1 si no existe.
+func findPeriod(name string) int {
+	for i, p := range clockPeriods {
+		if p.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// minuteAt retorna el minuto del reloj en el instante t, o nil si el reloj está detenido.
+// El primer minuto de cada periodo es el siguiente a su inicio: el saque inicial ocurre en el minuto 1.
+func (c matchClock) minuteAt(t time.Time) *ClockMinute {
+	i := findPeriod(c.Period)
+	if c.StartedAt == nil || i < 0 {
+		return nil
+	}
+	period := clockPeriods[i]
+	elapsed := max(int(t.Sub(*c.StartedAt)/time.Minute), 0)
+	minute := period.Start + elapsed + 1
+	if end := period.Start + period.Length; minute > end {
+		return &ClockMinute{Minute: end, Stoppage: minute - end}
+	}
+	return &ClockMinute{Minute: minute}
+}
+
+// applyClock aplica al estado un evento del reloj.
+func (s *matchState) applyClock(e MatchEvent) error {
+	switch e.Type {
+	case EventPeriodStarted:
+		var data PeriodData
+		if err := json.Unmarshal(e.Data, &data); err != nil {
+			return fmt.Errorf("evento %s inválido: %v", e.Type, err)
+		}
+		if s.Clock.StartedAt != nil {
+			return ErrClockRunning
+		}
+		if next := findPeriod(s.Clock.Period) + 1; next >= len(clockPeriods) || clockPeriods[next].Name != data.Period {
+			return fmt.Errorf("%w: %q", ErrInvalidPeriod, data.Period)
+		}
+		startedAt := e.OccurredAt
+		s.Clock = matchClock{Period: data.Period, StartedAt: &startedAt}
+		if data.Period == PeriodExtraTimeFirst {
+			s.ExtraTime = true
+		}
+	case EventPeriodEnded:
+		if s.Clock.StartedAt == nil {
+			return ErrClockStopped
+		}
+		s.Clock.StartedAt, s.Clock.Stoppage = nil, 0
+	case EventStoppageAnnounced:
+		var data StoppageTimeData
+		if err := json.Unmarshal(e.Data, &data); err != nil {
+			return fmt.Errorf("evento %s inválido: %v", e.Type, err)
+		}
+		if s.Clock.StartedAt == nil {
+			return ErrClockStopped
+		}
+		if data.Minutes < 1 || data.Minutes > MaxStoppageMinutes {
+			return fmt.Errorf("%w: %d", ErrInvalidStoppage, data.Minutes)
+		}
+		s.Clock.Stoppage = data.Minutes
+	}
+	return nil
+}
+
+// MatchClock es el estado del reloj de un partido en un instante.
+type MatchClock struct {
+	MatchID int    `json:"matchId"`
+	Status  string `json:"status" enums:"pre_match,in_play,break,finished"`
+	// Period es el periodo en curso o, durante un descanso, el último disputado.
+	Period  string `json:"period,omitempty" enums:"first_half,second_half,extra_time_first,extra_time_second"`
+	Running bool   `json:"running"`
+	// Minute es el minuto actual mientras el reloj está en marcha.
+	Minute  *ClockMinute `json:"minute,omitempty"`
+	Display string       `json:"display" example:"45+2'"`
+	// AnnouncedStoppage son los minutos de descuento anunciados en el periodo en curso.
+	AnnouncedStoppage int        `json:"announcedStoppage"`
+	PeriodStartedAt   *time.Time `json:"periodStartedAt,omitempty"`
+	ServerTime        time.Time  `json:"serverTime"`
+}
+
+// clockAt construye el estado del reloj del partido en el instante now.
+func (s matchState) clockAt(now time.Time) MatchClock {
+	clock := MatchClock{
+		MatchID:           s.ID,
+		Period:            s.Clock.Period,
+		Running:           s.Clock.StartedAt != nil,
+		AnnouncedStoppage: s.Clock.Stoppage,
+		PeriodStartedAt:   s.Clock.StartedAt,
+		ServerTime:        now,
+	}
+	switch {
+	case clock.Running:
+		clock.Status = ClockInPlay
+		clock.Minute = s.Clock.minuteAt(now)
+		clock.Display = clock.Minute.String()
+	case s.Finished:
+		clock.Status, clock.Display = ClockFinished, "FT"
+	case s.Clock.Period == "":
+		clock.Status = ClockPreMatch
+	case s.Clock.Period == PeriodFirstHalf:
+		clock.Status, clock.Display = ClockBreak, "HT"
+	default:
+		period := clockPeriods[findPeriod(s.Clock.Period)]
+		clock.Status, clock.Display = ClockBreak, strconv.Itoa(period.Start+period.Length)+"'"
+	}
+	return clock
+}
+
+// GetMatchClock retorna el reloj del partido en este instante.
+// Retorna sql.ErrNoRows si el partido no existe o está en la papelera.
+func GetMatchClock(id int) (MatchClock, error) {
+	state, err := scanMatchState(DB.QueryRow("SELECT "+stateColumns+" FROM matches WHERE id = $1 AND deleted_at IS NULL", id))
+	if err != nil {
+		return MatchClock{}, err
+	}
+	return state.clockAt(time.Now().UTC()), nil
+}
+
+// StartPeriod registra el inicio del periodo indicado, que debe ser el siguiente del partido:
+// el saque inicial es el inicio de PeriodFirstHalf. Iniciar PeriodExtraTimeFirst marca además
+// el partido con tiempo extra.
+func StartPeriod(id, version int, period string, info AuditInfo) error {
+	return inTx(func(tx *sql.Tx) error {
+		return appendEvent(tx, info, AuditClock, id, version, EventPeriodStarted, PeriodData{Period: period})
+	})
+}
+
+// EndPeriod registra el final del periodo en curso (por ejemplo el descanso). El pitazo final
+// se registra con FinishMatch, que también detiene el reloj.
+func EndPeriod(id, version int, info AuditInfo) error {
+	return inTx(func(tx *sql.Tx) error {
+		state, err := scanMatchState(tx.QueryRow("SELECT "+stateColumns+" FROM matches WHERE id = $1", id))
+		if err != nil {
+			return err
+		}
+		return appendEvent(tx, info, AuditClock, id, version, EventPeriodEnded, PeriodData{Period: state.Clock.Period})
+	})
+}
+
+// AnnounceStoppage registra los minutos de descuento anunciados para el periodo en curso.
+func AnnounceStoppage(id, version, minutes int, info AuditInfo) error {
+	return inTx(func(tx *sql.Tx) error {
+		return appendEvent(tx, info, AuditClock, id, version, EventStoppageAnnounced, StoppageTimeData{Minutes: minutes})
+	})
+}
@@ -0,0 +1,124 @@
+package internal
+
+import (
+	"testing"
+	"time"
+)
+
+func TestClockAt(t *testing.T) {
+	kickoff := time.Date(2025, 3, 1, 20, 0, 0, 0, time.UTC)
+	at := func(minutes, seconds int) time.Time {
+		return kickoff.Add(time.Duration(minutes)*time.Minute + time.Duration(seconds)*time.Second)
+	}
+
+	tests := []struct {
+		name        string
+		state       matchState
+		now         time.Time
+		wantStatus  string
+		wantDisplay string
+		wantMinute  *ClockMinute
+	}{
+		{
+			name:       "antes del saque inicial",
+			state:      matchState{},
+			now:        kickoff,
+			wantStatus: ClockPreMatch,
+		},
+		{
+			name:        "el saque inicial es el minuto 1",
+			state:       matchState{Clock: matchClock{Period: PeriodFirstHalf, StartedAt: &kickoff}},
+			now:         at(0, 30),
+			wantStatus:  ClockInPlay,
+			wantDisplay: "1'",
+			wantMinute:  &ClockMinute{Minute: 1},
+		},
+		{
+			name:        "último minuto reglamentario del primer tiempo",
+			state:       matchState{Clock: matchClock{Period: PeriodFirstHalf, StartedAt: &kickoff}},
+			now:         at(44, 59),
+			wantStatus:  ClockInPlay,
+			wantDisplay: "45'",
+			wantMinute:  &ClockMinute{Minute: 45},
+		},
+		{
+			name:        "descuento del primer tiempo",
+			state:       matchState{Clock: matchClock{Period: PeriodFirstHalf, StartedAt: &kickoff, Stoppage: 3}},
+			now:         at(46, 10),
+			wantStatus:  ClockInPlay,
+			wantDisplay: "45+2'",
+			wantMinute:  &ClockMinute{Minute: 45, Stoppage: 2},
+		},
+		{
+			name:        "segundo tiempo continúa desde el 46",
+			state:       matchState{Clock: matchClock{Period: PeriodSecondHalf, StartedAt: &kickoff}},
+			now:         at(0, 5),
+			wantStatus:  ClockInPlay,
+			wantDisplay: "46'",
+			wantMinute:  &ClockMinute{Minute: 46},
+		},
+		{
+			name:        "descuento del segundo tiempo",
+			state:       matchState{Clock: matchClock{Period: PeriodSecondHalf, StartedAt: &kickoff}},
+			now:         at(48, 0),
+			wantStatus:  ClockInPlay,
+			wantDisplay: "90+4'",
+			wantMinute:  &ClockMinute{Minute: 90, Stoppage: 4},
+		},
+		{
+			name:        "segundo tiempo extra",
+			state:       matchState{Clock: matchClock{Period: PeriodExtraTimeSecond, StartedAt: &kickoff}},
+			now:         at(10, 0),
+			wantStatus:  ClockInPlay,
+			wantDisplay: "116'",
+			wantMinute:  &ClockMinute{Minute: 116},
+		},
+		{
+			name:        "hora del servidor anterior al inicio del periodo",
+			state:       matchState{Clock: matchClock{Period: PeriodFirstHalf, StartedAt: &kickoff}},
+			now:         at(0, -20),
+			wantStatus:  ClockInPlay,
+			wantDisplay: "1'",
+			wantMinute:  &ClockMinute{Minute: 1},
+		},
+		{
+			name:        "medio tiempo",
+			state:       matchState{Clock: matchClock{Period: PeriodFirstHalf}},
+			now:         at(50, 0),
+			wantStatus:  ClockBreak,
+			wantDisplay: "HT",
+		},
+		{
+			name:        "descanso antes del tiempo extra",
+			state:       matchState{Clock: matchClock{Period: PeriodSecondHalf}},
+			now:         at(50, 0),
+			wantStatus:  ClockBreak,
+			wantDisplay: "90'",
+		},
+		{
+			name:        "partido finalizado",
+			state:       matchState{Match: Match{Finished: true}, Clock: matchClock{Period: PeriodSecondHalf}},
+			now:         at(120, 0),
+			wantStatus:  ClockFinished,
+			wantDisplay: "FT",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clock := tt.state.clockAt(tt.now)
+			if clock.Status != tt.wantStatus || clock.Display != tt.wantDisplay {
+				t.Errorf("reloj = %s %q, se esperaba %s %q", clock.Status, clock.Display, tt.wantStatus, tt.wantDisplay)
+			}
+			if clock.Running != (tt.wantStatus == ClockInPlay) {
+				t.Errorf("running = %v con status %s", clock.Running, clock.Status)
+			}
+			switch {
+			case tt.wantMinute == nil && clock.Minute != nil:
+				t.Errorf("minuto = %+v, se esperaba ninguno", *clock.Minute)
+			case tt.wantMinute != nil && (clock.Minute == nil || *clock.Minute != *tt.wantMinute):
+				t.Errorf("minuto = %v, se esperaba %+v", clock.Minute, *tt.wantMinute)
+			}
+		})
+	}
+}
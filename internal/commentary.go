@@ -0,0 +1,324 @@
+package internal
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// commentaryLockKey identifica, junto con el ID del partido, el advisory lock que serializa las
+// escrituras de comentarios de cada partido, para que el minuto del reloj y el evento enlazado se
+// lean sin que otra escritura del mismo partido cambie entre medio.
+const commentaryLockKey = 4_271_002
+
+// ErrCommentaryEvent indica que el evento enlazado no pertenece al partido del comentario.
+var ErrCommentaryEvent = errors.New("el evento no pertenece al partido")
+
+// CommentaryEntry es un comentario minuto a minuto de un partido.
+type CommentaryEntry struct {
+	ID       int    `json:"id"`
+	MatchID  int    `json:"matchId"`
+	Minute   *int   `json:"minute,omitempty" example:"45"`
+	Stoppage int    `json:"stoppage,omitempty" example:"2"`
+	Text     string `json:"text" example:"Disparo al palo desde fuera del área"`
+	EventID  *int64 `json:"eventId,omitempty"`
+	Pinned   bool   `json:"pinned"`
+	Author   string `json:"author"`
+	// Revision cambia con cada alta, edición o eliminación del comentario.
+	Revision  int64     `json:"revision"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+	// DeletedAt solo se informa en los cambios que recibe el hub cuando se elimina el comentario.
+	DeletedAt *time.Time `json:"deletedAt,omitempty"`
+}
+
+// CommentaryPatch son los cambios de una edición; los campos nil no se modifican.
+type CommentaryPatch struct {
+	Text     *string
+	Minute   *int
+	Stoppage *int
+	EventID  *int64
+	Pinned   *bool
+}
+
+// CommentaryFilter pagina los comentarios de un partido. Pinned en true retorna solo los destacados.
+type CommentaryFilter struct {
+	Pinned bool
+	Limit  int
+	Offset int
+}
+
+// CommentaryCursor es la posición de un lector de GetCommentaryChanges: la transacción y la
+// revisión del último cambio leído.
+type CommentaryCursor struct {
+	TxID     int64
+	Revision int64
+}
+
+// CommentaryChange es un comentario nuevo, editado o eliminado junto con los equipos de su partido
+// y la posición del cambio.
+type CommentaryChange struct {
+	Entry    CommentaryEntry
+	HomeTeam string
+	AwayTeam string
+	Cursor   CommentaryCursor
+}
+
+const commentaryColumns = "id, match_id, minute, stoppage, text, event_id, pinned, author, revision, created_at, updated_at, deleted_at"
+
+// scanCommentary lee un comentario a partir de una fila con commentaryColumns, seguida de las
+// columnas adicionales de extra.
+func scanCommentary(row rowScanner, extra ...any) (CommentaryEntry, error) {
+	var e CommentaryEntry
+	var stoppage sql.NullInt64
+	err := row.Scan(append([]any{&e.ID, &e.MatchID, &e.Minute, &stoppage, &e.Text, &e.EventID, &e.Pinned,
+		&e.Author, &e.Revision, &e.CreatedAt, &e.UpdatedAt, &e.DeletedAt}, extra...)...)
+	e.Stoppage = int(stoppage.Int64)
+	return e, err
+}
+
+// commentaryMinute resuelve el minuto de un comentario nuevo: el del evento enlazado si tiene uno,
+// o el minuto actual del reloj del partido si está en marcha. Verifica que el evento sea del partido.
+func commentaryMinute(tx *sql.Tx, state matchState, eventID *int64) (*ClockMinute, error) {
+	if eventID == nil {
+		return state.Clock.minuteAt(time.Now().UTC()), nil
+	}
+	var minute, stoppage sql.NullInt64
+	err := tx.QueryRow("SELECT clock_minute, clock_stoppage FROM match_events WHERE id = $1 AND match_id = $2",
+		*eventID, state.ID).Scan(&minute, &stoppage)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrCommentaryEvent
+	}
+	if err != nil || !minute.Valid {
+		return nil, err
+	}
+	return &ClockMinute{Minute: int(minute.Int64), Stoppage: int(stoppage.Int64)}, nil
+}
+
+// lockCommentary toma el lock de escrituras de comentarios del partido y verifica que exista.
+// Retorna sql.ErrNoRows si el partido no existe o está en la papelera.
+func lockCommentary(tx *sql.Tx, matchID int) (matchState, error) {
+	if _, err := tx.Exec("SELECT pg_advisory_xact_lock($1, $2)", commentaryLockKey, matchID); err != nil {
+		return matchState{}, fmt.Errorf("error al bloquear los comentarios: %v", err)
+	}
+	return scanMatchState(tx.QueryRow("SELECT "+stateColumns+" FROM matches WHERE id = $1 AND deleted_at IS NULL", matchID))
+}
+
+// notifyCommentary anuncia el comentario en changesChannel para que el hub de cada réplica lo difunda.
+func notifyCommentary(tx *sql.Tx, id int) error {
+	_, err := tx.Exec("SELECT pg_notify($1, json_build_object('commentaryId', $2::int)::text)", changesChannel, id)
+	if err != nil {
+		return fmt.Errorf("error al anunciar el comentario: %v", err)
+	}
+	return nil
+}
+
+// CreateCommentary registra un comentario. Si no indica minuto se usa el del evento enlazado o,
+// si no hay evento, el minuto actual del reloj. Retorna sql.ErrNoRows si el partido no existe y
+// ErrCommentaryEvent si el evento no es del partido.
+func CreateCommentary(entry CommentaryEntry, info AuditInfo) (CommentaryEntry, error) {
+	var created CommentaryEntry
+	err := inTx(func(tx *sql.Tx) error {
+		if err := completeIdempotencyKey(tx, info); err != nil {
+			return err
+		}
+		state, err := lockCommentary(tx, entry.MatchID)
+		if err != nil {
+			return err
+		}
+		clock, err := commentaryMinute(tx, state, entry.EventID)
+		if err != nil {
+			return err
+		}
+		if entry.Minute == nil && clock != nil {
+			entry.Minute, entry.Stoppage = &clock.Minute, clock.Stoppage
+		}
+
+		var stoppage *int
+		if entry.Minute != nil && entry.Stoppage > 0 {
+			stoppage = &entry.Stoppage
+		}
+		created, err = scanCommentary(tx.QueryRow(`
+            INSERT INTO match_commentary (match_id, minute, stoppage, text, event_id, pinned, author)
+            VALUES ($1, $2, $3, $4, $5, $6, $7)
+            RETURNING `+commentaryColumns,
+			entry.MatchID, entry.Minute, stoppage, entry.Text, entry.EventID, entry.Pinned, entry.Author))
+		if err != nil {
+			return fmt.Errorf("error al registrar el comentario: %v", err)
+		}
+		return notifyCommentary(tx, created.ID)
+	})
+	return created, err
+}
+
+// UpdateCommentary aplica la edición al comentario del partido y le asigna una nueva revisión.
+// Retorna sql.ErrNoRows si el partido o el comentario no existen.
+func UpdateCommentary(matchID, id int, patch CommentaryPatch, info AuditInfo) (CommentaryEntry, error) {
+	var updated CommentaryEntry
+	err := inTx(func(tx *sql.Tx) error {
+		if err := completeIdempotencyKey(tx, info); err != nil {
+			return err
+		}
+		state, err := lockCommentary(tx, matchID)
+		if err != nil {
+			return err
+		}
+		if patch.EventID != nil {
+			if _, err := commentaryMinute(tx, state, patch.EventID); err != nil {
+				return err
+			}
+		}
+
+		sets := []string{"updated_at = NOW()", "revision = nextval('match_commentary_revision_seq')", "tx_id = pg_current_xact_id()"}
+		args := []any{matchID, id}
+		set := func(column string, value any) {
+			args = append(args, value)
+			sets = append(sets, fmt.Sprintf("%s = $%d", column, len(args)))
+		}
+		if patch.Text != nil {
+			set("text", *patch.Text)
+		}
+		if patch.Minute != nil {
+			set("minute", *patch.Minute)
+		}
+		if patch.Stoppage != nil {
+			set("stoppage", *patch.Stoppage)
+		}
+		if patch.EventID != nil {
+			set("event_id", *patch.EventID)
+		}
+		if patch.Pinned != nil {
+			set("pinned", *patch.Pinned)
+		}
+
+		updated, err = scanCommentary(tx.QueryRow(`
+            UPDATE match_commentary SET `+strings.Join(sets, ", ")+`
+            WHERE match_id = $1 AND id = $2 AND deleted_at IS NULL
+            RETURNING `+commentaryColumns, args...))
+		if err != nil {
+			return err
+		}
+		return notifyCommentary(tx, updated.ID)
+	})
+	return updated, err
+}
+
+// DeleteCommentary elimina el comentario del partido. El registro se conserva marcado como
+// eliminado con una nueva revisión, para que el hub avise a los suscriptores.
+// Retorna sql.ErrNoRows si el partido o el comentario no existen.
+func DeleteCommentary(matchID, id int, info AuditInfo) error {
+	return inTx(func(tx *sql.Tx) error {
+		if err := completeIdempotencyKey(tx, info); err != nil {
+			return err
+		}
+		if _, err := lockCommentary(tx, matchID); err != nil {
+			return err
+		}
+		result, err := tx.Exec(`
+            UPDATE match_commentary
+            SET deleted_at = NOW(), updated_at = NOW(),
+                revision = nextval('match_commentary_revision_seq'), tx_id = pg_current_xact_id()
+            WHERE match_id = $1 AND id = $2 AND deleted_at IS NULL
+        `, matchID, id)
+		if err != nil {
+			return fmt.Errorf("error al eliminar el comentario: %v", err)
+		}
+		if n, _ := result.RowsAffected(); n == 0 {
+			return sql.ErrNoRows
+		}
+		return notifyCommentary(tx, id)
+	})
+}
+
+// GetCommentary obtiene los comentarios del partido, del más reciente al más antiguo, y la
+// cantidad total que cumple el filtro. Retorna sql.ErrNoRows si el partido no existe.
+func GetCommentary(matchID int, filter CommentaryFilter) ([]CommentaryEntry, int, error) {
+	var exists bool
+	err := DB.QueryRow("SELECT EXISTS (SELECT 1 FROM matches WHERE id = $1 AND deleted_at IS NULL)", matchID).Scan(&exists)
+	if err != nil {
+		return nil, 0, err
+	}
+	if !exists {
+		return nil, 0, sql.ErrNoRows
+	}
+
+	var total int
+	err = DB.QueryRow("SELECT count(*) FROM match_commentary WHERE match_id = $1 AND deleted_at IS NULL AND (pinned OR NOT $2)",
+		matchID, filter.Pinned).Scan(&total)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error al contar los comentarios: %v", err)
+	}
+
+	rows, err := DB.Query(`
+        SELECT `+commentaryColumns+`
+        FROM match_commentary
+        WHERE match_id = $1 AND deleted_at IS NULL AND (pinned OR NOT $2)
+        ORDER BY id DESC
+        LIMIT $3 OFFSET $4
+    `, matchID, filter.Pinned, filter.Limit, filter.Offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error al consultar los comentarios: %v", err)
+	}
+	defer rows.Close()
+
+	entries := []CommentaryEntry{}
+	for rows.Next() {
+		entry, err := scanCommentary(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		entries = append(entries, entry)
+	}
+	return entries, total, rows.Err()
+}
+
+// LatestCommentaryCursor retorna la posición del último cambio de comentarios, en el orden en que
+// los recorre GetCommentaryChanges, o la posición inicial si no hay ninguno.
+func LatestCommentaryCursor() (CommentaryCursor, error) {
+	var cursor CommentaryCursor
+	err := DB.QueryRow(`
+        SELECT tx_id, revision FROM match_commentary
+        WHERE `+streamHorizon+`
+        ORDER BY tx_id DESC, revision DESC
+        LIMIT 1
+    `).Scan(&cursor.TxID, &cursor.Revision)
+	if errors.Is(err, sql.ErrNoRows) {
+		return CommentaryCursor{}, nil
+	}
+	return cursor, err
+}
+
+// GetCommentaryChanges retorna hasta limit comentarios creados, editados o eliminados después de la
+// posición after, en orden de confirmación, junto con los equipos de su partido. Como en el log de
+// eventos, se recorren por (tx_id, revision) hasta streamHorizon: las escrituras de partidos
+// distintos no se serializan, por lo que una revisión menor puede confirmarse después de una mayor.
+func GetCommentaryChanges(after CommentaryCursor, limit int) ([]CommentaryChange, error) {
+	rows, err := DB.Query(`
+        SELECT c.id, c.match_id, c.minute, c.stoppage, c.text, c.event_id, c.pinned, c.author,
+               c.revision, c.created_at, c.updated_at, c.deleted_at, m.home_team, m.away_team, c.tx_id
+        FROM match_commentary c
+        JOIN matches m ON m.id = c.match_id
+        WHERE c.`+streamHorizon+`
+          AND (c.tx_id > $1::xid8 OR (c.tx_id = $1::xid8 AND c.revision > $2))
+        ORDER BY c.tx_id, c.revision
+        LIMIT $3
+    `, after.TxID, after.Revision, limit)
+	if err != nil {
+		return nil, fmt.Errorf("error al consultar los comentarios: %v", err)
+	}
+	defer rows.Close()
+
+	var changes []CommentaryChange
+	for rows.Next() {
+		var change CommentaryChange
+		entry, err := scanCommentary(rows, &change.HomeTeam, &change.AwayTeam, &change.Cursor.TxID)
+		if err != nil {
+			return nil, err
+		}
+		change.Entry, change.Cursor.Revision = entry, entry.Revision
+		changes = append(changes, change)
+	}
+	return changes, rows.Err()
+}
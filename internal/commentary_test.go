@@ -0,0 +1,316 @@
+package internal
+
+import (
+	"database/sql"
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+// commentaryRowColumns son las columnas de commentaryColumns en las filas simuladas.
+var commentaryRowColumns = []string{"id", "match_id", "minute", "stoppage", "text", "event_id", "pinned",
+	"author", "revision", "created_at", "updated_at", "deleted_at"}
+
+// expectCommentaryLock prepara el lock por partido y la lectura de su estado. Si startedAt no es
+// nil el reloj del segundo tiempo está en marcha desde ese instante.
+func expectCommentaryLock(mock sqlmock.Sqlmock, matchID int, startedAt *time.Time) {
+	mock.ExpectExec(regexp.QuoteMeta("SELECT pg_advisory_xact_lock($1, $2)")).
+		WithArgs(commentaryLockKey, matchID).WillReturnResult(sqlmock.NewResult(0, 0))
+	rows := sqlmock.NewRows([]string{"id", "home_team", "away_team", "match_date", "version", "goals_match",
+		"yellow_cards_match", "red_cards_match", "extra_time", "finished", "date_only", "deleted_at",
+		"clock_period", "clock_started_at", "clock_stoppage"})
+	if matchID > 0 {
+		period := ""
+		if startedAt != nil {
+			period = PeriodSecondHalf
+		}
+		rows.AddRow(matchID, "Sevilla", "Betis", time.Date(2025, 3, 1, 20, 0, 0, 0, time.UTC), 4, 0, 0, 0, false, false,
+			false, nil, period, startedAt, 0)
+	}
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT " + stateColumns + " FROM matches WHERE id = $1 AND deleted_at IS NULL")).
+		WithArgs(matchID).WillReturnRows(rows)
+}
+
+// commentaryRow retorna una fila de comentario con el minuto indicado.
+func commentaryRow(rows *sqlmock.Rows, id int, minute, stoppage any, revision int64) *sqlmock.Rows {
+	created := time.Date(2025, 3, 1, 21, 0, 0, 0, time.UTC)
+	return rows.AddRow(id, 7, minute, stoppage, "Disparo al palo", nil, false, "redactor", revision, created, created, nil)
+}
+
+func TestCreateCommentaryMinute(t *testing.T) {
+	startedAt := time.Now().Add(-50*time.Minute - 30*time.Second)
+	eventID := int64(12)
+	minute := 30
+
+	tests := []struct {
+		name         string
+		entry        CommentaryEntry
+		startedAt    *time.Time
+		event        func(mock sqlmock.Sqlmock)
+		wantMinute   any
+		wantStoppage any
+		wantErr      error
+	}{
+		{
+			name:       "sin reloj ni evento queda sin minuto",
+			entry:      CommentaryEntry{MatchID: 7, Text: "Previa"},
+			wantMinute: nil, wantStoppage: nil,
+		},
+		{
+			name:       "minuto del reloj en marcha con descuento",
+			entry:      CommentaryEntry{MatchID: 7, Text: "Ataque"},
+			startedAt:  &startedAt,
+			wantMinute: 90, wantStoppage: 6,
+		},
+		{
+			name:       "el minuto indicado prevalece sobre el reloj",
+			entry:      CommentaryEntry{MatchID: 7, Text: "Repaso", Minute: &minute},
+			startedAt:  &startedAt,
+			wantMinute: 30, wantStoppage: nil,
+		},
+		{
+			name:      "minuto del evento enlazado",
+			entry:     CommentaryEntry{MatchID: 7, Text: "Gol", EventID: &eventID},
+			startedAt: &startedAt,
+			event: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT clock_minute, clock_stoppage FROM match_events").WithArgs(eventID, 7).
+					WillReturnRows(sqlmock.NewRows([]string{"clock_minute", "clock_stoppage"}).AddRow(45, 2))
+			},
+			wantMinute: 45, wantStoppage: 2,
+		},
+		{
+			name:  "evento de otro partido",
+			entry: CommentaryEntry{MatchID: 7, Text: "Gol", EventID: &eventID},
+			event: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT clock_minute, clock_stoppage FROM match_events").WithArgs(eventID, 7).
+					WillReturnError(sql.ErrNoRows)
+			},
+			wantErr: ErrCommentaryEvent,
+		},
+		{
+			name:    "partido inexistente",
+			entry:   CommentaryEntry{Text: "Gol"},
+			wantErr: sql.ErrNoRows,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer db.Close()
+			previous := DB
+			DB = db
+			defer func() { DB = previous }()
+
+			mock.ExpectBegin()
+			expectCommentaryLock(mock, tt.entry.MatchID, tt.startedAt)
+			if tt.event != nil {
+				tt.event(mock)
+			}
+			if tt.wantErr == nil {
+				mock.ExpectQuery("INSERT INTO match_commentary").
+					WithArgs(7, tt.wantMinute, tt.wantStoppage, tt.entry.Text, tt.entry.EventID, false, "").
+					WillReturnRows(commentaryRow(sqlmock.NewRows(commentaryRowColumns), 3, tt.wantMinute, tt.wantStoppage, 10))
+				mock.ExpectExec("SELECT pg_notify").WithArgs(changesChannel, 3).WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectCommit()
+			} else {
+				mock.ExpectRollback()
+			}
+
+			_, err = CreateCommentary(tt.entry, AuditInfo{})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("error = %v, se esperaba %v", err, tt.wantErr)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func TestUpdateCommentary(t *testing.T) {
+	text := "Disparo al larguero"
+	minute := 44
+
+	tests := []struct {
+		name    string
+		found   bool
+		wantErr error
+	}{
+		{name: "edita el texto y el minuto con una nueva revisión", found: true},
+		{name: "comentario inexistente o eliminado", wantErr: sql.ErrNoRows},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer db.Close()
+			previous := DB
+			DB = db
+			defer func() { DB = previous }()
+
+			mock.ExpectBegin()
+			expectCommentaryLock(mock, 7, nil)
+			rows := sqlmock.NewRows(commentaryRowColumns)
+			if tt.found {
+				commentaryRow(rows, 3, minute, nil, 11)
+			}
+			mock.ExpectQuery(`UPDATE match_commentary SET updated_at = NOW\(\), revision = nextval\('match_commentary_revision_seq'\), `+
+				`tx_id = pg_current_xact_id\(\), text = \$3, minute = \$4\s+WHERE match_id = \$1 AND id = \$2 AND deleted_at IS NULL`).
+				WithArgs(7, 3, text, minute).WillReturnRows(rows)
+			if tt.found {
+				mock.ExpectExec("SELECT pg_notify").WithArgs(changesChannel, 3).WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectCommit()
+			} else {
+				mock.ExpectRollback()
+			}
+
+			entry, err := UpdateCommentary(7, 3, CommentaryPatch{Text: &text, Minute: &minute}, AuditInfo{})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("error = %v, se esperaba %v", err, tt.wantErr)
+			}
+			if tt.found && (entry.Revision != 11 || entry.Minute == nil || *entry.Minute != minute) {
+				t.Errorf("comentario = %+v", entry)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func TestDeleteCommentary(t *testing.T) {
+	tests := []struct {
+		name     string
+		affected int64
+		wantErr  error
+	}{
+		{name: "marca el comentario como eliminado y lo anuncia", affected: 1},
+		{name: "comentario inexistente o ya eliminado", affected: 0, wantErr: sql.ErrNoRows},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer db.Close()
+			previous := DB
+			DB = db
+			defer func() { DB = previous }()
+
+			mock.ExpectBegin()
+			expectCommentaryLock(mock, 7, nil)
+			mock.ExpectExec(`UPDATE match_commentary\s+SET deleted_at = NOW\(\), updated_at = NOW\(\),\s+`+
+				`revision = nextval\('match_commentary_revision_seq'\), tx_id = pg_current_xact_id\(\)\s+`+
+				`WHERE match_id = \$1 AND id = \$2 AND deleted_at IS NULL`).
+				WithArgs(7, 3).WillReturnResult(sqlmock.NewResult(0, tt.affected))
+			if tt.wantErr == nil {
+				mock.ExpectExec("SELECT pg_notify").WithArgs(changesChannel, 3).WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectCommit()
+			} else {
+				mock.ExpectRollback()
+			}
+
+			if err := DeleteCommentary(7, 3, AuditInfo{}); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("error = %v, se esperaba %v", err, tt.wantErr)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func TestGetCommentaryNewestFirst(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	previous := DB
+	DB = db
+	defer func() { DB = previous }()
+
+	mock.ExpectQuery("SELECT EXISTS").WithArgs(7).WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	mock.ExpectQuery(`SELECT count\(\*\) FROM match_commentary WHERE match_id = \$1 AND deleted_at IS NULL`).
+		WithArgs(7, false).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(3))
+	// Los comentarios se publican en cualquier orden de minutos, por ejemplo un repaso del primer
+	// tiempo durante el segundo; el listado sigue el orden de publicación
+	rows := sqlmock.NewRows(commentaryRowColumns)
+	commentaryRow(rows, 9, 30, nil, 15)
+	commentaryRow(rows, 8, 88, nil, 14)
+	commentaryRow(rows, 5, 90, 3, 20)
+	mock.ExpectQuery(`WHERE match_id = \$1 AND deleted_at IS NULL AND \(pinned OR NOT \$2\)\s+ORDER BY id DESC`).
+		WithArgs(7, false, 20, 0).WillReturnRows(rows)
+
+	entries, total, err := GetCommentary(7, CommentaryFilter{Limit: 20})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if total != 3 || len(entries) != 3 {
+		t.Fatalf("total = %d, comentarios = %d", total, len(entries))
+	}
+	for i, want := range []int{9, 8, 5} {
+		if entries[i].ID != want {
+			t.Errorf("comentario %d = %d, se esperaba %d", i, entries[i].ID, want)
+		}
+	}
+	if entries[2].Stoppage != 3 || entries[1].Stoppage != 0 {
+		t.Errorf("descuentos = %d y %d", entries[2].Stoppage, entries[1].Stoppage)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestGetCommentaryChanges(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	previous := DB
+	DB = db
+	defer func() { DB = previous }()
+
+	created := time.Date(2025, 3, 1, 21, 0, 0, 0, time.UTC)
+	deleted := created.Add(time.Minute)
+	// La revisión 12 se confirmó en una transacción anterior a la de la revisión 11
+	rows := sqlmock.NewRows(append(commentaryRowColumns, "home_team", "away_team", "tx_id")).
+		AddRow(4, 7, 50, nil, "Córner", nil, false, "redactor", 12, created, created, nil, "Sevilla", "Betis", "900").
+		AddRow(3, 8, 10, nil, "Error", nil, false, "redactor", 11, created, deleted, deleted, "Getafe", "Girona", "901")
+	mock.ExpectQuery(`WHERE c.tx_id < pg_snapshot_xmin\(pg_current_snapshot\(\)\)\s+`+
+		`AND \(c.tx_id > \$1::xid8 OR \(c.tx_id = \$1::xid8 AND c.revision > \$2\)\)\s+ORDER BY c.tx_id, c.revision`).
+		WithArgs(int64(899), int64(10), 50).WillReturnRows(rows)
+
+	changes, err := GetCommentaryChanges(CommentaryCursor{TxID: 899, Revision: 10}, 50)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 2 {
+		t.Fatalf("cambios = %d", len(changes))
+	}
+	if changes[0].Cursor != (CommentaryCursor{TxID: 900, Revision: 12}) || changes[1].Cursor != (CommentaryCursor{TxID: 901, Revision: 11}) {
+		t.Errorf("cursores = %+v y %+v", changes[0].Cursor, changes[1].Cursor)
+	}
+	if changes[0].HomeTeam != "Sevilla" || changes[0].Entry.DeletedAt != nil {
+		t.Errorf("primer cambio = %+v", changes[0])
+	}
+	if changes[1].Entry.DeletedAt == nil || !changes[1].Entry.DeletedAt.Equal(deleted) {
+		t.Errorf("el comentario eliminado no informa deletedAt: %+v", changes[1].Entry)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
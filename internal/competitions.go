@@ -0,0 +1,69 @@
+package internal
+
+import (
+	"fmt"
+
+	"github.com/lib/pq"
+)
+
+// Competition es una competición en la que se juegan partidos. La Liga tiene el ID 1, la
+// competición por defecto de los partidos.
+type Competition struct {
+	ID      int    `json:"id" example:"1"`
+	Name    string `json:"name" example:"La Liga"`
+	Country string `json:"country" example:"España"`
+}
+
+// GetCompetitions obtiene todas las competiciones, ordenadas por ID.
+func GetCompetitions() ([]Competition, error) {
+	rows, err := DB.Query("SELECT id, name, country FROM competitions ORDER BY id")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	competitions := []Competition{}
+	for rows.Next() {
+		var c Competition
+		if err := rows.Scan(&c.ID, &c.Name, &c.Country); err != nil {
+			return nil, err
+		}
+		competitions = append(competitions, c)
+	}
+	return competitions, rows.Err()
+}
+
+// GetCompetitionByID obtiene una competición según su ID.
+func GetCompetitionByID(id int) (Competition, error) {
+	var c Competition
+	err := DB.QueryRow("SELECT id, name, country FROM competitions WHERE id = $1", id).Scan(&c.ID, &c.Name, &c.Country)
+	return c, err
+}
+
+// GetMatchesByCompetition obtiene los partidos de la competición, ordenados por fecha.
+func GetMatchesByCompetition(id int) ([]Match, error) {
+	return queryMatches("WHERE deleted_at IS NULL AND competition_id = $1 ORDER BY match_date, id", id)
+}
+
+// GetMatchCompetitions obtiene la competición de cada uno de los partidos indicados, incluidos los
+// de la papelera. Los partidos que no existen no aparecen en el resultado.
+func GetMatchCompetitions(ids []int) (map[int]int, error) {
+	competitions := map[int]int{}
+	if len(ids) == 0 {
+		return competitions, nil
+	}
+	rows, err := DB.Query("SELECT id, competition_id FROM matches WHERE id = ANY($1)", pq.Array(toInt64s(ids)))
+	if err != nil {
+		return nil, fmt.Errorf("error al consultar las competiciones de los partidos: %v", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id, competition int
+		if err := rows.Scan(&id, &competition); err != nil {
+			return nil, err
+		}
+		competitions[id] = competition
+	}
+	return competitions, rows.Err()
+}
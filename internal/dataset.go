@@ -0,0 +1,380 @@
+package internal
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"iter"
+	"strings"
+
+	"github.com/lib/pq"
+)
+
+// Modos de resolución de conflictos de ImportDataset.
+const (
+	// DatasetConflictFail revierte la importación si algún registro ya existe.
+	DatasetConflictFail = "fail"
+	// DatasetConflictSkip conserva los registros existentes y omite los del respaldo.
+	// Una llave existente que corresponde a otro registro revierte la importación.
+	DatasetConflictSkip = "skip"
+	// DatasetConflictOverwrite reemplaza los registros existentes por los del respaldo.
+	// Una llave existente que corresponde a otro registro revierte la importación.
+	DatasetConflictOverwrite = "overwrite"
+	// DatasetConflictReplace vacía las tablas antes de importar.
+	DatasetConflictReplace = "replace"
+)
+
+// ErrInvalidDataset indica que los registros del respaldo no cumplen el esquema de la base de datos.
+var ErrInvalidDataset = errors.New("el respaldo contiene registros inválidos")
+
+// DatasetConflictError indica que un registro del respaldo ya existe en la tabla Table.
+type DatasetConflictError struct {
+	Table string
+}
+
+func (e *DatasetConflictError) Error() string {
+	return fmt.Sprintf("la tabla %s ya contiene registros del respaldo", e.Table)
+}
+
+// DatasetMismatchError indica que Rows registros del respaldo tienen la llave de un registro
+// existente en la tabla Table pero corresponden a otro registro, por lo que combinarlos mezclaría
+// datos no relacionados.
+type DatasetMismatchError struct {
+	Table string
+	Rows  int64
+}
+
+func (e *DatasetMismatchError) Error() string {
+	return fmt.Sprintf("%d registros de la tabla %s tienen la llave de otro registro existente", e.Rows, e.Table)
+}
+
+// datasetTable describe una tabla incluida en el respaldo.
+// Key es la llave primaria usada para ordenar y para sobrescribir registros. Identity son las
+// columnas que identifican el registro además de Key: en los modos skip y overwrite, un registro
+// del respaldo con la llave de uno existente solo se combina si coinciden. Unique es otra llave
+// única de la tabla, que no puede repetirse con una Key distinta. Defaults son los valores de las
+// columnas que los respaldos de versiones anteriores no incluyen.
+type datasetTable struct {
+	Name     string
+	Key      string
+	Columns  []string
+	Serial   bool
+	Identity []string
+	Unique   []string
+	Defaults map[string]string
+}
+
+// datasetTables son las tablas del respaldo, en un orden que respeta las llaves foráneas.
+// La columna generada search_vector, las claves de idempotencia, que expiran, y las competiciones,
+// que son datos de referencia de db/init.sql, no se incluyen; tampoco las columnas tx_id de
+// match_events y match_commentary, que solo tienen sentido en la base de origen: los registros
+// importados toman el de la transacción de importación.
+//
+// Los eventos son inmutables, por lo que un evento existente solo se combina si es idéntico, y
+// con él el partido al que pertenece; la proyección de los partidos importados se reconstruye a
+// partir de su log combinado.
+var datasetTables = []datasetTable{
+	{Name: "teams", Key: "id", Columns: []string{"id", "name", "stadium"}, Serial: true, Identity: []string{"name"}},
+	{Name: "team_aliases", Key: "alias_key", Columns: []string{"alias_key", "alias", "team_id"}},
+	{Name: "matches", Key: "id", Columns: []string{"id", "home_team", "away_team", "match_date", "goals_match",
+		"yellow_cards_match", "red_cards_match", "extra_time", "finished", "version", "deleted_at",
+		"clock_period", "clock_started_at", "clock_stoppage", "date_only", "competition_id"}, Serial: true,
+		Identity: []string{"home_team", "away_team"}, Defaults: map[string]string{"date_only": "FALSE", "competition_id": "1"}},
+	{Name: "match_events", Key: "id", Columns: []string{"id", "match_id", "sequence", "type", "data", "occurred_at",
+		"clock_minute", "clock_stoppage", "state"}, Serial: true,
+		Identity: []string{"match_id", "sequence", "type", "data", "occurred_at"}, Unique: []string{"match_id", "sequence"}},
+	{Name: "match_commentary", Key: "id", Columns: []string{"id", "match_id", "minute", "stoppage", "text", "event_id",
+		"pinned", "author", "created_at", "updated_at", "revision", "deleted_at"}, Serial: true, Identity: []string{"match_id", "created_at"}},
+	{Name: "audit_log", Key: "id", Columns: []string{"id", "match_id", "actor", "request_id", "operation",
+		"before", "after", "diff", "created_at"}, Serial: true, Identity: []string{"match_id", "request_id", "operation", "created_at"}},
+}
+
+// DatasetTables retorna los nombres de las tablas del respaldo en orden de importación.
+func DatasetTables() []string {
+	names := make([]string, len(datasetTables))
+	for i, table := range datasetTables {
+		names[i] = table.Name
+	}
+	return names
+}
+
+// DatasetTableResult resume la importación de una tabla.
+type DatasetTableResult struct {
+	Table    string `json:"table"`
+	Rows     int    `json:"rows"`
+	Imported int64  `json:"imported"`
+	Skipped  int64  `json:"skipped"`
+}
+
+// ExportDataset lee todas las tablas del respaldo dentro de una transacción de solo lectura,
+// de modo que correspondan al mismo instante. Para cada tabla llama a writeTable con sus
+// registros como objetos JSON, ordenados por llave primaria.
+func ExportDataset(writeTable func(table string, rows iter.Seq2[json.RawMessage, error]) error) error {
+	tx, err := DB.BeginTx(context.Background(), &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		return fmt.Errorf("error al iniciar la transacción: %v", err)
+	}
+	defer tx.Rollback()
+
+	for _, table := range datasetTables {
+		query := fmt.Sprintf("SELECT to_jsonb(r) FROM (SELECT %s FROM %s ORDER BY %s) r",
+			strings.Join(table.Columns, ", "), table.Name, table.Key)
+		rows := func(yield func(json.RawMessage, error) bool) {
+			result, err := tx.Query(query)
+			if err != nil {
+				yield(nil, fmt.Errorf("error al leer la tabla %s: %v", table.Name, err))
+				return
+			}
+			defer result.Close()
+			for result.Next() {
+				var row []byte
+				if err := result.Scan(&row); err != nil {
+					yield(nil, err)
+					return
+				}
+				if !yield(row, nil) {
+					return
+				}
+			}
+			if err := result.Err(); err != nil {
+				yield(nil, err)
+			}
+		}
+		if err := writeTable(table.Name, rows); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// ImportDataset restaura los registros de cada tabla en una única transacción. Las tablas
+// ausentes en tables no se modifican, salvo en modo DatasetConflictReplace, que vacía todas.
+// Al terminar se reconstruye la proyección de los partidos importados a partir de sus eventos y
+// las secuencias de IDs continúan después del mayor ID importado. Retorna DatasetConflictError
+// si en modo fail un registro ya existe y DatasetMismatchError si en modo skip u overwrite una
+// llave existente corresponde a otro registro.
+func ImportDataset(tables map[string][]json.RawMessage, conflict string, info AuditInfo) ([]DatasetTableResult, error) {
+	tx, err := DB.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("error al iniciar la transacción: %v", err)
+	}
+	defer tx.Rollback()
+
+	if err := completeIdempotencyKey(tx, info); err != nil {
+		return nil, err
+	}
+
+	names := DatasetTables()
+	if _, err := tx.Exec("LOCK TABLE " + strings.Join(names, ", ") + " IN SHARE ROW EXCLUSIVE MODE"); err != nil {
+		return nil, fmt.Errorf("error al bloquear las tablas: %v", err)
+	}
+	if conflict == DatasetConflictReplace {
+		if _, err := tx.Exec("TRUNCATE " + strings.Join(names, ", ")); err != nil {
+			return nil, fmt.Errorf("error al vaciar las tablas: %v", err)
+		}
+	}
+
+	var results []DatasetTableResult
+	for _, table := range datasetTables {
+		rows, ok := tables[table.Name]
+		if !ok {
+			continue
+		}
+		if conflict == DatasetConflictSkip || conflict == DatasetConflictOverwrite {
+			if err := checkDatasetIdentity(tx, table, rows); err != nil {
+				return nil, err
+			}
+		}
+		imported, err := importDatasetTable(tx, table, rows, conflict)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, DatasetTableResult{
+			Table:    table.Name,
+			Rows:     len(rows),
+			Imported: imported,
+			Skipped:  int64(len(rows)) - imported,
+		})
+
+		if table.Serial {
+			query := fmt.Sprintf("SELECT setval(pg_get_serial_sequence('%[1]s', 'id'), GREATEST((SELECT max(id) FROM %[1]s), 1))", table.Name)
+			if _, err := tx.Exec(query); err != nil {
+				return nil, fmt.Errorf("error al ajustar la secuencia de %s: %v", table.Name, err)
+			}
+		}
+	}
+
+	if err := rebuildImportedMatches(tx, tables); err != nil {
+		return nil, err
+	}
+	// Las revisiones de comentarios continúan después de la mayor importada
+	if _, err := tx.Exec("SELECT setval('match_commentary_revision_seq', GREATEST((SELECT max(revision) FROM match_commentary), 1))"); err != nil {
+		return nil, fmt.Errorf("error al ajustar la secuencia de revisiones de comentarios: %v", err)
+	}
+	if err := skipImportedWebhooks(tx, conflict); err != nil {
+		return nil, err
+	}
+	// Los eventos importados no pasan por insertEvent, por lo que se anuncian todos juntos
+	if _, err := tx.Exec("SELECT pg_notify($1, '{\"import\": true}')", changesChannel); err != nil {
+		return nil, fmt.Errorf("error al anunciar la importación: %v", err)
+	}
+	if err := commit(tx); err != nil {
+		return nil, fmt.Errorf("error al confirmar la transacción: %v", err)
+	}
+	return results, nil
+}
+
+// skipImportedWebhooks evita que los eventos importados, que ya ocurrieron, generen entregas de
+// webhooks. Los eventos insertados toman el tx_id de la importación, por lo que basta con registrar
+// la transacción: el cursor no se mueve y los eventos anteriores que aún no generaron entregas las
+// generan igual. En modo replace los eventos anteriores ya no existen y los IDs del respaldo pueden
+// repetir los del cursor, por lo que el cursor pasa al último evento importado en el orden de los
+// streams.
+func skipImportedWebhooks(tx *sql.Tx, conflict string) error {
+	if conflict == DatasetConflictReplace {
+		if _, err := tx.Exec("UPDATE webhook_cursor SET last_event_id = COALESCE((SELECT id FROM match_events ORDER BY tx_id DESC, id DESC LIMIT 1), 0)"); err != nil {
+			return fmt.Errorf("error al ajustar el cursor de webhooks: %v", err)
+		}
+		return nil
+	}
+	if _, err := tx.Exec("INSERT INTO webhook_skipped_transactions (tx_id) VALUES (pg_current_xact_id()) ON CONFLICT DO NOTHING"); err != nil {
+		return fmt.Errorf("error al omitir los webhooks de la importación: %v", err)
+	}
+	return nil
+}
+
+// importDatasetTable inserta los registros de una tabla y retorna cuántos se escribieron.
+// Los registros se convierten a filas de la tabla con jsonb_populate_recordset.
+func importDatasetTable(tx *sql.Tx, table datasetTable, rows []json.RawMessage, conflict string) (int64, error) {
+	if len(rows) == 0 {
+		return 0, nil
+	}
+	data, err := json.Marshal(rows)
+	if err != nil {
+		return 0, err
+	}
+
+	values := make([]string, len(table.Columns))
+	for i, column := range table.Columns {
+		values[i] = column
+		if value, ok := table.Defaults[column]; ok {
+			values[i] = fmt.Sprintf("COALESCE(%s, %s)", column, value)
+		}
+	}
+	columns := strings.Join(table.Columns, ", ")
+	query := fmt.Sprintf("INSERT INTO %s (%s) SELECT %s FROM jsonb_populate_recordset(NULL::%[1]s, $1::jsonb)",
+		table.Name, columns, strings.Join(values, ", "))
+	switch conflict {
+	case DatasetConflictSkip:
+		query += fmt.Sprintf(" ON CONFLICT (%s) DO NOTHING", table.Key)
+	case DatasetConflictOverwrite:
+		var updates []string
+		for _, column := range table.Columns {
+			if column != table.Key {
+				updates = append(updates, fmt.Sprintf("%[1]s = EXCLUDED.%[1]s", column))
+			}
+		}
+		query += fmt.Sprintf(" ON CONFLICT (%s) DO UPDATE SET %s", table.Key, strings.Join(updates, ", "))
+	}
+
+	result, err := tx.Exec(query, data)
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) {
+			switch {
+			case pqErr.Code == "23505":
+				return 0, &DatasetConflictError{Table: table.Name}
+			case pqErr.Code.Class() == "22" || pqErr.Code.Class() == "23":
+				return 0, fmt.Errorf("%w: tabla %s: %s", ErrInvalidDataset, table.Name, pqErr.Message)
+			}
+		}
+		return 0, fmt.Errorf("error al importar la tabla %s: %v", table.Name, err)
+	}
+	return result.RowsAffected()
+}
+
+// checkDatasetIdentity verifica que los registros del respaldo cuya llave ya existe sean el mismo
+// registro, comparando las columnas Identity, y que ninguno repita la llave Unique de otro registro.
+// Retorna DatasetMismatchError si alguno no cumple.
+func checkDatasetIdentity(tx *sql.Tx, table datasetTable, rows []json.RawMessage) error {
+	if len(rows) == 0 || (len(table.Identity) == 0 && len(table.Unique) == 0) {
+		return nil
+	}
+	data, err := json.Marshal(rows)
+	if err != nil {
+		return err
+	}
+
+	var conditions []string
+	if len(table.Identity) > 0 {
+		conditions = append(conditions, fmt.Sprintf("(t.%[1]s = r.%[1]s AND (%[2]s) IS DISTINCT FROM (%[3]s))",
+			table.Key, qualify("t", table.Identity), qualify("r", table.Identity)))
+	}
+	if len(table.Unique) > 0 {
+		conditions = append(conditions, fmt.Sprintf("((%s) = (%s) AND t.%[3]s <> r.%[3]s)",
+			qualify("t", table.Unique), qualify("r", table.Unique), table.Key))
+	}
+	query := fmt.Sprintf("SELECT count(*) FROM jsonb_populate_recordset(NULL::%[1]s, $1::jsonb) r JOIN %[1]s t ON %[2]s",
+		table.Name, strings.Join(conditions, " OR "))
+	var mismatched int64
+	if err := tx.QueryRow(query, data).Scan(&mismatched); err != nil {
+		return fmt.Errorf("error al comparar la tabla %s: %v", table.Name, err)
+	}
+	if mismatched > 0 {
+		return &DatasetMismatchError{Table: table.Name, Rows: mismatched}
+	}
+	return nil
+}
+
+// qualify antepone el alias a cada columna y las separa con comas.
+func qualify(alias string, columns []string) string {
+	qualified := make([]string, len(columns))
+	for i, column := range columns {
+		qualified[i] = alias + "." + column
+	}
+	return strings.Join(qualified, ", ")
+}
+
+// rebuildImportedMatches reconstruye, a partir de su log combinado, la proyección de los partidos
+// que aparecen en las tablas matches y match_events del respaldo, y guarda en cada uno de sus
+// eventos el estado resultante. Los partidos sin eventos conservan la fila importada.
+func rebuildImportedMatches(tx *sql.Tx, tables map[string][]json.RawMessage) error {
+	seen := map[int64]bool{}
+	var ids []int64
+	for table, column := range map[string]string{"matches": "id", "match_events": "match_id"} {
+		for _, row := range tables[table] {
+			var fields map[string]json.RawMessage
+			var id int64
+			if err := json.Unmarshal(row, &fields); err != nil || json.Unmarshal(fields[column], &id) != nil {
+				return fmt.Errorf("%w: tabla %s: registro sin %s", ErrInvalidDataset, table, column)
+			}
+			if !seen[id] {
+				seen[id] = true
+				ids = append(ids, id)
+			}
+		}
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+
+	events, err := queryEvents(tx, "WHERE match_id = ANY($1)", pq.Array(ids))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	for start := 0; start < len(events); {
+		end := start
+		for end < len(events) && events[end].MatchID == events[start].MatchID {
+			end++
+		}
+		if _, err := rebuildMatch(tx, events[start:end]); err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidDataset, err)
+		}
+		start = end
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+package internal
+
+import (
+	"encoding/json"
+	"errors"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestCheckDatasetIdentity(t *testing.T) {
+	rows := []json.RawMessage{json.RawMessage(`{"id": 5, "match_id": 5, "sequence": 1}`)}
+
+	tests := []struct {
+		name       string
+		table      string
+		wantQuery  string
+		mismatched int64
+		wantErr    bool
+	}{
+		{
+			name:      "partidos: misma llave con otros equipos",
+			table:     "matches",
+			wantQuery: "JOIN matches t ON (t.id = r.id AND (t.home_team, t.away_team) IS DISTINCT FROM (r.home_team, r.away_team))",
+		},
+		{
+			name:  "eventos: misma llave con otro contenido o misma secuencia con otra llave",
+			table: "match_events",
+			wantQuery: "JOIN match_events t ON (t.id = r.id AND (t.match_id, t.sequence, t.type, t.data, t.occurred_at) IS DISTINCT FROM " +
+				"(r.match_id, r.sequence, r.type, r.data, r.occurred_at)) OR ((t.match_id, t.sequence) = (r.match_id, r.sequence) AND t.id <> r.id)",
+			mismatched: 2,
+			wantErr:    true,
+		},
+		{
+			name:  "alias: la llave natural siempre identifica el registro",
+			table: "team_aliases",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer db.Close()
+
+			mock.ExpectBegin()
+			if tt.wantQuery != "" {
+				mock.ExpectQuery(regexp.QuoteMeta(tt.wantQuery)).
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(tt.mismatched))
+			}
+			tx, err := db.Begin()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var table datasetTable
+			for _, candidate := range datasetTables {
+				if candidate.Name == tt.table {
+					table = candidate
+				}
+			}
+			err = checkDatasetIdentity(tx, table, rows)
+
+			var mismatch *DatasetMismatchError
+			if tt.wantErr {
+				if !errors.As(err, &mismatch) || mismatch.Table != tt.table || mismatch.Rows != tt.mismatched {
+					t.Errorf("error = %v, se esperaba DatasetMismatchError de %s con %d registros", err, tt.table, tt.mismatched)
+				}
+			} else if err != nil {
+				t.Errorf("error inesperado: %v", err)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
@@ -13,6 +13,40 @@ import (
 // @Description Instancia global que almacena la conexión a la base de datos.
 var DB *sql.DB
 
+// querier agrupa los métodos comunes de *sql.DB y *sql.Tx, de modo que las
+// operaciones sobre partidos puedan ejecutarse dentro o fuera de una transacción.
+type querier interface {
+	Exec(query string, args ...any) (sql.Result, error)
+	Query(query string, args ...any) (*sql.Rows, error)
+	QueryRow(query string, args ...any) *sql.Row
+}
+
+// inTx ejecuta fn dentro de una transacción, que se confirma solo si fn no retorna error.
+func inTx(fn func(tx *sql.Tx) error) error {
+	tx, err := DB.Begin()
+	if err != nil {
+		return fmt.Errorf("error al iniciar la transacción: %v", err)
+	}
+	defer tx.Rollback()
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+	return commit(tx)
+}
+
+// connectionString arma la cadena de conexión a partir de las variables de entorno.
+func connectionString() string {
+	dbHost := os.Getenv("DB_HOST")
+	dbPort := os.Getenv("DB_PORT")
+	dbUser := os.Getenv("DB_USER")
+	dbPassword := os.Getenv("DB_PASSWORD")
+	dbName := os.Getenv("DB_NAME")
+
+	return fmt.Sprintf("postgres://%s:%s@%s:%s/%s?sslmode=disable",
+		dbUser, dbPassword, dbHost, dbPort, dbName)
+}
+
 // InitDB inicializa la conexión a la base de datos.
 // @Summary Inicializa la base de datos
 // @Description Conecta a la base de datos usando las variables de entorno definidas y reintenta la conexión hasta 5 veces.
@@ -21,14 +55,7 @@ var DB *sql.DB
 // @Success 200 {string} string "Conexión exitosa a la base de datos"
 // @Failure 500 {string} string "No se pudo conectar a la base de datos después de varios intentos"
 func InitDB() error {
-	dbHost := os.Getenv("DB_HOST")
-	dbPort := os.Getenv("DB_PORT")
-	dbUser := os.Getenv("DB_USER")
-	dbPassword := os.Getenv("DB_PASSWORD")
-	dbName := os.Getenv("DB_NAME")
-
-	connStr := fmt.Sprintf("postgres://%s:%s@%s:%s/%s?sslmode=disable",
-		dbUser, dbPassword, dbHost, dbPort, dbName)
+	connStr := connectionString()
 
 	var err error
 	for i := 0; i < 5; i++ {
@@ -0,0 +1,485 @@
+package internal
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// Tipos de eventos del log de un partido. El estado de cada partido es el resultado de
+// aplicar sus eventos en orden; la tabla "matches" es solo una proyección de ese estado.
+const (
+	EventMatchScheduled    = "MatchScheduled"
+	EventGoalScored        = "GoalScored"
+	EventCardShown         = "CardShown"
+	EventExtraTimeStarted  = "ExtraTimeStarted"
+	EventMatchFinished     = "MatchFinished"
+	EventMatchDeleted      = "MatchDeleted"
+	EventMatchRestored     = "MatchRestored"
+	EventTeamsRenamed      = "TeamsRenamed"
+	EventPeriodStarted     = "PeriodStarted"
+	EventPeriodEnded       = "PeriodEnded"
+	EventStoppageAnnounced = "StoppageTimeAnnounced"
+)
+
+// Colores de tarjeta de un evento CardShown.
+const (
+	CardYellow = "yellow"
+	CardRed    = "red"
+)
+
+// MatchEvent es un evento del log de un partido. Sequence comienza en 1 y coincide con
+// la versión del partido después de aplicar el evento. Clock es el minuto del reloj del
+// partido en que ocurrió, si el reloj estaba en marcha.
+type MatchEvent struct {
+	ID         int64           `json:"id"`
+	MatchID    int             `json:"matchId"`
+	Sequence   int             `json:"sequence"`
+	Type       string          `json:"type"`
+	Data       json.RawMessage `json:"data" swaggertype:"object"`
+	OccurredAt time.Time       `json:"occurredAt"`
+	Clock      *ClockMinute    `json:"clock,omitempty"`
+}
+
+// MatchScheduledData son los datos de MatchScheduled: los equipos y la fecha del partido.
+// Se emite al crear el partido y cada vez que se reprograma.
+type MatchScheduledData struct {
+	HomeTeam  string    `json:"homeTeam"`
+	AwayTeam  string    `json:"awayTeam"`
+	MatchDate time.Time `json:"matchDate"`
+	DateOnly  bool      `json:"dateOnly,omitempty"`
+}
+
+// Equipos a los que puede atribuirse un gol o una tarjeta.
+const (
+	SideHome = "home"
+	SideAway = "away"
+)
+
+// GoalScoredData son los datos de GoalScored. Team indica el equipo que anotó (SideHome o SideAway)
+// y está vacío en los goles registrados sin atribuir, como los de PATCH /matches/{id}/goals.
+type GoalScoredData struct {
+	Team string `json:"team,omitempty"`
+}
+
+// CardShownData son los datos de CardShown. Team indica el equipo amonestado, si se conoce.
+type CardShownData struct {
+	Color string `json:"color"`
+	Team  string `json:"team,omitempty"`
+}
+
+// TeamsRenamedData son los datos de TeamsRenamed, emitido al unificar nombres de equipos.
+type TeamsRenamedData struct {
+	HomeTeam string `json:"homeTeam"`
+	AwayTeam string `json:"awayTeam"`
+}
+
+// ErrMatchFinished indica que el partido ya finalizó y no admite más eventos de juego.
+var ErrMatchFinished = errors.New("el partido ya finalizó")
+
+// matchState es el estado de un partido reconstruido a partir de sus eventos.
+type matchState struct {
+	Match
+	DeletedAt *time.Time
+	Clock     matchClock
+}
+
+// apply aplica el evento al estado. Retorna un error si el evento no es válido en el estado actual.
+func (s *matchState) apply(e MatchEvent) error {
+	switch e.Type {
+	case EventMatchScheduled:
+		var data MatchScheduledData
+		if err := json.Unmarshal(e.Data, &data); err != nil {
+			return fmt.Errorf("evento %s inválido: %v", e.Type, err)
+		}
+		s.ID, s.HomeTeam, s.AwayTeam, s.MatchDate, s.DateOnly = e.MatchID, data.HomeTeam, data.AwayTeam, data.MatchDate, data.DateOnly
+	case EventTeamsRenamed:
+		var data TeamsRenamedData
+		if err := json.Unmarshal(e.Data, &data); err != nil {
+			return fmt.Errorf("evento %s inválido: %v", e.Type, err)
+		}
+		s.HomeTeam, s.AwayTeam = data.HomeTeam, data.AwayTeam
+	case EventGoalScored:
+		if s.Finished {
+			return ErrMatchFinished
+		}
+		s.Goals++
+	case EventCardShown:
+		if s.Finished {
+			return ErrMatchFinished
+		}
+		var data CardShownData
+		if err := json.Unmarshal(e.Data, &data); err != nil {
+			return fmt.Errorf("evento %s inválido: %v", e.Type, err)
+		}
+		switch data.Color {
+		case CardYellow:
+			s.YellowCards++
+		case CardRed:
+			s.RedCards++
+		default:
+			return fmt.Errorf("color de tarjeta desconocido: %q", data.Color)
+		}
+	case EventExtraTimeStarted:
+		if s.Finished {
+			return ErrMatchFinished
+		}
+		s.ExtraTime = true
+	case EventMatchFinished:
+		if s.Finished {
+			return ErrMatchFinished
+		}
+		s.Finished = true
+		s.Clock.StartedAt = nil
+	case EventPeriodStarted, EventPeriodEnded, EventStoppageAnnounced:
+		if s.Finished {
+			return ErrMatchFinished
+		}
+		if err := s.applyClock(e); err != nil {
+			return err
+		}
+	case EventMatchDeleted:
+		deletedAt := e.OccurredAt
+		s.DeletedAt = &deletedAt
+	case EventMatchRestored:
+		s.DeletedAt = nil
+	default:
+		return fmt.Errorf("tipo de evento desconocido: %q", e.Type)
+	}
+	s.Version = e.Sequence
+	return nil
+}
+
+// replay reconstruye el estado de un partido aplicando sus eventos en orden.
+func replay(events []MatchEvent) (matchState, error) {
+	var s matchState
+	for _, e := range events {
+		if err := s.apply(e); err != nil {
+			return s, fmt.Errorf("error al aplicar el evento %d del partido %d: %w", e.Sequence, e.MatchID, err)
+		}
+	}
+	return s, nil
+}
+
+// newEvent construye el evento que sigue al estado actual del partido.
+func newEvent(s matchState, eventType string, data any) (MatchEvent, error) {
+	if data == nil {
+		data = struct{}{}
+	}
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return MatchEvent{}, err
+	}
+	// Se trunca a la precisión de PostgreSQL para que la repetición obtenga el mismo estado
+	occurredAt := time.Now().UTC().Truncate(time.Microsecond)
+	return MatchEvent{
+		MatchID:    s.ID,
+		Sequence:   s.Version + 1,
+		Type:       eventType,
+		Data:       encoded,
+		OccurredAt: occurredAt,
+		Clock:      s.Clock.minuteAt(occurredAt),
+	}, nil
+}
+
+// projectionRow es la fila de la proyección "matches" que corresponde a un estado, con los
+// nombres de sus columnas. Cada evento guarda en match_events.state la fila resultante, de modo
+// que los lectores del log obtengan el partido después del evento sin repetir su historia.
+type projectionRow struct {
+	ID             int        `json:"id"`
+	HomeTeam       string     `json:"home_team"`
+	AwayTeam       string     `json:"away_team"`
+	MatchDate      time.Time  `json:"match_date"`
+	Version        int        `json:"version"`
+	Goals          int        `json:"goals_match"`
+	YellowCards    int        `json:"yellow_cards_match"`
+	RedCards       int        `json:"red_cards_match"`
+	ExtraTime      bool       `json:"extra_time"`
+	Finished       bool       `json:"finished"`
+	DateOnly       bool       `json:"date_only"`
+	DeletedAt      *time.Time `json:"deleted_at"`
+	ClockPeriod    string     `json:"clock_period"`
+	ClockStartedAt *time.Time `json:"clock_started_at"`
+	ClockStoppage  int        `json:"clock_stoppage"`
+}
+
+func newProjectionRow(s matchState) projectionRow {
+	return projectionRow{
+		ID: s.ID, HomeTeam: s.HomeTeam, AwayTeam: s.AwayTeam, MatchDate: s.MatchDate, Version: s.Version,
+		Goals: s.Goals, YellowCards: s.YellowCards, RedCards: s.RedCards, ExtraTime: s.ExtraTime,
+		Finished: s.Finished, DateOnly: s.DateOnly, DeletedAt: s.DeletedAt,
+		ClockPeriod: s.Clock.Period, ClockStartedAt: s.Clock.StartedAt, ClockStoppage: s.Clock.Stoppage,
+	}
+}
+
+func (r projectionRow) state() matchState {
+	return matchState{
+		Match: Match{ID: r.ID, HomeTeam: r.HomeTeam, AwayTeam: r.AwayTeam, MatchDate: r.MatchDate, Version: r.Version,
+			Goals: r.Goals, YellowCards: r.YellowCards, RedCards: r.RedCards, ExtraTime: r.ExtraTime, Finished: r.Finished,
+			DateOnly: r.DateOnly},
+		DeletedAt: r.DeletedAt,
+		Clock:     matchClock{Period: r.ClockPeriod, StartedAt: r.ClockStartedAt, Stoppage: r.ClockStoppage},
+	}
+}
+
+// insertEvent agrega el evento al log junto con state, el estado del partido después de aplicarlo.
+// La restricción única (match_id, sequence) impide que dos escrituras concurrentes agreguen el
+// mismo número de secuencia; las escrituras de un mismo partido además se serializan con el
+// bloqueo de su fila en "matches". Debe llamarse dentro de una transacción.
+// El evento se anuncia con NOTIFY en changesChannel, que Postgres entrega a las demás réplicas
+// solo si la transacción se confirma.
+func insertEvent(q querier, e MatchEvent, state matchState) error {
+	row, err := json.Marshal(newProjectionRow(state))
+	if err != nil {
+		return err
+	}
+	query := `
+        WITH inserted AS (
+            INSERT INTO match_events (match_id, sequence, type, data, occurred_at, clock_minute, clock_stoppage, state)
+            VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+            RETURNING id, match_id, type
+        )
+        SELECT pg_notify($9, json_build_object('eventId', id, 'matchId', match_id, 'type', type)::text)
+        FROM inserted
+    `
+	var minute, stoppage *int
+	if e.Clock != nil {
+		minute, stoppage = &e.Clock.Minute, &e.Clock.Stoppage
+	}
+	if _, err := q.Exec(query, e.MatchID, e.Sequence, e.Type, []byte(e.Data), e.OccurredAt, minute, stoppage, row, changesChannel); err != nil {
+		return fmt.Errorf("error al registrar el evento: %v", err)
+	}
+	return nil
+}
+
+// saveProjection escribe el estado del partido en la proyección "matches".
+func saveProjection(q querier, s matchState) error {
+	query := `
+        INSERT INTO matches (id, home_team, away_team, match_date, version, goals_match,
+                             yellow_cards_match, red_cards_match, extra_time, finished, date_only, deleted_at,
+                             clock_period, clock_started_at, clock_stoppage)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
+        ON CONFLICT (id) DO UPDATE SET
+            home_team = EXCLUDED.home_team,
+            away_team = EXCLUDED.away_team,
+            match_date = EXCLUDED.match_date,
+            version = EXCLUDED.version,
+            goals_match = EXCLUDED.goals_match,
+            yellow_cards_match = EXCLUDED.yellow_cards_match,
+            red_cards_match = EXCLUDED.red_cards_match,
+            extra_time = EXCLUDED.extra_time,
+            finished = EXCLUDED.finished,
+            date_only = EXCLUDED.date_only,
+            deleted_at = EXCLUDED.deleted_at,
+            clock_period = EXCLUDED.clock_period,
+            clock_started_at = EXCLUDED.clock_started_at,
+            clock_stoppage = EXCLUDED.clock_stoppage
+    `
+	_, err := q.Exec(query, s.ID, s.HomeTeam, s.AwayTeam, s.MatchDate, s.Version, s.Goals,
+		s.YellowCards, s.RedCards, s.ExtraTime, s.Finished, s.DateOnly, s.DeletedAt,
+		s.Clock.Period, s.Clock.StartedAt, s.Clock.Stoppage)
+	if err != nil {
+		return fmt.Errorf("error al actualizar la proyección del partido: %v", err)
+	}
+	return nil
+}
+
+// stateColumns son las columnas de la proyección que forman el estado completo de un partido.
+// Las columnas del reloj admiten NULL para los partidos restaurados de respaldos anteriores al reloj.
+const stateColumns = matchColumns + ", deleted_at, COALESCE(clock_period, ''), clock_started_at, COALESCE(clock_stoppage, 0)"
+
+// scanMatchState lee el estado de un partido a partir de una fila con stateColumns.
+func scanMatchState(row rowScanner) (matchState, error) {
+	var s matchState
+	m := &s.Match
+	err := row.Scan(&m.ID, &m.HomeTeam, &m.AwayTeam, &m.MatchDate, &m.Version,
+		&m.Goals, &m.YellowCards, &m.RedCards, &m.ExtraTime, &m.Finished, &m.DateOnly, &s.DeletedAt,
+		&s.Clock.Period, &s.Clock.StartedAt, &s.Clock.Stoppage)
+	return s, err
+}
+
+// appendEvent ejecuta un comando sobre un partido existente: valida el evento contra el estado
+// actual, lo agrega al log, actualiza la proyección y registra el cambio en la auditoría.
+// expectedVersion en 0 omite el control de versión. q debe ser una transacción.
+func appendEvent(q querier, info AuditInfo, op string, id, expectedVersion int, eventType string, data any) error {
+	before, err := snapshotMatch(q, id)
+	if err != nil {
+		return err
+	}
+	state, err := scanMatchState(q.QueryRow("SELECT "+stateColumns+" FROM matches WHERE id = $1", id))
+	if err != nil {
+		return err
+	}
+
+	// Solo MatchRestored aplica a partidos en la papelera; TeamsRenamed aplica a cualquiera
+	switch {
+	case eventType == EventMatchRestored && state.DeletedAt == nil:
+		return ErrMatchNotDeleted
+	case eventType != EventMatchRestored && eventType != EventTeamsRenamed && state.DeletedAt != nil:
+		return sql.ErrNoRows
+	case expectedVersion != 0 && state.Version != expectedVersion:
+		return ErrVersionMismatch
+	}
+
+	event, err := newEvent(state, eventType, data)
+	if err != nil {
+		return err
+	}
+	if err := state.apply(event); err != nil {
+		return err
+	}
+	if err := insertEvent(q, event, state); err != nil {
+		return err
+	}
+	if err := saveProjection(q, state); err != nil {
+		return err
+	}
+	return recordAudit(q, info, op, id, before)
+}
+
+// GetMatchEvents obtiene el log completo de eventos de un partido.
+// Retorna sql.ErrNoRows si el partido no tiene eventos.
+func GetMatchEvents(id int) ([]MatchEvent, error) {
+	return queryEvents(DB, "WHERE match_id = $1", id)
+}
+
+// GetEventsByMatches obtiene los eventos de los partidos indicados, ordenados por partido y secuencia.
+func GetEventsByMatches(ids []int) ([]MatchEvent, error) {
+	events, err := queryEvents(DB, "WHERE match_id = ANY($1)", pq.Array(toInt64s(ids)))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return events, err
+}
+
+// GetMatchAsOf reconstruye el partido tal como estaba en el instante asOf.
+// Retorna sql.ErrNoRows si el partido no existía o estaba en la papelera en ese momento.
+func GetMatchAsOf(id int, asOf time.Time) (Match, error) {
+	events, err := queryEvents(DB, "WHERE match_id = $1 AND occurred_at <= $2", id, asOf)
+	if err != nil {
+		return Match{}, err
+	}
+	state, err := replay(events)
+	if err != nil {
+		return Match{}, err
+	}
+	if state.DeletedAt != nil {
+		return Match{}, sql.ErrNoRows
+	}
+	return state.Match, nil
+}
+
+// queryEvents obtiene los eventos que cumplen la condición, ordenados por partido y secuencia.
+// Retorna sql.ErrNoRows si no hay ninguno.
+func queryEvents(q querier, where string, args ...any) ([]MatchEvent, error) {
+	rows, err := q.Query(`
+        SELECT id, match_id, sequence, type, data, occurred_at, clock_minute, clock_stoppage
+        FROM match_events
+        `+where+`
+        ORDER BY match_id, sequence
+    `, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error al consultar eventos: %v", err)
+	}
+	defer rows.Close()
+
+	var events []MatchEvent
+	for rows.Next() {
+		var e MatchEvent
+		var data []byte
+		var minute, stoppage sql.NullInt64
+		if err := rows.Scan(&e.ID, &e.MatchID, &e.Sequence, &e.Type, &data, &e.OccurredAt, &minute, &stoppage); err != nil {
+			return nil, err
+		}
+		e.Data = data
+		if minute.Valid {
+			e.Clock = &ClockMinute{Minute: int(minute.Int64), Stoppage: int(stoppage.Int64)}
+		}
+		events = append(events, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(events) == 0 {
+		return nil, sql.ErrNoRows
+	}
+	return events, nil
+}
+
+// rebuildMatch repite el log de un partido, guarda en cada evento el estado resultante y
+// actualiza la proyección. events son los eventos del partido en orden de secuencia.
+func rebuildMatch(q querier, events []MatchEvent) (matchState, error) {
+	var s matchState
+	ids := make([]int64, len(events))
+	rows := make([]string, len(events))
+	for i, e := range events {
+		if err := s.apply(e); err != nil {
+			return s, fmt.Errorf("error al aplicar el evento %d del partido %d: %w", e.Sequence, e.MatchID, err)
+		}
+		row, err := json.Marshal(newProjectionRow(s))
+		if err != nil {
+			return s, err
+		}
+		ids[i], rows[i] = e.ID, string(row)
+	}
+	query := `
+        UPDATE match_events e SET state = s.state::jsonb
+        FROM unnest($1::bigint[], $2::text[]) AS s(id, state)
+        WHERE e.id = s.id
+    `
+	if _, err := q.Exec(query, pq.Array(ids), pq.Array(rows)); err != nil {
+		return s, fmt.Errorf("error al guardar el estado de los eventos: %v", err)
+	}
+	return s, saveProjection(q, s)
+}
+
+// RebuildProjections reconstruye la tabla "matches" repitiendo el log completo de eventos.
+// También vuelve a guardar en cada evento el estado resultante.
+// Los partidos sin eventos se eliminan de la proyección. Retorna la cantidad de partidos reconstruidos.
+func RebuildProjections(info AuditInfo) (int, error) {
+	var rebuilt int
+	err := inTx(func(tx *sql.Tx) error {
+		if err := completeIdempotencyKey(tx, info); err != nil {
+			return err
+		}
+		// Se bloquea la proyección para que ninguna escritura concurrente quede fuera de la repetición
+		if _, err := tx.Exec("LOCK TABLE matches IN EXCLUSIVE MODE"); err != nil {
+			return fmt.Errorf("error al bloquear la proyección: %v", err)
+		}
+		events, err := queryEvents(tx, "")
+		if errors.Is(err, sql.ErrNoRows) {
+			events = nil
+		} else if err != nil {
+			return err
+		}
+
+		ids := []int64{}
+		for start := 0; start < len(events); {
+			end := start
+			for end < len(events) && events[end].MatchID == events[start].MatchID {
+				end++
+			}
+			state, err := rebuildMatch(tx, events[start:end])
+			if err != nil {
+				return err
+			}
+			ids = append(ids, int64(state.ID))
+			start = end
+		}
+
+		if _, err := tx.Exec("DELETE FROM matches WHERE NOT (id = ANY($1))", pq.Array(ids)); err != nil {
+			return fmt.Errorf("error al limpiar la proyección: %v", err)
+		}
+		// La secuencia de IDs continúa después del mayor ID reconstruido
+		if _, err := tx.Exec("SELECT setval(pg_get_serial_sequence('matches', 'id'), GREATEST((SELECT max(id) FROM matches), 1))"); err != nil {
+			return fmt.Errorf("error al ajustar la secuencia de partidos: %v", err)
+		}
+		rebuilt = len(ids)
+		return nil
+	})
+	return rebuilt, err
+}
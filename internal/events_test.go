@@ -0,0 +1,232 @@
+package internal
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+// kickoff es la fecha de los partidos de prueba.
+var kickoff = time.Date(2025, 4, 5, 19, 0, 0, 0, time.UTC)
+
+// testEvents construye un log con secuencias consecutivas, un minuto entre cada evento.
+func testEvents(events ...MatchEvent) []MatchEvent {
+	for i := range events {
+		events[i].MatchID = 7
+		events[i].Sequence = i + 1
+		events[i].OccurredAt = kickoff.Add(time.Duration(i) * time.Minute)
+		if events[i].Data == nil {
+			events[i].Data = json.RawMessage(`{}`)
+		}
+	}
+	return events
+}
+
+func scheduled(home, away string) MatchEvent {
+	data, _ := json.Marshal(MatchScheduledData{HomeTeam: home, AwayTeam: away, MatchDate: kickoff})
+	return MatchEvent{Type: EventMatchScheduled, Data: data}
+}
+
+func event(eventType, data string) MatchEvent {
+	return MatchEvent{Type: eventType, Data: json.RawMessage(data)}
+}
+
+func TestReplay(t *testing.T) {
+	tests := []struct {
+		name    string
+		events  []MatchEvent
+		want    Match
+		deleted bool
+		wantErr error
+	}{
+		{
+			name:   "partido programado",
+			events: testEvents(scheduled("Sevilla", "Betis")),
+			want:   Match{ID: 7, HomeTeam: "Sevilla", AwayTeam: "Betis", MatchDate: kickoff, Version: 1},
+		},
+		{
+			name: "goles, tarjetas, tiempo extra y final",
+			events: testEvents(scheduled("Sevilla", "Betis"),
+				event(EventGoalScored, `{"team": "home"}`),
+				event(EventGoalScored, `{}`),
+				event(EventCardShown, `{"color": "yellow", "team": "away"}`),
+				event(EventCardShown, `{"color": "red"}`),
+				event(EventExtraTimeStarted, `{}`),
+				event(EventMatchFinished, `{}`)),
+			want: Match{ID: 7, HomeTeam: "Sevilla", AwayTeam: "Betis", MatchDate: kickoff, Version: 7,
+				Goals: 2, YellowCards: 1, RedCards: 1, ExtraTime: true, Finished: true},
+		},
+		{
+			name: "reprogramación y cambio de nombres",
+			events: testEvents(scheduled("Sevilla", "Betis"),
+				event(EventTeamsRenamed, `{"homeTeam": "Sevilla FC", "awayTeam": "Real Betis"}`)),
+			want: Match{ID: 7, HomeTeam: "Sevilla FC", AwayTeam: "Real Betis", MatchDate: kickoff, Version: 2},
+		},
+		{
+			name: "reprogramación sin hora y luego con hora",
+			events: testEvents(
+				event(EventMatchScheduled, `{"homeTeam": "Sevilla", "awayTeam": "Betis", "matchDate": "2025-04-05T00:00:00Z", "dateOnly": true}`),
+				event(EventMatchScheduled, `{"homeTeam": "Sevilla", "awayTeam": "Betis", "matchDate": "2025-04-06T00:00:00Z"}`)),
+			want: Match{ID: 7, HomeTeam: "Sevilla", AwayTeam: "Betis", MatchDate: time.Date(2025, 4, 6, 0, 0, 0, 0, time.UTC), Version: 2},
+		},
+		{
+			name: "partido sin hora",
+			events: testEvents(
+				event(EventMatchScheduled, `{"homeTeam": "Sevilla", "awayTeam": "Betis", "matchDate": "2025-04-05T00:00:00Z", "dateOnly": true}`)),
+			want: Match{ID: 7, HomeTeam: "Sevilla", AwayTeam: "Betis", MatchDate: time.Date(2025, 4, 5, 0, 0, 0, 0, time.UTC), Version: 1, DateOnly: true},
+		},
+		{
+			name: "eliminado",
+			events: testEvents(scheduled("Sevilla", "Betis"),
+				event(EventMatchDeleted, `{}`)),
+			want:    Match{ID: 7, HomeTeam: "Sevilla", AwayTeam: "Betis", MatchDate: kickoff, Version: 2},
+			deleted: true,
+		},
+		{
+			name: "eliminado y restaurado",
+			events: testEvents(scheduled("Sevilla", "Betis"),
+				event(EventMatchDeleted, `{}`),
+				event(EventMatchRestored, `{}`)),
+			want: Match{ID: 7, HomeTeam: "Sevilla", AwayTeam: "Betis", MatchDate: kickoff, Version: 3},
+		},
+		{
+			name: "gol después del final",
+			events: testEvents(scheduled("Sevilla", "Betis"),
+				event(EventMatchFinished, `{}`),
+				event(EventGoalScored, `{}`)),
+			wantErr: ErrMatchFinished,
+		},
+		{
+			name: "periodo fuera de orden",
+			events: testEvents(scheduled("Sevilla", "Betis"),
+				event(EventPeriodStarted, `{"period": "second_half"}`)),
+			wantErr: ErrInvalidPeriod,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			state, err := replay(tt.events)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("error = %v, se esperaba %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if state.Match != tt.want {
+				t.Errorf("partido = %+v, se esperaba %+v", state.Match, tt.want)
+			}
+			if (state.DeletedAt != nil) != tt.deleted {
+				t.Errorf("deletedAt = %v, se esperaba eliminado = %v", state.DeletedAt, tt.deleted)
+			}
+		})
+	}
+}
+
+func TestReplayInvalidEvents(t *testing.T) {
+	tests := []struct {
+		name  string
+		event MatchEvent
+	}{
+		{name: "tipo desconocido", event: event("PenaltyMissed", `{}`)},
+		{name: "color de tarjeta desconocido", event: event(EventCardShown, `{"color": "blue"}`)},
+		{name: "datos que no son JSON", event: event(EventTeamsRenamed, `no es json`)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := replay(testEvents(scheduled("Sevilla", "Betis"), tt.event)); err == nil {
+				t.Error("se esperaba un error")
+			}
+		})
+	}
+}
+
+func TestNewEventRecordsClockMinute(t *testing.T) {
+	state, err := replay(testEvents(scheduled("Sevilla", "Betis")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	started := time.Now().UTC().Add(-47 * time.Minute)
+	state.Clock = matchClock{Period: PeriodFirstHalf, StartedAt: &started}
+
+	e, err := newEvent(state, EventGoalScored, GoalScoredData{Team: SideHome})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e.Sequence != 2 || e.MatchID != 7 {
+		t.Errorf("evento %d del partido %d, se esperaba el 2 del partido 7", e.Sequence, e.MatchID)
+	}
+	if e.Clock == nil || *e.Clock != (ClockMinute{Minute: 45, Stoppage: 3}) {
+		t.Errorf("clock = %+v, se esperaba 45+3", e.Clock)
+	}
+}
+
+func TestGetMatchAsOf(t *testing.T) {
+	asOf := kickoff.Add(90 * time.Minute)
+	columns := []string{"id", "match_id", "sequence", "type", "data", "occurred_at", "clock_minute", "clock_stoppage"}
+
+	tests := []struct {
+		name    string
+		events  []MatchEvent
+		want    Match
+		wantErr error
+	}{
+		{
+			name: "estado en el instante pedido",
+			events: testEvents(scheduled("Sevilla", "Betis"),
+				event(EventGoalScored, `{"team": "away"}`)),
+			want: Match{ID: 7, HomeTeam: "Sevilla", AwayTeam: "Betis", MatchDate: kickoff, Version: 2, Goals: 1},
+		},
+		{
+			name:    "todavía no existía",
+			wantErr: sql.ErrNoRows,
+		},
+		{
+			name: "estaba en la papelera",
+			events: testEvents(scheduled("Sevilla", "Betis"),
+				event(EventMatchDeleted, `{}`)),
+			wantErr: sql.ErrNoRows,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer db.Close()
+			previous := DB
+			DB = db
+			defer func() { DB = previous }()
+
+			rows := sqlmock.NewRows(columns)
+			for i, e := range tt.events {
+				rows.AddRow(i+1, e.MatchID, e.Sequence, e.Type, []byte(e.Data), e.OccurredAt, nil, nil)
+			}
+			mock.ExpectQuery(`WHERE match_id = \$1 AND occurred_at <= \$2`).WithArgs(7, asOf).WillReturnRows(rows)
+
+			got, err := GetMatchAsOf(7, asOf)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("error = %v, se esperaba %v", err, tt.wantErr)
+				}
+			} else if err != nil {
+				t.Fatal(err)
+			} else if got != tt.want {
+				t.Errorf("partido = %+v, se esperaba %+v", got, tt.want)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
@@ -0,0 +1,166 @@
+package internal
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// IdempotencyKey identifica una clave de idempotencia. Las claves se aíslan por método y ruta: la
+// misma clave enviada a otra ruta es una solicitud distinta. No se aíslan por actor porque X-Actor
+// lo envía el cliente; un cliente que repite la clave de otro solo recibe su respuesta si envía
+// exactamente la misma solicitud, que la huella del cuerpo verifica.
+type IdempotencyKey struct {
+	Method string
+	Path   string
+	Key    string
+}
+
+// IdempotencyRecord representa una clave de idempotencia almacenada y la respuesta asociada.
+// Committed indica que la escritura de la solicitud ya se confirmó, aunque su respuesta
+// todavía no se haya guardado; Completed, que la respuesta está disponible para reproducirla.
+type IdempotencyRecord struct {
+	Key         IdempotencyKey
+	Fingerprint string
+	Committed   bool
+	Completed   bool
+	StatusCode  int
+	Headers     map[string]string
+	Body        []byte
+}
+
+// idempotencyKeyCondition ubica el registro de una clave; sus parámetros empiezan en $1.
+const idempotencyKeyCondition = "method = $1 AND path = $2 AND idempotency_key = $3"
+
+func (k IdempotencyKey) args(extra ...any) []any {
+	return append([]any{k.Method, k.Path, k.Key}, extra...)
+}
+
+// ReserveIdempotencyKey intenta reservar la clave para una nueva solicitud.
+// Si la clave ya existe y no ha expirado retorna el registro existente y false;
+// si se reservó correctamente retorna nil y true.
+func ReserveIdempotencyKey(key IdempotencyKey, ttl time.Duration) (*IdempotencyRecord, bool, error) {
+	// Se descarta la clave si ya expiró para que pueda reutilizarse
+	if _, err := DB.Exec("DELETE FROM idempotency_keys WHERE "+idempotencyKeyCondition+" AND expires_at < NOW()", key.args()...); err != nil {
+		return nil, false, fmt.Errorf("error al limpiar clave de idempotencia: %v", err)
+	}
+
+	query := `
+        INSERT INTO idempotency_keys (method, path, idempotency_key, expires_at)
+        VALUES ($1, $2, $3, NOW() + $4 * INTERVAL '1 second')
+        ON CONFLICT (method, path, idempotency_key) DO NOTHING
+    `
+	res, err := DB.Exec(query, key.args(int64(ttl.Seconds()))...)
+	if err != nil {
+		return nil, false, fmt.Errorf("error al reservar clave de idempotencia: %v", err)
+	}
+	if affected, err := res.RowsAffected(); err != nil {
+		return nil, false, err
+	} else if affected == 1 {
+		return nil, true, nil
+	}
+
+	record, err := GetIdempotencyKey(key)
+	if err != nil {
+		return nil, false, err
+	}
+	return record, false, nil
+}
+
+// GetIdempotencyKey obtiene el registro almacenado para la clave dada.
+func GetIdempotencyKey(key IdempotencyKey) (*IdempotencyRecord, error) {
+	var (
+		record      = IdempotencyRecord{Key: key}
+		fingerprint sql.NullString
+		status      sql.NullInt64
+		headers     []byte
+	)
+	query := `
+        SELECT fingerprint, committed_at IS NOT NULL, status_code, headers, body
+        FROM idempotency_keys
+        WHERE ` + idempotencyKeyCondition
+	err := DB.QueryRow(query, key.args()...).Scan(&fingerprint, &record.Committed, &status, &headers, &record.Body)
+	if err != nil {
+		return nil, err
+	}
+	record.Fingerprint = fingerprint.String
+	if status.Valid {
+		record.Completed = true
+		record.StatusCode = int(status.Int64)
+	}
+	if len(headers) > 0 {
+		if err := json.Unmarshal(headers, &record.Headers); err != nil {
+			return nil, fmt.Errorf("error al leer encabezados almacenados: %v", err)
+		}
+	}
+	return &record, nil
+}
+
+// completeIdempotencyKey marca la clave de la solicitud como aplicada dentro de la transacción de
+// la escritura. Así la clave queda tomada si y solo si la escritura se confirmó, aunque después
+// falle el guardado de la respuesta. No hace nada si la solicitud no envió Idempotency-Key.
+func completeIdempotencyKey(q querier, info AuditInfo) error {
+	if info.Idempotency == nil {
+		return nil
+	}
+	query := "UPDATE idempotency_keys SET committed_at = NOW() WHERE " + idempotencyKeyCondition
+	if _, err := q.Exec(query, info.Idempotency.args()...); err != nil {
+		return fmt.Errorf("error al marcar clave de idempotencia: %v", err)
+	}
+	return nil
+}
+
+// withIdempotencyKey ejecuta una escritura que no registra eventos en la misma transacción que
+// la marca de su Idempotency-Key. A diferencia de inTx no avisa a los suscriptores de cambios.
+func withIdempotencyKey(info AuditInfo, fn func(q querier) error) error {
+	if info.Idempotency == nil {
+		return fn(DB)
+	}
+	tx, err := DB.Begin()
+	if err != nil {
+		return fmt.Errorf("error al iniciar la transacción: %v", err)
+	}
+	defer tx.Rollback()
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+	if err := completeIdempotencyKey(tx, info); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// SaveIdempotencyResponse guarda la huella de la solicitud y la respuesta original asociadas a
+// una clave reservada.
+func SaveIdempotencyResponse(key IdempotencyKey, fingerprint string, statusCode int, headers map[string]string, body []byte) error {
+	encoded, err := json.Marshal(headers)
+	if err != nil {
+		return err
+	}
+	query := `
+        UPDATE idempotency_keys
+        SET fingerprint = $4, status_code = $5, headers = $6, body = $7
+        WHERE ` + idempotencyKeyCondition
+	if _, err := DB.Exec(query, key.args(fingerprint, statusCode, encoded, body)...); err != nil {
+		return fmt.Errorf("error al guardar respuesta idempotente: %v", err)
+	}
+	return nil
+}
+
+// ReleaseIdempotencyKey elimina una clave reservada para permitir que la solicitud se reintente.
+// Una clave cuya escritura ya se confirmó no se libera, porque el reintento la repetiría.
+func ReleaseIdempotencyKey(key IdempotencyKey) error {
+	_, err := DB.Exec("DELETE FROM idempotency_keys WHERE "+idempotencyKeyCondition+" AND committed_at IS NULL", key.args()...)
+	return err
+}
+
+// PurgeExpiredIdempotencyKeys elimina las claves expiradas y retorna cuántas se borraron.
+func PurgeExpiredIdempotencyKeys() (int64, error) {
+	res, err := DB.Exec("DELETE FROM idempotency_keys WHERE expires_at < NOW()")
+	if err != nil {
+		return 0, fmt.Errorf("error al purgar claves de idempotencia: %v", err)
+	}
+	return res.RowsAffected()
+}
@@ -0,0 +1,109 @@
+package internal
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrDuplicateMatch indica que ya existe un partido entre los mismos equipos en la misma fecha.
+var ErrDuplicateMatch = errors.New("ya existe un partido entre esos equipos en esa fecha")
+
+// ImportRow es una fila de un archivo de importación ya convertida a partido.
+// Line es la línea (CSV) o fila (XLSX) de origen, usada en el reporte. Si Result no es nil
+// el partido se importa finalizado, con sus goles y tarjetas.
+type ImportRow struct {
+	Line   int
+	Match  Match
+	Result *MatchResult
+}
+
+// ImportResult es el resultado de validar o importar una fila.
+// Match contiene los nombres canónicos de los equipos; Err es nil si la fila es válida.
+type ImportResult struct {
+	Line  int
+	ID    int
+	Match Match
+	Err   error
+}
+
+// ImportMatches valida las filas y, si todas son válidas y dryRun es falso, crea los partidos
+// en una única transacción. Cada fila se valida resolviendo los equipos y buscando partidos
+// duplicados, tanto en la base de datos como en las filas anteriores del mismo archivo.
+// El booleano retornado indica si la importación se confirmó.
+func ImportMatches(rows []ImportRow, dryRun bool, info AuditInfo) ([]ImportResult, bool, error) {
+	tx, err := DB.Begin()
+	if err != nil {
+		return nil, false, fmt.Errorf("error al iniciar la transacción: %v", err)
+	}
+	defer tx.Rollback()
+
+	results := make([]ImportResult, len(rows))
+	seen := map[string]bool{}
+	valid := true
+	for i, row := range rows {
+		results[i] = ImportResult{Line: row.Line, Match: row.Match}
+		if err := validateImportRow(tx, &results[i].Match, seen); err != nil {
+			if !isImportRowError(err) {
+				return nil, false, err
+			}
+			results[i].Err = err
+			valid = false
+		}
+	}
+	if dryRun || !valid {
+		return results, false, nil
+	}
+
+	for i := range results {
+		if results[i].ID, err = createMatch(tx, results[i].Match, info); err != nil {
+			return nil, false, fmt.Errorf("error al importar la línea %d: %v", results[i].Line, err)
+		}
+		if rows[i].Result == nil {
+			continue
+		}
+		if err := recordResult(tx, info, results[i].ID, *rows[i].Result); err != nil {
+			return nil, false, fmt.Errorf("error al importar el resultado de la línea %d: %v", results[i].Line, err)
+		}
+	}
+	if err := commit(tx); err != nil {
+		return nil, false, fmt.Errorf("error al confirmar la transacción: %v", err)
+	}
+	return results, true, nil
+}
+
+// validateImportRow canonicaliza los equipos de m y verifica que el partido no esté duplicado.
+func validateImportRow(q querier, m *Match, seen map[string]bool) error {
+	if err := canonicalizeMatch(q, m); err != nil {
+		return err
+	}
+
+	// Dos partidos se consideran el mismo si enfrentan a los mismos equipos el mismo día (UTC)
+	key := fmt.Sprintf("%s\x00%s\x00%s", m.HomeTeam, m.AwayTeam, m.MatchDate.UTC().Format(time.DateOnly))
+	if seen[key] {
+		return ErrDuplicateMatch
+	}
+	seen[key] = true
+
+	var exists bool
+	query := `
+        SELECT EXISTS (
+            SELECT 1 FROM matches
+            WHERE deleted_at IS NULL AND home_team = $1 AND away_team = $2
+              AND (match_date AT TIME ZONE 'UTC')::date = $3::date
+        )
+    `
+	if err := q.QueryRow(query, m.HomeTeam, m.AwayTeam, m.MatchDate.UTC().Format(time.DateOnly)).Scan(&exists); err != nil {
+		return fmt.Errorf("error al buscar partidos duplicados: %v", err)
+	}
+	if exists {
+		return ErrDuplicateMatch
+	}
+	return nil
+}
+
+// isImportRowError indica si err describe un problema de la fila y no una falla de la base de datos.
+func isImportRowError(err error) bool {
+	var unknownTeam *UnknownTeamError
+	return errors.As(err, &unknownTeam) || errors.Is(err, ErrDuplicateMatch)
+}
@@ -0,0 +1,156 @@
+package internal
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+const (
+	resolveTeamSQL    = `WHERE a.alias_key = team_key\(\$1\)`
+	suggestTeamsSQL   = `similarity\(a.alias_key, team_key\(\$1\)\)`
+	duplicateMatchSQL = `SELECT EXISTS`
+)
+
+// expectResolve prepara la resolución de un nombre; canonical vacío indica un equipo desconocido.
+func expectResolve(mock sqlmock.Sqlmock, name, canonical string) {
+	if canonical == "" {
+		mock.ExpectQuery(resolveTeamSQL).WithArgs(name).WillReturnError(sql.ErrNoRows)
+		mock.ExpectQuery(suggestTeamsSQL).WithArgs(name, minTeamSimilarity).
+			WillReturnRows(sqlmock.NewRows([]string{"name"}).AddRow("Real Betis"))
+		return
+	}
+	mock.ExpectQuery(resolveTeamSQL).WithArgs(name).
+		WillReturnRows(sqlmock.NewRows([]string{"name"}).AddRow(canonical))
+}
+
+func expectDuplicate(mock sqlmock.Sqlmock, home, away string, exists bool) {
+	mock.ExpectQuery(duplicateMatchSQL).WithArgs(home, away, "2025-04-05").
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(exists))
+}
+
+func TestImportMatchesValidation(t *testing.T) {
+	date := time.Date(2025, 4, 5, 19, 0, 0, 0, time.UTC)
+	row := func(line int, home, away string) ImportRow {
+		return ImportRow{Line: line, Match: Match{HomeTeam: home, AwayTeam: away, MatchDate: date}}
+	}
+
+	tests := []struct {
+		name      string
+		rows      []ImportRow
+		dryRun    bool
+		setup     func(mock sqlmock.Sqlmock)
+		wantErrs  []error
+		wantNames []string
+	}{
+		{
+			name:   "simulación con filas válidas no escribe",
+			rows:   []ImportRow{row(2, "Ath Bilbao", "Sevilla FC")},
+			dryRun: true,
+			setup: func(mock sqlmock.Sqlmock) {
+				expectResolve(mock, "Ath Bilbao", "Athletic Club")
+				expectResolve(mock, "Sevilla FC", "Sevilla")
+				expectDuplicate(mock, "Athletic Club", "Sevilla", false)
+			},
+			wantErrs:  []error{nil},
+			wantNames: []string{"Athletic Club vs Sevilla"},
+		},
+		{
+			name: "una fila inválida impide importar el archivo",
+			rows: []ImportRow{
+				row(2, "Ath Bilbao", "Sevilla"),
+				row(3, "Athletic Club", "Sevilla"),
+				row(4, "Betis", "Getafe"),
+				row(5, "Betiz", "Getafe"),
+			},
+			setup: func(mock sqlmock.Sqlmock) {
+				expectResolve(mock, "Ath Bilbao", "Athletic Club")
+				expectResolve(mock, "Sevilla", "Sevilla")
+				expectDuplicate(mock, "Athletic Club", "Sevilla", false)
+				// La línea 3 repite la 2 con el nombre canónico
+				expectResolve(mock, "Athletic Club", "Athletic Club")
+				expectResolve(mock, "Sevilla", "Sevilla")
+				// La línea 4 ya existe en la base
+				expectResolve(mock, "Betis", "Betis")
+				expectResolve(mock, "Getafe", "Getafe")
+				expectDuplicate(mock, "Betis", "Getafe", true)
+				expectResolve(mock, "Betiz", "")
+			},
+			wantErrs:  []error{nil, ErrDuplicateMatch, ErrDuplicateMatch, &UnknownTeamError{}},
+			wantNames: []string{"Athletic Club vs Sevilla", "Athletic Club vs Sevilla", "Betis vs Getafe", "Betiz vs Getafe"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer db.Close()
+			previous := DB
+			DB = db
+			defer func() { DB = previous }()
+
+			mock.ExpectBegin()
+			tt.setup(mock)
+			mock.ExpectRollback()
+
+			results, committed, err := ImportMatches(tt.rows, tt.dryRun, AuditInfo{Actor: "tester"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if committed {
+				t.Error("la importación no debía confirmarse")
+			}
+			for i, result := range results {
+				if result.Line != tt.rows[i].Line {
+					t.Errorf("línea = %d, se esperaba %d", result.Line, tt.rows[i].Line)
+				}
+				if name := result.Match.HomeTeam + " vs " + result.Match.AwayTeam; name != tt.wantNames[i] {
+					t.Errorf("línea %d: partido = %s, se esperaba %s", result.Line, name, tt.wantNames[i])
+				}
+				var unknown *UnknownTeamError
+				switch want := tt.wantErrs[i]; {
+				case want == nil && result.Err != nil:
+					t.Errorf("línea %d: error inesperado %v", result.Line, result.Err)
+				case errors.As(want, &unknown):
+					if !errors.As(result.Err, &unknown) || unknown.Name != "Betiz" || len(unknown.Suggestions) != 1 {
+						t.Errorf("línea %d: error = %v, se esperaba equipo desconocido con sugerencias", result.Line, result.Err)
+					}
+				case want != nil && !errors.Is(result.Err, want):
+					t.Errorf("línea %d: error = %v, se esperaba %v", result.Line, result.Err, want)
+				}
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func TestImportMatchesDatabaseError(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	previous := DB
+	DB = db
+	defer func() { DB = previous }()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(resolveTeamSQL).WillReturnError(errors.New("conexión perdida"))
+	mock.ExpectRollback()
+
+	rows := []ImportRow{{Line: 2, Match: Match{HomeTeam: "Betis", AwayTeam: "Getafe"}}}
+	if _, _, err := ImportMatches(rows, true, AuditInfo{}); err == nil || isImportRowError(err) {
+		t.Errorf("error = %v, se esperaba la falla de la base de datos", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
@@ -1,10 +1,12 @@
 package internal
 
 import (	
+	"database/sql"
+	"errors"
 	"fmt"
 	"time"
 	// Asegúrate de importar el driver de PostgreSQL
-	_ "github.com/lib/pq"
+	"github.com/lib/pq"
 )
 
 
@@ -15,16 +17,42 @@ type Match struct {
 	HomeTeam  string    `json:"homeTeam"`
 	AwayTeam  string    `json:"awayTeam"`
 	MatchDate time.Time `json:"matchDate"`
+	Version   int       `json:"version"`
+
+	// Las estadísticas no forman parte de la representación v1, por eso no se serializan.
+	Goals       int  `json:"-"`
+	YellowCards int  `json:"-"`
+	RedCards    int  `json:"-"`
+	ExtraTime   bool `json:"-"`
+	Finished    bool `json:"-"`
+
+	// DateOnly indica que el partido se registró sin hora (v1, /batch o importaciones sin hora).
+	// MatchDate queda entonces a medianoche UTC y solo su fecha es significativa.
+	DateOnly bool `json:"-"`
+}
+
+// matchColumns son las columnas que se leen de la tabla "matches", en el orden que espera scanMatch.
+const matchColumns = "id, home_team, away_team, match_date, version, goals_match, yellow_cards_match, red_cards_match, extra_time, finished, date_only"
+
+// rowScanner es implementado por *sql.Row y *sql.Rows.
+type rowScanner interface {
+	Scan(dest ...any) error
 }
 
+// scanMatch lee un partido a partir de una fila obtenida con matchColumns.
+func scanMatch(row rowScanner) (Match, error) {
+	var m Match
+	err := row.Scan(&m.ID, &m.HomeTeam, &m.AwayTeam, &m.MatchDate, &m.Version,
+		&m.Goals, &m.YellowCards, &m.RedCards, &m.ExtraTime, &m.Finished, &m.DateOnly)
+	return m, err
+}
+
+// ErrVersionMismatch indica que la versión enviada por el cliente ya no es la actual.
+var ErrVersionMismatch = errors.New("la versión del partido no coincide")
+
 // GetMatches obtiene todos los partidos de la base de datos.
-// @Summary Obtiene todos los partidos
-// @Description Realiza una consulta a la tabla "matches" y retorna una lista de partidos.
-// @Success 200 {array} Match "Lista de partidos"
-// @Failure 500 {object} map[string]string "Error interno"
-// @Router /matches [get]
 func GetMatches() ([]Match, error) {
-	rows, err := DB.Query("SELECT id, home_team, away_team, match_date FROM matches")
+	rows, err := DB.Query("SELECT " + matchColumns + " FROM matches WHERE deleted_at IS NULL")
 	if err != nil {
 		return nil, err
 	}
@@ -32,8 +60,8 @@ func GetMatches() ([]Match, error) {
 
 	var matches []Match
 	for rows.Next() {
-		var m Match
-		if err := rows.Scan(&m.ID, &m.HomeTeam, &m.AwayTeam, &m.MatchDate); err != nil {
+		m, err := scanMatch(rows)
+		if err != nil {
 			return nil, err
 		}
 		matches = append(matches, m)
@@ -41,134 +69,167 @@ func GetMatches() ([]Match, error) {
 	return matches, nil
 }
 
+// GetMatchesBetween obtiene los partidos cuyo inicio está en el intervalo [from, to), ordenados por fecha.
+func GetMatchesBetween(from, to time.Time) ([]Match, error) {
+	return queryMatches("WHERE deleted_at IS NULL AND match_date >= $1 AND match_date < $2 ORDER BY match_date, id", from, to)
+}
+
+// GetMatchesByTeam obtiene los partidos en los que juega el equipo, de local o de visitante, ordenados por fecha.
+func GetMatchesByTeam(name string) ([]Match, error) {
+	return queryMatches("WHERE deleted_at IS NULL AND (home_team = $1 OR away_team = $1) ORDER BY match_date, id", name)
+}
+
+// GetMatchesByTeams obtiene los partidos en los que juega alguno de los equipos, ordenados por fecha.
+func GetMatchesByTeams(names []string) ([]Match, error) {
+	return queryMatches("WHERE deleted_at IS NULL AND (home_team = ANY($1) OR away_team = ANY($1)) ORDER BY match_date, id", pq.Array(names))
+}
+
+// queryMatches obtiene los partidos que cumplen la condición indicada.
+func queryMatches(where string, args ...any) ([]Match, error) {
+	rows, err := DB.Query("SELECT "+matchColumns+" FROM matches "+where, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	matches := []Match{}
+	for rows.Next() {
+		m, err := scanMatch(rows)
+		if err != nil {
+			return nil, err
+		}
+		matches = append(matches, m)
+	}
+	return matches, rows.Err()
+}
+
 // GetMatchByID obtiene un partido según su ID.
-// @Summary Obtiene un partido por ID
-// @Description Realiza una consulta para obtener un partido específico mediante su ID.
-// @Param id path int true "ID del partido"
-// @Success 200 {object} Match "Partido encontrado"
-// @Failure 404 {object} map[string]string "Partido no encontrado"
-// @Router /matches/{id} [get]
 func GetMatchByID(id int) (Match, error) {
-	var m Match
-	if err := DB.QueryRow("SELECT id, home_team, away_team, match_date FROM matches WHERE id = $1", id).
-		Scan(&m.ID, &m.HomeTeam, &m.AwayTeam, &m.MatchDate); err != nil {
-		return m, err
-	}
-	return m, nil
+	return scanMatch(DB.QueryRow("SELECT "+matchColumns+" FROM matches WHERE id = $1 AND deleted_at IS NULL", id))
 }
 
 // CreateMatch inserta un nuevo partido en la base de datos.
-// @Summary Crea un nuevo partido
-// @Description Inserta en la tabla "matches" un nuevo registro y retorna su ID.
-// @Param m body Match true "Objeto Match sin ID"
-// @Success 201 {int} int "ID del partido creado"
-// @Failure 500 {object} map[string]string "Error al crear el partido"
-// @Router /matches [post]
-func CreateMatch(m Match) (int, error) {
-	query := `
-        INSERT INTO matches (home_team, away_team, match_date)
-        VALUES ($1, $2, $3)
-        RETURNING id
-    `
+func CreateMatch(m Match, info AuditInfo) (int, error) {
 	var newID int
-	err := DB.QueryRow(query, m.HomeTeam, m.AwayTeam, m.MatchDate).Scan(&newID)
+	err := inTx(func(tx *sql.Tx) error {
+		var err error
+		newID, err = createMatch(tx, m, info)
+		return err
+	})
 	return newID, err
 }
 
-// UpdateMatch actualiza un partido existente en la base de datos.
-// @Summary Actualiza un partido
-// @Description Actualiza los campos home_team, away_team y match_date para un partido dado.
-// @Param m body Match true "Objeto Match con ID y datos actualizados"
-// @Success 200 {object} map[string]string "Partido actualizado correctamente"
-// @Failure 500 {object} map[string]string "Error al actualizar el partido"
-// @Router /matches/{id} [put]
-func UpdateMatch(m Match) error {
-	query := `
-        UPDATE matches
-        SET home_team = $1, away_team = $2, match_date = $3
-        WHERE id = $4
-    `
-	_, err := DB.Exec(query, m.HomeTeam, m.AwayTeam, m.MatchDate, m.ID)
-	return err
-}
-
-// DeleteMatch elimina un partido de la base de datos.
-// @Summary Elimina un partido
-// @Description Elimina el registro de la tabla "matches" correspondiente al ID proporcionado.
-// @Param id path int true "ID del partido"
-// @Success 200 {object} map[string]string "Partido eliminado"
-// @Failure 500 {object} map[string]string "Error al eliminar el partido"
-// @Router /matches/{id} [delete]
-func DeleteMatch(id int) error {
+func createMatch(q querier, m Match, info AuditInfo) (int, error) {
+	if err := canonicalizeMatch(q, &m); err != nil {
+		return 0, err
+	}
 	query := `
-        DELETE FROM matches
-        WHERE id = $1
+        INSERT INTO matches (home_team, away_team, match_date, date_only)
+        VALUES ($1, $2, $3, $4)
+        RETURNING id
     `
-	_, err := DB.Exec(query, id)
-	return err
-}
-
-// UpdateGoals incrementa en 1 el campo goals_match para el partido dado.
-// @Summary Incrementa goles del partido
-// @Description Incrementa el valor de "goals_match" en 1 para el partido especificado.
-// @Param id path int true "ID del partido"
-// @Success 200 {object} map[string]string "Gol incrementado correctamente"
-// @Failure 500 {object} map[string]string "Error al incrementar goles"
-// @Router /matches/{id}/goals [patch]
-func UpdateGoals(id int) error {
-	query := "UPDATE matches SET goals_match = goals_match + 1 WHERE id = $1"
-	_, err := DB.Exec(query, id)
-	if err != nil {
-		return fmt.Errorf("error al incrementar goles: %v", err)
+	var newID int
+	if err := q.QueryRow(query, m.HomeTeam, m.AwayTeam, m.MatchDate, m.DateOnly).Scan(&newID); err != nil {
+		return 0, err
 	}
-	return nil
-}
-
-// UpdateYellowCards incrementa en 1 el campo yellow_cards_match para el partido dado.
-// @Summary Incrementa tarjetas amarillas
-// @Description Incrementa el valor de "yellow_cards_match" en 1 para el partido especificado.
-// @Param id path int true "ID del partido"
-// @Success 200 {object} map[string]string "Tarjeta amarilla incrementada correctamente"
-// @Failure 500 {object} map[string]string "Error al incrementar tarjeta amarilla"
-// @Router /matches/{id}/yellowcards [patch]
-func UpdateYellowCards(id int) error {
-	query := "UPDATE matches SET yellow_cards_match = yellow_cards_match + 1 WHERE id = $1"
-	_, err := DB.Exec(query, id)
+
+	// El primer evento del partido corresponde a la versión 1 de la proyección recién insertada
+	state := matchState{Match: Match{ID: newID}}
+	event, err := newEvent(state, EventMatchScheduled,
+		MatchScheduledData{HomeTeam: m.HomeTeam, AwayTeam: m.AwayTeam, MatchDate: m.MatchDate, DateOnly: m.DateOnly})
 	if err != nil {
-		return fmt.Errorf("error al incrementar tarjeta amarilla: %v", err)
+		return 0, err
 	}
-	return nil
-}
-
-// UpdateRedCards incrementa en 1 el campo red_cards_match para el partido dado.
-// @Summary Incrementa tarjetas rojas
-// @Description Incrementa el valor de "red_cards_match" en 1 para el partido especificado.
-// @Param id path int true "ID del partido"
-// @Success 200 {object} map[string]string "Tarjeta roja incrementada correctamente"
-// @Failure 500 {object} map[string]string "Error al incrementar tarjeta roja"
-// @Router /matches/{id}/redcards [patch]
-func UpdateRedCards(id int) error {
-	query := "UPDATE matches SET red_cards_match = red_cards_match + 1 WHERE id = $1"
-	_, err := DB.Exec(query, id)
-	if err != nil {
-		return fmt.Errorf("error al incrementar tarjeta roja: %v", err)
+	if err := state.apply(event); err != nil {
+		return 0, err
 	}
-	return nil
-}
-
-// UpdateExtraTime establece en TRUE el campo extra_time para el partido dado.
-// @Summary Activa tiempo extra
-// @Description Establece el valor de "extra_time" en TRUE para el partido especificado.
-// @Param id path int true "ID del partido"
-// @Success 200 {object} map[string]string "Tiempo extra establecido correctamente"
-// @Failure 500 {object} map[string]string "Error al establecer tiempo extra"
-// @Router /matches/{id}/extratime [patch]
-func UpdateExtraTime(id int) error {
-	query := "UPDATE matches SET extra_time = TRUE WHERE id = $1"
-	_, err := DB.Exec(query, id)
-	if err != nil {
-		return fmt.Errorf("error al establecer tiempo extra: %v", err)
+	if err := insertEvent(q, event, state); err != nil {
+		return 0, err
+	}
+	return newID, recordAudit(q, info, AuditCreate, newID, nil)
+}
+
+// UpdateMatch reprograma un partido existente registrando un evento MatchScheduled.
+// Solo se aplica si m.Version coincide con la versión almacenada.
+func UpdateMatch(m Match, info AuditInfo) error {
+	return inTx(func(tx *sql.Tx) error { return updateMatch(tx, m, info) })
+}
+
+func updateMatch(q querier, m Match, info AuditInfo) error {
+	if err := canonicalizeMatch(q, &m); err != nil {
+		return err
+	}
+	return appendEvent(q, info, AuditUpdate, m.ID, m.Version, EventMatchScheduled,
+		MatchScheduledData{HomeTeam: m.HomeTeam, AwayTeam: m.AwayTeam, MatchDate: m.MatchDate, DateOnly: m.DateOnly})
+}
+
+// DeleteMatch envía un partido a la papelera si su versión coincide.
+// El partido deja de aparecer en las consultas hasta que se restaure o se purgue.
+func DeleteMatch(id, version int, info AuditInfo) error {
+	return inTx(func(tx *sql.Tx) error { return deleteMatch(tx, id, version, info) })
+}
+
+func deleteMatch(q querier, id, version int, info AuditInfo) error {
+	return appendEvent(q, info, AuditDelete, id, version, EventMatchDeleted, nil)
+}
+
+// UpdateGoals registra un evento GoalScored para el partido dado.
+func UpdateGoals(id, version int, info AuditInfo) error {
+	return IncrementStat(StatGoals, id, version, info)
+}
+
+// UpdateYellowCards registra un evento CardShown amarillo para el partido dado.
+func UpdateYellowCards(id, version int, info AuditInfo) error {
+	return IncrementStat(StatYellowCards, id, version, info)
+}
+
+// UpdateRedCards registra un evento CardShown rojo para el partido dado.
+func UpdateRedCards(id, version int, info AuditInfo) error {
+	return IncrementStat(StatRedCards, id, version, info)
+}
+
+// UpdateExtraTime registra un evento ExtraTimeStarted para el partido dado.
+func UpdateExtraTime(id, version int, info AuditInfo) error {
+	return IncrementStat(StatExtraTime, id, version, info)
+}
+
+// Estadísticas de un partido que pueden modificarse de forma individual.
+// Coinciden con las rutas PATCH de /matches/{id}.
+const (
+	StatGoals       = "goals"
+	StatYellowCards = "yellowcards"
+	StatRedCards    = "redcards"
+	StatExtraTime   = "extratime"
+)
+
+// ErrUnknownStat indica que la estadística solicitada no existe.
+var ErrUnknownStat = errors.New("estadística desconocida")
+
+// IncrementStat registra el evento correspondiente a la estadística indicada.
+// Retorna ErrMatchFinished si el partido ya finalizó.
+func IncrementStat(stat string, id, version int, info AuditInfo) error {
+	return inTx(func(tx *sql.Tx) error { return incrementStat(tx, stat, id, version, info) })
+}
+
+func incrementStat(q querier, stat string, id, version int, info AuditInfo) error {
+	switch stat {
+	case StatGoals:
+		return appendEvent(q, info, AuditIncrement, id, version, EventGoalScored, nil)
+	case StatYellowCards:
+		return appendEvent(q, info, AuditIncrement, id, version, EventCardShown, CardShownData{Color: CardYellow})
+	case StatRedCards:
+		return appendEvent(q, info, AuditIncrement, id, version, EventCardShown, CardShownData{Color: CardRed})
+	case StatExtraTime:
+		return appendEvent(q, info, AuditIncrement, id, version, EventExtraTimeStarted, nil)
 	}
-	return nil
+	return fmt.Errorf("%w: %q", ErrUnknownStat, stat)
+}
+
+// FinishMatch registra el fin del partido si su versión coincide. Después del evento
+// MatchFinished el partido no admite más goles, tarjetas ni tiempo extra.
+func FinishMatch(id, version int, info AuditInfo) error {
+	return inTx(func(tx *sql.Tx) error {
+		return appendEvent(tx, info, AuditFinish, id, version, EventMatchFinished, nil)
+	})
 }
 
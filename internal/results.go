@@ -0,0 +1,136 @@
+package internal
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/lib/pq"
+)
+
+// MatchResult es el resultado final de un partido con las estadísticas de cada equipo,
+// como lo publican las fuentes de resultados históricos.
+type MatchResult struct {
+	HomeGoals       int
+	AwayGoals       int
+	HomeYellowCards int
+	AwayYellowCards int
+	HomeRedCards    int
+	AwayRedCards    int
+}
+
+// SideCount cuenta una estadística por equipo. Unattributed son los eventos registrados sin
+// indicar el equipo, con los que no puede reconstruirse el reparto.
+type SideCount struct {
+	Home         int
+	Away         int
+	Unattributed int
+}
+
+// MatchSides son las estadísticas de un partido por equipo, obtenidas de su log de eventos.
+type MatchSides struct {
+	Goals       SideCount
+	YellowCards SideCount
+	RedCards    SideCount
+}
+
+// recordResult registra el resultado de un partido recién creado: un evento por gol y por tarjeta,
+// atribuido a su equipo, y el evento MatchFinished. Actualiza la proyección una sola vez y deja una
+// única entrada de auditoría. q debe ser una transacción.
+func recordResult(q querier, info AuditInfo, id int, result MatchResult) error {
+	before, err := snapshotMatch(q, id)
+	if err != nil {
+		return err
+	}
+	state, err := scanMatchState(q.QueryRow("SELECT "+stateColumns+" FROM matches WHERE id = $1", id))
+	if err != nil {
+		return err
+	}
+
+	type pending struct {
+		count     int
+		eventType string
+		data      any
+	}
+	var events []pending
+	for _, side := range []struct {
+		team                    string
+		goals, yellowCards, red int
+	}{
+		{SideHome, result.HomeGoals, result.HomeYellowCards, result.HomeRedCards},
+		{SideAway, result.AwayGoals, result.AwayYellowCards, result.AwayRedCards},
+	} {
+		events = append(events,
+			pending{side.goals, EventGoalScored, GoalScoredData{Team: side.team}},
+			pending{side.yellowCards, EventCardShown, CardShownData{Color: CardYellow, Team: side.team}},
+			pending{side.red, EventCardShown, CardShownData{Color: CardRed, Team: side.team}})
+	}
+	events = append(events, pending{1, EventMatchFinished, nil})
+
+	for _, p := range events {
+		for range p.count {
+			event, err := newEvent(state, p.eventType, p.data)
+			if err != nil {
+				return err
+			}
+			if err := state.apply(event); err != nil {
+				return err
+			}
+			if err := insertEvent(q, event, state); err != nil {
+				return err
+			}
+		}
+	}
+	if err := saveProjection(q, state); err != nil {
+		return err
+	}
+	return recordAudit(q, info, AuditFinish, id, before)
+}
+
+// GetMatchSides obtiene las estadísticas por equipo de los partidos indicados a partir de sus
+// eventos GoalScored y CardShown. Los partidos sin goles ni tarjetas no aparecen en el resultado.
+func GetMatchSides(ids []int) (map[int]MatchSides, error) {
+	rows, err := DB.Query(`
+        SELECT match_id,
+               CASE WHEN type = 'GoalScored' THEN 'goals' ELSE data->>'color' END AS stat,
+               count(*) FILTER (WHERE data->>'team' = 'home'),
+               count(*) FILTER (WHERE data->>'team' = 'away'),
+               count(*) FILTER (WHERE data->>'team' IS NULL OR data->>'team' NOT IN ('home', 'away'))
+        FROM match_events
+        WHERE match_id = ANY($1) AND type IN ('GoalScored', 'CardShown')
+        GROUP BY 1, 2
+    `, pq.Array(toInt64s(ids)))
+	if err != nil {
+		return nil, fmt.Errorf("error al consultar las estadísticas por equipo: %v", err)
+	}
+	defer rows.Close()
+
+	sides := map[int]MatchSides{}
+	for rows.Next() {
+		var id int
+		var stat sql.NullString
+		var count SideCount
+		if err := rows.Scan(&id, &stat, &count.Home, &count.Away, &count.Unattributed); err != nil {
+			return nil, err
+		}
+		s := sides[id]
+		switch stat.String {
+		case "goals":
+			s.Goals = count
+		case CardYellow:
+			s.YellowCards = count
+		case CardRed:
+			s.RedCards = count
+		}
+		sides[id] = s
+	}
+	return sides, rows.Err()
+}
+
+// toInt64s convierte una lista de IDs al tipo que pq.Array envía como bigint[].
+func toInt64s(values []int) []int64 {
+	result := make([]int64, len(values))
+	for i, v := range values {
+		result[i] = int64(v)
+	}
+	return result
+}
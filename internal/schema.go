@@ -0,0 +1,94 @@
+package internal
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// schemaLockKey identifica el advisory lock que impide que dos instancias apliquen el esquema a la vez.
+const schemaLockKey = 4_271_001
+
+// schemaColumns son columnas que solo existen en la versión actual de db/init.sql, con su tipo
+// cuando cambió respecto de versiones anteriores. MigrateSchema las agrega a las bases creadas con
+// una versión anterior; CheckSchema verifica que el resultado las tenga.
+var schemaColumns = map[string]string{
+	"matches.match_date":                 "timestamp with time zone",
+	"matches.version":                    "",
+	"matches.deleted_at":                 "",
+	"matches.search_vector":              "",
+	"matches.clock_stoppage":             "",
+	"matches.date_only":                  "",
+	"matches.competition_id":             "",
+	"match_events.state":                 "",
+	"match_events.tx_id":                 "xid8",
+	"idempotency_keys.committed_at":      "",
+	"audit_log.request_id":               "",
+	"teams.name":                         "",
+	"teams.stadium":                      "",
+	"competitions.name":                  "",
+	"team_aliases.alias_key":             "",
+	"webhook_deliveries.next_attempt_at": "",
+	"webhook_cursor.last_event_id":       "",
+	"webhook_skipped_transactions.tx_id": "",
+	"match_commentary.revision":          "",
+	"match_commentary.tx_id":             "xid8",
+}
+
+// MigrateSchema ejecuta el script de db/init.sql en una transacción. El script es idempotente, por
+// lo que sobre una base existente solo crea las tablas y agrega las columnas que le falten.
+func MigrateSchema(script string) error {
+	tx, err := DB.Begin()
+	if err != nil {
+		return fmt.Errorf("error al iniciar la transacción: %v", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec("SELECT pg_advisory_xact_lock($1)", schemaLockKey); err != nil {
+		return fmt.Errorf("error al bloquear el esquema: %v", err)
+	}
+	if _, err := tx.Exec(script); err != nil {
+		return fmt.Errorf("error al aplicar el esquema: %v", err)
+	}
+	return tx.Commit()
+}
+
+// CheckSchema verifica que la base tenga el esquema actual de db/init.sql. Si le faltan columnas
+// retorna un error que las enumera, para detener el arranque en lugar de fallar en cada consulta.
+func CheckSchema() error {
+	rows, err := DB.Query(`
+        SELECT table_name || '.' || column_name, data_type
+        FROM information_schema.columns
+        WHERE table_schema = current_schema()
+    `)
+	if err != nil {
+		return fmt.Errorf("error al consultar el esquema: %v", err)
+	}
+	defer rows.Close()
+
+	found := map[string]string{}
+	for rows.Next() {
+		var column, dataType string
+		if err := rows.Scan(&column, &dataType); err != nil {
+			return err
+		}
+		found[column] = dataType
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	var missing []string
+	for column, dataType := range schemaColumns {
+		if got, ok := found[column]; !ok {
+			missing = append(missing, column)
+		} else if dataType != "" && got != dataType {
+			missing = append(missing, fmt.Sprintf("%s (%s en lugar de %s)", column, got, dataType))
+		}
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return fmt.Errorf("la base de datos tiene un esquema anterior, faltan: %s", strings.Join(missing, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,138 @@
+package internal
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"lab6/db"
+)
+
+func TestCheckSchema(t *testing.T) {
+	current := func() map[string]string {
+		columns := map[string]string{}
+		for column, dataType := range schemaColumns {
+			if dataType == "" {
+				dataType = "integer"
+			}
+			columns[column] = dataType
+		}
+		return columns
+	}
+
+	tests := []struct {
+		name    string
+		change  func(columns map[string]string)
+		wantErr []string
+	}{
+		{name: "esquema actual", change: func(map[string]string) {}},
+		{
+			name: "base creada antes del log de eventos",
+			change: func(columns map[string]string) {
+				delete(columns, "match_events.state")
+				delete(columns, "match_events.tx_id")
+			},
+			wantErr: []string{"match_events.state", "match_events.tx_id"},
+		},
+		{
+			name: "fecha de partido sin hora",
+			change: func(columns map[string]string) {
+				columns["matches.match_date"] = "date"
+			},
+			wantErr: []string{"matches.match_date (date en lugar de timestamp with time zone)"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer db.Close()
+			previous := DB
+			DB = db
+			defer func() { DB = previous }()
+
+			columns := current()
+			tt.change(columns)
+			rows := sqlmock.NewRows([]string{"column", "data_type"})
+			for column, dataType := range columns {
+				rows.AddRow(column, dataType)
+			}
+			mock.ExpectQuery("FROM information_schema.columns").WillReturnRows(rows)
+
+			err = CheckSchema()
+			if len(tt.wantErr) == 0 {
+				if err != nil {
+					t.Errorf("error inesperado: %v", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatal("se esperaba un error")
+			}
+			for _, want := range tt.wantErr {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("el error %q no menciona %q", err, want)
+				}
+			}
+		})
+	}
+}
+
+func TestMigrateSchema(t *testing.T) {
+	tests := []struct {
+		name    string
+		execErr error
+	}{
+		{name: "aplica el script en una transacción"},
+		{name: "un error revierte el script", execErr: errors.New("permiso denegado")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conn, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer conn.Close()
+			previous := DB
+			DB = conn
+			defer func() { DB = previous }()
+
+			mock.ExpectBegin()
+			mock.ExpectExec(regexp.QuoteMeta("SELECT pg_advisory_xact_lock($1)")).WithArgs(schemaLockKey).
+				WillReturnResult(sqlmock.NewResult(0, 0))
+			script := mock.ExpectExec(regexp.QuoteMeta("CREATE TABLE IF NOT EXISTS matches"))
+			if tt.execErr != nil {
+				script.WillReturnError(tt.execErr)
+				mock.ExpectRollback()
+			} else {
+				script.WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectCommit()
+			}
+
+			err = MigrateSchema(db.InitSQL)
+			if (err != nil) != (tt.execErr != nil) {
+				t.Errorf("error = %v, se esperaba %v", err, tt.execErr)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+// TestInitSQLIsIdempotent verifica que init.sql pueda ejecutarse sobre una base existente, como
+// hace MigrateSchema en cada arranque.
+func TestInitSQLIsIdempotent(t *testing.T) {
+	statement := regexp.MustCompile(`(?i)\b(CREATE (?:TABLE|INDEX|SEQUENCE|EXTENSION)|ADD COLUMN)\s+(\S+ \S+ \S+)?`)
+	for _, match := range statement.FindAllStringSubmatch(db.InitSQL, -1) {
+		if !strings.HasPrefix(strings.ToUpper(match[2]), "IF NOT EXISTS") {
+			t.Errorf("%q no usa IF NOT EXISTS", match[0])
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package internal
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// SearchHit es un resultado de búsqueda de texto completo.
+type SearchHit struct {
+	ID    int     `json:"id,omitempty"`
+	Title string  `json:"title"`
+	Rank  float64 `json:"rank"`
+}
+
+// searchSource describe un tipo de recurso que participa en la búsqueda.
+// La consulta recibe el tsquery en $1, el límite en $2 y el texto original en $3, y retorna id,
+// título y ranking. El ranking suma ts_rank, que pondera cuántos términos coinciden, y la
+// similitud de trigramas entre el texto y el título, que favorece los títulos más parecidos a lo
+// buscado: así "Real" ubica a Real Madrid antes que a Real Valladolid.
+type searchSource struct {
+	Type  string
+	Query string
+}
+
+// searchSources son los recursos en los que busca Search, en el orden en que se consultan.
+// Agregar un recurso nuevo solo requiere agregar su consulta a esta lista. Los estadios no tienen
+// tabla propia: cada resultado es el estadio de un equipo y su ID es el del equipo. La API todavía
+// no tiene jugadores, por lo que no forman parte de la búsqueda.
+var searchSources = []searchSource{
+	{
+		Type: "teams",
+		Query: `
+            SELECT id, name, ts_rank(document, query) + similarity(unaccent(name), unaccent($3)) AS rank
+            FROM (
+                SELECT t.id, t.name, to_tsvector('es_unaccent', t.name || ' ' || coalesce(string_agg(a.alias, ' '), '')) AS document
+                FROM teams t
+                LEFT JOIN team_aliases a ON a.team_id = t.id AND a.alias <> t.name
+                GROUP BY t.id, t.name
+            ) teams,
+            to_tsquery('es_unaccent', $1) query
+            WHERE document @@ query
+            ORDER BY rank DESC, name
+            LIMIT $2
+        `,
+	},
+	{
+		Type: "matches",
+		Query: `
+            SELECT id, home_team || ' vs ' || away_team,
+                ts_rank(search_vector, query) + similarity(unaccent(home_team || ' ' || away_team), unaccent($3)) AS rank
+            FROM matches, to_tsquery('es_unaccent', $1) query
+            WHERE search_vector @@ query AND deleted_at IS NULL
+            ORDER BY rank DESC, id
+            LIMIT $2
+        `,
+	},
+	{
+		Type: "stadiums",
+		Query: `
+            SELECT id, stadium, ts_rank(to_tsvector('es_unaccent', stadium), query) + similarity(unaccent(stadium), unaccent($3)) AS rank
+            FROM teams, to_tsquery('es_unaccent', $1) query
+            WHERE stadium IS NOT NULL AND to_tsvector('es_unaccent', stadium) @@ query
+            ORDER BY rank DESC, stadium
+            LIMIT $2
+        `,
+	},
+}
+
+// BuildSearchQuery convierte el texto ingresado por el usuario en un tsquery de prefijos,
+// descartando signos de puntuación. Retorna una cadena vacía si no queda ningún término.
+func BuildSearchQuery(text string) string {
+	words := strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for i, word := range words {
+		words[i] = word + ":*"
+	}
+	return strings.Join(words, " & ")
+}
+
+// Search busca el texto en todos los recursos y retorna los resultados ordenados
+// por relevancia y agrupados por tipo de recurso.
+func Search(text string, limit int) (map[string][]SearchHit, error) {
+	tsquery := BuildSearchQuery(text)
+	results := make(map[string][]SearchHit, len(searchSources))
+	if tsquery == "" {
+		return results, nil
+	}
+
+	for _, source := range searchSources {
+		rows, err := DB.Query(source.Query, tsquery, limit, text)
+		if err != nil {
+			return nil, fmt.Errorf("error al buscar %s: %v", source.Type, err)
+		}
+
+		hits := []SearchHit{}
+		for rows.Next() {
+			var hit SearchHit
+			if err := rows.Scan(&hit.ID, &hit.Title, &hit.Rank); err != nil {
+				rows.Close()
+				return nil, err
+			}
+			hits = append(hits, hit)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return nil, err
+		}
+		results[source.Type] = hits
+	}
+	return results, nil
+}
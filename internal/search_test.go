@@ -0,0 +1,71 @@
+package internal
+
+import (
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestBuildSearchQuery(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{name: "un término", text: "Real", want: "Real:*"},
+		{name: "varios términos", text: "Real Madrid", want: "Real:* & Madrid:*"},
+		{name: "acentos y puntuación", text: "Atlético, de-Madrid!", want: "Atlético:* & de:* & Madrid:*"},
+		{name: "solo puntuación", text: "' & | !", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := BuildSearchQuery(tt.text); got != tt.want {
+				t.Errorf("BuildSearchQuery(%q) = %q, se esperaba %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestSearchRanksBySimilarity verifica que cada recurso reciba el texto original para calcular la
+// similitud y que los resultados se agrupen por tipo en el orden que retorna la base.
+func TestSearchRanksBySimilarity(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	previous := DB
+	DB = db
+	defer func() { DB = previous }()
+
+	mock.ExpectQuery(`similarity\(unaccent\(name\), unaccent\(\$3\)\)`).
+		WithArgs("Real:*", 10, "Real").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "rank"}).
+			AddRow(1, "Real Madrid", 0.52).
+			AddRow(2, "Real Valladolid", 0.39))
+	mock.ExpectQuery(`similarity\(unaccent\(home_team \|\| ' ' \|\| away_team\), unaccent\(\$3\)\)`).
+		WithArgs("Real:*", 10, "Real").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "title", "rank"}))
+	mock.ExpectQuery(`similarity\(unaccent\(stadium\), unaccent\(\$3\)\)`).
+		WithArgs("Real:*", 10, "Real").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "stadium", "rank"}).
+			AddRow(5, "Reale Arena", 0.31))
+
+	results, err := Search("Real", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if teams := results["teams"]; len(teams) != 2 || teams[0].Title != "Real Madrid" {
+		t.Errorf("teams = %+v, se esperaba Real Madrid primero", teams)
+	}
+	if matches, ok := results["matches"]; !ok || len(matches) != 0 {
+		t.Errorf("matches = %+v, se esperaba una lista vacía", matches)
+	}
+	if stadiums := results["stadiums"]; len(stadiums) != 1 || stadiums[0].ID != 5 || stadiums[0].Title != "Reale Arena" {
+		t.Errorf("stadiums = %+v, se esperaba el estadio de la Real Sociedad con el ID del equipo", stadiums)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,79 @@
+package internal
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// seasonExpr calcula la temporada de un partido: las temporadas de La Liga van de julio a junio
+// y se identifican por su año de inicio, por lo que basta con restar seis meses a la fecha (UTC).
+const seasonExpr = "EXTRACT(YEAR FROM (match_date AT TIME ZONE 'UTC') - INTERVAL '6 months')::int"
+
+// Season es una temporada con la cantidad de partidos registrados en ella.
+type Season struct {
+	Year     int `json:"year" example:"2024"`
+	Matches  int `json:"matches"`
+	Finished int `json:"finished"`
+}
+
+// Name retorna el nombre habitual de la temporada, por ejemplo "2024-25".
+func (s Season) Name() string {
+	return fmt.Sprintf("%d-%02d", s.Year, (s.Year+1)%100)
+}
+
+// SeasonOf retorna la temporada a la que pertenece el instante t.
+func SeasonOf(t time.Time) int {
+	t = t.UTC()
+	if t.Month() < time.July {
+		return t.Year() - 1
+	}
+	return t.Year()
+}
+
+// SeasonBounds retorna el intervalo [from, to) de la temporada que comienza en year.
+func SeasonBounds(year int) (time.Time, time.Time) {
+	from := time.Date(year, time.July, 1, 0, 0, 0, 0, time.UTC)
+	return from, from.AddDate(1, 0, 0)
+}
+
+// GetSeasons obtiene las temporadas que tienen partidos, de la más reciente a la más antigua.
+func GetSeasons() ([]Season, error) {
+	return querySeasons("")
+}
+
+// GetSeasonsByYear obtiene las temporadas indicadas que tienen partidos.
+func GetSeasonsByYear(years []int) ([]Season, error) {
+	return querySeasons("AND "+seasonExpr+" = ANY($1)", pq.Array(toInt64s(years)))
+}
+
+// querySeasons agrupa por temporada los partidos que cumplen la condición indicada.
+func querySeasons(where string, args ...any) ([]Season, error) {
+	rows, err := DB.Query(`
+        SELECT `+seasonExpr+` AS season, count(*), count(*) FILTER (WHERE finished)
+        FROM matches
+        WHERE deleted_at IS NULL `+where+`
+        GROUP BY 1
+        ORDER BY 1 DESC
+    `, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error al consultar las temporadas: %v", err)
+	}
+	defer rows.Close()
+
+	seasons := []Season{}
+	for rows.Next() {
+		var s Season
+		if err := rows.Scan(&s.Year, &s.Matches, &s.Finished); err != nil {
+			return nil, err
+		}
+		seasons = append(seasons, s)
+	}
+	return seasons, rows.Err()
+}
+
+// GetMatchesBySeasons obtiene los partidos de las temporadas indicadas, ordenados por fecha.
+func GetMatchesBySeasons(years []int) ([]Match, error) {
+	return queryMatches("WHERE deleted_at IS NULL AND "+seasonExpr+" = ANY($1) ORDER BY match_date, id", pq.Array(toInt64s(years)))
+}
@@ -0,0 +1,360 @@
+package internal
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/lib/pq"
+)
+
+// Team representa un equipo canónico junto con sus alias. Stadium es el estadio donde juega de
+// local, vacío si no se conoce.
+type Team struct {
+	ID      int      `json:"id"`
+	Name    string   `json:"name"`
+	Stadium string   `json:"stadium,omitempty"`
+	Aliases []string `json:"aliases"`
+}
+
+// TeamMergeResult resume el resultado de MergeTeamNames.
+type TeamMergeResult struct {
+	Target         string   `json:"target"`
+	Aliases        []string `json:"aliases"`
+	UpdatedMatches int64    `json:"updatedMatches"`
+}
+
+// minTeamSimilarity es la similitud mínima (pg_trgm) para sugerir un equipo.
+const minTeamSimilarity = 0.3
+
+// ErrAliasTaken indica que el alias ya pertenece a otro equipo.
+var ErrAliasTaken = errors.New("el alias ya pertenece a otro equipo")
+
+// ErrTeamExists indica que ya existe un equipo con ese nombre canónico.
+var ErrTeamExists = errors.New("ya existe un equipo con ese nombre")
+
+// UnknownTeamError indica que un nombre no corresponde a ningún equipo ni alias registrado.
+// Suggestions contiene los equipos con nombres parecidos, ordenados por similitud.
+type UnknownTeamError struct {
+	Name        string
+	Suggestions []string
+}
+
+func (e *UnknownTeamError) Error() string {
+	if len(e.Suggestions) == 0 {
+		return fmt.Sprintf("equipo desconocido: %q", e.Name)
+	}
+	return fmt.Sprintf("equipo desconocido: %q; ¿quiso decir %s?", e.Name, strings.Join(e.Suggestions, ", "))
+}
+
+// ResolveTeam retorna el nombre canónico del equipo al que corresponde name.
+// Si no se reconoce retorna un *UnknownTeamError con sugerencias.
+func ResolveTeam(name string) (string, error) {
+	return resolveTeam(DB, name)
+}
+
+func resolveTeam(q querier, name string) (string, error) {
+	var canonical string
+	query := `
+        SELECT t.name
+        FROM team_aliases a
+        JOIN teams t ON t.id = a.team_id
+        WHERE a.alias_key = team_key($1)
+    `
+	err := q.QueryRow(query, name).Scan(&canonical)
+	if err == nil {
+		return canonical, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return "", fmt.Errorf("error al resolver equipo: %v", err)
+	}
+
+	suggestions, err := suggestTeams(q, name)
+	if err != nil {
+		return "", err
+	}
+	return "", &UnknownTeamError{Name: name, Suggestions: suggestions}
+}
+
+// suggestTeams retorna hasta tres equipos cuyo nombre o alias se parece a name.
+func suggestTeams(q querier, name string) ([]string, error) {
+	query := `
+        SELECT name FROM (
+            SELECT t.name, max(similarity(a.alias_key, team_key($1))) AS score
+            FROM team_aliases a
+            JOIN teams t ON t.id = a.team_id
+            GROUP BY t.name
+        ) s
+        WHERE score >= $2
+        ORDER BY score DESC, name
+        LIMIT 3
+    `
+	rows, err := q.Query(query, name, minTeamSimilarity)
+	if err != nil {
+		return nil, fmt.Errorf("error al sugerir equipos: %v", err)
+	}
+	defer rows.Close()
+
+	suggestions := []string{}
+	for rows.Next() {
+		var suggestion string
+		if err := rows.Scan(&suggestion); err != nil {
+			return nil, err
+		}
+		suggestions = append(suggestions, suggestion)
+	}
+	return suggestions, rows.Err()
+}
+
+// canonicalizeMatch reemplaza los nombres de los equipos del partido por sus nombres canónicos.
+func canonicalizeMatch(q querier, m *Match) error {
+	home, err := resolveTeam(q, m.HomeTeam)
+	if err != nil {
+		return err
+	}
+	away, err := resolveTeam(q, m.AwayTeam)
+	if err != nil {
+		return err
+	}
+	m.HomeTeam, m.AwayTeam = home, away
+	return nil
+}
+
+// GetTeams obtiene todos los equipos con sus alias.
+func GetTeams() ([]Team, error) {
+	return queryTeams("")
+}
+
+// GetTeamsByName obtiene los equipos cuyo nombre canónico está en names, con sus alias.
+// Los nombres que no corresponden a ningún equipo se omiten.
+func GetTeamsByName(names []string) ([]Team, error) {
+	return queryTeams("WHERE t.name = ANY($1)", pq.Array(names))
+}
+
+// queryTeams obtiene los equipos que cumplen la condición indicada, ordenados por nombre.
+func queryTeams(where string, args ...any) ([]Team, error) {
+	query := `
+        SELECT t.id, t.name, COALESCE(t.stadium, ''), array_remove(array_agg(a.alias ORDER BY a.alias), NULL)
+        FROM teams t
+        LEFT JOIN team_aliases a ON a.team_id = t.id AND a.alias <> t.name
+        ` + where + `
+        GROUP BY t.id, t.name, t.stadium
+        ORDER BY t.name
+    `
+	rows, err := DB.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	teams := []Team{}
+	for rows.Next() {
+		var t Team
+		if err := rows.Scan(&t.ID, &t.Name, &t.Stadium, pq.Array(&t.Aliases)); err != nil {
+			return nil, err
+		}
+		if t.Aliases == nil {
+			t.Aliases = []string{}
+		}
+		teams = append(teams, t)
+	}
+	return teams, rows.Err()
+}
+
+// GetTeamByID obtiene un equipo y sus alias según su ID.
+func GetTeamByID(id int) (Team, error) {
+	var t Team
+	query := `
+        SELECT t.id, t.name, COALESCE(t.stadium, ''), array_remove(array_agg(a.alias ORDER BY a.alias), NULL)
+        FROM teams t
+        LEFT JOIN team_aliases a ON a.team_id = t.id AND a.alias <> t.name
+        WHERE t.id = $1
+        GROUP BY t.id, t.name, t.stadium
+    `
+	if err := DB.QueryRow(query, id).Scan(&t.ID, &t.Name, &t.Stadium, pq.Array(&t.Aliases)); err != nil {
+		return t, err
+	}
+	if t.Aliases == nil {
+		t.Aliases = []string{}
+	}
+	return t, nil
+}
+
+// CreateTeam registra un equipo canónico con su estadio y sus alias y retorna su ID.
+// Un estadio vacío queda sin registrar.
+func CreateTeam(name, stadium string, aliases []string, info AuditInfo) (int, error) {
+	tx, err := DB.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	if err := completeIdempotencyKey(tx, info); err != nil {
+		return 0, err
+	}
+
+	var id int
+	if err := tx.QueryRow("INSERT INTO teams (name, stadium) VALUES ($1, NULLIF($2, '')) RETURNING id", name, stadium).Scan(&id); err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+			return 0, ErrTeamExists
+		}
+		return 0, fmt.Errorf("error al crear equipo: %v", err)
+	}
+	for _, alias := range append([]string{name}, aliases...) {
+		if err := addTeamAlias(tx, id, alias); err != nil {
+			return 0, err
+		}
+	}
+	return id, tx.Commit()
+}
+
+// AddTeamAlias registra un alias para el equipo indicado.
+// Retorna ErrAliasTaken si el alias ya pertenece a otro equipo.
+func AddTeamAlias(teamID int, alias string, info AuditInfo) error {
+	tx, err := DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var exists bool
+	if err := tx.QueryRow("SELECT EXISTS(SELECT 1 FROM teams WHERE id = $1)", teamID).Scan(&exists); err != nil {
+		return err
+	}
+	if !exists {
+		return sql.ErrNoRows
+	}
+	if err := addTeamAlias(tx, teamID, alias); err != nil {
+		return err
+	}
+	if err := completeIdempotencyKey(tx, info); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func addTeamAlias(q querier, teamID int, alias string) error {
+	query := `
+        INSERT INTO team_aliases (alias_key, alias, team_id)
+        VALUES (team_key($1), $1, $2)
+        ON CONFLICT (alias_key) DO NOTHING
+        RETURNING team_id
+    `
+	var owner int
+	err := q.QueryRow(query, alias, teamID).Scan(&owner)
+	if err == nil {
+		return nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("error al registrar alias: %v", err)
+	}
+
+	// El alias ya existía: solo es un error si pertenece a otro equipo
+	if err := q.QueryRow("SELECT team_id FROM team_aliases WHERE alias_key = team_key($1)", alias).Scan(&owner); err != nil {
+		return err
+	}
+	if owner != teamID {
+		return ErrAliasTaken
+	}
+	return nil
+}
+
+// MergeTeamNames unifica nombres duplicados bajo el equipo target.
+// Registra cada duplicado como alias de target (creando target si no existe), absorbe
+// los equipos registrados con esos nombres y registra un evento TeamsRenamed en cada partido
+// que los usa.
+func MergeTeamNames(target string, duplicates []string, info AuditInfo) (TeamMergeResult, error) {
+	result := TeamMergeResult{Target: target, Aliases: []string{}}
+
+	tx, err := DB.Begin()
+	if err != nil {
+		return result, err
+	}
+	defer tx.Rollback()
+
+	// La unión puede no renombrar partidos, por lo que la clave se marca aunque no haya auditoría
+	if err := completeIdempotencyKey(tx, info); err != nil {
+		return result, err
+	}
+
+	var targetID int
+	err = tx.QueryRow(`
+        SELECT t.id, t.name FROM team_aliases a JOIN teams t ON t.id = a.team_id
+        WHERE a.alias_key = team_key($1)
+    `, target).Scan(&targetID, &result.Target)
+	if errors.Is(err, sql.ErrNoRows) {
+		if err = tx.QueryRow("INSERT INTO teams (name) VALUES ($1) RETURNING id", target).Scan(&targetID); err == nil {
+			err = addTeamAlias(tx, targetID, target)
+		}
+	}
+	if err != nil {
+		return result, fmt.Errorf("error al obtener equipo destino: %v", err)
+	}
+
+	for _, name := range duplicates {
+		// Si el duplicado es un equipo registrado, sus alias pasan al destino y el equipo se elimina
+		var otherID int
+		err := tx.QueryRow("SELECT team_id FROM team_aliases WHERE alias_key = team_key($1)", name).Scan(&otherID)
+		switch {
+		case err == nil && otherID != targetID:
+			// El destino conserva su estadio y, si no tiene, toma el del duplicado
+			if _, err := tx.Exec("UPDATE teams SET stadium = COALESCE(stadium, (SELECT stadium FROM teams WHERE id = $2)) WHERE id = $1", targetID, otherID); err != nil {
+				return result, fmt.Errorf("error al mover el estadio: %v", err)
+			}
+			if _, err := tx.Exec("UPDATE team_aliases SET team_id = $1 WHERE team_id = $2", targetID, otherID); err != nil {
+				return result, fmt.Errorf("error al mover alias: %v", err)
+			}
+			if _, err := tx.Exec("DELETE FROM teams WHERE id = $1", otherID); err != nil {
+				return result, fmt.Errorf("error al eliminar equipo duplicado: %v", err)
+			}
+		case errors.Is(err, sql.ErrNoRows):
+			if err := addTeamAlias(tx, targetID, name); err != nil {
+				return result, err
+			}
+		case err != nil:
+			return result, err
+		}
+		result.Aliases = append(result.Aliases, name)
+	}
+
+	// Se renombran los partidos cuyos equipos son alias del destino pero no su nombre canónico
+	rows, err := tx.Query(`
+        WITH aliases AS (SELECT alias_key FROM team_aliases WHERE team_id = $2)
+        SELECT id,
+               CASE WHEN team_key(home_team) IN (SELECT alias_key FROM aliases) THEN $1 ELSE home_team END,
+               CASE WHEN team_key(away_team) IN (SELECT alias_key FROM aliases) THEN $1 ELSE away_team END
+        FROM matches
+        WHERE (home_team <> $1 AND team_key(home_team) IN (SELECT alias_key FROM aliases))
+           OR (away_team <> $1 AND team_key(away_team) IN (SELECT alias_key FROM aliases))
+        ORDER BY id
+    `, result.Target, targetID)
+	if err != nil {
+		return result, fmt.Errorf("error al obtener partidos: %v", err)
+	}
+	var ids []int
+	renames := map[int]TeamsRenamedData{}
+	for rows.Next() {
+		var id int
+		var data TeamsRenamedData
+		if err := rows.Scan(&id, &data.HomeTeam, &data.AwayTeam); err != nil {
+			rows.Close()
+			return result, err
+		}
+		ids = append(ids, id)
+		renames[id] = data
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return result, err
+	}
+
+	for _, id := range ids {
+		if err := appendEvent(tx, info, AuditMerge, id, 0, EventTeamsRenamed, renames[id]); err != nil {
+			return result, fmt.Errorf("error al renombrar equipos del partido %d: %w", id, err)
+		}
+	}
+	result.UpdatedMatches = int64(len(ids))
+	return result, commit(tx)
+}
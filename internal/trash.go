@@ -0,0 +1,99 @@
+package internal
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// DeletedMatch es un partido que está en la papelera.
+type DeletedMatch struct {
+	Match
+	DeletedAt time.Time `json:"deletedAt"`
+}
+
+// ErrMatchNotDeleted indica que el partido que se quiere restaurar no está en la papelera.
+var ErrMatchNotDeleted = errors.New("el partido no está en la papelera")
+
+// GetTrash obtiene los partidos de la papelera, del más reciente al más antiguo.
+func GetTrash() ([]DeletedMatch, error) {
+	rows, err := DB.Query(`
+        SELECT ` + stateColumns + `
+        FROM matches
+        WHERE deleted_at IS NOT NULL
+        ORDER BY deleted_at DESC, id
+    `)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	matches := []DeletedMatch{}
+	for rows.Next() {
+		state, err := scanMatchState(rows)
+		if err != nil {
+			return nil, err
+		}
+		matches = append(matches, DeletedMatch{Match: state.Match, DeletedAt: *state.DeletedAt})
+	}
+	return matches, rows.Err()
+}
+
+// RestoreMatch saca un partido de la papelera registrando un evento MatchRestored si su versión coincide.
+// Retorna sql.ErrNoRows si el partido no existe y ErrMatchNotDeleted si no estaba eliminado.
+func RestoreMatch(id, version int, info AuditInfo) error {
+	return inTx(func(tx *sql.Tx) error {
+		return appendEvent(tx, info, AuditRestore, id, version, EventMatchRestored, nil)
+	})
+}
+
+// PurgeTrash elimina definitivamente los partidos que llevan en la papelera más que retention.
+// Se eliminan también sus eventos, de modo que reconstruir las proyecciones no los recupere;
+// la auditoría de los partidos eliminados se conserva. Retorna la cantidad de partidos eliminados.
+func PurgeTrash(retention time.Duration, info AuditInfo) (int64, error) {
+	var purged int64
+	err := inTx(func(tx *sql.Tx) error {
+		// La purga puede no eliminar partidos, por lo que la clave se marca aunque no haya auditoría
+		if err := completeIdempotencyKey(tx, info); err != nil {
+			return err
+		}
+		rows, err := tx.Query(`
+            DELETE FROM matches m
+            WHERE deleted_at IS NOT NULL AND deleted_at < NOW() - $1 * INTERVAL '1 second'
+            RETURNING id, to_jsonb(m) - 'search_vector'
+        `, int64(retention.Seconds()))
+		if err != nil {
+			return fmt.Errorf("error al purgar la papelera: %v", err)
+		}
+
+		// Las filas se leen completas antes de registrar la auditoría en la misma transacción
+		snapshots := map[int]json.RawMessage{}
+		for rows.Next() {
+			var id int
+			var snapshot []byte
+			if err := rows.Scan(&id, &snapshot); err != nil {
+				rows.Close()
+				return err
+			}
+			snapshots[id] = snapshot
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return err
+		}
+
+		for id, before := range snapshots {
+			if _, err := tx.Exec("DELETE FROM match_events WHERE match_id = $1", id); err != nil {
+				return fmt.Errorf("error al eliminar los eventos del partido: %v", err)
+			}
+			if err := insertAudit(tx, info, AuditPurge, id, before, nil); err != nil {
+				return err
+			}
+		}
+		purged = int64(len(snapshots))
+		return nil
+	})
+	return purged, err
+}
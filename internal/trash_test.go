@@ -0,0 +1,261 @@
+package internal
+
+import (
+	"database/sql"
+	"errors"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+// expectTrashState prepara las lecturas con las que appendEvent carga un partido que puede
+// estar en la papelera; deletedAt nil indica un partido vigente.
+func expectTrashState(mock sqlmock.Sqlmock, id, version int, deletedAt *time.Time) {
+	mock.ExpectQuery(regexp.QuoteMeta(matchSnapshotQuery)).WithArgs(id).
+		WillReturnRows(sqlmock.NewRows([]string{"snapshot"}).AddRow([]byte(`{"id": 1, "deleted_at": null}`)))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT " + stateColumns + " FROM matches WHERE id = $1")).WithArgs(id).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "home_team", "away_team", "match_date", "version", "goals_match",
+			"yellow_cards_match", "red_cards_match", "extra_time", "finished", "date_only", "deleted_at",
+			"clock_period", "clock_started_at", "clock_stoppage"}).
+			AddRow(id, "Sevilla", "Betis", time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC), version, 0, 0, 0, false, false, true, deletedAt, "", nil, 0))
+}
+
+func TestTrashWrites(t *testing.T) {
+	deletedAt := time.Date(2025, 3, 2, 10, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		write   func() error
+		setup   func(mock sqlmock.Sqlmock)
+		wantErr error
+	}{
+		{
+			name:  "restaurar un partido de la papelera",
+			write: func() error { return RestoreMatch(1, 3, AuditInfo{Actor: "admin"}) },
+			setup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				expectTrashState(mock, 1, 3, &deletedAt)
+				mock.ExpectExec("INSERT INTO match_events").WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec("INSERT INTO matches").WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectQuery(regexp.QuoteMeta(matchSnapshotQuery)).WithArgs(1).
+					WillReturnRows(sqlmock.NewRows([]string{"snapshot"}).AddRow([]byte(`{"id": 1, "deleted_at": null}`)))
+				mock.ExpectExec("INSERT INTO audit_log").WithArgs(1, "admin", "", AuditRestore, sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name:  "restaurar un partido que no está en la papelera",
+			write: func() error { return RestoreMatch(1, 3, AuditInfo{}) },
+			setup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				expectTrashState(mock, 1, 3, nil)
+				mock.ExpectRollback()
+			},
+			wantErr: ErrMatchNotDeleted,
+		},
+		{
+			name:  "restaurar con una versión desactualizada",
+			write: func() error { return RestoreMatch(1, 2, AuditInfo{}) },
+			setup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				expectTrashState(mock, 1, 3, &deletedAt)
+				mock.ExpectRollback()
+			},
+			wantErr: ErrVersionMismatch,
+		},
+		{
+			name:  "restaurar un partido inexistente",
+			write: func() error { return RestoreMatch(9, 1, AuditInfo{}) },
+			setup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery(regexp.QuoteMeta(matchSnapshotQuery)).WithArgs(9).WillReturnError(sql.ErrNoRows)
+				mock.ExpectQuery(regexp.QuoteMeta("SELECT " + stateColumns + " FROM matches WHERE id = $1")).WithArgs(9).
+					WillReturnError(sql.ErrNoRows)
+				mock.ExpectRollback()
+			},
+			wantErr: sql.ErrNoRows,
+		},
+		{
+			name:  "eliminar un partido que ya está en la papelera",
+			write: func() error { return DeleteMatch(1, 3, AuditInfo{}) },
+			setup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				expectTrashState(mock, 1, 3, &deletedAt)
+				mock.ExpectRollback()
+			},
+			wantErr: sql.ErrNoRows,
+		},
+		{
+			name:  "registrar un gol en un partido de la papelera",
+			write: func() error { return IncrementStat(StatGoals, 1, 3, AuditInfo{}) },
+			setup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				expectTrashState(mock, 1, 3, &deletedAt)
+				mock.ExpectRollback()
+			},
+			wantErr: sql.ErrNoRows,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer db.Close()
+			previous := DB
+			DB = db
+			defer func() { DB = previous }()
+			tt.setup(mock)
+
+			if err := tt.write(); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("error = %v, se esperaba %v", err, tt.wantErr)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+// TestPurgeTrash verifica que la purga solo elimine partidos de la papelera con la antigüedad
+// indicada, que elimine sus eventos y que conserve la auditoría con el estado anterior.
+func TestPurgeTrash(t *testing.T) {
+	const purgeSQL = `DELETE FROM matches m\s+WHERE deleted_at IS NOT NULL AND deleted_at < NOW\(\) - \$1 \* INTERVAL '1 second'`
+	retention := 30 * 24 * time.Hour
+
+	tests := []struct {
+		name       string
+		purged     map[int]string
+		wantPurged int64
+	}{
+		{name: "sin partidos en la papelera no elimina eventos", purged: map[int]string{}},
+		{
+			name:       "elimina los eventos y registra la purga de cada partido",
+			purged:     map[int]string{3: `{"id": 3, "deleted_at": "2025-01-01T00:00:00Z"}`, 5: `{"id": 5, "deleted_at": "2025-01-02T00:00:00Z"}`},
+			wantPurged: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer db.Close()
+			previous := DB
+			DB = db
+			defer func() { DB = previous }()
+
+			mock.ExpectBegin()
+			rows := sqlmock.NewRows([]string{"id", "snapshot"})
+			for id, snapshot := range tt.purged {
+				rows.AddRow(id, []byte(snapshot))
+			}
+			mock.ExpectQuery(purgeSQL).WithArgs(int64(retention.Seconds())).WillReturnRows(rows)
+			// Los partidos purgados se recorren en cualquier orden
+			mock.MatchExpectationsInOrder(len(tt.purged) <= 1)
+			for id, snapshot := range tt.purged {
+				mock.ExpectExec(`DELETE FROM match_events WHERE match_id = \$1`).WithArgs(id).WillReturnResult(sqlmock.NewResult(0, 4))
+				mock.ExpectExec("INSERT INTO audit_log").
+					WithArgs(id, "admin", "", AuditPurge, []byte(snapshot), nil, sqlmock.AnyArg()).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			}
+			mock.ExpectCommit()
+
+			purged, err := PurgeTrash(retention, AuditInfo{Actor: "admin"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if purged != tt.wantPurged {
+				t.Errorf("purgados = %d, se esperaba %d", purged, tt.wantPurged)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+// TestReadsExcludeDeletedMatches verifica que los listados y la búsqueda filtren los partidos de
+// la papelera, que solo aparecen en GetTrash.
+func TestReadsExcludeDeletedMatches(t *testing.T) {
+	tests := []struct {
+		name string
+		read func() error
+	}{
+		{name: "listado", read: func() error { _, err := GetMatches(); return err }},
+		{name: "partido por ID", read: func() error { _, err := GetMatchByID(1); return err }},
+		{name: "intervalo de fechas", read: func() error { _, err := GetMatchesBetween(time.Time{}, time.Now()); return err }},
+		{name: "equipo", read: func() error { _, err := GetMatchesByTeam("Betis"); return err }},
+		{name: "varios equipos", read: func() error { _, err := GetMatchesByTeams([]string{"Betis"}); return err }},
+		{name: "temporadas", read: func() error { _, err := GetMatchesBySeasons([]int{2024}); return err }},
+		{name: "competición", read: func() error { _, err := GetMatchesByCompetition(1); return err }},
+		{name: "resumen de temporadas", read: func() error { _, err := GetSeasons(); return err }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer db.Close()
+			previous := DB
+			DB = db
+			defer func() { DB = previous }()
+
+			// La consulta solo coincide si filtra la papelera; el error confirma que se ejecutó
+			filtered := errors.New("consulta filtrada")
+			mock.ExpectQuery(`FROM matches\s+WHERE (id = \$1 AND )?deleted_at IS NULL`).WillReturnError(filtered)
+			if err := tt.read(); err == nil || !strings.Contains(err.Error(), filtered.Error()) {
+				t.Fatalf("error = %v; la consulta no filtra deleted_at IS NULL", err)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+
+	t.Run("búsqueda", func(t *testing.T) {
+		for _, source := range searchSources {
+			if source.Type == "matches" && !strings.Contains(source.Query, "deleted_at IS NULL") {
+				t.Errorf("la búsqueda de partidos no excluye la papelera: %s", source.Query)
+			}
+		}
+	})
+
+	t.Run("papelera", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer db.Close()
+		previous := DB
+		DB = db
+		defer func() { DB = previous }()
+
+		deletedAt := time.Date(2025, 3, 2, 10, 0, 0, 0, time.UTC)
+		mock.ExpectQuery(`FROM matches\s+WHERE deleted_at IS NOT NULL\s+ORDER BY deleted_at DESC`).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "home_team", "away_team", "match_date", "version", "goals_match",
+				"yellow_cards_match", "red_cards_match", "extra_time", "finished", "date_only", "deleted_at",
+				"clock_period", "clock_started_at", "clock_stoppage"}).
+				AddRow(1, "Sevilla", "Betis", time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC), 4, 0, 0, 0, false, false, true, deletedAt, "", nil, 0))
+		trash, err := GetTrash()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(trash) != 1 || trash[0].ID != 1 || !trash[0].DeletedAt.Equal(deletedAt) {
+			t.Errorf("papelera = %+v", trash)
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Error(err)
+		}
+	})
+}
@@ -0,0 +1,362 @@
+package internal
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// Estados de una entrega de webhook.
+const (
+	WebhookPending   = "pending"
+	WebhookDelivered = "delivered"
+	WebhookDead      = "dead"
+)
+
+// ErrDeliveryPending indica que se pidió reenviar una entrega que todavía está pendiente.
+var ErrDeliveryPending = errors.New("la entrega todavía está pendiente")
+
+// WebhookSubscription es una suscripción a los eventos de los partidos.
+// EventTypes vacío significa todos los tipos. El secreto solo se expone al crearla.
+type WebhookSubscription struct {
+	ID         int       `json:"id"`
+	URL        string    `json:"url"`
+	EventTypes []string  `json:"eventTypes"`
+	Secret     string    `json:"secret,omitempty"`
+	Active     bool      `json:"active"`
+	CreatedAt  time.Time `json:"createdAt"`
+}
+
+// WebhookDelivery es el envío de un evento a una suscripción.
+// EventID es nil para los eventos de prueba, que no provienen del log.
+type WebhookDelivery struct {
+	ID             int64            `json:"id"`
+	SubscriptionID int              `json:"subscriptionId"`
+	EventID        *int64           `json:"eventId"`
+	EventType      string           `json:"eventType"`
+	Status         string           `json:"status"`
+	Attempts       int              `json:"attempts"`
+	NextAttemptAt  *time.Time       `json:"nextAttemptAt,omitempty"`
+	CreatedAt      time.Time        `json:"createdAt"`
+	DeliveredAt    *time.Time       `json:"deliveredAt,omitempty"`
+	Log            []WebhookAttempt `json:"log"`
+}
+
+// WebhookAttempt es un intento de entrega con la respuesta obtenida.
+type WebhookAttempt struct {
+	AttemptedAt  time.Time `json:"attemptedAt"`
+	StatusCode   *int      `json:"statusCode,omitempty"`
+	Error        string    `json:"error,omitempty"`
+	ResponseBody string    `json:"responseBody,omitempty"`
+	DurationMS   int       `json:"durationMs"`
+}
+
+// WebhookJob es una entrega pendiente junto con los datos necesarios para enviarla.
+type WebhookJob struct {
+	DeliveryID int64
+	EventType  string
+	Payload    []byte
+	Attempts   int
+	URL        string
+	Secret     string
+}
+
+// WebhookDeliveryFilter son los filtros de GetWebhookDeliveries.
+// SubscriptionID y Status se ignoran si son cero o vacíos.
+type WebhookDeliveryFilter struct {
+	SubscriptionID int
+	Status         string
+	Limit          int
+	Offset         int
+}
+
+// CreateWebhookSubscription registra una suscripción y la retorna con su secreto.
+func CreateWebhookSubscription(url string, eventTypes []string, secret string, info AuditInfo) (WebhookSubscription, error) {
+	s := WebhookSubscription{URL: url, EventTypes: eventTypes, Secret: secret, Active: true}
+	query := `
+        INSERT INTO webhook_subscriptions (url, event_types, secret)
+        VALUES ($1, $2, $3)
+        RETURNING id, created_at
+    `
+	err := withIdempotencyKey(info, func(q querier) error {
+		if err := q.QueryRow(query, url, pq.Array(eventTypes), secret).Scan(&s.ID, &s.CreatedAt); err != nil {
+			return fmt.Errorf("error al crear la suscripción: %v", err)
+		}
+		return nil
+	})
+	return s, err
+}
+
+// GetWebhookSubscriptions obtiene todas las suscripciones, sin sus secretos.
+func GetWebhookSubscriptions() ([]WebhookSubscription, error) {
+	rows, err := DB.Query("SELECT id, url, event_types, active, created_at FROM webhook_subscriptions ORDER BY id")
+	if err != nil {
+		return nil, fmt.Errorf("error al consultar suscripciones: %v", err)
+	}
+	defer rows.Close()
+
+	subscriptions := []WebhookSubscription{}
+	for rows.Next() {
+		var s WebhookSubscription
+		if err := rows.Scan(&s.ID, &s.URL, pq.Array(&s.EventTypes), &s.Active, &s.CreatedAt); err != nil {
+			return nil, err
+		}
+		subscriptions = append(subscriptions, s)
+	}
+	return subscriptions, rows.Err()
+}
+
+// GetWebhookSubscription obtiene una suscripción por ID, sin su secreto.
+func GetWebhookSubscription(id int) (WebhookSubscription, error) {
+	var s WebhookSubscription
+	query := "SELECT id, url, event_types, active, created_at FROM webhook_subscriptions WHERE id = $1"
+	err := DB.QueryRow(query, id).Scan(&s.ID, &s.URL, pq.Array(&s.EventTypes), &s.Active, &s.CreatedAt)
+	return s, err
+}
+
+// DeleteWebhookSubscription elimina la suscripción junto con sus entregas.
+func DeleteWebhookSubscription(id int, info AuditInfo) error {
+	return withIdempotencyKey(info, func(q querier) error {
+		result, err := q.Exec("DELETE FROM webhook_subscriptions WHERE id = $1", id)
+		if err != nil {
+			return fmt.Errorf("error al eliminar la suscripción: %v", err)
+		}
+		if n, _ := result.RowsAffected(); n == 0 {
+			return sql.ErrNoRows
+		}
+		return nil
+	})
+}
+
+// EnqueueWebhookDeliveries genera una entrega por cada suscripción activa interesada en cada evento
+// posterior al cursor de webhooks, salvo los importados desde un respaldo, y avanza el cursor. El cursor se bloquea durante la transacción,
+// por lo que varias réplicas pueden llamarla a la vez sin duplicar entregas. payload construye el
+// cuerpo que recibe el suscriptor a partir del evento y del partido resultante.
+// No usa inTx porque no registra eventos y no debe despertar a los suscriptores de cambios.
+func EnqueueWebhookDeliveries(limit int, payload func(MatchChange) ([]byte, error)) (int, error) {
+	tx, err := DB.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("error al iniciar la transacción: %v", err)
+	}
+	defer tx.Rollback()
+
+	var after int64
+	if err := tx.QueryRow("SELECT last_event_id FROM webhook_cursor FOR UPDATE").Scan(&after); err != nil {
+		return 0, fmt.Errorf("error al leer el cursor de webhooks: %v", err)
+	}
+	changes, err := GetMatchChanges(after, 0, limit)
+	if err != nil || len(changes) == 0 {
+		return 0, err
+	}
+
+	query := `
+        INSERT INTO webhook_deliveries (subscription_id, event_id, event_type, payload)
+        SELECT id, $1, $2, $3 FROM webhook_subscriptions
+        WHERE active AND (cardinality(event_types) = 0 OR $2 = ANY(event_types))
+          AND NOT EXISTS (
+              SELECT 1 FROM match_events e JOIN webhook_skipped_transactions s ON s.tx_id = e.tx_id
+              WHERE e.id = $1
+          )
+        ON CONFLICT (subscription_id, event_id) DO NOTHING
+    `
+	var enqueued int
+	for _, change := range changes {
+		body, err := payload(change)
+		if err != nil {
+			return 0, err
+		}
+		result, err := tx.Exec(query, change.Event.ID, change.Event.Type, body)
+		if err != nil {
+			return 0, fmt.Errorf("error al generar entregas del evento %d: %v", change.Event.ID, err)
+		}
+		n, _ := result.RowsAffected()
+		enqueued += int(n)
+	}
+	last := changes[len(changes)-1].Event.ID
+	if _, err := tx.Exec("UPDATE webhook_cursor SET last_event_id = $1", last); err != nil {
+		return 0, fmt.Errorf("error al avanzar el cursor de webhooks: %v", err)
+	}
+	// Las importaciones anteriores al evento del cursor ya no tienen eventos pendientes
+	if _, err := tx.Exec("DELETE FROM webhook_skipped_transactions WHERE tx_id < (SELECT tx_id FROM match_events WHERE id = $1)", last); err != nil {
+		return 0, fmt.Errorf("error al limpiar las importaciones omitidas: %v", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("error al confirmar la transacción: %v", err)
+	}
+	return enqueued, nil
+}
+
+// ClaimWebhookJobs toma hasta limit entregas pendientes cuyo próximo intento ya venció y posterga
+// ese intento en lease, de modo que ninguna otra réplica las tome mientras se envían.
+func ClaimWebhookJobs(limit int, lease time.Duration) ([]WebhookJob, error) {
+	rows, err := DB.Query(`
+        WITH due AS (
+            SELECT id FROM webhook_deliveries
+            WHERE status = 'pending' AND next_attempt_at <= NOW()
+            ORDER BY next_attempt_at
+            LIMIT $1
+            FOR UPDATE SKIP LOCKED
+        )
+        UPDATE webhook_deliveries d
+        SET next_attempt_at = NOW() + make_interval(secs => $2)
+        FROM due, webhook_subscriptions s
+        WHERE d.id = due.id AND s.id = d.subscription_id
+        RETURNING d.id, d.event_type, d.payload, d.attempts, s.url, s.secret
+    `, limit, lease.Seconds())
+	if err != nil {
+		return nil, fmt.Errorf("error al tomar entregas pendientes: %v", err)
+	}
+	defer rows.Close()
+
+	var jobs []WebhookJob
+	for rows.Next() {
+		var job WebhookJob
+		if err := rows.Scan(&job.DeliveryID, &job.EventType, &job.Payload, &job.Attempts, &job.URL, &job.Secret); err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, job)
+	}
+	return jobs, rows.Err()
+}
+
+// RecordWebhookAttempt registra el resultado de un intento. Si el intento falló, la entrega se
+// reprograma para retryAt o, si retryAt es nil, pasa a la lista de entregas muertas.
+func RecordWebhookAttempt(deliveryID int64, attempt WebhookAttempt, delivered bool, retryAt *time.Time) error {
+	tx, err := DB.Begin()
+	if err != nil {
+		return fmt.Errorf("error al iniciar la transacción: %v", err)
+	}
+	defer tx.Rollback()
+
+	query := `
+        INSERT INTO webhook_attempts (delivery_id, attempted_at, status_code, error, response_body, duration_ms)
+        VALUES ($1, $2, $3, NULLIF($4, ''), NULLIF($5, ''), $6)
+    `
+	if _, err := tx.Exec(query, deliveryID, attempt.AttemptedAt, attempt.StatusCode, attempt.Error,
+		attempt.ResponseBody, attempt.DurationMS); err != nil {
+		return fmt.Errorf("error al registrar el intento: %v", err)
+	}
+
+	switch {
+	case delivered:
+		query = "UPDATE webhook_deliveries SET attempts = attempts + 1, status = 'delivered', delivered_at = $2 WHERE id = $1"
+		_, err = tx.Exec(query, deliveryID, attempt.AttemptedAt)
+	case retryAt != nil:
+		query = "UPDATE webhook_deliveries SET attempts = attempts + 1, next_attempt_at = $2 WHERE id = $1"
+		_, err = tx.Exec(query, deliveryID, *retryAt)
+	default:
+		query = "UPDATE webhook_deliveries SET attempts = attempts + 1, status = 'dead' WHERE id = $1"
+		_, err = tx.Exec(query, deliveryID)
+	}
+	if err != nil {
+		return fmt.Errorf("error al actualizar la entrega: %v", err)
+	}
+	return tx.Commit()
+}
+
+// CreateWebhookTestDelivery genera una entrega de prueba para la suscripción, que se envía
+// como cualquier otra aunque no provenga del log de eventos.
+func CreateWebhookTestDelivery(subscriptionID int, eventType string, payload []byte, info AuditInfo) (WebhookJob, error) {
+	job := WebhookJob{EventType: eventType, Payload: payload}
+	query := `
+        WITH delivery AS (
+            INSERT INTO webhook_deliveries (subscription_id, event_type, payload, next_attempt_at)
+            SELECT id, $2, $3, NOW() + INTERVAL '1 minute' FROM webhook_subscriptions WHERE id = $1
+            RETURNING id, subscription_id
+        )
+        SELECT d.id, s.url, s.secret FROM delivery d JOIN webhook_subscriptions s ON s.id = d.subscription_id
+    `
+	err := withIdempotencyKey(info, func(q querier) error {
+		return q.QueryRow(query, subscriptionID, eventType, payload).Scan(&job.DeliveryID, &job.URL, &job.Secret)
+	})
+	return job, err
+}
+
+// RedeliverWebhook vuelve a poner en cola una entrega entregada o muerta para enviarla de inmediato.
+// Retorna sql.ErrNoRows si no existe y ErrDeliveryPending si todavía está pendiente.
+func RedeliverWebhook(deliveryID int64, info AuditInfo) error {
+	return withIdempotencyKey(info, func(q querier) error {
+		return redeliverWebhook(q, deliveryID)
+	})
+}
+
+func redeliverWebhook(q querier, deliveryID int64) error {
+	var status string
+	err := q.QueryRow(`
+        UPDATE webhook_deliveries d
+        SET status = 'pending', next_attempt_at = NOW(), delivered_at = NULL
+        FROM (SELECT id, status FROM webhook_deliveries WHERE id = $1 FOR UPDATE) previous
+        WHERE d.id = previous.id AND previous.status <> 'pending'
+        RETURNING previous.status
+    `, deliveryID).Scan(&status)
+	if errors.Is(err, sql.ErrNoRows) {
+		var exists bool
+		if err := q.QueryRow("SELECT EXISTS (SELECT 1 FROM webhook_deliveries WHERE id = $1)", deliveryID).Scan(&exists); err != nil {
+			return err
+		}
+		if exists {
+			return ErrDeliveryPending
+		}
+		return sql.ErrNoRows
+	}
+	return err
+}
+
+// GetWebhookDeliveries obtiene las entregas que cumplen el filtro, de la más reciente a la más
+// antigua, cada una con el registro de sus intentos.
+func GetWebhookDeliveries(filter WebhookDeliveryFilter) ([]WebhookDelivery, error) {
+	rows, err := DB.Query(`
+        SELECT id, subscription_id, event_id, event_type, status, attempts,
+               CASE WHEN status = 'pending' THEN next_attempt_at END, created_at, delivered_at
+        FROM webhook_deliveries
+        WHERE ($1 = 0 OR subscription_id = $1) AND ($2 = '' OR status = $2)
+        ORDER BY id DESC
+        LIMIT $3 OFFSET $4
+    `, filter.SubscriptionID, filter.Status, filter.Limit, filter.Offset)
+	if err != nil {
+		return nil, fmt.Errorf("error al consultar entregas: %v", err)
+	}
+	defer rows.Close()
+
+	deliveries := []WebhookDelivery{}
+	index := map[int64]int{}
+	var ids []int64
+	for rows.Next() {
+		var d WebhookDelivery
+		if err := rows.Scan(&d.ID, &d.SubscriptionID, &d.EventID, &d.EventType, &d.Status, &d.Attempts,
+			&d.NextAttemptAt, &d.CreatedAt, &d.DeliveredAt); err != nil {
+			return nil, err
+		}
+		d.Log = []WebhookAttempt{}
+		index[d.ID] = len(deliveries)
+		ids = append(ids, d.ID)
+		deliveries = append(deliveries, d)
+	}
+	if err := rows.Err(); err != nil || len(ids) == 0 {
+		return deliveries, err
+	}
+
+	attempts, err := DB.Query(`
+        SELECT delivery_id, attempted_at, status_code, COALESCE(error, ''), COALESCE(response_body, ''), duration_ms
+        FROM webhook_attempts
+        WHERE delivery_id = ANY($1)
+        ORDER BY id
+    `, pq.Array(ids))
+	if err != nil {
+		return nil, fmt.Errorf("error al consultar intentos: %v", err)
+	}
+	defer attempts.Close()
+	for attempts.Next() {
+		var deliveryID int64
+		var a WebhookAttempt
+		if err := attempts.Scan(&deliveryID, &a.AttemptedAt, &a.StatusCode, &a.Error, &a.ResponseBody, &a.DurationMS); err != nil {
+			return nil, err
+		}
+		d := &deliveries[index[deliveryID]]
+		d.Log = append(d.Log, a)
+	}
+	return deliveries, attempts.Err()
+}
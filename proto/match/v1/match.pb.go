@@ -0,0 +1,1368 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        (unknown)
+// source: match/v1/match.proto
+
+// MatchService expone por gRPC las mismas operaciones que la API REST sobre partidos.
+// El código Go se genera con `buf generate` (ver buf.gen.yaml) en este mismo directorio.
+
+package matchv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// Stat es la estadística que registra RecordStat.
+type Stat int32
+
+const (
+	Stat_STAT_UNSPECIFIED  Stat = 0
+	Stat_STAT_GOALS        Stat = 1
+	Stat_STAT_YELLOW_CARDS Stat = 2
+	Stat_STAT_RED_CARDS    Stat = 3
+	Stat_STAT_EXTRA_TIME   Stat = 4
+)
+
+// Enum value maps for Stat.
+var (
+	Stat_name = map[int32]string{
+		0: "STAT_UNSPECIFIED",
+		1: "STAT_GOALS",
+		2: "STAT_YELLOW_CARDS",
+		3: "STAT_RED_CARDS",
+		4: "STAT_EXTRA_TIME",
+	}
+	Stat_value = map[string]int32{
+		"STAT_UNSPECIFIED":  0,
+		"STAT_GOALS":        1,
+		"STAT_YELLOW_CARDS": 2,
+		"STAT_RED_CARDS":    3,
+		"STAT_EXTRA_TIME":   4,
+	}
+)
+
+func (x Stat) Enum() *Stat {
+	p := new(Stat)
+	*p = x
+	return p
+}
+
+func (x Stat) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (Stat) Descriptor() protoreflect.EnumDescriptor {
+	return file_match_v1_match_proto_enumTypes[0].Descriptor()
+}
+
+func (Stat) Type() protoreflect.EnumType {
+	return &file_match_v1_match_proto_enumTypes[0]
+}
+
+func (x Stat) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use Stat.Descriptor instead.
+func (Stat) EnumDescriptor() ([]byte, []int) {
+	return file_match_v1_match_proto_rawDescGZIP(), []int{0}
+}
+
+// Match es un partido con sus estadísticas.
+type Match struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int32                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Version       int32                  `protobuf:"varint,2,opt,name=version,proto3" json:"version,omitempty"`
+	HomeTeam      string                 `protobuf:"bytes,3,opt,name=home_team,json=homeTeam,proto3" json:"home_team,omitempty"`
+	AwayTeam      string                 `protobuf:"bytes,4,opt,name=away_team,json=awayTeam,proto3" json:"away_team,omitempty"`
+	Kickoff       *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=kickoff,proto3" json:"kickoff,omitempty"`
+	Goals         int32                  `protobuf:"varint,6,opt,name=goals,proto3" json:"goals,omitempty"`
+	YellowCards   int32                  `protobuf:"varint,7,opt,name=yellow_cards,json=yellowCards,proto3" json:"yellow_cards,omitempty"`
+	RedCards      int32                  `protobuf:"varint,8,opt,name=red_cards,json=redCards,proto3" json:"red_cards,omitempty"`
+	ExtraTime     bool                   `protobuf:"varint,9,opt,name=extra_time,json=extraTime,proto3" json:"extra_time,omitempty"`
+	Finished      bool                   `protobuf:"varint,10,opt,name=finished,proto3" json:"finished,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Match) Reset() {
+	*x = Match{}
+	mi := &file_match_v1_match_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Match) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Match) ProtoMessage() {}
+
+func (x *Match) ProtoReflect() protoreflect.Message {
+	mi := &file_match_v1_match_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Match.ProtoReflect.Descriptor instead.
+func (*Match) Descriptor() ([]byte, []int) {
+	return file_match_v1_match_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Match) GetId() int32 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *Match) GetVersion() int32 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+func (x *Match) GetHomeTeam() string {
+	if x != nil {
+		return x.HomeTeam
+	}
+	return ""
+}
+
+func (x *Match) GetAwayTeam() string {
+	if x != nil {
+		return x.AwayTeam
+	}
+	return ""
+}
+
+func (x *Match) GetKickoff() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Kickoff
+	}
+	return nil
+}
+
+func (x *Match) GetGoals() int32 {
+	if x != nil {
+		return x.Goals
+	}
+	return 0
+}
+
+func (x *Match) GetYellowCards() int32 {
+	if x != nil {
+		return x.YellowCards
+	}
+	return 0
+}
+
+func (x *Match) GetRedCards() int32 {
+	if x != nil {
+		return x.RedCards
+	}
+	return 0
+}
+
+func (x *Match) GetExtraTime() bool {
+	if x != nil {
+		return x.ExtraTime
+	}
+	return false
+}
+
+func (x *Match) GetFinished() bool {
+	if x != nil {
+		return x.Finished
+	}
+	return false
+}
+
+// ClockMinute es el minuto del reloj del partido en que ocurrió un evento.
+type ClockMinute struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Minute        int32                  `protobuf:"varint,1,opt,name=minute,proto3" json:"minute,omitempty"`
+	Stoppage      int32                  `protobuf:"varint,2,opt,name=stoppage,proto3" json:"stoppage,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ClockMinute) Reset() {
+	*x = ClockMinute{}
+	mi := &file_match_v1_match_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ClockMinute) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ClockMinute) ProtoMessage() {}
+
+func (x *ClockMinute) ProtoReflect() protoreflect.Message {
+	mi := &file_match_v1_match_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ClockMinute.ProtoReflect.Descriptor instead.
+func (*ClockMinute) Descriptor() ([]byte, []int) {
+	return file_match_v1_match_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *ClockMinute) GetMinute() int32 {
+	if x != nil {
+		return x.Minute
+	}
+	return 0
+}
+
+func (x *ClockMinute) GetStoppage() int32 {
+	if x != nil {
+		return x.Stoppage
+	}
+	return 0
+}
+
+// MatchEvent es un evento del log de un partido.
+type MatchEvent struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Id      int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	MatchId int32                  `protobuf:"varint,2,opt,name=match_id,json=matchId,proto3" json:"match_id,omitempty"`
+	// sequence coincide con la versión del partido después del evento.
+	Sequence int32 `protobuf:"varint,3,opt,name=sequence,proto3" json:"sequence,omitempty"`
+	// type es el tipo del evento, por ejemplo GoalScored o CardShown.
+	Type string `protobuf:"bytes,4,opt,name=type,proto3" json:"type,omitempty"`
+	// data_json son los datos del evento en JSON, tal como están en el log.
+	DataJson   string                 `protobuf:"bytes,5,opt,name=data_json,json=dataJson,proto3" json:"data_json,omitempty"`
+	OccurredAt *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=occurred_at,json=occurredAt,proto3" json:"occurred_at,omitempty"`
+	// clock no se informa si el reloj no estaba en marcha.
+	Clock         *ClockMinute `protobuf:"bytes,7,opt,name=clock,proto3" json:"clock,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MatchEvent) Reset() {
+	*x = MatchEvent{}
+	mi := &file_match_v1_match_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MatchEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MatchEvent) ProtoMessage() {}
+
+func (x *MatchEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_match_v1_match_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MatchEvent.ProtoReflect.Descriptor instead.
+func (*MatchEvent) Descriptor() ([]byte, []int) {
+	return file_match_v1_match_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *MatchEvent) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *MatchEvent) GetMatchId() int32 {
+	if x != nil {
+		return x.MatchId
+	}
+	return 0
+}
+
+func (x *MatchEvent) GetSequence() int32 {
+	if x != nil {
+		return x.Sequence
+	}
+	return 0
+}
+
+func (x *MatchEvent) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *MatchEvent) GetDataJson() string {
+	if x != nil {
+		return x.DataJson
+	}
+	return ""
+}
+
+func (x *MatchEvent) GetOccurredAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.OccurredAt
+	}
+	return nil
+}
+
+func (x *MatchEvent) GetClock() *ClockMinute {
+	if x != nil {
+		return x.Clock
+	}
+	return nil
+}
+
+type ListMatchesRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// team filtra por nombre o alias de un equipo, de local o de visitante.
+	Team string `protobuf:"bytes,1,opt,name=team,proto3" json:"team,omitempty"`
+	// from y to limitan el inicio de los partidos al intervalo [from, to).
+	From          *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=from,proto3" json:"from,omitempty"`
+	To            *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=to,proto3" json:"to,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListMatchesRequest) Reset() {
+	*x = ListMatchesRequest{}
+	mi := &file_match_v1_match_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListMatchesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListMatchesRequest) ProtoMessage() {}
+
+func (x *ListMatchesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_match_v1_match_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListMatchesRequest.ProtoReflect.Descriptor instead.
+func (*ListMatchesRequest) Descriptor() ([]byte, []int) {
+	return file_match_v1_match_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *ListMatchesRequest) GetTeam() string {
+	if x != nil {
+		return x.Team
+	}
+	return ""
+}
+
+func (x *ListMatchesRequest) GetFrom() *timestamppb.Timestamp {
+	if x != nil {
+		return x.From
+	}
+	return nil
+}
+
+func (x *ListMatchesRequest) GetTo() *timestamppb.Timestamp {
+	if x != nil {
+		return x.To
+	}
+	return nil
+}
+
+type ListMatchesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Matches       []*Match               `protobuf:"bytes,1,rep,name=matches,proto3" json:"matches,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListMatchesResponse) Reset() {
+	*x = ListMatchesResponse{}
+	mi := &file_match_v1_match_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListMatchesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListMatchesResponse) ProtoMessage() {}
+
+func (x *ListMatchesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_match_v1_match_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListMatchesResponse.ProtoReflect.Descriptor instead.
+func (*ListMatchesResponse) Descriptor() ([]byte, []int) {
+	return file_match_v1_match_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *ListMatchesResponse) GetMatches() []*Match {
+	if x != nil {
+		return x.Matches
+	}
+	return nil
+}
+
+type GetMatchRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int32                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetMatchRequest) Reset() {
+	*x = GetMatchRequest{}
+	mi := &file_match_v1_match_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetMatchRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetMatchRequest) ProtoMessage() {}
+
+func (x *GetMatchRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_match_v1_match_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetMatchRequest.ProtoReflect.Descriptor instead.
+func (*GetMatchRequest) Descriptor() ([]byte, []int) {
+	return file_match_v1_match_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *GetMatchRequest) GetId() int32 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+type GetMatchResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Match         *Match                 `protobuf:"bytes,1,opt,name=match,proto3" json:"match,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetMatchResponse) Reset() {
+	*x = GetMatchResponse{}
+	mi := &file_match_v1_match_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetMatchResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetMatchResponse) ProtoMessage() {}
+
+func (x *GetMatchResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_match_v1_match_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetMatchResponse.ProtoReflect.Descriptor instead.
+func (*GetMatchResponse) Descriptor() ([]byte, []int) {
+	return file_match_v1_match_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *GetMatchResponse) GetMatch() *Match {
+	if x != nil {
+		return x.Match
+	}
+	return nil
+}
+
+type CreateMatchRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	HomeTeam      string                 `protobuf:"bytes,1,opt,name=home_team,json=homeTeam,proto3" json:"home_team,omitempty"`
+	AwayTeam      string                 `protobuf:"bytes,2,opt,name=away_team,json=awayTeam,proto3" json:"away_team,omitempty"`
+	Kickoff       *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=kickoff,proto3" json:"kickoff,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateMatchRequest) Reset() {
+	*x = CreateMatchRequest{}
+	mi := &file_match_v1_match_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateMatchRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateMatchRequest) ProtoMessage() {}
+
+func (x *CreateMatchRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_match_v1_match_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateMatchRequest.ProtoReflect.Descriptor instead.
+func (*CreateMatchRequest) Descriptor() ([]byte, []int) {
+	return file_match_v1_match_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *CreateMatchRequest) GetHomeTeam() string {
+	if x != nil {
+		return x.HomeTeam
+	}
+	return ""
+}
+
+func (x *CreateMatchRequest) GetAwayTeam() string {
+	if x != nil {
+		return x.AwayTeam
+	}
+	return ""
+}
+
+func (x *CreateMatchRequest) GetKickoff() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Kickoff
+	}
+	return nil
+}
+
+type CreateMatchResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Match         *Match                 `protobuf:"bytes,1,opt,name=match,proto3" json:"match,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateMatchResponse) Reset() {
+	*x = CreateMatchResponse{}
+	mi := &file_match_v1_match_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateMatchResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateMatchResponse) ProtoMessage() {}
+
+func (x *CreateMatchResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_match_v1_match_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateMatchResponse.ProtoReflect.Descriptor instead.
+func (*CreateMatchResponse) Descriptor() ([]byte, []int) {
+	return file_match_v1_match_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *CreateMatchResponse) GetMatch() *Match {
+	if x != nil {
+		return x.Match
+	}
+	return nil
+}
+
+type UpdateMatchRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int32                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Version       int32                  `protobuf:"varint,2,opt,name=version,proto3" json:"version,omitempty"`
+	HomeTeam      string                 `protobuf:"bytes,3,opt,name=home_team,json=homeTeam,proto3" json:"home_team,omitempty"`
+	AwayTeam      string                 `protobuf:"bytes,4,opt,name=away_team,json=awayTeam,proto3" json:"away_team,omitempty"`
+	Kickoff       *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=kickoff,proto3" json:"kickoff,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateMatchRequest) Reset() {
+	*x = UpdateMatchRequest{}
+	mi := &file_match_v1_match_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateMatchRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateMatchRequest) ProtoMessage() {}
+
+func (x *UpdateMatchRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_match_v1_match_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateMatchRequest.ProtoReflect.Descriptor instead.
+func (*UpdateMatchRequest) Descriptor() ([]byte, []int) {
+	return file_match_v1_match_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *UpdateMatchRequest) GetId() int32 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *UpdateMatchRequest) GetVersion() int32 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+func (x *UpdateMatchRequest) GetHomeTeam() string {
+	if x != nil {
+		return x.HomeTeam
+	}
+	return ""
+}
+
+func (x *UpdateMatchRequest) GetAwayTeam() string {
+	if x != nil {
+		return x.AwayTeam
+	}
+	return ""
+}
+
+func (x *UpdateMatchRequest) GetKickoff() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Kickoff
+	}
+	return nil
+}
+
+type UpdateMatchResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Match         *Match                 `protobuf:"bytes,1,opt,name=match,proto3" json:"match,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateMatchResponse) Reset() {
+	*x = UpdateMatchResponse{}
+	mi := &file_match_v1_match_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateMatchResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateMatchResponse) ProtoMessage() {}
+
+func (x *UpdateMatchResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_match_v1_match_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateMatchResponse.ProtoReflect.Descriptor instead.
+func (*UpdateMatchResponse) Descriptor() ([]byte, []int) {
+	return file_match_v1_match_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *UpdateMatchResponse) GetMatch() *Match {
+	if x != nil {
+		return x.Match
+	}
+	return nil
+}
+
+type DeleteMatchRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int32                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Version       int32                  `protobuf:"varint,2,opt,name=version,proto3" json:"version,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteMatchRequest) Reset() {
+	*x = DeleteMatchRequest{}
+	mi := &file_match_v1_match_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteMatchRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteMatchRequest) ProtoMessage() {}
+
+func (x *DeleteMatchRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_match_v1_match_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteMatchRequest.ProtoReflect.Descriptor instead.
+func (*DeleteMatchRequest) Descriptor() ([]byte, []int) {
+	return file_match_v1_match_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *DeleteMatchRequest) GetId() int32 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *DeleteMatchRequest) GetVersion() int32 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+type DeleteMatchResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteMatchResponse) Reset() {
+	*x = DeleteMatchResponse{}
+	mi := &file_match_v1_match_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteMatchResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteMatchResponse) ProtoMessage() {}
+
+func (x *DeleteMatchResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_match_v1_match_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteMatchResponse.ProtoReflect.Descriptor instead.
+func (*DeleteMatchResponse) Descriptor() ([]byte, []int) {
+	return file_match_v1_match_proto_rawDescGZIP(), []int{12}
+}
+
+type RecordStatRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int32                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Version       int32                  `protobuf:"varint,2,opt,name=version,proto3" json:"version,omitempty"`
+	Stat          Stat                   `protobuf:"varint,3,opt,name=stat,proto3,enum=match.v1.Stat" json:"stat,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RecordStatRequest) Reset() {
+	*x = RecordStatRequest{}
+	mi := &file_match_v1_match_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RecordStatRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RecordStatRequest) ProtoMessage() {}
+
+func (x *RecordStatRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_match_v1_match_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RecordStatRequest.ProtoReflect.Descriptor instead.
+func (*RecordStatRequest) Descriptor() ([]byte, []int) {
+	return file_match_v1_match_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *RecordStatRequest) GetId() int32 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *RecordStatRequest) GetVersion() int32 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+func (x *RecordStatRequest) GetStat() Stat {
+	if x != nil {
+		return x.Stat
+	}
+	return Stat_STAT_UNSPECIFIED
+}
+
+type RecordStatResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Match         *Match                 `protobuf:"bytes,1,opt,name=match,proto3" json:"match,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RecordStatResponse) Reset() {
+	*x = RecordStatResponse{}
+	mi := &file_match_v1_match_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RecordStatResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RecordStatResponse) ProtoMessage() {}
+
+func (x *RecordStatResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_match_v1_match_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RecordStatResponse.ProtoReflect.Descriptor instead.
+func (*RecordStatResponse) Descriptor() ([]byte, []int) {
+	return file_match_v1_match_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *RecordStatResponse) GetMatch() *Match {
+	if x != nil {
+		return x.Match
+	}
+	return nil
+}
+
+type FinishMatchRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int32                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Version       int32                  `protobuf:"varint,2,opt,name=version,proto3" json:"version,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FinishMatchRequest) Reset() {
+	*x = FinishMatchRequest{}
+	mi := &file_match_v1_match_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FinishMatchRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FinishMatchRequest) ProtoMessage() {}
+
+func (x *FinishMatchRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_match_v1_match_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FinishMatchRequest.ProtoReflect.Descriptor instead.
+func (*FinishMatchRequest) Descriptor() ([]byte, []int) {
+	return file_match_v1_match_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *FinishMatchRequest) GetId() int32 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *FinishMatchRequest) GetVersion() int32 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+type FinishMatchResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Match         *Match                 `protobuf:"bytes,1,opt,name=match,proto3" json:"match,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FinishMatchResponse) Reset() {
+	*x = FinishMatchResponse{}
+	mi := &file_match_v1_match_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FinishMatchResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FinishMatchResponse) ProtoMessage() {}
+
+func (x *FinishMatchResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_match_v1_match_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FinishMatchResponse.ProtoReflect.Descriptor instead.
+func (*FinishMatchResponse) Descriptor() ([]byte, []int) {
+	return file_match_v1_match_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *FinishMatchResponse) GetMatch() *Match {
+	if x != nil {
+		return x.Match
+	}
+	return nil
+}
+
+type WatchMatchRequest struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	MatchId int32                  `protobuf:"varint,1,opt,name=match_id,json=matchId,proto3" json:"match_id,omitempty"`
+	// after_event_id reanuda el stream después de ese evento, como Last-Event-ID en SSE.
+	// Si es 0 solo se envían los eventos posteriores a la llamada.
+	AfterEventId  int64 `protobuf:"varint,2,opt,name=after_event_id,json=afterEventId,proto3" json:"after_event_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WatchMatchRequest) Reset() {
+	*x = WatchMatchRequest{}
+	mi := &file_match_v1_match_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchMatchRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchMatchRequest) ProtoMessage() {}
+
+func (x *WatchMatchRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_match_v1_match_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchMatchRequest.ProtoReflect.Descriptor instead.
+func (*WatchMatchRequest) Descriptor() ([]byte, []int) {
+	return file_match_v1_match_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *WatchMatchRequest) GetMatchId() int32 {
+	if x != nil {
+		return x.MatchId
+	}
+	return 0
+}
+
+func (x *WatchMatchRequest) GetAfterEventId() int64 {
+	if x != nil {
+		return x.AfterEventId
+	}
+	return 0
+}
+
+// WatchMatchResponse es un evento nuevo junto con el estado del partido después de aplicarlo.
+type WatchMatchResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Event         *MatchEvent            `protobuf:"bytes,1,opt,name=event,proto3" json:"event,omitempty"`
+	Match         *Match                 `protobuf:"bytes,2,opt,name=match,proto3" json:"match,omitempty"`
+	Deleted       bool                   `protobuf:"varint,3,opt,name=deleted,proto3" json:"deleted,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WatchMatchResponse) Reset() {
+	*x = WatchMatchResponse{}
+	mi := &file_match_v1_match_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchMatchResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchMatchResponse) ProtoMessage() {}
+
+func (x *WatchMatchResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_match_v1_match_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchMatchResponse.ProtoReflect.Descriptor instead.
+func (*WatchMatchResponse) Descriptor() ([]byte, []int) {
+	return file_match_v1_match_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *WatchMatchResponse) GetEvent() *MatchEvent {
+	if x != nil {
+		return x.Event
+	}
+	return nil
+}
+
+func (x *WatchMatchResponse) GetMatch() *Match {
+	if x != nil {
+		return x.Match
+	}
+	return nil
+}
+
+func (x *WatchMatchResponse) GetDeleted() bool {
+	if x != nil {
+		return x.Deleted
+	}
+	return false
+}
+
+var File_match_v1_match_proto protoreflect.FileDescriptor
+
+const file_match_v1_match_proto_rawDesc = "" +
+	"\n" +
+	"\x14match/v1/match.proto\x12\bmatch.v1\x1a\x1fgoogle/protobuf/timestamp.proto\"\xb2\x02\n" +
+	"\x05Match\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x05R\x02id\x12\x18\n" +
+	"\aversion\x18\x02 \x01(\x05R\aversion\x12\x1b\n" +
+	"\thome_team\x18\x03 \x01(\tR\bhomeTeam\x12\x1b\n" +
+	"\taway_team\x18\x04 \x01(\tR\bawayTeam\x124\n" +
+	"\akickoff\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\akickoff\x12\x14\n" +
+	"\x05goals\x18\x06 \x01(\x05R\x05goals\x12!\n" +
+	"\fyellow_cards\x18\a \x01(\x05R\vyellowCards\x12\x1b\n" +
+	"\tred_cards\x18\b \x01(\x05R\bredCards\x12\x1d\n" +
+	"\n" +
+	"extra_time\x18\t \x01(\bR\textraTime\x12\x1a\n" +
+	"\bfinished\x18\n" +
+	" \x01(\bR\bfinished\"A\n" +
+	"\vClockMinute\x12\x16\n" +
+	"\x06minute\x18\x01 \x01(\x05R\x06minute\x12\x1a\n" +
+	"\bstoppage\x18\x02 \x01(\x05R\bstoppage\"\xee\x01\n" +
+	"\n" +
+	"MatchEvent\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x19\n" +
+	"\bmatch_id\x18\x02 \x01(\x05R\amatchId\x12\x1a\n" +
+	"\bsequence\x18\x03 \x01(\x05R\bsequence\x12\x12\n" +
+	"\x04type\x18\x04 \x01(\tR\x04type\x12\x1b\n" +
+	"\tdata_json\x18\x05 \x01(\tR\bdataJson\x12;\n" +
+	"\voccurred_at\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\n" +
+	"occurredAt\x12+\n" +
+	"\x05clock\x18\a \x01(\v2\x15.match.v1.ClockMinuteR\x05clock\"\x84\x01\n" +
+	"\x12ListMatchesRequest\x12\x12\n" +
+	"\x04team\x18\x01 \x01(\tR\x04team\x12.\n" +
+	"\x04from\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\x04from\x12*\n" +
+	"\x02to\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\x02to\"@\n" +
+	"\x13ListMatchesResponse\x12)\n" +
+	"\amatches\x18\x01 \x03(\v2\x0f.match.v1.MatchR\amatches\"!\n" +
+	"\x0fGetMatchRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x05R\x02id\"9\n" +
+	"\x10GetMatchResponse\x12%\n" +
+	"\x05match\x18\x01 \x01(\v2\x0f.match.v1.MatchR\x05match\"\x84\x01\n" +
+	"\x12CreateMatchRequest\x12\x1b\n" +
+	"\thome_team\x18\x01 \x01(\tR\bhomeTeam\x12\x1b\n" +
+	"\taway_team\x18\x02 \x01(\tR\bawayTeam\x124\n" +
+	"\akickoff\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\akickoff\"<\n" +
+	"\x13CreateMatchResponse\x12%\n" +
+	"\x05match\x18\x01 \x01(\v2\x0f.match.v1.MatchR\x05match\"\xae\x01\n" +
+	"\x12UpdateMatchRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x05R\x02id\x12\x18\n" +
+	"\aversion\x18\x02 \x01(\x05R\aversion\x12\x1b\n" +
+	"\thome_team\x18\x03 \x01(\tR\bhomeTeam\x12\x1b\n" +
+	"\taway_team\x18\x04 \x01(\tR\bawayTeam\x124\n" +
+	"\akickoff\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\akickoff\"<\n" +
+	"\x13UpdateMatchResponse\x12%\n" +
+	"\x05match\x18\x01 \x01(\v2\x0f.match.v1.MatchR\x05match\">\n" +
+	"\x12DeleteMatchRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x05R\x02id\x12\x18\n" +
+	"\aversion\x18\x02 \x01(\x05R\aversion\"\x15\n" +
+	"\x13DeleteMatchResponse\"a\n" +
+	"\x11RecordStatRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x05R\x02id\x12\x18\n" +
+	"\aversion\x18\x02 \x01(\x05R\aversion\x12\"\n" +
+	"\x04stat\x18\x03 \x01(\x0e2\x0e.match.v1.StatR\x04stat\";\n" +
+	"\x12RecordStatResponse\x12%\n" +
+	"\x05match\x18\x01 \x01(\v2\x0f.match.v1.MatchR\x05match\">\n" +
+	"\x12FinishMatchRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x05R\x02id\x12\x18\n" +
+	"\aversion\x18\x02 \x01(\x05R\aversion\"<\n" +
+	"\x13FinishMatchResponse\x12%\n" +
+	"\x05match\x18\x01 \x01(\v2\x0f.match.v1.MatchR\x05match\"T\n" +
+	"\x11WatchMatchRequest\x12\x19\n" +
+	"\bmatch_id\x18\x01 \x01(\x05R\amatchId\x12$\n" +
+	"\x0eafter_event_id\x18\x02 \x01(\x03R\fafterEventId\"\x81\x01\n" +
+	"\x12WatchMatchResponse\x12*\n" +
+	"\x05event\x18\x01 \x01(\v2\x14.match.v1.MatchEventR\x05event\x12%\n" +
+	"\x05match\x18\x02 \x01(\v2\x0f.match.v1.MatchR\x05match\x12\x18\n" +
+	"\adeleted\x18\x03 \x01(\bR\adeleted*l\n" +
+	"\x04Stat\x12\x14\n" +
+	"\x10STAT_UNSPECIFIED\x10\x00\x12\x0e\n" +
+	"\n" +
+	"STAT_GOALS\x10\x01\x12\x15\n" +
+	"\x11STAT_YELLOW_CARDS\x10\x02\x12\x12\n" +
+	"\x0eSTAT_RED_CARDS\x10\x03\x12\x13\n" +
+	"\x0fSTAT_EXTRA_TIME\x10\x042\xe1\x04\n" +
+	"\fMatchService\x12J\n" +
+	"\vListMatches\x12\x1c.match.v1.ListMatchesRequest\x1a\x1d.match.v1.ListMatchesResponse\x12A\n" +
+	"\bGetMatch\x12\x19.match.v1.GetMatchRequest\x1a\x1a.match.v1.GetMatchResponse\x12J\n" +
+	"\vCreateMatch\x12\x1c.match.v1.CreateMatchRequest\x1a\x1d.match.v1.CreateMatchResponse\x12J\n" +
+	"\vUpdateMatch\x12\x1c.match.v1.UpdateMatchRequest\x1a\x1d.match.v1.UpdateMatchResponse\x12J\n" +
+	"\vDeleteMatch\x12\x1c.match.v1.DeleteMatchRequest\x1a\x1d.match.v1.DeleteMatchResponse\x12G\n" +
+	"\n" +
+	"RecordStat\x12\x1b.match.v1.RecordStatRequest\x1a\x1c.match.v1.RecordStatResponse\x12J\n" +
+	"\vFinishMatch\x12\x1c.match.v1.FinishMatchRequest\x1a\x1d.match.v1.FinishMatchResponse\x12I\n" +
+	"\n" +
+	"WatchMatch\x12\x1b.match.v1.WatchMatchRequest\x1a\x1c.match.v1.WatchMatchResponse0\x01B\x1dZ\x1blab6/proto/match/v1;matchv1b\x06proto3"
+
+var (
+	file_match_v1_match_proto_rawDescOnce sync.Once
+	file_match_v1_match_proto_rawDescData []byte
+)
+
+func file_match_v1_match_proto_rawDescGZIP() []byte {
+	file_match_v1_match_proto_rawDescOnce.Do(func() {
+		file_match_v1_match_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_match_v1_match_proto_rawDesc), len(file_match_v1_match_proto_rawDesc)))
+	})
+	return file_match_v1_match_proto_rawDescData
+}
+
+var file_match_v1_match_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_match_v1_match_proto_msgTypes = make([]protoimpl.MessageInfo, 19)
+var file_match_v1_match_proto_goTypes = []any{
+	(Stat)(0),                     // 0: match.v1.Stat
+	(*Match)(nil),                 // 1: match.v1.Match
+	(*ClockMinute)(nil),           // 2: match.v1.ClockMinute
+	(*MatchEvent)(nil),            // 3: match.v1.MatchEvent
+	(*ListMatchesRequest)(nil),    // 4: match.v1.ListMatchesRequest
+	(*ListMatchesResponse)(nil),   // 5: match.v1.ListMatchesResponse
+	(*GetMatchRequest)(nil),       // 6: match.v1.GetMatchRequest
+	(*GetMatchResponse)(nil),      // 7: match.v1.GetMatchResponse
+	(*CreateMatchRequest)(nil),    // 8: match.v1.CreateMatchRequest
+	(*CreateMatchResponse)(nil),   // 9: match.v1.CreateMatchResponse
+	(*UpdateMatchRequest)(nil),    // 10: match.v1.UpdateMatchRequest
+	(*UpdateMatchResponse)(nil),   // 11: match.v1.UpdateMatchResponse
+	(*DeleteMatchRequest)(nil),    // 12: match.v1.DeleteMatchRequest
+	(*DeleteMatchResponse)(nil),   // 13: match.v1.DeleteMatchResponse
+	(*RecordStatRequest)(nil),     // 14: match.v1.RecordStatRequest
+	(*RecordStatResponse)(nil),    // 15: match.v1.RecordStatResponse
+	(*FinishMatchRequest)(nil),    // 16: match.v1.FinishMatchRequest
+	(*FinishMatchResponse)(nil),   // 17: match.v1.FinishMatchResponse
+	(*WatchMatchRequest)(nil),     // 18: match.v1.WatchMatchRequest
+	(*WatchMatchResponse)(nil),    // 19: match.v1.WatchMatchResponse
+	(*timestamppb.Timestamp)(nil), // 20: google.protobuf.Timestamp
+}
+var file_match_v1_match_proto_depIdxs = []int32{
+	20, // 0: match.v1.Match.kickoff:type_name -> google.protobuf.Timestamp
+	20, // 1: match.v1.MatchEvent.occurred_at:type_name -> google.protobuf.Timestamp
+	2,  // 2: match.v1.MatchEvent.clock:type_name -> match.v1.ClockMinute
+	20, // 3: match.v1.ListMatchesRequest.from:type_name -> google.protobuf.Timestamp
+	20, // 4: match.v1.ListMatchesRequest.to:type_name -> google.protobuf.Timestamp
+	1,  // 5: match.v1.ListMatchesResponse.matches:type_name -> match.v1.Match
+	1,  // 6: match.v1.GetMatchResponse.match:type_name -> match.v1.Match
+	20, // 7: match.v1.CreateMatchRequest.kickoff:type_name -> google.protobuf.Timestamp
+	1,  // 8: match.v1.CreateMatchResponse.match:type_name -> match.v1.Match
+	20, // 9: match.v1.UpdateMatchRequest.kickoff:type_name -> google.protobuf.Timestamp
+	1,  // 10: match.v1.UpdateMatchResponse.match:type_name -> match.v1.Match
+	0,  // 11: match.v1.RecordStatRequest.stat:type_name -> match.v1.Stat
+	1,  // 12: match.v1.RecordStatResponse.match:type_name -> match.v1.Match
+	1,  // 13: match.v1.FinishMatchResponse.match:type_name -> match.v1.Match
+	3,  // 14: match.v1.WatchMatchResponse.event:type_name -> match.v1.MatchEvent
+	1,  // 15: match.v1.WatchMatchResponse.match:type_name -> match.v1.Match
+	4,  // 16: match.v1.MatchService.ListMatches:input_type -> match.v1.ListMatchesRequest
+	6,  // 17: match.v1.MatchService.GetMatch:input_type -> match.v1.GetMatchRequest
+	8,  // 18: match.v1.MatchService.CreateMatch:input_type -> match.v1.CreateMatchRequest
+	10, // 19: match.v1.MatchService.UpdateMatch:input_type -> match.v1.UpdateMatchRequest
+	12, // 20: match.v1.MatchService.DeleteMatch:input_type -> match.v1.DeleteMatchRequest
+	14, // 21: match.v1.MatchService.RecordStat:input_type -> match.v1.RecordStatRequest
+	16, // 22: match.v1.MatchService.FinishMatch:input_type -> match.v1.FinishMatchRequest
+	18, // 23: match.v1.MatchService.WatchMatch:input_type -> match.v1.WatchMatchRequest
+	5,  // 24: match.v1.MatchService.ListMatches:output_type -> match.v1.ListMatchesResponse
+	7,  // 25: match.v1.MatchService.GetMatch:output_type -> match.v1.GetMatchResponse
+	9,  // 26: match.v1.MatchService.CreateMatch:output_type -> match.v1.CreateMatchResponse
+	11, // 27: match.v1.MatchService.UpdateMatch:output_type -> match.v1.UpdateMatchResponse
+	13, // 28: match.v1.MatchService.DeleteMatch:output_type -> match.v1.DeleteMatchResponse
+	15, // 29: match.v1.MatchService.RecordStat:output_type -> match.v1.RecordStatResponse
+	17, // 30: match.v1.MatchService.FinishMatch:output_type -> match.v1.FinishMatchResponse
+	19, // 31: match.v1.MatchService.WatchMatch:output_type -> match.v1.WatchMatchResponse
+	24, // [24:32] is the sub-list for method output_type
+	16, // [16:24] is the sub-list for method input_type
+	16, // [16:16] is the sub-list for extension type_name
+	16, // [16:16] is the sub-list for extension extendee
+	0,  // [0:16] is the sub-list for field type_name
+}
+
+func init() { file_match_v1_match_proto_init() }
+func file_match_v1_match_proto_init() {
+	if File_match_v1_match_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_match_v1_match_proto_rawDesc), len(file_match_v1_match_proto_rawDesc)),
+			NumEnums:      1,
+			NumMessages:   19,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_match_v1_match_proto_goTypes,
+		DependencyIndexes: file_match_v1_match_proto_depIdxs,
+		EnumInfos:         file_match_v1_match_proto_enumTypes,
+		MessageInfos:      file_match_v1_match_proto_msgTypes,
+	}.Build()
+	File_match_v1_match_proto = out.File
+	file_match_v1_match_proto_goTypes = nil
+	file_match_v1_match_proto_depIdxs = nil
+}
@@ -0,0 +1,426 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: match/v1/match.proto
+
+// MatchService expone por gRPC las mismas operaciones que la API REST sobre partidos.
+// El código Go se genera con `buf generate` (ver buf.gen.yaml) en este mismo directorio.
+
+package matchv1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	MatchService_ListMatches_FullMethodName = "/match.v1.MatchService/ListMatches"
+	MatchService_GetMatch_FullMethodName    = "/match.v1.MatchService/GetMatch"
+	MatchService_CreateMatch_FullMethodName = "/match.v1.MatchService/CreateMatch"
+	MatchService_UpdateMatch_FullMethodName = "/match.v1.MatchService/UpdateMatch"
+	MatchService_DeleteMatch_FullMethodName = "/match.v1.MatchService/DeleteMatch"
+	MatchService_RecordStat_FullMethodName  = "/match.v1.MatchService/RecordStat"
+	MatchService_FinishMatch_FullMethodName = "/match.v1.MatchService/FinishMatch"
+	MatchService_WatchMatch_FullMethodName  = "/match.v1.MatchService/WatchMatch"
+)
+
+// MatchServiceClient is the client API for MatchService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// MatchService administra los partidos de La Liga. Las escrituras reciben la versión del
+// partido leída por el cliente, como If-Match en REST: si ya no es la actual fallan con
+// ABORTED. Los errores incluyen un google.rpc.ErrorInfo cuyo reason es el código estable
+// del catálogo (por ejemplo MATCH_NOT_FOUND). Los metadatos accept-language, x-actor y
+// x-request-id cumplen la misma función que los encabezados HTTP homónimos.
+type MatchServiceClient interface {
+	// ListMatches lista los partidos ordenados por fecha.
+	ListMatches(ctx context.Context, in *ListMatchesRequest, opts ...grpc.CallOption) (*ListMatchesResponse, error)
+	// GetMatch obtiene un partido. Falla con NOT_FOUND si no existe o está en la papelera.
+	GetMatch(ctx context.Context, in *GetMatchRequest, opts ...grpc.CallOption) (*GetMatchResponse, error)
+	// CreateMatch crea un partido. Falla con INVALID_ARGUMENT si un equipo no se reconoce.
+	CreateMatch(ctx context.Context, in *CreateMatchRequest, opts ...grpc.CallOption) (*CreateMatchResponse, error)
+	// UpdateMatch reprograma un partido.
+	UpdateMatch(ctx context.Context, in *UpdateMatchRequest, opts ...grpc.CallOption) (*UpdateMatchResponse, error)
+	// DeleteMatch envía un partido a la papelera.
+	DeleteMatch(ctx context.Context, in *DeleteMatchRequest, opts ...grpc.CallOption) (*DeleteMatchResponse, error)
+	// RecordStat registra un gol, una tarjeta o el inicio del tiempo extra. Falla con
+	// FAILED_PRECONDITION si el partido ya finalizó.
+	RecordStat(ctx context.Context, in *RecordStatRequest, opts ...grpc.CallOption) (*RecordStatResponse, error)
+	// FinishMatch registra el fin del partido.
+	FinishMatch(ctx context.Context, in *FinishMatchRequest, opts ...grpc.CallOption) (*FinishMatchResponse, error)
+	// WatchMatch envía cada evento nuevo del partido junto con el partido resultante, hasta
+	// que el cliente cancela la llamada.
+	WatchMatch(ctx context.Context, in *WatchMatchRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[WatchMatchResponse], error)
+}
+
+type matchServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewMatchServiceClient(cc grpc.ClientConnInterface) MatchServiceClient {
+	return &matchServiceClient{cc}
+}
+
+func (c *matchServiceClient) ListMatches(ctx context.Context, in *ListMatchesRequest, opts ...grpc.CallOption) (*ListMatchesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListMatchesResponse)
+	err := c.cc.Invoke(ctx, MatchService_ListMatches_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *matchServiceClient) GetMatch(ctx context.Context, in *GetMatchRequest, opts ...grpc.CallOption) (*GetMatchResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetMatchResponse)
+	err := c.cc.Invoke(ctx, MatchService_GetMatch_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *matchServiceClient) CreateMatch(ctx context.Context, in *CreateMatchRequest, opts ...grpc.CallOption) (*CreateMatchResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CreateMatchResponse)
+	err := c.cc.Invoke(ctx, MatchService_CreateMatch_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *matchServiceClient) UpdateMatch(ctx context.Context, in *UpdateMatchRequest, opts ...grpc.CallOption) (*UpdateMatchResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(UpdateMatchResponse)
+	err := c.cc.Invoke(ctx, MatchService_UpdateMatch_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *matchServiceClient) DeleteMatch(ctx context.Context, in *DeleteMatchRequest, opts ...grpc.CallOption) (*DeleteMatchResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeleteMatchResponse)
+	err := c.cc.Invoke(ctx, MatchService_DeleteMatch_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *matchServiceClient) RecordStat(ctx context.Context, in *RecordStatRequest, opts ...grpc.CallOption) (*RecordStatResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RecordStatResponse)
+	err := c.cc.Invoke(ctx, MatchService_RecordStat_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *matchServiceClient) FinishMatch(ctx context.Context, in *FinishMatchRequest, opts ...grpc.CallOption) (*FinishMatchResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(FinishMatchResponse)
+	err := c.cc.Invoke(ctx, MatchService_FinishMatch_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *matchServiceClient) WatchMatch(ctx context.Context, in *WatchMatchRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[WatchMatchResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &MatchService_ServiceDesc.Streams[0], MatchService_WatchMatch_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[WatchMatchRequest, WatchMatchResponse]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type MatchService_WatchMatchClient = grpc.ServerStreamingClient[WatchMatchResponse]
+
+// MatchServiceServer is the server API for MatchService service.
+// All implementations must embed UnimplementedMatchServiceServer
+// for forward compatibility.
+//
+// MatchService administra los partidos de La Liga. Las escrituras reciben la versión del
+// partido leída por el cliente, como If-Match en REST: si ya no es la actual fallan con
+// ABORTED. Los errores incluyen un google.rpc.ErrorInfo cuyo reason es el código estable
+// del catálogo (por ejemplo MATCH_NOT_FOUND). Los metadatos accept-language, x-actor y
+// x-request-id cumplen la misma función que los encabezados HTTP homónimos.
+type MatchServiceServer interface {
+	// ListMatches lista los partidos ordenados por fecha.
+	ListMatches(context.Context, *ListMatchesRequest) (*ListMatchesResponse, error)
+	// GetMatch obtiene un partido. Falla con NOT_FOUND si no existe o está en la papelera.
+	GetMatch(context.Context, *GetMatchRequest) (*GetMatchResponse, error)
+	// CreateMatch crea un partido. Falla con INVALID_ARGUMENT si un equipo no se reconoce.
+	CreateMatch(context.Context, *CreateMatchRequest) (*CreateMatchResponse, error)
+	// UpdateMatch reprograma un partido.
+	UpdateMatch(context.Context, *UpdateMatchRequest) (*UpdateMatchResponse, error)
+	// DeleteMatch envía un partido a la papelera.
+	DeleteMatch(context.Context, *DeleteMatchRequest) (*DeleteMatchResponse, error)
+	// RecordStat registra un gol, una tarjeta o el inicio del tiempo extra. Falla con
+	// FAILED_PRECONDITION si el partido ya finalizó.
+	RecordStat(context.Context, *RecordStatRequest) (*RecordStatResponse, error)
+	// FinishMatch registra el fin del partido.
+	FinishMatch(context.Context, *FinishMatchRequest) (*FinishMatchResponse, error)
+	// WatchMatch envía cada evento nuevo del partido junto con el partido resultante, hasta
+	// que el cliente cancela la llamada.
+	WatchMatch(*WatchMatchRequest, grpc.ServerStreamingServer[WatchMatchResponse]) error
+	mustEmbedUnimplementedMatchServiceServer()
+}
+
+// UnimplementedMatchServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedMatchServiceServer struct{}
+
+func (UnimplementedMatchServiceServer) ListMatches(context.Context, *ListMatchesRequest) (*ListMatchesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListMatches not implemented")
+}
+func (UnimplementedMatchServiceServer) GetMatch(context.Context, *GetMatchRequest) (*GetMatchResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetMatch not implemented")
+}
+func (UnimplementedMatchServiceServer) CreateMatch(context.Context, *CreateMatchRequest) (*CreateMatchResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateMatch not implemented")
+}
+func (UnimplementedMatchServiceServer) UpdateMatch(context.Context, *UpdateMatchRequest) (*UpdateMatchResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateMatch not implemented")
+}
+func (UnimplementedMatchServiceServer) DeleteMatch(context.Context, *DeleteMatchRequest) (*DeleteMatchResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteMatch not implemented")
+}
+func (UnimplementedMatchServiceServer) RecordStat(context.Context, *RecordStatRequest) (*RecordStatResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RecordStat not implemented")
+}
+func (UnimplementedMatchServiceServer) FinishMatch(context.Context, *FinishMatchRequest) (*FinishMatchResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method FinishMatch not implemented")
+}
+func (UnimplementedMatchServiceServer) WatchMatch(*WatchMatchRequest, grpc.ServerStreamingServer[WatchMatchResponse]) error {
+	return status.Errorf(codes.Unimplemented, "method WatchMatch not implemented")
+}
+func (UnimplementedMatchServiceServer) mustEmbedUnimplementedMatchServiceServer() {}
+func (UnimplementedMatchServiceServer) testEmbeddedByValue()                      {}
+
+// UnsafeMatchServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to MatchServiceServer will
+// result in compilation errors.
+type UnsafeMatchServiceServer interface {
+	mustEmbedUnimplementedMatchServiceServer()
+}
+
+func RegisterMatchServiceServer(s grpc.ServiceRegistrar, srv MatchServiceServer) {
+	// If the following call pancis, it indicates UnimplementedMatchServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&MatchService_ServiceDesc, srv)
+}
+
+func _MatchService_ListMatches_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListMatchesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MatchServiceServer).ListMatches(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MatchService_ListMatches_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MatchServiceServer).ListMatches(ctx, req.(*ListMatchesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MatchService_GetMatch_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetMatchRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MatchServiceServer).GetMatch(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MatchService_GetMatch_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MatchServiceServer).GetMatch(ctx, req.(*GetMatchRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MatchService_CreateMatch_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateMatchRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MatchServiceServer).CreateMatch(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MatchService_CreateMatch_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MatchServiceServer).CreateMatch(ctx, req.(*CreateMatchRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MatchService_UpdateMatch_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateMatchRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MatchServiceServer).UpdateMatch(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MatchService_UpdateMatch_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MatchServiceServer).UpdateMatch(ctx, req.(*UpdateMatchRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MatchService_DeleteMatch_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteMatchRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MatchServiceServer).DeleteMatch(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MatchService_DeleteMatch_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MatchServiceServer).DeleteMatch(ctx, req.(*DeleteMatchRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MatchService_RecordStat_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RecordStatRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MatchServiceServer).RecordStat(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MatchService_RecordStat_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MatchServiceServer).RecordStat(ctx, req.(*RecordStatRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MatchService_FinishMatch_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FinishMatchRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MatchServiceServer).FinishMatch(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MatchService_FinishMatch_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MatchServiceServer).FinishMatch(ctx, req.(*FinishMatchRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MatchService_WatchMatch_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchMatchRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(MatchServiceServer).WatchMatch(m, &grpc.GenericServerStream[WatchMatchRequest, WatchMatchResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type MatchService_WatchMatchServer = grpc.ServerStreamingServer[WatchMatchResponse]
+
+// MatchService_ServiceDesc is the grpc.ServiceDesc for MatchService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var MatchService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "match.v1.MatchService",
+	HandlerType: (*MatchServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ListMatches",
+			Handler:    _MatchService_ListMatches_Handler,
+		},
+		{
+			MethodName: "GetMatch",
+			Handler:    _MatchService_GetMatch_Handler,
+		},
+		{
+			MethodName: "CreateMatch",
+			Handler:    _MatchService_CreateMatch_Handler,
+		},
+		{
+			MethodName: "UpdateMatch",
+			Handler:    _MatchService_UpdateMatch_Handler,
+		},
+		{
+			MethodName: "DeleteMatch",
+			Handler:    _MatchService_DeleteMatch_Handler,
+		},
+		{
+			MethodName: "RecordStat",
+			Handler:    _MatchService_RecordStat_Handler,
+		},
+		{
+			MethodName: "FinishMatch",
+			Handler:    _MatchService_FinishMatch_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WatchMatch",
+			Handler:       _MatchService_WatchMatch_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "match/v1/match.proto",
+}